@@ -0,0 +1,170 @@
+package accounts
+
+import (
+	"errors"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type (
+	// Credentials is the request body, or the form, to log in or register
+	Credentials struct {
+		Username string `json:"username" schema:"username" validate:"required,max=64"`
+		Password string `json:"password" schema:"password" validate:"required,max=1024"`
+		// Next is the page to go to once logged in from the login page
+		Next string `json:"-" schema:"next"`
+		// CSRFToken is checked by auth.CSRFMiddleware, it is read here only to be allowed in the form
+		CSRFToken string `json:"-" schema:"csrf_token"`
+	}
+
+	// Account is a user as shown to themselves, without the password hash
+	Account struct {
+		ID        int64     `json:"id"`
+		Username  string    `json:"username"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+)
+
+func newAccount(user *models.User) *Account {
+	return &Account{
+		ID:        user.ID,
+		Username:  user.Username,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+// LoginHandler logs in with a username and password, setting the session cookie
+func LoginHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	authn *auth.Authenticator,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[Credentials](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		user, err := authn.Login(ctx, w, body.Username, body.Password)
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusUnauthorized).
+				WithDetail(err.Error()).
+				Build())
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to log in", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, newAccount(user))
+	}
+}
+
+// RegisterHandler creates an account and logs in with it. The first account can always be
+// created, further ones only when registration is open.
+func RegisterHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	authn *auth.Authenticator,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[Credentials](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		user, err := authn.Register(ctx, body.Username, body.Password)
+		if problem, ok := registrationProblem(err); ok {
+			jw.WriteProblem(ctx, r, w, problem)
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to register user", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		err = authn.StartSession(ctx, w, user.ID)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to start session", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Write(ctx, w, http.StatusCreated, newAccount(user))
+	}
+}
+
+// LogoutHandler ends the session of the request
+func LogoutHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	authn *auth.Authenticator,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := authn.Logout(ctx, w, r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to log out", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// MeHandler returns the logged-in user
+func MeHandler(jw *response.JSONWriter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jw.Ok(r.Context(), w, newAccount(auth.User(r.Context())))
+	}
+}
+
+// registrationProblem returns the problem response of a registration that was refused
+func registrationProblem(err error) (response.Problem, bool) {
+	var status int
+
+	switch {
+	case errors.Is(err, db.ErrRegistrationClosed):
+		status = http.StatusForbidden
+	case errors.Is(err, db.ErrUsernameTaken):
+		status = http.StatusConflict
+	case errors.Is(err, auth.ErrWeakPassword):
+		status = http.StatusUnprocessableEntity
+	default:
+		return nil, false
+	}
+
+	return response.NewProblem().
+		WithStatus(status).
+		WithDetail(err.Error()).
+		Build(), true
+}
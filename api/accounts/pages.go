@@ -0,0 +1,177 @@
+package accounts
+
+import (
+	"errors"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/web"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// loginPage is what the login page shows
+type loginPage struct {
+	// Next is the page to go to once logged in
+	Next     string
+	Username string
+	Error    string
+}
+
+// LoginPageHandler renders the login page, along with the registration form while accounts can
+// be created
+func LoginPageHandler(
+	log *slog.Logger,
+	tr *web.Renderer,
+	authn *auth.Authenticator,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		renderLogin(log, tr, authn, w, r, http.StatusOK, loginPage{Next: next(r.URL.Query().Get("next"))})
+	}
+}
+
+// LoginFormHandler logs in with the login page's form and goes to the page the user was sent
+// to the login page from
+func LoginFormHandler(
+	log *slog.Logger,
+	reader *request.Reader,
+	tr *web.Renderer,
+	authn *auth.Authenticator,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[Credentials](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		form, err := typedReader.ReadAndValidateForm(r)
+		if err != nil {
+			renderLogin(log, tr, authn, w, r, http.StatusBadRequest, loginPage{
+				Next:  r.PostFormValue("next"),
+				Error: "Enter your username and password.",
+			})
+			return
+		}
+
+		_, err = authn.Login(ctx, w, form.Username, form.Password)
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			renderLogin(log, tr, authn, w, r, http.StatusUnauthorized, loginPage{
+				Next:     form.Next,
+				Username: form.Username,
+				Error:    "Wrong username or password.",
+			})
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to log in", logger.Error(err))
+			http.Error(w, "Failed to log in", http.StatusInternalServerError)
+			return
+		}
+
+		http.Redirect(w, r, next(form.Next), http.StatusSeeOther)
+	}
+}
+
+// RegisterFormHandler creates an account with the login page's registration form and logs in
+// with it
+func RegisterFormHandler(
+	log *slog.Logger,
+	reader *request.Reader,
+	tr *web.Renderer,
+	authn *auth.Authenticator,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[Credentials](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		form, err := typedReader.ReadAndValidateForm(r)
+		if err != nil {
+			renderLogin(log, tr, authn, w, r, http.StatusBadRequest, loginPage{
+				Next:  r.PostFormValue("next"),
+				Error: "Choose a username and a password.",
+			})
+			return
+		}
+
+		user, err := authn.Register(ctx, form.Username, form.Password)
+		if problem, ok := registrationProblem(err); ok {
+			renderLogin(log, tr, authn, w, r, problem.Status(), loginPage{
+				Next:     form.Next,
+				Username: form.Username,
+				Error:    "Could not create the account: " + err.Error() + ".",
+			})
+			return
+		}
+
+		if err == nil {
+			err = authn.StartSession(ctx, w, user.ID)
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to register user", logger.Error(err))
+			http.Error(w, "Failed to create account", http.StatusInternalServerError)
+			return
+		}
+
+		http.Redirect(w, r, next(form.Next), http.StatusSeeOther)
+	}
+}
+
+// LogoutFormHandler logs out and goes to the login page
+func LogoutFormHandler(
+	log *slog.Logger,
+	authn *auth.Authenticator,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := authn.Logout(ctx, w, r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to log out", logger.Error(err))
+		}
+
+		http.Redirect(w, r, auth.LoginPath, http.StatusSeeOther)
+	}
+}
+
+func renderLogin(
+	log *slog.Logger,
+	tr *web.Renderer,
+	authn *auth.Authenticator,
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	page loginPage,
+) {
+	ctx := r.Context()
+
+	canRegister, err := authn.CanRegister(ctx)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to check registration", logger.Error(err))
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+
+	err = tr.Render(w, r, web.TemplateLogin, map[string]any{
+		"Next":        page.Next,
+		"Username":    page.Username,
+		"Error":       page.Error,
+		"CanRegister": canRegister,
+	})
+	if err != nil {
+		log.ErrorContext(ctx, "error rendering login template", logger.Error(err))
+	}
+}
+
+// next returns the page to go to once logged in: the page asked for when it is on this site,
+// the dashboard otherwise
+func next(page string) string {
+	if !strings.HasPrefix(page, "/") || strings.HasPrefix(page, "//") || strings.HasPrefix(page, "/\\") {
+		return "/"
+	}
+
+	return page
+}
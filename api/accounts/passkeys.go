@@ -0,0 +1,232 @@
+package accounts
+
+import (
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type (
+	// PasskeyQuery names the passkey being registered, as the body is the authenticator's response
+	PasskeyQuery struct {
+		Name string `schema:"name" validate:"required,max=64"`
+	}
+
+	// Passkey is a passkey as listed to its user, without its public key
+	Passkey struct {
+		ID         int64      `json:"id"`
+		Name       string     `json:"name"`
+		LastUsedAt *time.Time `json:"last_used_at"`
+		CreatedAt  time.Time  `json:"created_at"`
+	}
+)
+
+func newPasskey(record *models.Passkey) *Passkey {
+	return &Passkey{
+		ID:         record.ID,
+		Name:       record.Name,
+		LastUsedAt: record.LastUsedAt,
+		CreatedAt:  record.CreatedAt,
+	}
+}
+
+// BeginPasskeyRegistrationHandler returns the options to create a passkey of the user with
+func BeginPasskeyRegistrationHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	authn *auth.Authenticator,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		creation, err := authn.BeginPasskeyRegistration(ctx, w, auth.User(ctx))
+		if err != nil {
+			log.ErrorContext(ctx, "failed to begin passkey registration", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, creation)
+	}
+}
+
+// FinishPasskeyRegistrationHandler stores the passkey created by the authenticator, named by the
+// name query parameter
+func FinishPasskeyRegistrationHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	authn *auth.Authenticator,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[PasskeyQuery](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		query, err := typedReader.ReadAndValidateQueryParams(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse query params", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		passkey, err := authn.FinishPasskeyRegistration(ctx, w, r, auth.User(ctx), query.Name, r.Body)
+		if problem, ok := passkeyProblem(err); ok {
+			log.WarnContext(ctx, "passkey registration failed", logger.Error(err))
+			jw.WriteProblem(ctx, r, w, problem)
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to register passkey", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Write(ctx, w, http.StatusCreated, newPasskey(passkey))
+	}
+}
+
+// BeginPasskeyLoginHandler returns the options to log in with any passkey of this site with
+func BeginPasskeyLoginHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	authn *auth.Authenticator,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		assertion, err := authn.BeginPasskeyLogin(ctx, w)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to begin passkey login", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, assertion)
+	}
+}
+
+// FinishPasskeyLoginHandler logs in with the assertion of the authenticator, setting the session
+// cookie
+func FinishPasskeyLoginHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	authn *auth.Authenticator,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		user, err := authn.FinishPasskeyLogin(ctx, w, r, r.Body)
+		if problem, ok := passkeyProblem(err); ok {
+			log.WarnContext(ctx, "passkey login failed", logger.Error(err))
+			jw.WriteProblem(ctx, r, w, problem)
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to log in with passkey", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, newAccount(user))
+	}
+}
+
+// GetPasskeysHandler returns the passkeys of the user, most recent first
+func GetPasskeysHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type Response struct {
+		Passkeys []*Passkey `json:"passkeys"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		records, err := dbConn.Queries.GetUserPasskeys(ctx, auth.User(ctx).ID)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get passkeys", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		list := make([]*Passkey, 0, len(records))
+		for _, record := range records {
+			list = append(list, newPasskey(record))
+		}
+
+		jw.Ok(ctx, w, Response{Passkeys: list})
+	}
+}
+
+// DeletePasskeyHandler removes a passkey of the user, who can no longer log in with it
+func DeletePasskeyHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusBadRequest).
+				WithDetail("passkey id must be a number").
+				Build())
+			return
+		}
+
+		n, err := dbConn.Queries.DeleteUserPasskey(ctx, models.DeleteUserPasskeyParams{
+			ID:     id,
+			UserID: auth.User(ctx).ID,
+		})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to delete passkey", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if n == 0 {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusNotFound).
+				WithDetail("passkey not found").
+				Build())
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// passkeyProblem returns the problem response of a passkey registration or login that failed
+func passkeyProblem(err error) (response.Problem, bool) {
+	var status int
+
+	switch {
+	case errors.Is(err, auth.ErrCeremonyExpired):
+		status = http.StatusBadRequest
+	case errors.Is(err, auth.ErrPasskeyRejected):
+		status = http.StatusUnauthorized
+	default:
+		return nil, false
+	}
+
+	return response.NewProblem().
+		WithStatus(status).
+		WithDetail(err.Error()).
+		Build(), true
+}
@@ -0,0 +1,438 @@
+package drafts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"github.com/pushkar-anand/cardmax/internal/taxonomy"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type (
+	// ApproveRequest is the request body to approve or discard drafts
+	ApproveRequest struct {
+		IDs []int64 `json:"ids" schema:"ids" validate:"required,min=1,dive,min=1"`
+	}
+
+	// MergeRequest is the request body to merge duplicate drafts into one
+	MergeRequest struct {
+		IDs []int64 `json:"ids" schema:"ids" validate:"required,min=2,dive,min=1"`
+		// Keep is the draft the others are merged into, defaults to the most confident one
+		Keep int64 `json:"keep" schema:"keep" validate:"omitempty,min=1"`
+	}
+
+	// DraftRequest is the request body to edit a draft
+	DraftRequest struct {
+		CardID          int64   `json:"card_id" schema:"card_id" validate:"required,min=1"`
+		Kind            string  `json:"kind" schema:"kind" validate:"required,oneof=spend refund payment"`
+		TransactionDate string  `json:"transaction_date" schema:"transaction_date" validate:"required,datetime=2006-01-02"`
+		Description     string  `json:"description" schema:"description" validate:"required"`
+		Merchant        *string `json:"merchant" schema:"merchant"`
+		Category        *string `json:"category" schema:"category"`
+		// Amount is the unsigned amount, a refund or a payment being told apart by its kind
+		Amount money.Money `json:"amount" schema:"amount" validate:"positive_money"`
+	}
+)
+
+// errUnknownCard is returned when a draft is edited to a card that does not exist
+var errUnknownCard = errors.New("card_id does not refer to a known card")
+
+// GetAllHandler returns the draft transactions of the user waiting for review, optionally of a single
+// imported statement or only those read with a confidence up to max_confidence
+func GetAllHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type (
+		Query struct {
+			StatementImportID int64    `schema:"statement_import_id" validate:"omitempty,min=1"`
+			MaxConfidence     *float64 `schema:"max_confidence" validate:"omitempty,min=0,max=1"`
+		}
+
+		Response struct {
+			Drafts []*models.DraftTransaction `json:"drafts"`
+		}
+	)
+
+	typedReader := request.NewTypedReader[Query](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		query, err := typedReader.ReadAndValidateQueryParams(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse query params", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		var list []*models.DraftTransaction
+		if query.StatementImportID != 0 {
+			list, err = dbConn.Queries.GetDraftTransactionsByStatementImportID(ctx,
+				models.GetDraftTransactionsByStatementImportIDParams{
+					StatementImportID: &query.StatementImportID,
+					UserID:            auth.UserID(ctx),
+				})
+		} else {
+			list, err = dbConn.Queries.GetUserDraftTransactions(ctx, auth.UserID(ctx))
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get draft transactions", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if query.MaxConfidence != nil {
+			list = slices.DeleteFunc(list, func(d *models.DraftTransaction) bool {
+				return d.Confidence > *query.MaxConfidence
+			})
+		}
+
+		if list == nil {
+			list = []*models.DraftTransaction{}
+		}
+
+		jw.Ok(ctx, w, Response{Drafts: list})
+	}
+}
+
+// ApproveHandler moves drafts to the ledger, either all of them or none when one does not exist or
+// would import a transaction or payment twice
+func ApproveHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type Response struct {
+		Transactions []*models.Transaction `json:"transactions"`
+		Payments     []*models.Payment     `json:"payments"`
+	}
+
+	typedReader := request.NewTypedReader[ApproveRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		txns, payments, err := dbConn.ApproveDrafts(ctx, log, auth.UserID(ctx), body.IDs)
+		if errors.Is(err, db.ErrDraftNotFound) {
+			jw.WriteProblem(ctx, r, w, notFound(err.Error()))
+			return
+		}
+
+		var duplicates *db.DuplicateDraftsError
+		if errors.As(err, &duplicates) {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusConflict).
+				WithDetail(err.Error()).
+				WithCustomMember("draft_ids", duplicates.IDs).
+				Build())
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to approve drafts", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		resp := Response{Transactions: txns, Payments: payments}
+
+		if resp.Transactions == nil {
+			resp.Transactions = []*models.Transaction{}
+		}
+
+		if resp.Payments == nil {
+			resp.Payments = []*models.Payment{}
+		}
+
+		jw.Ok(ctx, w, resp)
+	}
+}
+
+// UpdateHandler edits a draft, e.g. to correct what was misread or to move it to another card
+func UpdateHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+	normaliser *taxonomy.Normaliser,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[DraftRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, ok := draftID(ctx, r, w, jw)
+		if !ok {
+			return
+		}
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		draft, err := update(ctx, dbConn, normaliser, id, body)
+		switch {
+		case errors.Is(err, errUnknownCard):
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusUnprocessableEntity).
+				WithDetail(err.Error()).
+				Build())
+			return
+		case errors.Is(err, sql.ErrNoRows):
+			jw.WriteProblem(ctx, r, w, notFound("draft transaction not found"))
+			return
+		case err != nil:
+			log.ErrorContext(ctx, "failed to update draft transaction", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, draft)
+	}
+}
+
+// DiscardHandler discards drafts, either all of them or none when one does not exist
+func DiscardHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[ApproveRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		err = dbConn.DiscardDrafts(ctx, log, auth.UserID(ctx), body.IDs)
+		if errors.Is(err, db.ErrDraftNotFound) {
+			jw.WriteProblem(ctx, r, w, notFound(err.Error()))
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to discard drafts", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// MergeHandler merges drafts of the same transaction into one, deleting the others
+func MergeHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[MergeRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		draft, err := merge(ctx, log, dbConn, body)
+		switch {
+		case errors.Is(err, db.ErrDraftNotFound):
+			jw.WriteProblem(ctx, r, w, notFound(err.Error()))
+			return
+		case errors.Is(err, db.ErrDraftsMismatch):
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusUnprocessableEntity).
+				WithDetail(err.Error()).
+				Build())
+			return
+		case err != nil:
+			log.ErrorContext(ctx, "failed to merge drafts", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, draft)
+	}
+}
+
+// DeleteHandler discards a draft
+func DeleteHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, ok := draftID(ctx, r, w, jw)
+		if !ok {
+			return
+		}
+
+		n, err := dbConn.Queries.DeleteDraftTransaction(ctx, models.DeleteDraftTransactionParams{
+			ID:     id,
+			UserID: auth.UserID(ctx),
+		})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to delete draft transaction", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if n == 0 {
+			jw.WriteProblem(ctx, r, w, notFound("draft transaction not found"))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// update saves the edit of a draft of the user, returning sql.ErrNoRows when it does not exist.
+// The merchant the reviewer gave the draft is learned for its description, so that drafts of the
+// same descriptor are named alike.
+func update(
+	ctx context.Context,
+	dbConn *db.DB,
+	normaliser *taxonomy.Normaliser,
+	id int64,
+	body *DraftRequest,
+) (*models.DraftTransaction, error) {
+	_, err := dbConn.Queries.GetUserCardByID(ctx, models.GetUserCardByIDParams{
+		ID:     body.CardID,
+		UserID: auth.UserID(ctx),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errUnknownCard
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card: %w", err)
+	}
+
+	draft, err := dbConn.Queries.UpdateDraftTransaction(ctx, models.UpdateDraftTransactionParams{
+		ID:              id,
+		CardID:          body.CardID,
+		Kind:            body.Kind,
+		TransactionDate: body.TransactionDate,
+		Description:     strings.TrimSpace(body.Description),
+		Merchant:        optional(body.Merchant),
+		Category:        optional(body.Category),
+		AmountMinor:     body.Amount,
+		UserID:          auth.UserID(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if draft.Merchant != nil && draft.Kind != db.DraftKindPayment {
+		err = normaliser.Learn(ctx, auth.UserID(ctx), draft.Description, *draft.Merchant, draft.Category)
+		if err != nil {
+			return nil, fmt.Errorf("failed to learn merchant: %w", err)
+		}
+	}
+
+	return draft, nil
+}
+
+// merge merges the drafts of the request into the one to keep, or into the most confident one
+func merge(ctx context.Context, log *slog.Logger, dbConn *db.DB, body *MergeRequest) (*models.DraftTransaction, error) {
+	keep := body.Keep
+
+	if keep == 0 {
+		var confidence float64
+
+		for _, id := range body.IDs {
+			draft, err := dbConn.Queries.GetDraftTransactionByID(ctx, models.GetDraftTransactionByIDParams{
+				ID:     id,
+				UserID: auth.UserID(ctx),
+			})
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("%w: %d", db.ErrDraftNotFound, id)
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("failed to get draft transaction %d: %w", id, err)
+			}
+
+			if keep == 0 || draft.Confidence > confidence {
+				keep, confidence = id, draft.Confidence
+			}
+		}
+	}
+
+	return dbConn.MergeDrafts(ctx, log, auth.UserID(ctx), keep, body.IDs)
+}
+
+func draftID(ctx context.Context, r *http.Request, w http.ResponseWriter, jw *response.JSONWriter) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		jw.WriteProblem(ctx, r, w, response.NewProblem().
+			WithStatus(http.StatusBadRequest).
+			WithDetail("draft id must be a number").
+			Build())
+		return 0, false
+	}
+
+	return id, true
+}
+
+// optional trims a string, returning nil when it is empty, e.g. a field left blank in a form
+func optional(s *string) *string {
+	if s == nil {
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(*s)
+	if trimmed == "" {
+		return nil
+	}
+
+	return &trimmed
+}
+
+func notFound(detail string) response.Problem {
+	return response.NewProblem().
+		WithStatus(http.StatusNotFound).
+		WithDetail(detail).
+		Build()
+}
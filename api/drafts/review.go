@@ -0,0 +1,333 @@
+package drafts
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/taxonomy"
+	"github.com/pushkar-anand/cardmax/web"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// lowConfidence is the confidence under which a draft is highlighted on the review page
+const lowConfidence = 0.7
+
+type (
+	// reviewRow is a draft as listed on the review page
+	reviewRow struct {
+		*models.DraftTransaction
+		// Card is the name of the card the draft is assigned to
+		Card string
+		// Percent is the confidence as a percentage
+		Percent int
+		Low     bool
+		// Suggestion is the suggested category when it is not the draft's category
+		Suggestion string
+		// SuggestionPercent is the confidence of the suggestion as a percentage
+		SuggestionPercent int
+	}
+
+	// reviewForm is the drafts selected on the review page for a bulk action
+	reviewForm struct {
+		IDs []int64 `schema:"ids"`
+	}
+
+	// queue is what the review page shows along with the drafts: the outcome of the last action
+	// and the draft being edited, if any
+	queue struct {
+		Message string
+		Error   string
+		// Editing is the draft being edited, with the values submitted when they were invalid
+		Editing *models.DraftTransaction
+	}
+)
+
+// ReviewHTMLHandler renders the review queue of the drafts page
+func ReviewHTMLHandler(
+	log *slog.Logger,
+	tr *web.Renderer,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		renderQueue(log, tr, dbConn, w, r, queue{})
+	}
+}
+
+// ActionHTMLHandler approves, discards or merges the drafts selected on the review page and
+// renders the queue again with the outcome
+func ActionHTMLHandler(
+	log *slog.Logger,
+	reader *request.Reader,
+	tr *web.Renderer,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[reviewForm](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		form, err := typedReader.ReadAndValidateForm(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse form", logger.Error(err))
+			http.Error(w, "Failed to parse form", http.StatusBadRequest)
+			return
+		}
+
+		var q queue
+
+		switch action := mux.Vars(r)["action"]; {
+		case len(form.IDs) == 0:
+			q.Error = "Select the drafts to " + action + "."
+		case action == "approve":
+			var (
+				txns     []*models.Transaction
+				payments []*models.Payment
+			)
+
+			txns, payments, err = dbConn.ApproveDrafts(ctx, log, auth.UserID(ctx), form.IDs)
+			q.Message = fmt.Sprintf("Approved %d transactions and %d payments into the ledger.", len(txns), len(payments))
+		case action == "discard":
+			err = dbConn.DiscardDrafts(ctx, log, auth.UserID(ctx), form.IDs)
+			q.Message = fmt.Sprintf("Discarded %d drafts.", len(form.IDs))
+		case len(form.IDs) < 2:
+			q.Error = "Select at least two drafts to merge."
+		default:
+			var merged *models.DraftTransaction
+
+			merged, err = merge(ctx, log, dbConn, &MergeRequest{IDs: form.IDs})
+			if err == nil {
+				q.Message = fmt.Sprintf("Merged %d drafts into #%d.", len(form.IDs), merged.ID)
+			}
+		}
+
+		var duplicates *db.DuplicateDraftsError
+
+		switch {
+		case errors.Is(err, db.ErrDraftNotFound), errors.Is(err, db.ErrDraftsMismatch), errors.As(err, &duplicates):
+			q.Message, q.Error = "", err.Error()
+		case err != nil:
+			log.ErrorContext(ctx, "failed to review drafts", logger.Error(err))
+			http.Error(w, "Failed to review drafts", http.StatusInternalServerError)
+			return
+		}
+
+		renderQueue(log, tr, dbConn, w, r, q)
+	}
+}
+
+// EditHTMLHandler renders the review queue with the form to edit a draft
+func EditHTMLHandler(
+	log *slog.Logger,
+	tr *web.Renderer,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			http.Error(w, "Draft id must be a number", http.StatusBadRequest)
+			return
+		}
+
+		var q queue
+
+		q.Editing, err = dbConn.Queries.GetDraftTransactionByID(ctx, models.GetDraftTransactionByIDParams{
+			ID:     id,
+			UserID: auth.UserID(ctx),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			q.Error = "The draft was already approved or discarded."
+		} else if err != nil {
+			log.ErrorContext(ctx, "failed to get draft transaction", logger.Error(err))
+			http.Error(w, "Failed to get draft", http.StatusInternalServerError)
+			return
+		}
+
+		renderQueue(log, tr, dbConn, w, r, q)
+	}
+}
+
+// SaveHTMLHandler saves the edit of a draft from the review page. An invalid edit is shown again
+// with what was wrong.
+func SaveHTMLHandler(
+	log *slog.Logger,
+	reader *request.Reader,
+	tr *web.Renderer,
+	dbConn *db.DB,
+	normaliser *taxonomy.Normaliser,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[DraftRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			http.Error(w, "Draft id must be a number", http.StatusBadRequest)
+			return
+		}
+
+		var q queue
+
+		body, err := typedReader.ReadAndValidateForm(r)
+		if err == nil {
+			var draft *models.DraftTransaction
+
+			draft, err = update(ctx, dbConn, normaliser, id, body)
+			if err == nil {
+				q.Message = fmt.Sprintf("Saved draft #%d.", draft.ID)
+			}
+		}
+
+		if detail, ok := invalidDetail(err); ok {
+			q.Error = "Check the draft: " + detail
+			q.Editing = submitted(id, r)
+			err = nil
+		}
+
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			q.Error = "The draft was already approved or discarded."
+		case err != nil:
+			log.ErrorContext(ctx, "failed to update draft transaction", logger.Error(err))
+			http.Error(w, "Failed to save draft", http.StatusInternalServerError)
+			return
+		}
+
+		renderQueue(log, tr, dbConn, w, r, q)
+	}
+}
+
+// renderQueue renders the drafts of the user waiting for review, the least confident first
+func renderQueue(
+	log *slog.Logger,
+	tr *web.Renderer,
+	dbConn *db.DB,
+	w http.ResponseWriter,
+	r *http.Request,
+	q queue,
+) {
+	ctx := r.Context()
+
+	rows, cardList, err := loadQueue(ctx, dbConn)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to load review queue", logger.Error(err))
+		http.Error(w, "Failed to load drafts", http.StatusInternalServerError)
+		return
+	}
+
+	err = tr.RenderPartial(w, r, web.PartialDraftQueue, map[string]any{
+		"Drafts":  rows,
+		"Cards":   cardList,
+		"Message": q.Message,
+		"Error":   q.Error,
+		"Editing": q.Editing,
+	})
+	if err != nil {
+		log.ErrorContext(ctx, "error rendering review queue template", logger.Error(err))
+		http.Error(w, "Failed to render drafts", http.StatusInternalServerError)
+		return
+	}
+}
+
+func loadQueue(ctx context.Context, dbConn *db.DB) ([]*reviewRow, []*models.Card, error) {
+	cardList, err := dbConn.Queries.GetUserCards(ctx, auth.UserID(ctx))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get cards: %w", err)
+	}
+
+	names := make(map[int64]string, len(cardList))
+	for _, card := range cardList {
+		names[card.ID] = fmt.Sprintf("%s (%s)", card.Name, card.Last4Digits)
+	}
+
+	list, err := dbConn.Queries.GetUserDraftTransactions(ctx, auth.UserID(ctx))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get draft transactions: %w", err)
+	}
+
+	rows := make([]*reviewRow, 0, len(list))
+	for _, draft := range list {
+		row := &reviewRow{
+			DraftTransaction: draft,
+			Card:             names[draft.CardID],
+			Percent:          int(math.Round(draft.Confidence * 100)),
+			Low:              draft.Confidence < lowConfidence,
+		}
+
+		if s := draft.SuggestedCategory; s != nil && draft.SuggestionConfidence != nil &&
+			(draft.Category == nil || *draft.Category != *s) {
+			row.Suggestion = *s
+			row.SuggestionPercent = int(math.Round(*draft.SuggestionConfidence * 100))
+		}
+
+		rows = append(rows, row)
+	}
+
+	// Stable, so that drafts of the same confidence stay in date order
+	slices.SortStableFunc(rows, func(a, b *reviewRow) int {
+		return cmp.Compare(a.Confidence, b.Confidence)
+	})
+
+	return rows, cardList, nil
+}
+
+// submitted returns the draft as submitted in the edit form, to be shown again when invalid
+func submitted(id int64, r *http.Request) *models.DraftTransaction {
+	cardID, _ := strconv.ParseInt(r.PostFormValue("card_id"), 10, 64)
+	merchant, category := r.PostFormValue("merchant"), r.PostFormValue("category")
+
+	draft := &models.DraftTransaction{
+		ID:              id,
+		CardID:          cardID,
+		Kind:            r.PostFormValue("kind"),
+		TransactionDate: r.PostFormValue("transaction_date"),
+		Description:     r.PostFormValue("description"),
+		Merchant:        optional(&merchant),
+		Category:        optional(&category),
+	}
+
+	_ = draft.AmountMinor.UnmarshalText([]byte(cmp.Or(r.PostFormValue("amount"), "0")))
+
+	return draft
+}
+
+// invalidDetail describes why an edit was rejected, reporting false for any other error
+func invalidDetail(err error) (string, bool) {
+	var (
+		invalid *request.ValidationError
+		readErr *request.ReadError
+	)
+
+	switch {
+	case errors.As(err, &invalid):
+		reasons := make([]string, 0, len(invalid.Result.Failed))
+		for _, reason := range invalid.Result.Failed {
+			reasons = append(reasons, reason.Message)
+		}
+
+		slices.Sort(reasons)
+
+		return strings.Join(reasons, ", "), true
+	case errors.As(err, &readErr):
+		return readErr.Message, true
+	case errors.Is(err, errUnknownCard):
+		return err.Error(), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,178 @@
+package exports
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/api/recommend"
+	"github.com/pushkar-anand/cardmax/internal/cards"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/exporter"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// ErrUnknownCard is returned when the card to export does not exist
+var ErrUnknownCard = errors.New("card does not exist")
+
+// Exporter builds exports of the ledger along with the rewards earned on the spends
+type Exporter struct {
+	db *db.DB
+	// cards are the predefined cards keyed by card key
+	cards map[string]*cards.Card
+}
+
+// NewExporter creates an exporter computing rewards with the predefined cards
+func NewExporter(dbConn *db.DB, cardList []*cards.Card) *Exporter {
+	byKey := make(map[string]*cards.Card, len(cardList))
+	for _, card := range cardList {
+		byKey[card.Key] = card
+	}
+
+	return &Exporter{db: dbConn, cards: byKey}
+}
+
+// Statement returns the transactions and payments dated from the start to the end date, both
+// inclusive, of the card or of every card of the user when cardID is nil
+func (ex *Exporter) Statement(
+	ctx context.Context,
+	userID, cardID *int64,
+	start, end time.Time,
+) (*exporter.Statement, error) {
+	balances, err := ex.db.Queries.GetCardBalances(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card balances: %w", err)
+	}
+
+	s := &exporter.Statement{Start: start, End: end, Generated: time.Now()}
+
+	for _, row := range balances {
+		if cardID != nil && row.Card.ID != *cardID {
+			continue
+		}
+
+		account, err := ex.account(ctx, &row.Card, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		account.Balance = money.New(row.Paid-row.Spent, money.INR)
+		s.Accounts = append(s.Accounts, *account)
+	}
+
+	if cardID != nil && len(s.Accounts) == 0 {
+		return nil, ErrUnknownCard
+	}
+
+	return s, nil
+}
+
+// account returns the entries of a card. Rewards are computed from the start of the cycle of the
+// start date so that the spends before it consume the caps they share with the exported spends.
+func (ex *Exporter) account(ctx context.Context, card *models.Card, start, end time.Time) (*exporter.Account, error) {
+	schedule := db.CardSchedule(card)
+	from := start.Format(time.DateOnly)
+
+	txns, err := ex.db.Queries.GetCardTransactionsBetween(ctx, models.GetCardTransactionsBetweenParams{
+		CardID:   card.ID,
+		FromDate: schedule.CycleFor(start).Start.Format(time.DateOnly),
+		ToDate:   end.Format(time.DateOnly),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions of card %d: %w", card.ID, err)
+	}
+
+	payments, err := ex.db.Queries.GetCardPaymentsBetween(ctx, models.GetCardPaymentsBetweenParams{
+		CardID:   card.ID,
+		FromDate: from,
+		ToDate:   end.Format(time.DateOnly),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payments of card %d: %w", card.ID, err)
+	}
+
+	var predefined *cards.Card
+	if card.PredefinedCardKey != nil {
+		predefined = ex.cards[*card.PredefinedCardKey]
+	}
+
+	earner := recommend.NewEarner()
+	account := &exporter.Account{Name: card.Name, Last4: card.Last4Digits}
+
+	for _, txn := range txns {
+		date, err := time.Parse(time.DateOnly, txn.TransactionDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date of transaction %d: %w", txn.ID, err)
+		}
+
+		var result *recommend.RewardResult
+		if predefined != nil {
+			result = earner.Earn(predefined, schedule.CycleFor(date).StatementDate.Format(time.DateOnly),
+				deref(txn.Merchant), deref(txn.Category), deref(txn.Channel), txn.AmountMinor)
+		}
+
+		if txn.TransactionDate < from {
+			continue
+		}
+
+		account.Entries = append(account.Entries, transactionEntry(txn, date, result))
+	}
+
+	for _, p := range payments {
+		date, err := time.Parse(time.DateOnly, p.PaymentDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date of payment %d: %w", p.ID, err)
+		}
+
+		account.Entries = append(account.Entries, exporter.Entry{
+			ID:     db.PaymentExternalID(p),
+			Type:   exporter.TypePayment,
+			Date:   date,
+			Name:   cmp.Or(deref(p.Notes), "Payment"),
+			Amount: p.AmountMinor,
+			Notes:  deref(p.Notes),
+		})
+	}
+
+	slices.SortStableFunc(account.Entries, func(a, b exporter.Entry) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	return account, nil
+}
+
+// transactionEntry returns the entry of a transaction, a debit for a spend and a credit for a refund
+func transactionEntry(txn *models.Transaction, date time.Time, result *recommend.RewardResult) exporter.Entry {
+	entry := exporter.Entry{
+		ID:       db.TransactionExternalID(txn),
+		Type:     exporter.TypeDebit,
+		Date:     date,
+		Name:     cmp.Or(deref(txn.Merchant), deref(txn.Category), "Transaction"),
+		Amount:   txn.AmountMinor.Neg(),
+		Category: deref(txn.Category),
+		Notes:    deref(txn.Notes),
+	}
+
+	if txn.AmountMinor.IsNegative() {
+		entry.Type = exporter.TypeCredit
+	}
+
+	if result != nil && result.RewardValue > 0 {
+		entry.Reward = fmt.Sprintf("%s %s (%s)",
+			strconv.FormatFloat(result.RewardValue, 'f', -1, 64), result.RewardType, result.CashValue)
+	}
+
+	return entry
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
@@ -0,0 +1,160 @@
+package exports
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/exporter"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Query is the query params of an export
+type Query struct {
+	CardID *int64 `schema:"card_id" validate:"omitempty,min=1"`
+	// From and To are the dates of the range to export as YYYY-MM-DD, both inclusive.
+	// To defaults to today and From to a year before To.
+	From string `schema:"from" validate:"omitempty,datetime=2006-01-02"`
+	To   string `schema:"to" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// Range returns the dates of the range to export
+func (q *Query) Range(now time.Time) (time.Time, time.Time) {
+	end, err := time.Parse(time.DateOnly, q.To)
+	if err != nil {
+		end, _ = time.Parse(time.DateOnly, now.Format(time.DateOnly))
+	}
+
+	start, err := time.Parse(time.DateOnly, q.From)
+	if err != nil {
+		start = end.AddDate(-1, 0, 1)
+	}
+
+	return start, end
+}
+
+// OFXHandler exports the ledger as an OFX credit card statement download, version 1 (SGML) by
+// default or 2 (XML) with ?version=2
+func OFXHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	ex *Exporter,
+) http.HandlerFunc {
+	type OFXQuery struct {
+		Query
+		Version int `schema:"version" validate:"omitempty,oneof=1 2"`
+	}
+
+	typedReader := request.NewTypedReader[OFXQuery](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		query, err := typedReader.ReadAndValidateQueryParams(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse query params", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		version := query.Version
+		if version == 0 {
+			version = exporter.OFXVersion1
+		}
+
+		writeExport(log, jw, w, r, ex, &query.Query, "application/x-ofx", "ofx",
+			func(buf *bytes.Buffer, s *exporter.Statement) error {
+				return exporter.WriteOFX(buf, s, version)
+			})
+	}
+}
+
+// QIFHandler exports the ledger as QIF
+func QIFHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	ex *Exporter,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[Query](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		query, err := typedReader.ReadAndValidateQueryParams(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse query params", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		writeExport(log, jw, w, r, ex, query, "application/qif", "qif",
+			func(buf *bytes.Buffer, s *exporter.Statement) error {
+				return exporter.WriteQIF(buf, s)
+			})
+	}
+}
+
+// writeExport writes the export as an attachment. It is written to a buffer first so that a
+// failure can still be reported as a problem response.
+func writeExport(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	w http.ResponseWriter,
+	r *http.Request,
+	ex *Exporter,
+	query *Query,
+	contentType, extension string,
+	write func(*bytes.Buffer, *exporter.Statement) error,
+) {
+	ctx := r.Context()
+
+	start, end := query.Range(time.Now())
+	if start.After(end) {
+		jw.WriteProblem(ctx, r, w, response.NewProblem().
+			WithStatus(http.StatusUnprocessableEntity).
+			WithDetail("from must not be after to").
+			Build())
+		return
+	}
+
+	s, err := ex.Statement(ctx, auth.UserID(ctx), query.CardID, start, end)
+	if errors.Is(err, ErrUnknownCard) {
+		jw.WriteProblem(ctx, r, w, response.NewProblem().
+			WithStatus(http.StatusNotFound).
+			WithDetail("card not found").
+			Build())
+		return
+	}
+
+	if err != nil {
+		log.ErrorContext(ctx, "failed to get statement to export", logger.Error(err))
+		jw.WriteError(ctx, r, w, err)
+		return
+	}
+
+	var buf bytes.Buffer
+
+	err = write(&buf, s)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to write export", logger.Error(err))
+		jw.WriteError(ctx, r, w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="cardmax-%s-%s.%s"`,
+		start.Format(time.DateOnly), end.Format(time.DateOnly), extension))
+	w.WriteHeader(http.StatusOK)
+
+	_, err = buf.WriteTo(w)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to write response", logger.Error(err))
+	}
+}
@@ -0,0 +1,105 @@
+package fx
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/fx"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GetAllRatesHandler returns the stored exchange rate table
+func GetAllRatesHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type Response struct {
+		Base  string                 `json:"base"`
+		Rates []*models.ExchangeRate `json:"rates"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		rates, err := dbConn.Queries.GetAllExchangeRates(ctx)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get exchange rates", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, Response{Base: fx.BaseCurrency, Rates: rates})
+	}
+}
+
+// UpdateRateHandler stores the exchange rate of a currency.
+// This allows the rate table to be maintained offline, without any external rate provider.
+func UpdateRateHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type Request struct {
+		RateToINR float64 `json:"rate_to_inr" validate:"required,gt=0"`
+		// AsOf is the date of the rate as YYYY-MM-DD, defaults to today
+		AsOf string `json:"as_of" validate:"omitempty,datetime=2006-01-02"`
+	}
+
+	typedReader := request.NewTypedReader[Request](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		currency := strings.ToUpper(mux.Vars(r)["currency"])
+		if len(currency) != 3 || fx.IsBase(currency) {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusBadRequest).
+				WithDetail("currency must be a foreign ISO 4217 code").
+				Build())
+			return
+		}
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		asOf := body.AsOf
+		if asOf == "" {
+			asOf = time.Now().Format(time.DateOnly)
+		}
+
+		rate, err := dbConn.Queries.UpsertExchangeRate(ctx, models.UpsertExchangeRateParams{
+			Currency:  currency,
+			RateToInr: body.RateToINR,
+			AsOf:      asOf,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusConflict).
+				WithDetail("a newer rate is already stored for "+currency).
+				Build())
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to update exchange rate", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, rate)
+	}
+}
@@ -0,0 +1,529 @@
+package households
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// HouseholdRequest is the request body to create a household
+	HouseholdRequest struct {
+		Name string `json:"name" validate:"required,max=64"`
+	}
+
+	// InviteRequest is the request body to invite a user to the household
+	InviteRequest struct {
+		Username string `json:"username" validate:"required,max=64"`
+	}
+
+	// Household is a household as seen by its members, along with the cards they share
+	Household struct {
+		ID        int64         `json:"id"`
+		Name      string        `json:"name"`
+		CreatedAt time.Time     `json:"created_at"`
+		Members   []*Member     `json:"members"`
+		Cards     []*SharedCard `json:"cards"`
+		// Invites are the pending invites, only listed to the owner
+		Invites []*Invite `json:"invites,omitempty"`
+	}
+
+	// Member is a member of a household
+	Member struct {
+		UserID   int64     `json:"user_id"`
+		Username string    `json:"username"`
+		Owner    bool      `json:"owner"`
+		JoinedAt time.Time `json:"joined_at"`
+	}
+
+	// SharedCard is a card a member shares with the household, read-only to the others
+	SharedCard struct {
+		*models.Card
+		// Holder is the username of the member holding the card
+		Holder string `json:"holder"`
+	}
+
+	// Invite is an invite to a household. The owner sees who was invited, the invited user which
+	// household they are invited to and by whom.
+	Invite struct {
+		ID        int64     `json:"id"`
+		Username  string    `json:"username,omitempty"`
+		Household string    `json:"household,omitempty"`
+		InvitedBy string    `json:"invited_by,omitempty"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+)
+
+// GetHandler returns the household of the user with its members and shared cards
+func GetHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		household, ok := userHousehold(ctx, log, jw, w, r, dbConn)
+		if !ok {
+			return
+		}
+
+		view, err := loadHousehold(ctx, dbConn.Queries, household, auth.User(ctx).ID)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to load household", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, view)
+	}
+}
+
+// CreateHandler creates a household owned by the user
+func CreateHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[HouseholdRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID := auth.User(ctx).ID
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		household, err := dbConn.CreateHousehold(ctx, log, userID, strings.TrimSpace(body.Name))
+		if errors.Is(err, db.ErrInHousehold) {
+			jw.WriteProblem(ctx, r, w, conflict(err.Error()))
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to create household", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		view, err := loadHousehold(ctx, dbConn.Queries, household, userID)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to load household", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Write(ctx, w, http.StatusCreated, view)
+	}
+}
+
+// DeleteHandler removes the household the user owns, along with its members and invites
+func DeleteHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		household, ok := userHousehold(ctx, log, jw, w, r, dbConn)
+		if !ok {
+			return
+		}
+
+		if household.OwnerID != auth.User(ctx).ID {
+			jw.WriteProblem(ctx, r, w, forbidden("only the owner can delete the household"))
+			return
+		}
+
+		err := dbConn.DeleteHousehold(ctx, log, household.ID)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to delete household", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// RemoveMemberHandler removes a member from the household of the user. Members can leave, and
+// the owner can remove anyone else; the owner leaves by deleting the household.
+func RemoveMemberHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID := auth.User(ctx).ID
+
+		memberID, ok := pathID(ctx, r, w, jw, "member")
+		if !ok {
+			return
+		}
+
+		household, ok := userHousehold(ctx, log, jw, w, r, dbConn)
+		if !ok {
+			return
+		}
+
+		switch {
+		case memberID == household.OwnerID:
+			jw.WriteProblem(ctx, r, w, conflict("the owner cannot leave the household, delete it instead"))
+			return
+		case memberID != userID && household.OwnerID != userID:
+			jw.WriteProblem(ctx, r, w, forbidden("only the owner can remove other members"))
+			return
+		}
+
+		n, err := dbConn.Queries.DeleteHouseholdMember(ctx, models.DeleteHouseholdMemberParams{
+			HouseholdID: household.ID,
+			UserID:      memberID,
+		})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to remove household member", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if n == 0 {
+			jw.WriteProblem(ctx, r, w, notFound("member not found"))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// InviteHandler invites a user to the household the user owns
+func InviteHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[InviteRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID := auth.User(ctx).ID
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		household, ok := userHousehold(ctx, log, jw, w, r, dbConn)
+		if !ok {
+			return
+		}
+
+		if household.OwnerID != userID {
+			jw.WriteProblem(ctx, r, w, forbidden("only the owner can invite members"))
+			return
+		}
+
+		invitee, err := dbConn.Queries.GetUserByUsername(ctx, strings.TrimSpace(body.Username))
+		if errors.Is(err, sql.ErrNoRows) {
+			jw.WriteProblem(ctx, r, w, notFound("user not found"))
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get user", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		_, err = dbConn.Queries.GetUserHousehold(ctx, invitee.ID)
+		if err == nil {
+			jw.WriteProblem(ctx, r, w, conflict("the user is already a member of a household"))
+			return
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.ErrorContext(ctx, "failed to get household", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		pending, err := dbConn.Queries.GetHouseholdInvites(ctx, household.ID)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get household invites", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		for _, row := range pending {
+			if row.HouseholdInvite.UserID == invitee.ID {
+				jw.WriteProblem(ctx, r, w, conflict("the user is already invited"))
+				return
+			}
+		}
+
+		invite, err := dbConn.Queries.CreateHouseholdInvite(ctx, models.CreateHouseholdInviteParams{
+			HouseholdID: household.ID,
+			UserID:      invitee.ID,
+			InvitedBy:   userID,
+		})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to create household invite", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Write(ctx, w, http.StatusCreated, &Invite{
+			ID:        invite.ID,
+			Username:  invitee.Username,
+			CreatedAt: invite.CreatedAt,
+		})
+	}
+}
+
+// GetInvitesHandler returns the pending invites of the user to households
+func GetInvitesHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type Response struct {
+		Invites []*Invite `json:"invites"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		rows, err := dbConn.Queries.GetUserHouseholdInvites(ctx, auth.User(ctx).ID)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get household invites", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		list := make([]*Invite, 0, len(rows))
+		for _, row := range rows {
+			list = append(list, &Invite{
+				ID:        row.HouseholdInvite.ID,
+				Household: row.Household,
+				InvitedBy: row.InvitedByUsername,
+				CreatedAt: row.HouseholdInvite.CreatedAt,
+			})
+		}
+
+		jw.Ok(ctx, w, Response{Invites: list})
+	}
+}
+
+// AcceptInviteHandler accepts an invite of the user, joining its household
+func AcceptInviteHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID := auth.User(ctx).ID
+
+		id, ok := pathID(ctx, r, w, jw, "invite")
+		if !ok {
+			return
+		}
+
+		household, err := dbConn.JoinHousehold(ctx, log, userID, id)
+		if errors.Is(err, db.ErrInviteNotFound) {
+			jw.WriteProblem(ctx, r, w, notFound(err.Error()))
+			return
+		}
+
+		if errors.Is(err, db.ErrInHousehold) {
+			jw.WriteProblem(ctx, r, w, conflict(err.Error()))
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to join household", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		view, err := loadHousehold(ctx, dbConn.Queries, household, userID)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to load household", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, view)
+	}
+}
+
+// DeleteInviteHandler declines an invite of the user, or revokes an invite of the household the
+// user owns
+func DeleteInviteHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, ok := pathID(ctx, r, w, jw, "invite")
+		if !ok {
+			return
+		}
+
+		n, err := dbConn.Queries.DeleteHouseholdInvite(ctx, models.DeleteHouseholdInviteParams{
+			ID:     id,
+			UserID: auth.User(ctx).ID,
+		})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to delete household invite", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if n == 0 {
+			jw.WriteProblem(ctx, r, w, notFound(db.ErrInviteNotFound.Error()))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// loadHousehold loads the members and shared cards of the household, and its pending invites
+// when the user owns it
+func loadHousehold(ctx context.Context, q *models.Queries, household *models.Household, userID int64) (*Household, error) {
+	members, err := q.GetHouseholdMembers(ctx, household.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := q.GetHouseholdCards(ctx, household.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &Household{
+		ID:        household.ID,
+		Name:      household.Name,
+		CreatedAt: household.CreatedAt,
+		Members:   make([]*Member, 0, len(members)),
+		Cards:     make([]*SharedCard, 0, len(shared)),
+	}
+
+	for _, member := range members {
+		view.Members = append(view.Members, &Member{
+			UserID:   member.ID,
+			Username: member.Username,
+			Owner:    member.ID == household.OwnerID,
+			JoinedAt: member.JoinedAt,
+		})
+	}
+
+	for _, row := range shared {
+		view.Cards = append(view.Cards, &SharedCard{
+			Card:   &row.Card,
+			Holder: row.Username,
+		})
+	}
+
+	if household.OwnerID != userID {
+		return view, nil
+	}
+
+	invites, err := q.GetHouseholdInvites(ctx, household.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range invites {
+		view.Invites = append(view.Invites, &Invite{
+			ID:        row.HouseholdInvite.ID,
+			Username:  row.Username,
+			CreatedAt: row.HouseholdInvite.CreatedAt,
+		})
+	}
+
+	return view, nil
+}
+
+// userHousehold returns the household of the user, writing a problem response when the user is not
+// a member of one
+func userHousehold(
+	ctx context.Context,
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	w http.ResponseWriter,
+	r *http.Request,
+	dbConn *db.DB,
+) (*models.Household, bool) {
+	household, err := dbConn.Queries.GetUserHousehold(ctx, auth.User(ctx).ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		jw.WriteProblem(ctx, r, w, notFound("not a member of a household"))
+		return nil, false
+	}
+
+	if err != nil {
+		log.ErrorContext(ctx, "failed to get household", logger.Error(err))
+		jw.WriteError(ctx, r, w, err)
+		return nil, false
+	}
+
+	return household, true
+}
+
+// pathID reads the ID from the path, writing a problem response when it is invalid
+func pathID(ctx context.Context, r *http.Request, w http.ResponseWriter, jw *response.JSONWriter, what string) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		jw.WriteProblem(ctx, r, w, response.NewProblem().
+			WithStatus(http.StatusBadRequest).
+			WithDetail(what+" id must be a number").
+			Build())
+		return 0, false
+	}
+
+	return id, true
+}
+
+func notFound(detail string) response.Problem {
+	return response.NewProblem().
+		WithStatus(http.StatusNotFound).
+		WithDetail(detail).
+		Build()
+}
+
+func forbidden(detail string) response.Problem {
+	return response.NewProblem().
+		WithStatus(http.StatusForbidden).
+		WithDetail(detail).
+		Build()
+}
+
+func conflict(detail string) response.Problem {
+	return response.NewProblem().
+		WithStatus(http.StatusConflict).
+		WithDetail(detail).
+		Build()
+}
@@ -0,0 +1,128 @@
+package households
+
+import (
+	"cmp"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+)
+
+type (
+	// LedgerEntry is a transaction of the household's ledger, attributed to the member who made it
+	LedgerEntry struct {
+		*models.Transaction
+		CardName string `json:"card_name"`
+		// Holder is the username of the member holding the card
+		Holder   string `json:"holder"`
+		MemberID int64  `json:"member_id"`
+		// Member is the username of the member who made the spend, the holder unless recorded
+		// otherwise
+		Member string `json:"member"`
+	}
+
+	// MemberSpend sums up the transactions a member made over a period
+	MemberSpend struct {
+		UserID       int64       `json:"user_id"`
+		Username     string      `json:"username"`
+		Transactions int         `json:"transactions"`
+		Spend        money.Money `json:"spend"`
+	}
+)
+
+// LedgerHandler returns the transactions of the user's cards and of the cards shared with them
+// over a period, this month by default, along with the spend of each member who made them
+func LedgerHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type (
+		Query struct {
+			// From and To are the first and last dates of the period as YYYY-MM-DD
+			From string `schema:"from" validate:"omitempty,datetime=2006-01-02"`
+			To   string `schema:"to" validate:"omitempty,datetime=2006-01-02"`
+		}
+
+		Response struct {
+			From         string         `json:"from"`
+			To           string         `json:"to"`
+			Members      []*MemberSpend `json:"members"`
+			Transactions []*LedgerEntry `json:"transactions"`
+			Total        money.Money    `json:"total"`
+		}
+	)
+
+	typedReader := request.NewTypedReader[Query](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		query, err := typedReader.ReadAndValidateQueryParams(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse query params", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		now := time.Now()
+
+		resp := Response{
+			From: cmp.Or(query.From, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Format(time.DateOnly)),
+			To:   cmp.Or(query.To, now.Format(time.DateOnly)),
+		}
+
+		rows, err := dbConn.Queries.GetHouseholdLedger(ctx, models.GetHouseholdLedgerParams{
+			UserID:   auth.UserID(ctx),
+			FromDate: resp.From,
+			ToDate:   resp.To,
+		})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get household ledger", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		resp.Transactions = make([]*LedgerEntry, 0, len(rows))
+		spends := make(map[int64]*MemberSpend)
+
+		for _, row := range rows {
+			resp.Transactions = append(resp.Transactions, &LedgerEntry{
+				Transaction: &row.Transaction,
+				CardName:    row.CardName,
+				Holder:      row.Holder,
+				MemberID:    row.MemberID,
+				Member:      row.Member,
+			})
+
+			spend, ok := spends[row.MemberID]
+			if !ok {
+				spend = &MemberSpend{UserID: row.MemberID, Username: row.Member}
+				spends[row.MemberID] = spend
+				resp.Members = append(resp.Members, spend)
+			}
+
+			spend.Transactions++
+			spend.Spend = spend.Spend.Add(row.Transaction.AmountMinor)
+			resp.Total = resp.Total.Add(row.Transaction.AmountMinor)
+		}
+
+		slices.SortStableFunc(resp.Members, func(a, b *MemberSpend) int {
+			return b.Spend.Cmp(a.Spend)
+		})
+
+		if resp.Members == nil {
+			resp.Members = []*MemberSpend{}
+		}
+
+		jw.Ok(ctx, w, resp)
+	}
+}
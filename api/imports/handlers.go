@@ -0,0 +1,478 @@
+package imports
+
+import (
+	"errors"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/importer"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// maxUploadSize is the largest statement file accepted
+const maxUploadSize = 10 << 20
+
+// MappingsHandler returns the issuer mappings CSV files can be imported with
+func MappingsHandler(
+	jw *response.JSONWriter,
+	im *Importer,
+) http.HandlerFunc {
+	type Response struct {
+		Mappings []*importer.Mapping `json:"mappings"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		jw.Ok(r.Context(), w, Response{Mappings: im.Mappings()})
+	}
+}
+
+// Result is the preview of an import, along with what a commit recorded for review
+type Result struct {
+	*Preview
+	Committed bool `json:"committed"`
+	// StatementImport is the summary of a committed PDF statement
+	StatementImport *models.StatementImport `json:"statement_import,omitempty"`
+	// Drafts are the rows a commit recorded, to be approved into the ledger
+	Drafts []*models.DraftTransaction `json:"drafts,omitempty"`
+}
+
+// TemplatesHandler returns the issuer templates PDF statements can be parsed with
+func TemplatesHandler(
+	jw *response.JSONWriter,
+	im *Importer,
+) http.HandlerFunc {
+	type Response struct {
+		Templates []*importer.Template `json:"templates"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		jw.Ok(r.Context(), w, Response{Templates: im.Templates()})
+	}
+}
+
+// AlertTemplatesHandler returns the issuer templates transaction alerts can be read with
+func AlertTemplatesHandler(
+	jw *response.JSONWriter,
+	im *Importer,
+) http.HandlerFunc {
+	type Response struct {
+		Templates []*importer.AlertTemplate `json:"templates"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		jw.Ok(r.Context(), w, Response{Templates: im.AlertTemplates()})
+	}
+}
+
+// StatementsHandler returns the summaries of the PDF statements the user imported
+func StatementsHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type Response struct {
+		Statements []*models.StatementImport `json:"statements"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		list, err := dbConn.Queries.GetStatementImports(ctx, auth.UserID(ctx))
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get statement imports", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if list == nil {
+			list = []*models.StatementImport{}
+		}
+
+		jw.Ok(ctx, w, Response{Statements: list})
+	}
+}
+
+// EmailsHandler returns the alert emails processed by the mail worker, most recent first
+func EmailsHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type (
+		Query struct {
+			Limit int64 `schema:"limit" validate:"omitempty,min=1,max=500"`
+		}
+
+		Response struct {
+			Emails []*models.MailMessage `json:"emails"`
+		}
+	)
+
+	typedReader := request.NewTypedReader[Query](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		query, err := typedReader.ReadAndValidateQueryParams(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse query params", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if query.Limit == 0 {
+			query.Limit = 50
+		}
+
+		list, err := dbConn.Queries.GetMailMessages(ctx, query.Limit)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get mail messages", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if list == nil {
+			list = []*models.MailMessage{}
+		}
+
+		jw.Ok(ctx, w, Response{Emails: list})
+	}
+}
+
+// CSVHandler imports the transactions of a CSV statement export uploaded as the "file" field of a
+// multipart form. It returns a preview unless commit is set, in which case the rows that are not
+// invalid are recorded as drafts to review, duplicates flagged.
+func CSVHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	im *Importer,
+) http.HandlerFunc {
+	type Form struct {
+		// Mapping is the key of the issuer mapping, e.g. hdfc
+		Mapping string `schema:"mapping" validate:"required"`
+		// CardID is the card rows without a matching card number are imported into
+		CardID *int64 `schema:"card_id" validate:"omitempty,min=1"`
+		Commit bool   `schema:"commit"`
+	}
+
+	typedReader := request.NewTypedReader[Form](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if !parseUpload(log, jw, w, r) {
+			return
+		}
+
+		form, err := typedReader.ReadAndValidateForm(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse form", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		mapping, err := im.Mapping(form.Mapping)
+		if err != nil {
+			jw.WriteProblem(ctx, r, w, unprocessable(err.Error()))
+			return
+		}
+
+		importUpload(log, jw, w, r, im, mapping.ParseCSV, form.CardID, form.Commit)
+	}
+}
+
+// OFXHandler imports the transactions of an OFX or QFX file, the same way as CSVHandler
+func OFXHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	im *Importer,
+) http.HandlerFunc {
+	return fileHandler(log, jw, reader, im, importer.ParseOFX)
+}
+
+// QIFHandler imports the transactions of a QIF file, the same way as CSVHandler
+func QIFHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	im *Importer,
+) http.HandlerFunc {
+	return fileHandler(log, jw, reader, im, importer.ParseQIF)
+}
+
+// fileHandler imports files of a format that needs no mapping
+func fileHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	im *Importer,
+	parse func(io.Reader) (*importer.File, error),
+) http.HandlerFunc {
+	type Form struct {
+		// CardID is the card rows without a matching account number are imported into
+		CardID *int64 `schema:"card_id" validate:"omitempty,min=1"`
+		Commit bool   `schema:"commit"`
+	}
+
+	typedReader := request.NewTypedReader[Form](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if !parseUpload(log, jw, w, r) {
+			return
+		}
+
+		form, err := typedReader.ReadAndValidateForm(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse form", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		importUpload(log, jw, w, r, im, parse, form.CardID, form.Commit)
+	}
+}
+
+// PDFHandler reads a PDF statement uploaded as the "file" field of a multipart form, decrypting it
+// with the password. It returns a preview of its transactions and summary unless commit is set,
+// in which case they are recorded for review the same way as by CSVHandler.
+func PDFHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	im *Importer,
+) http.HandlerFunc {
+	type Form struct {
+		Password string `schema:"password"`
+		// Template is the key of the issuer template, detected from the statement when empty
+		Template string `schema:"template"`
+		// CardID is the card rows without a matching card number are imported into
+		CardID *int64 `schema:"card_id" validate:"omitempty,min=1"`
+		Commit bool   `schema:"commit"`
+	}
+
+	typedReader := request.NewTypedReader[Form](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if !parseUpload(log, jw, w, r) {
+			return
+		}
+
+		form, err := typedReader.ReadAndValidateForm(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse form", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		upload, _, err := r.FormFile("file")
+		if err != nil {
+			jw.WriteProblem(ctx, r, w, badRequest("file is required"))
+			return
+		}
+		defer upload.Close()
+
+		data, err := io.ReadAll(upload)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to read upload", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		file, err := im.ParsePDF(data, form.Password, form.Template)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse PDF statement", logger.Error(err))
+			jw.WriteProblem(ctx, r, w, unprocessable(err.Error()))
+			return
+		}
+
+		preview, err := im.Preview(ctx, auth.UserID(ctx), file, form.CardID)
+		if errors.Is(err, ErrUnknownCard) {
+			jw.WriteProblem(ctx, r, w, unprocessable("card_id does not refer to a known card"))
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to preview import", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		resp := Result{Preview: preview}
+
+		if form.Commit {
+			resp.StatementImport, resp.Drafts, err = im.SaveDrafts(ctx, log, preview)
+			if err != nil {
+				log.ErrorContext(ctx, "failed to save drafts", logger.Error(err))
+				jw.WriteError(ctx, r, w, err)
+				return
+			}
+
+			resp.Committed = true
+		}
+
+		jw.Ok(ctx, w, resp)
+	}
+}
+
+// AlertsHandler reads the transactions of pasted or forwarded SMS and email alerts, one message per
+// paragraph. It returns a preview unless commit is set, in which case they are recorded as drafts
+// to review. Alerts without a date are dated today.
+func AlertsHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	im *Importer,
+) http.HandlerFunc {
+	type Request struct {
+		Text string `json:"text" validate:"required"`
+		// Template is the key of the issuer template, detected from each alert when empty
+		Template string `json:"template"`
+		// CardID is the card alerts without a matching card number are recorded against
+		CardID *int64 `json:"card_id" validate:"omitempty,min=1"`
+		Commit bool   `json:"commit"`
+	}
+
+	typedReader := request.NewTypedReader[Request](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		file, err := im.ParseAlerts(body.Text, body.Template, time.Now())
+		if err != nil {
+			jw.WriteProblem(ctx, r, w, unprocessable(err.Error()))
+			return
+		}
+
+		preview, err := im.Preview(ctx, auth.UserID(ctx), file, body.CardID)
+		if errors.Is(err, ErrUnknownCard) {
+			jw.WriteProblem(ctx, r, w, unprocessable("card_id does not refer to a known card"))
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to preview import", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		resp := Result{Preview: preview}
+
+		if body.Commit {
+			resp.StatementImport, resp.Drafts, err = im.SaveDrafts(ctx, log, preview)
+			if err != nil {
+				log.ErrorContext(ctx, "failed to save drafts", logger.Error(err))
+				jw.WriteError(ctx, r, w, err)
+				return
+			}
+
+			resp.Committed = true
+		}
+
+		jw.Ok(ctx, w, resp)
+	}
+}
+
+// parseUpload parses the multipart form of an upload, writing a problem response when it is invalid
+func parseUpload(log *slog.Logger, jw *response.JSONWriter, w http.ResponseWriter, r *http.Request) bool {
+	ctx := r.Context()
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+
+	err := r.ParseMultipartForm(maxUploadSize)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to parse multipart form", logger.Error(err))
+		jw.WriteProblem(ctx, r, w, badRequest("the request must be a multipart form with the file"))
+		return false
+	}
+
+	return true
+}
+
+// importUpload parses the uploaded file and previews its import, or saves it as drafts
+func importUpload(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	w http.ResponseWriter,
+	r *http.Request,
+	im *Importer,
+	parse func(io.Reader) (*importer.File, error),
+	cardID *int64,
+	commit bool,
+) {
+	ctx := r.Context()
+
+	upload, _, err := r.FormFile("file")
+	if err != nil {
+		jw.WriteProblem(ctx, r, w, badRequest("file is required"))
+		return
+	}
+	defer upload.Close()
+
+	file, err := parse(upload)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to parse statement file", logger.Error(err))
+		jw.WriteProblem(ctx, r, w, unprocessable(err.Error()))
+		return
+	}
+
+	preview, err := im.Preview(ctx, auth.UserID(ctx), file, cardID)
+	if errors.Is(err, ErrUnknownCard) {
+		jw.WriteProblem(ctx, r, w, unprocessable("card_id does not refer to a known card"))
+		return
+	}
+
+	if err != nil {
+		log.ErrorContext(ctx, "failed to preview import", logger.Error(err))
+		jw.WriteError(ctx, r, w, err)
+		return
+	}
+
+	resp := Result{Preview: preview}
+
+	if commit {
+		_, resp.Drafts, err = im.SaveDrafts(ctx, log, preview)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to save drafts", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		resp.Committed = true
+	}
+
+	jw.Ok(ctx, w, resp)
+}
+
+func badRequest(detail string) response.Problem {
+	return response.NewProblem().
+		WithStatus(http.StatusBadRequest).
+		WithDetail(detail).
+		Build()
+}
+
+func unprocessable(detail string) response.Problem {
+	return response.NewProblem().
+		WithStatus(http.StatusUnprocessableEntity).
+		WithDetail(detail).
+		Build()
+}
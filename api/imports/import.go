@@ -0,0 +1,747 @@
+package imports
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/api/recommend"
+	"github.com/pushkar-anand/cardmax/internal/cards"
+	"github.com/pushkar-anand/cardmax/internal/categoriser"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/importer"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"github.com/pushkar-anand/cardmax/internal/taxonomy"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// penaltyOtherCard is how much less confident a row is when assigned to the default card rather
+// than to the card ending in the digits it shows
+const penaltyOtherCard = 0.1
+
+// Kinds of imported rows
+const (
+	KindSpend   = db.DraftKindSpend
+	KindRefund  = db.DraftKindRefund
+	KindPayment = db.DraftKindPayment
+)
+
+var (
+	// ErrUnknownMapping is returned for a mapping key that is not known
+	ErrUnknownMapping = errors.New("unknown import mapping")
+	// ErrUnknownTemplate is returned for a statement template key that is not known
+	ErrUnknownTemplate = errors.New("unknown statement template")
+	// ErrUndetectedTemplate is returned when no template matches a PDF statement
+	ErrUndetectedTemplate = errors.New("the statement's issuer could not be detected, choose the template")
+	// ErrUnknownAlertTemplate is returned for an alert template key that is not known
+	ErrUnknownAlertTemplate = errors.New("unknown alert template")
+	// ErrUnknownCard is returned when the default card does not exist
+	ErrUnknownCard = errors.New("card does not exist")
+)
+
+type (
+	// PreviewRow is a parsed row along with what importing it does
+	PreviewRow struct {
+		importer.Row
+		// Kind is spend, refund or payment
+		Kind string `json:"kind"`
+		// CardID is the user card the row is assigned to
+		CardID *int64 `json:"card_id"`
+		// Duplicate marks a row that matches a transaction or payment already in the ledger
+		Duplicate bool `json:"duplicate"`
+		// RewardValue and CashValue are the reward a spend earns on a card linked to a predefined card
+		RewardValue *float64    `json:"reward_value,omitempty"`
+		CashValue   money.Money `json:"cash_value,omitzero"`
+		// Error is why the row cannot be imported, e.g. no card could be assigned
+		Error string `json:"error,omitempty"`
+	}
+
+	// Summary counts the rows of an import
+	Summary struct {
+		Rows       int `json:"rows"`
+		Spends     int `json:"spends"`
+		Refunds    int `json:"refunds"`
+		Payments   int `json:"payments"`
+		Duplicates int `json:"duplicates"`
+		Invalid    int `json:"invalid"`
+		// Spend is the total of the spends to import
+		Spend money.Money `json:"spend"`
+		// CashValue is the total reward value of the spends to import
+		CashValue money.Money `json:"cash_value"`
+	}
+
+	// Preview is what an import would add to the review queue
+	Preview struct {
+		// Source is the format of the file, csv, ofx or qif
+		Source  string              `json:"source"`
+		Mapping string              `json:"mapping,omitempty"`
+		Rows    []*PreviewRow       `json:"rows"`
+		Errors  []importer.RowError `json:"errors"`
+		Summary Summary             `json:"summary"`
+		// Statement is the summary of a PDF statement
+		Statement *importer.Statement `json:"statement,omitempty"`
+
+		// userID is the user whose cards the rows are assigned to, whose category model suggests
+		// the categories of the drafts
+		userID *int64
+	}
+
+	// Importer previews statement imports and saves them as drafts to review
+	Importer struct {
+		db          *db.DB
+		cards       map[string]*cards.Card
+		mappings    map[string]*importer.Mapping
+		templates   []*importer.Template
+		alerts      []*importer.AlertTemplate
+		normaliser  *taxonomy.Normaliser
+		categoriser *categoriser.Categoriser
+	}
+)
+
+// NewImporter creates an importer for the CSV mappings, PDF statement templates and alert
+// templates. Rewards are computed with the predefined cards, and the merchants of the rows are
+// named by the normaliser. The categoriser suggests the categories of the drafts.
+func NewImporter(
+	dbConn *db.DB,
+	cardList []*cards.Card,
+	mappings []*importer.Mapping,
+	templates []*importer.Template,
+	alerts []*importer.AlertTemplate,
+	normaliser *taxonomy.Normaliser,
+	cat *categoriser.Categoriser,
+) *Importer {
+	im := &Importer{
+		db:          dbConn,
+		cards:       make(map[string]*cards.Card, len(cardList)),
+		mappings:    make(map[string]*importer.Mapping, len(mappings)),
+		templates:   slices.Clone(templates),
+		alerts:      slices.Clone(alerts),
+		normaliser:  normaliser,
+		categoriser: cat,
+	}
+
+	slices.SortFunc(im.templates, func(a, b *importer.Template) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+
+	slices.SortFunc(im.alerts, func(a, b *importer.AlertTemplate) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+
+	for _, card := range cardList {
+		im.cards[card.Key] = card
+	}
+
+	for _, m := range mappings {
+		im.mappings[m.Key] = m
+	}
+
+	return im
+}
+
+// Mappings returns the known mappings sorted by key
+func (im *Importer) Mappings() []*importer.Mapping {
+	list := make([]*importer.Mapping, 0, len(im.mappings))
+	for _, m := range im.mappings {
+		list = append(list, m)
+	}
+
+	slices.SortFunc(list, func(a, b *importer.Mapping) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+
+	return list
+}
+
+// Mapping returns the mapping with the key
+func (im *Importer) Mapping(key string) (*importer.Mapping, error) {
+	m, ok := im.mappings[strings.ToLower(key)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownMapping, key)
+	}
+
+	return m, nil
+}
+
+// Templates returns the known PDF statement templates sorted by key
+func (im *Importer) Templates() []*importer.Template {
+	return im.templates
+}
+
+// ParsePDF reads a PDF statement, decrypting it with the password. It is parsed with the
+// template with the key, or with the template detected from its text when key is empty.
+func (im *Importer) ParsePDF(data []byte, password, key string) (*importer.File, error) {
+	lines, err := importer.ExtractText(data, password)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == "" {
+		t, ok := importer.DetectTemplate(im.templates, lines)
+		if !ok {
+			return nil, ErrUndetectedTemplate
+		}
+
+		return t.Parse(lines), nil
+	}
+
+	for _, t := range im.templates {
+		if strings.EqualFold(t.Key, key) {
+			return t.Parse(lines), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownTemplate, key)
+}
+
+// AlertTemplates returns the known alert templates sorted by key
+func (im *Importer) AlertTemplates() []*importer.AlertTemplate {
+	return im.alerts
+}
+
+// ParseAlerts reads the transactions of pasted or forwarded alert text, received at the time.
+// Messages are read with the template with the key, or with the template detected from each
+// when key is empty.
+func (im *Importer) ParseAlerts(text, key string, received time.Time) (*importer.File, error) {
+	templates := im.alerts
+
+	if key != "" {
+		i := slices.IndexFunc(im.alerts, func(t *importer.AlertTemplate) bool {
+			return strings.EqualFold(t.Key, key)
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownAlertTemplate, key)
+		}
+
+		templates = im.alerts[i : i+1]
+	}
+
+	return importer.ParseAlerts(templates, text, received), nil
+}
+
+// SaveAlerts saves the alerts of text received at the time as drafts on the cards of the user,
+// as AlertsHandler does with commit, e.g. for an alert email. Nothing is saved when an alert cannot be, such as one of
+// an unknown card, and the reason is returned instead.
+func (im *Importer) SaveAlerts(
+	ctx context.Context,
+	log *slog.Logger,
+	userID *int64,
+	text string,
+	received time.Time,
+) ([]*models.DraftTransaction, string, error) {
+	file, err := im.ParseAlerts(text, "", received)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(file.Rows) == 0 {
+		if len(file.Errors) > 0 {
+			return nil, file.Errors[0].Error, nil
+		}
+
+		return nil, "no transaction alert found", nil
+	}
+
+	preview, err := im.Preview(ctx, userID, file, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to preview alerts: %w", err)
+	}
+
+	for _, row := range preview.Rows {
+		if row.Error != "" {
+			return nil, row.Error, nil
+		}
+	}
+
+	_, drafts, err := im.SaveDrafts(ctx, log, preview)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to save drafts: %w", err)
+	}
+
+	return drafts, "", nil
+}
+
+// Preview names the merchants of the rows of the file, assigns the rows to the cards of the user,
+// flags duplicates of ledger rows and computes the rewards of the spends. Rows are assigned to the
+// card ending in their last four digits, and to the default card, if any, otherwise.
+func (im *Importer) Preview(
+	ctx context.Context,
+	userID *int64,
+	file *importer.File,
+	defaultCardID *int64,
+) (*Preview, error) {
+	cardList, err := im.db.Queries.GetUserCards(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cards: %w", err)
+	}
+
+	var defaultCard *models.Card
+
+	byLast4 := make(map[string][]*models.Card)
+	byID := make(map[int64]*models.Card, len(cardList))
+
+	for _, card := range cardList {
+		byLast4[card.Last4Digits] = append(byLast4[card.Last4Digits], card)
+		byID[card.ID] = card
+
+		if defaultCardID != nil && card.ID == *defaultCardID {
+			defaultCard = card
+		}
+	}
+
+	if defaultCardID != nil && defaultCard == nil {
+		return nil, ErrUnknownCard
+	}
+
+	preview := &Preview{
+		Source:    file.Source,
+		Mapping:   file.Mapping,
+		Rows:      make([]*PreviewRow, 0, len(file.Rows)),
+		Errors:    file.Errors,
+		Statement: file.Statement,
+		userID:    userID,
+	}
+
+	if preview.Errors == nil {
+		preview.Errors = []importer.RowError{}
+	}
+
+	// Rows of each card, to detect duplicates and compute rewards card by card
+	perCard := make(map[int64][]*PreviewRow)
+
+	for _, row := range file.Rows {
+		pr := &PreviewRow{Row: row, Kind: kindOf(row)}
+		im.normalise(userID, &pr.Row)
+
+		card, err := assignCard(row, byLast4, defaultCard)
+		if err != nil {
+			pr.Error = err.Error()
+		} else {
+			pr.CardID = &card.ID
+			perCard[card.ID] = append(perCard[card.ID], pr)
+
+			// The card number read may be wrong rather than that of an add-on card
+			if row.Last4 != "" && row.Last4 != card.Last4Digits {
+				pr.Penalise(penaltyOtherCard)
+			}
+		}
+
+		preview.Rows = append(preview.Rows, pr)
+	}
+
+	for cardID, cardRows := range perCard {
+		err = im.checkCard(ctx, byID[cardID], cardRows)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	preview.summarise()
+
+	return preview, nil
+}
+
+// normalise names the merchant of a spend or refund row by its merchant, or its description
+// when it has none, and categorises the row by the merchant when it has no category. A merchant
+// the normaliser does not know for the user is kept as read.
+func (im *Importer) normalise(userID *int64, row *importer.Row) {
+	if row.Payment {
+		return
+	}
+
+	m, ok := im.normaliser.Normalise(userID, cmp.Or(row.Merchant, row.Description))
+	if !ok {
+		return
+	}
+
+	row.Merchant = m.Merchant
+	if row.Category == "" {
+		row.Category = m.Category
+	}
+}
+
+// SaveDrafts records the rows of the preview that are not invalid as drafts to review, along with
+// the summary of a PDF statement. Duplicates are kept and flagged so that the reviewer can tell.
+// Spends and refunds carry the category the categoriser suggests, by the model of the user the
+// preview is for, apart from the one they were read with.
+func (im *Importer) SaveDrafts(
+	ctx context.Context,
+	log *slog.Logger,
+	preview *Preview,
+) (*models.StatementImport, []*models.DraftTransaction, error) {
+	var (
+		statement *models.CreateStatementImportParams
+		drafts    []models.CreateDraftTransactionParams
+		cardID    *int64
+	)
+
+	for _, row := range preview.Rows {
+		if row.Error != "" {
+			continue
+		}
+
+		// The statement is of the card of its first row, as statements list the primary card's
+		// transactions before those of add-on cards
+		if cardID == nil {
+			cardID = row.CardID
+		}
+
+		params := models.CreateDraftTransactionParams{
+			CardID:          *row.CardID,
+			Source:          preview.Source,
+			Kind:            row.Kind,
+			TransactionDate: row.Date,
+			Description:     row.Description,
+			Category:        optional(row.Category),
+			AmountMinor:     row.Amount,
+			ExternalID:      optional(row.ExternalID),
+			Duplicate:       row.Duplicate,
+			Merchant:        optional(row.Merchant),
+			Parser:          row.Parser,
+			Confidence:      row.Confidence,
+		}
+
+		if row.Kind != db.DraftKindPayment && preview.userID != nil {
+			suggestion, err := im.categoriser.Suggest(
+				ctx,
+				*preview.userID,
+				categoriser.Text(params.Merchant, &row.Description),
+				row.Amount,
+			)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if suggestion != nil {
+				params.SuggestedCategory = &suggestion.Category
+				params.SuggestionConfidence = &suggestion.Confidence
+			}
+		}
+
+		drafts = append(drafts, params)
+	}
+
+	if s := preview.Statement; s != nil {
+		statement = &models.CreateStatementImportParams{
+			CardID:          cardID,
+			Template:        preview.Mapping,
+			StatementDate:   optional(s.StatementDate),
+			DueDate:         optional(s.DueDate),
+			TotalDueMinor:   s.TotalDue,
+			MinimumDueMinor: s.MinimumDue,
+		}
+
+		if p := s.Points; p != nil {
+			statement.PointsOpening = &p.Opening
+			statement.PointsEarned = &p.Earned
+			statement.PointsRedeemed = &p.Redeemed
+			statement.PointsExpired = &p.Expired
+			statement.PointsClosing = &p.Closing
+		}
+	}
+
+	return im.db.SaveDrafts(ctx, log, statement, drafts)
+}
+
+// checkCard flags the rows of a card that duplicate its ledger rows and computes their rewards
+func (im *Importer) checkCard(ctx context.Context, card *models.Card, rows []*PreviewRow) error {
+	schedule := db.CardSchedule(card)
+
+	first, last := rows[0].Date, rows[0].Date
+	for _, row := range rows {
+		first, last = min(first, row.Date), max(last, row.Date)
+	}
+
+	// Spends already in the ledger in the cycles of the import count towards the same caps
+	firstDate, _ := time.Parse(time.DateOnly, first)
+	lastDate, _ := time.Parse(time.DateOnly, last)
+
+	existing, err := im.db.Queries.GetCardTransactionsBetween(ctx, models.GetCardTransactionsBetweenParams{
+		CardID:   card.ID,
+		FromDate: schedule.CycleFor(firstDate).Start.Format(time.DateOnly),
+		ToDate:   schedule.CycleFor(lastDate).StatementDate.Format(time.DateOnly),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get transactions of card %d: %w", card.ID, err)
+	}
+
+	payments, err := im.db.Queries.GetCardPaymentsBetween(ctx, models.GetCardPaymentsBetweenParams{
+		CardID:   card.ID,
+		FromDate: first,
+		ToDate:   last,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get payments of card %d: %w", card.ID, err)
+	}
+
+	// Drafts waiting for review are duplicates too, e.g. when the same statement is uploaded twice
+	drafts, err := im.db.Queries.GetCardDraftTransactionsBetween(ctx, models.GetCardDraftTransactionsBetweenParams{
+		CardID:   card.ID,
+		FromDate: first,
+		ToDate:   last,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get drafts of card %d: %w", card.ID, err)
+	}
+
+	d := newDedupe()
+	for _, txn := range existing {
+		d.add(transactionKey(txn.TransactionDate, txn.AmountMinor, deref(txn.Merchant)),
+			db.TransactionExternalID(txn), txn.ExternalID != nil)
+	}
+
+	for _, p := range payments {
+		d.add(paymentKey(p.PaymentDate, p.AmountMinor), db.PaymentExternalID(p), p.ExternalID != nil)
+	}
+
+	for _, draft := range drafts {
+		d.add(draftKey(draft), deref(draft.ExternalID), draft.ExternalID != nil)
+	}
+
+	for _, row := range rows {
+		key := transactionKey(row.Date, row.signedAmount(), row.merchant())
+		if row.Kind == KindPayment {
+			key = paymentKey(row.Date, row.Amount)
+		}
+
+		row.Duplicate = d.duplicate(key, row.ExternalID)
+	}
+
+	if card.PredefinedCardKey == nil {
+		return nil
+	}
+
+	predefined, ok := im.cards[*card.PredefinedCardKey]
+	if !ok {
+		return nil
+	}
+
+	im.earn(card, predefined, existing, rows)
+
+	return nil
+}
+
+// earn computes the rewards of the spends to import, in date order after the spends already in
+// the ledger so that they consume the caps first
+func (im *Importer) earn(card *models.Card, predefined *cards.Card, existing []*models.Transaction, rows []*PreviewRow) {
+	schedule := db.CardSchedule(card)
+	earner := recommend.NewEarner()
+
+	cycle := func(date string) string {
+		d, _ := time.Parse(time.DateOnly, date)
+
+		return schedule.CycleFor(d).StatementDate.Format(time.DateOnly)
+	}
+
+	type spend struct {
+		date string
+		row  *PreviewRow
+		txn  *models.Transaction
+	}
+
+	spends := make([]spend, 0, len(existing)+len(rows))
+	for _, txn := range existing {
+		spends = append(spends, spend{date: txn.TransactionDate, txn: txn})
+	}
+
+	for _, row := range rows {
+		if row.Kind == KindSpend && !row.Duplicate {
+			spends = append(spends, spend{date: row.Date, row: row})
+		}
+	}
+
+	slices.SortStableFunc(spends, func(a, b spend) int {
+		return cmp.Compare(a.date, b.date)
+	})
+
+	for _, s := range spends {
+		if s.txn != nil {
+			earner.Earn(predefined, cycle(s.date), deref(s.txn.Merchant), deref(s.txn.Category),
+				deref(s.txn.Channel), s.txn.AmountMinor)
+			continue
+		}
+
+		result := earner.Earn(predefined, cycle(s.date), s.row.merchant(), s.row.Category, "", s.row.Amount)
+		if result != nil {
+			s.row.RewardValue = &result.RewardValue
+			s.row.CashValue = result.CashValue
+		}
+	}
+}
+
+func (p *Preview) summarise() {
+	p.Summary = Summary{Rows: len(p.Rows), Invalid: len(p.Errors)}
+
+	for _, row := range p.Rows {
+		switch {
+		case row.Error != "":
+			p.Summary.Invalid++
+			continue
+		case row.Duplicate:
+			p.Summary.Duplicates++
+			continue
+		}
+
+		switch row.Kind {
+		case KindSpend:
+			p.Summary.Spends++
+			p.Summary.Spend = p.Summary.Spend.Add(row.Amount)
+			p.Summary.CashValue = p.Summary.CashValue.Add(row.CashValue)
+		case KindRefund:
+			p.Summary.Refunds++
+		case KindPayment:
+			p.Summary.Payments++
+		}
+	}
+}
+
+// merchant is the merchant the row is recorded with: its taxonomy name, if matched, or its description
+func (r *PreviewRow) merchant() string {
+	return cmp.Or(r.Merchant, r.Description)
+}
+
+// signedAmount is the amount as stored in the ledger, negative for a refund
+func (r *PreviewRow) signedAmount() money.Money {
+	if r.Credit {
+		return r.Amount.Neg()
+	}
+
+	return r.Amount
+}
+
+func kindOf(row importer.Row) string {
+	switch {
+	case row.Payment:
+		return KindPayment
+	case row.Credit:
+		return KindRefund
+	default:
+		return KindSpend
+	}
+}
+
+// assignCard picks the user card of a row by its last four digits, falling back to the default card
+// when the row has none or no card ends in them
+func assignCard(row importer.Row, byLast4 map[string][]*models.Card, defaultCard *models.Card) (*models.Card, error) {
+	if row.Last4 == "" {
+		if defaultCard == nil {
+			return nil, errors.New("the row has no card number, choose the card to import into")
+		}
+
+		return defaultCard, nil
+	}
+
+	matches := byLast4[row.Last4]
+
+	switch {
+	case len(matches) == 1:
+		return matches[0], nil
+	case len(matches) > 1 && defaultCard != nil && defaultCard.Last4Digits == row.Last4:
+		return defaultCard, nil
+	case len(matches) > 1:
+		return nil, fmt.Errorf("more than one card ends in %s, choose the card to import into", row.Last4)
+	case defaultCard != nil:
+		// e.g. an add-on card billed to the chosen card
+		return defaultCard, nil
+	default:
+		return nil, fmt.Errorf("no card ends in %s", row.Last4)
+	}
+}
+
+func transactionKey(date string, amount money.Money, merchant string) string {
+	return fmt.Sprintf("t|%s|%d|%s", date, amount.Minor(), strings.ToLower(strings.TrimSpace(merchant)))
+}
+
+func draftKey(draft *models.DraftTransaction) string {
+	merchant := draft.Description
+	if draft.Merchant != nil {
+		merchant = *draft.Merchant
+	}
+
+	switch draft.Kind {
+	case KindPayment:
+		return paymentKey(draft.TransactionDate, draft.AmountMinor)
+	case KindRefund:
+		return transactionKey(draft.TransactionDate, draft.AmountMinor.Neg(), merchant)
+	default:
+		return transactionKey(draft.TransactionDate, draft.AmountMinor, merchant)
+	}
+}
+
+func paymentKey(date string, amount money.Money) string {
+	return fmt.Sprintf("p|%s|%d", date, amount.Minor())
+}
+
+// dedupe matches imported rows to ledger rows. Rows with an external ID, such as the FITID of an
+// OFX transaction, are matched by it. They are only matched by date, amount and description to
+// ledger rows without one, e.g. rows entered by hand, as two rows with different IDs are distinct.
+type dedupe struct {
+	ids map[string]bool
+	// all and unidentified count the ledger rows by key, with and without an external ID. Each
+	// ledger row can only be the duplicate of one imported row.
+	all, unidentified map[string]int
+}
+
+func newDedupe() *dedupe {
+	return &dedupe{
+		ids:          make(map[string]bool),
+		all:          make(map[string]int),
+		unidentified: make(map[string]int),
+	}
+}
+
+func (d *dedupe) add(key, externalID string, identified bool) {
+	d.ids[externalID] = true
+	d.all[key]++
+
+	if !identified {
+		d.unidentified[key]++
+	}
+}
+
+// duplicate reports whether an imported row duplicates a ledger row or an earlier row of the import
+func (d *dedupe) duplicate(key, externalID string) bool {
+	if externalID == "" {
+		if d.all[key] > 0 {
+			d.all[key]--
+			return true
+		}
+
+		return false
+	}
+
+	if d.ids[externalID] {
+		return true
+	}
+
+	d.ids[externalID] = true
+
+	if d.unidentified[key] > 0 {
+		d.unidentified[key]--
+		d.all[key]--
+
+		return true
+	}
+
+	return false
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
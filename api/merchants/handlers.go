@@ -0,0 +1,160 @@
+package merchants
+
+import (
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/taxonomy"
+	"log/slog"
+	"net/http"
+)
+
+type (
+	// MappingRequest is the request body to map a statement descriptor to a merchant
+	MappingRequest struct {
+		// Descriptor is the descriptor as it appears on statements, cleaned before it is stored
+		Descriptor string  `json:"descriptor" validate:"required"`
+		Merchant   string  `json:"merchant" validate:"required"`
+		Category   *string `json:"category"`
+	}
+
+	// ForgetRequest is the request body to remove the mapping of a descriptor
+	ForgetRequest struct {
+		Descriptor string `json:"descriptor" validate:"required"`
+	}
+)
+
+// NormaliseHandler returns the merchant a statement descriptor names for the user
+func NormaliseHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	normaliser *taxonomy.Normaliser,
+) http.HandlerFunc {
+	type Query struct {
+		Descriptor string `schema:"descriptor" validate:"required"`
+	}
+
+	typedReader := request.NewTypedReader[Query](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		query, err := typedReader.ReadAndValidateQueryParams(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse query params", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		m, ok := normaliser.Normalise(auth.UserID(ctx), query.Descriptor)
+		if !ok {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusNotFound).
+				WithDetail("the descriptor does not name a known merchant").
+				Build())
+			return
+		}
+
+		jw.Ok(ctx, w, m)
+	}
+}
+
+// GetMappingsHandler returns the descriptor mappings learned from the user's corrections
+func GetMappingsHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type Response struct {
+		Mappings []*models.MerchantMapping `json:"mappings"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		list, err := dbConn.Queries.GetUserMerchantMappings(ctx, auth.UserID(ctx))
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get merchant mappings", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if list == nil {
+			list = []*models.MerchantMapping{}
+		}
+
+		jw.Ok(ctx, w, Response{Mappings: list})
+	}
+}
+
+// TeachHandler maps a descriptor to a merchant for the user, replacing what it was mapped to
+func TeachHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	normaliser *taxonomy.Normaliser,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[MappingRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		m, err := normaliser.Teach(ctx, auth.UserID(ctx), body.Descriptor, body.Merchant, body.Category)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to save merchant mapping", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, m)
+	}
+}
+
+// ForgetHandler removes the user's mapping of a descriptor
+func ForgetHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	normaliser *taxonomy.Normaliser,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[ForgetRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		found, err := normaliser.Forget(ctx, auth.UserID(ctx), body.Descriptor)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to delete merchant mapping", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if !found {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusNotFound).
+				WithDetail("merchant mapping not found").
+				Build())
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,210 @@
+package notifications
+
+import (
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/notify"
+	"log/slog"
+	"net/http"
+	"slices"
+)
+
+type (
+	// PreferenceRequest is the request body to enable or disable a channel for a kind of
+	// notification
+	PreferenceRequest struct {
+		// Kind is the kind of notification, "*" sets the channel for every kind
+		Kind    string `json:"kind" validate:"required"`
+		Channel string `json:"channel" validate:"required,oneof=log webhook email ntfy push"`
+		Enabled *bool  `json:"enabled" validate:"required"`
+	}
+)
+
+// GetPreferencesHandler returns the configured channels that deliver to the user and the user's
+// channel preferences
+func GetPreferencesHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+	dispatcher *notify.Dispatcher,
+) http.HandlerFunc {
+	type Response struct {
+		// Channels are the channels set up in the config, without the destinations of another
+		// account
+		Channels    []string                         `json:"channels"`
+		Preferences []*models.NotificationPreference `json:"preferences"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		channels, err := dispatcher.UserChannels(ctx, auth.UserID(ctx))
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get notification channels", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		prefs, err := dbConn.Queries.GetNotificationPreferences(ctx, auth.UserID(ctx))
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get notification preferences", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if prefs == nil {
+			prefs = []*models.NotificationPreference{}
+		}
+
+		jw.Ok(ctx, w, Response{
+			Channels:    channels,
+			Preferences: prefs,
+		})
+	}
+}
+
+// UpdatePreferenceHandler enables or disables a channel for a kind of notification. The channels
+// set up in the config with the destination of another account are refused, as notifications of the
+// user are never delivered to it.
+func UpdatePreferenceHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+	dispatcher *notify.Dispatcher,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[PreferenceRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		channels, err := dispatcher.UserChannels(ctx, auth.UserID(ctx))
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get notification channels", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if slices.Contains(dispatcher.Channels(), body.Channel) && !slices.Contains(channels, body.Channel) {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusUnprocessableEntity).
+				WithDetail("the "+body.Channel+" destination belongs to another account").
+				Build())
+			return
+		}
+
+		pref, err := dbConn.Queries.UpsertNotificationPreference(ctx, models.UpsertNotificationPreferenceParams{
+			UserID:  auth.UserID(ctx),
+			Kind:    body.Kind,
+			Channel: body.Channel,
+			Enabled: *body.Enabled,
+		})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to update notification preference", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, pref)
+	}
+}
+
+// GetDeliveriesHandler returns the delivery log of the user, most recent first
+func GetDeliveriesHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type (
+		Query struct {
+			Limit int64 `schema:"limit" validate:"omitempty,min=1,max=500"`
+		}
+
+		Response struct {
+			Deliveries []*models.NotificationDelivery `json:"deliveries"`
+		}
+	)
+
+	typedReader := request.NewTypedReader[Query](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		query, err := typedReader.ReadAndValidateQueryParams(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse query params", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if query.Limit == 0 {
+			query.Limit = 50
+		}
+
+		list, err := dbConn.Queries.GetNotificationDeliveries(ctx, models.GetNotificationDeliveriesParams{
+			UserID: auth.UserID(ctx),
+			Limit:  query.Limit,
+		})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get notification deliveries", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if list == nil {
+			list = []*models.NotificationDelivery{}
+		}
+
+		jw.Ok(ctx, w, Response{Deliveries: list})
+	}
+}
+
+// TestHandler sends a test notification through the channels enabled for it
+func TestHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dispatcher *notify.Dispatcher,
+) http.HandlerFunc {
+	type Response struct {
+		// Channels are the channels the notification was sent through
+		Channels []string `json:"channels"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		channels, err := dispatcher.EnabledChannels(ctx, auth.UserID(ctx), notify.KindTest)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get enabled channels", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		err = dispatcher.Notify(ctx, notify.Notification{
+			UserID: auth.UserID(ctx),
+			Kind:   notify.KindTest,
+			Title:  "CardMax test notification",
+			Body:   "Notifications from CardMax are reaching you through this channel.",
+		})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to send test notification", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		// Delivery is confirmed in the delivery log, failed attempts are retried
+		jw.Write(ctx, w, http.StatusAccepted, Response{Channels: channels})
+	}
+}
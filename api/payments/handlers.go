@@ -0,0 +1,144 @@
+package payments
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// PaymentRequest is the request body to record a payment towards a card
+type PaymentRequest struct {
+	CardID int64 `json:"card_id" validate:"required,min=1"`
+	// StatementID is the statement the payment is made towards, defaults to the card's oldest
+	// unpaid statement
+	StatementID *int64      `json:"statement_id" validate:"omitempty,min=1"`
+	Amount      money.Money `json:"amount" validate:"positive_money"`
+	// PaymentDate is the date of the payment as YYYY-MM-DD, defaults to today
+	PaymentDate string  `json:"payment_date" validate:"omitempty,datetime=2006-01-02"`
+	Notes       *string `json:"notes"`
+}
+
+// GetAllHandler returns the recorded payments of the user, optionally of a single card
+func GetAllHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type (
+		Query struct {
+			CardID int64 `schema:"card_id" validate:"omitempty,min=1"`
+		}
+
+		Response struct {
+			Payments []*models.Payment `json:"payments"`
+		}
+	)
+
+	typedReader := request.NewTypedReader[Query](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		query, err := typedReader.ReadAndValidateQueryParams(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse query params", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		var list []*models.Payment
+		if query.CardID != 0 {
+			list, err = dbConn.Queries.GetPaymentsByCardID(ctx, models.GetPaymentsByCardIDParams{
+				CardID: query.CardID,
+				UserID: auth.UserID(ctx),
+			})
+		} else {
+			list, err = dbConn.Queries.GetPayments(ctx, auth.UserID(ctx))
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get payments", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, Response{Payments: list})
+	}
+}
+
+// CreateHandler records a payment towards a card of the user
+func CreateHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[PaymentRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		_, err = dbConn.Queries.GetUserCardByID(ctx, models.GetUserCardByIDParams{
+			ID:     body.CardID,
+			UserID: auth.UserID(ctx),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusUnprocessableEntity).
+				WithDetail("card_id does not refer to a known card").
+				Build())
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get card", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		date := body.PaymentDate
+		if date == "" {
+			date = time.Now().Format(time.DateOnly)
+		}
+
+		payment, _, err := dbConn.RecordPayment(ctx, log, db.RecordPaymentParams{
+			CardID:      body.CardID,
+			StatementID: body.StatementID,
+			Amount:      body.Amount,
+			PaymentDate: date,
+			Notes:       body.Notes,
+		})
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, db.ErrStatementCardMismatch) {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusUnprocessableEntity).
+				WithDetail("statement_id does not refer to a statement of the card").
+				Build())
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to record payment", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Write(ctx, w, http.StatusCreated, payment)
+	}
+}
@@ -0,0 +1,145 @@
+package push
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/webpush"
+	"log/slog"
+	"net/http"
+)
+
+type (
+	// SubscriptionRequest is a browser's PushSubscription, as serialised by its toJSON()
+	SubscriptionRequest struct {
+		Endpoint string `json:"endpoint" validate:"required,url"`
+		Keys     struct {
+			P256dh string `json:"p256dh" validate:"required"`
+			Auth   string `json:"auth" validate:"required"`
+		} `json:"keys"`
+	}
+
+	// UnsubscribeRequest is the request body to remove a subscription
+	UnsubscribeRequest struct {
+		Endpoint string `json:"endpoint" validate:"required"`
+	}
+)
+
+// KeyHandler returns the VAPID public key browsers subscribe with
+func KeyHandler(
+	jw *response.JSONWriter,
+	vapid *webpush.VAPID,
+) http.HandlerFunc {
+	type Response struct {
+		PublicKey string `json:"public_key"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		jw.Ok(r.Context(), w, Response{PublicKey: vapid.PublicKey()})
+	}
+}
+
+// SubscribeHandler stores a push subscription of the user's browser, replacing the keys of an
+// endpoint the user subscribed before. The endpoint of another user's subscription is refused.
+func SubscribeHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[SubscriptionRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		sub := webpush.Subscription{
+			Endpoint: body.Endpoint,
+			P256dh:   body.Keys.P256dh,
+			Auth:     body.Keys.Auth,
+		}
+
+		err = sub.Validate()
+		if err != nil {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusUnprocessableEntity).
+				WithDetail(err.Error()).
+				Build())
+			return
+		}
+
+		stored, err := dbConn.Queries.UpsertPushSubscription(ctx, models.UpsertPushSubscriptionParams{
+			Endpoint: sub.Endpoint,
+			P256dh:   sub.P256dh,
+			Auth:     sub.Auth,
+			UserID:   auth.UserID(ctx),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusConflict).
+				WithDetail("the endpoint is subscribed by another user").
+				Build())
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to store push subscription", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Write(ctx, w, http.StatusCreated, stored)
+	}
+}
+
+// UnsubscribeHandler removes a push subscription of the user's browser
+func UnsubscribeHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[UnsubscribeRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		n, err := dbConn.Queries.DeleteUserPushSubscription(ctx, models.DeleteUserPushSubscriptionParams{
+			Endpoint: body.Endpoint,
+			UserID:   auth.UserID(ctx),
+		})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to delete push subscription", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if n == 0 {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusNotFound).
+				WithDetail("push subscription not found").
+				Build())
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
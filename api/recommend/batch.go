@@ -0,0 +1,262 @@
+package recommend
+
+import (
+	"cmp"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/cards"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"github.com/pushkar-anand/cardmax/internal/taxonomy"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+)
+
+type (
+	// BatchRequest is the request body for the batch recommendation API
+	BatchRequest struct {
+		Items []RecommendationRequest `json:"items" validate:"required,min=1,max=100,dive"`
+		// OrderInsensitive evaluates the items in a canonical order (largest amount first)
+		// so that the result does not depend on the order in which the items were sent
+		OrderInsensitive bool `json:"order_insensitive"`
+	}
+
+	// BatchItemResult is the recommendation for a single item of a batch
+	BatchItemResult struct {
+		Index    int                   `json:"index"`
+		Request  RecommendationRequest `json:"request"`
+		BestCard *RewardResult         `json:"best_card"`
+		AllCards []*RewardResult       `json:"all_cards"`
+	}
+
+	// CardAggregate sums up the items of a batch for which a card was the best choice.
+	// Spend and values are in INR.
+	CardAggregate struct {
+		Card            *cards.Card `json:"card"`
+		Items           int         `json:"items"`
+		Spend           money.Money `json:"spend"`
+		RewardValue     float64     `json:"reward_value"`
+		CashValue       money.Money `json:"cash_value"`
+		ForexMarkupCost money.Money `json:"forex_markup_cost"`
+		NetValue        money.Money `json:"net_value"`
+	}
+
+	// capUsage tracks the spend consumed against capped reward rules, keyed by card and rule
+	capUsage map[string]money.Money
+)
+
+// GetBatchRecommendationHandler handles recommendation requests for many items at once.
+// Items are evaluated one after another so that spend caps consumed by earlier items
+// apply to later ones.
+func GetBatchRecommendationHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	cfg config.Recommend,
+	dbConn *db.DB,
+	cardList []*cards.Card,
+	normaliser *taxonomy.Normaliser,
+) http.HandlerFunc {
+	type (
+		Request struct {
+			BatchRequest
+		}
+
+		Response struct {
+			Items          []*BatchItemResult `json:"items"`
+			Cards          []*CardAggregate   `json:"cards"`
+			TotalSpend     money.Money        `json:"total_spend"`
+			TotalCashValue money.Money        `json:"total_cash_value"`
+			TotalNetValue  money.Money        `json:"total_net_value"`
+		}
+	)
+
+	typedReader := request.NewTypedReader[Request](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		purchases := make([]purchase, 0, len(body.Items))
+
+		for _, item := range body.Items {
+			p, err := newPurchase(ctx, dbConn.Queries, cfg, normaliser, item)
+			if err != nil {
+				log.ErrorContext(ctx, "failed to convert purchase amount", logger.Error(err))
+				jw.WriteProblem(ctx, r, w, purchaseProblem(err))
+				return
+			}
+
+			purchases = append(purchases, p)
+		}
+
+		held, err := loadWallet(ctx, dbConn.Queries, auth.UserID(ctx))
+		if err != nil {
+			log.ErrorContext(ctx, "failed to load user cards", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		for _, p := range purchases {
+			err = checkStrategy(p, held)
+			if err != nil {
+				jw.WriteProblem(ctx, r, w, purchaseProblem(err))
+				return
+			}
+		}
+
+		// The items share the caps left in the current cycle of each card
+		usage, err := held.capUsage(ctx, dbConn.Queries, cardList, time.Now())
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get cap usage", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		items, aggregates := analyzeBatch(cardList, purchases, body.OrderInsensitive, held, usage)
+
+		resp := Response{
+			Items: items,
+			Cards: aggregates,
+		}
+
+		for _, agg := range aggregates {
+			resp.TotalSpend = resp.TotalSpend.Add(agg.Spend)
+			resp.TotalCashValue = resp.TotalCashValue.Add(agg.CashValue)
+			resp.TotalNetValue = resp.TotalNetValue.Add(agg.NetValue)
+		}
+
+		jw.Ok(ctx, w, resp)
+	}
+}
+
+// analyzeBatch recommends a card for each item of the batch and aggregates the results per card.
+// The items consume the caps after the spend usage already holds, which may be nil.
+// The returned items are always in the order of the request.
+func analyzeBatch(
+	cardList []*cards.Card,
+	items []purchase,
+	orderInsensitive bool,
+	held wallet,
+	usage capUsage,
+) ([]*BatchItemResult, []*CardAggregate) {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+
+	if orderInsensitive {
+		slices.SortStableFunc(order, func(a, b int) int {
+			x, y := items[a], items[b]
+
+			return cmp.Or(
+				y.AmountINR.Cmp(x.AmountINR),
+				cmp.Compare(x.Merchant, y.Merchant),
+				cmp.Compare(x.Category, y.Category),
+				cmp.Compare(x.Channel, y.Channel),
+			)
+		})
+	}
+
+	if usage == nil {
+		usage = make(capUsage)
+	}
+
+	results := make([]*BatchItemResult, len(items))
+
+	aggregates := make(map[string]*CardAggregate, len(cardList))
+	for _, card := range cardList {
+		aggregates[card.Key] = &CardAggregate{Card: card}
+	}
+
+	for _, i := range order {
+		item := items[i]
+
+		best, all := analyzeCards(cardList, item, usage, held)
+		results[i] = &BatchItemResult{
+			Index:    i,
+			Request:  item.RecommendationRequest,
+			BestCard: best,
+			AllCards: all,
+		}
+
+		if best == nil {
+			continue
+		}
+
+		for _, c := range best.Components {
+			usage.consume(best.Card, c.Rule, item.AmountINR)
+		}
+
+		agg := aggregates[best.Card.Key]
+		agg.Items++
+		agg.Spend = agg.Spend.Add(item.AmountINR)
+		agg.RewardValue += best.RewardValue
+		agg.CashValue = agg.CashValue.Add(best.CashValue)
+		agg.ForexMarkupCost = agg.ForexMarkupCost.Add(best.ForexMarkupCost)
+		agg.NetValue = agg.NetValue.Add(best.NetValue)
+	}
+
+	all := make([]*CardAggregate, 0, len(aggregates))
+	for _, agg := range aggregates {
+		// Cards rounding per statement truncate the total of the batch, not each item
+		if agg.Card.RoundingMode() == cards.RoundingFloorStatement {
+			rewardValue := agg.Card.EarnStatement(agg.RewardValue)
+			cash := cashValue(agg.Card, agg.Card.RewardType, rewardValue)
+
+			agg.NetValue = agg.NetValue.Add(cash.Sub(agg.CashValue))
+			agg.RewardValue = rewardValue
+			agg.CashValue = cash
+		}
+
+		all = append(all, agg)
+	}
+
+	// Sort by net value (highest first), card key keeps the order stable
+	sort.Slice(all, func(i, j int) bool {
+		if c := all[i].NetValue.Cmp(all[j].NetValue); c != 0 {
+			return c > 0
+		}
+
+		return all[i].Card.Key < all[j].Card.Key
+	})
+
+	return results, all
+}
+
+func capKey(card *cards.Card, rule *cards.Reward) string {
+	return card.Key + "|" + rule.Type + "|" + rule.EntityName + "|" + rule.Channel
+}
+
+// eligible returns how much of the amount still earns the rule's rate given its spend cap
+func (u capUsage) eligible(card *cards.Card, rule *cards.Reward, amount money.Money) money.Money {
+	if !rule.SpendCap.IsPositive() {
+		return amount
+	}
+
+	remaining := money.Max(rule.SpendCap.Sub(u[capKey(card, rule)]), money.Money{})
+
+	return money.Min(amount, remaining)
+}
+
+// consume records the amount as spent against the rule's cap
+func (u capUsage) consume(card *cards.Card, rule *cards.Reward, amount money.Money) {
+	if rule == nil || !rule.SpendCap.IsPositive() {
+		return
+	}
+
+	key := capKey(card, rule)
+	u[key] = u[key].Add(amount)
+}
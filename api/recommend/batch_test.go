@@ -0,0 +1,75 @@
+package recommend
+
+import (
+	"github.com/pushkar-anand/cardmax/internal/cards"
+	"math"
+	"testing"
+)
+
+func TestAnalyzeBatchCaps(t *testing.T) {
+	card := &cards.Card{
+		Key:               "TEST",
+		DefaultRewardRate: 1,
+		RewardType:        "Cashback",
+		RewardRules: []cards.Reward{
+			{Type: "Category", EntityName: "dining", RewardRate: 5, SpendCap: rupees(1000)},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		rupees           []int64
+		orderInsensitive bool
+		// rewards of the items in the order of the request
+		rewards []float64
+		total   float64
+	}{
+		{
+			name:    "earlier items use the cap",
+			rupees:  []int64{600, 600, 300},
+			rewards: []float64{30, 400*0.05 + 200*0.01, 3},
+			total:   55,
+		},
+		{
+			name:    "in order",
+			rupees:  []int64{300, 900},
+			rewards: []float64{15, 700*0.05 + 200*0.01},
+			total:   52,
+		},
+		{
+			name:             "order insensitive evaluates the largest first",
+			rupees:           []int64{300, 900},
+			orderInsensitive: true,
+			rewards:          []float64{100*0.05 + 200*0.01, 45},
+			total:            52,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := make([]purchase, len(tt.rupees))
+			for i, r := range tt.rupees {
+				items[i] = purchase{
+					RecommendationRequest: RecommendationRequest{Category: "dining", Amount: rupees(r)},
+					AmountINR:             rupees(r),
+				}
+			}
+
+			results, aggregates := analyzeBatch([]*cards.Card{card}, items, tt.orderInsensitive, nil, nil)
+
+			for i, result := range results {
+				if result.Index != i {
+					t.Errorf("item %d: index = %d", i, result.Index)
+				}
+
+				if got := result.BestCard.RewardValue; math.Abs(got-tt.rewards[i]) > 1e-9 {
+					t.Errorf("item %d: reward = %v, want %v", i, got, tt.rewards[i])
+				}
+			}
+
+			if got := aggregates[0].RewardValue; math.Abs(got-tt.total) > 1e-9 {
+				t.Errorf("total reward = %v, want %v", got, tt.total)
+			}
+		})
+	}
+}
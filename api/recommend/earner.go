@@ -0,0 +1,58 @@
+package recommend
+
+import (
+	"github.com/pushkar-anand/cardmax/internal/cards"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"strings"
+)
+
+// Earner calculates the rewards earned on spends that were made, e.g. imported from a statement.
+// Spends billed in the same cycle of a card share its spend caps, consumed in the order the
+// spends are earned.
+type Earner struct {
+	// usage is the cap usage of each cycle, keyed by cycle
+	usage map[string]capUsage
+}
+
+// NewEarner creates an earner with no spend consumed against any cap
+func NewEarner() *Earner {
+	return &Earner{usage: make(map[string]capUsage)}
+}
+
+// Earn returns the reward a card earns on a domestic spend in INR, or nil for a refund.
+// cycle identifies the statement cycle of the user card the spend is billed in.
+func (e *Earner) Earn(card *cards.Card, cycle, merchant, category, channel string, amount money.Money) *RewardResult {
+	if !amount.IsPositive() {
+		return nil
+	}
+
+	usage, ok := e.usage[cycle]
+	if !ok {
+		usage = make(capUsage)
+		e.usage[cycle] = usage
+	}
+
+	return usage.earn(card, merchant, category, channel, amount)
+}
+
+// earn returns the reward the card earns on a domestic spend in INR, consuming the caps of the
+// rules it earns under
+func (u capUsage) earn(card *cards.Card, merchant, category, channel string, amount money.Money) *RewardResult {
+	p := purchase{
+		RecommendationRequest: RecommendationRequest{
+			Merchant: strings.ToLower(merchant),
+			Category: strings.ToLower(category),
+			Amount:   amount,
+			Channel:  strings.ToLower(channel),
+		},
+		AmountINR: amount,
+	}
+
+	result := evaluateRules(p, card, u)
+
+	for _, c := range result.Components {
+		u.consume(card, c.Rule, amount)
+	}
+
+	return result
+}
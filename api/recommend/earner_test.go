@@ -0,0 +1,87 @@
+package recommend
+
+import (
+	"github.com/pushkar-anand/cardmax/internal/cards"
+	"math"
+	"testing"
+)
+
+func TestEarnerCapsPerCycle(t *testing.T) {
+	newCard := func(key string) *cards.Card {
+		return &cards.Card{
+			Key:               key,
+			DefaultRewardRate: 1,
+			RewardType:        "Cashback",
+			RewardRules: []cards.Reward{
+				{Type: "Category", EntityName: "dining", RewardRate: 5, SpendCap: rupees(1000)},
+			},
+		}
+	}
+
+	first, second := newCard("FIRST"), newCard("SECOND")
+
+	// Spends are earned in order on the same earner
+	spends := []struct {
+		name   string
+		card   *cards.Card
+		cycle  string
+		rupees int64
+		reward float64
+	}{
+		{name: "within the cap", card: first, cycle: "2025-01", rupees: 600, reward: 30},
+		{name: "split at the cap", card: first, cycle: "2025-01", rupees: 600, reward: 400*0.05 + 200*0.01},
+		{name: "next cycle starts a new cap", card: first, cycle: "2025-02", rupees: 600, reward: 30},
+		{name: "cap of the previous cycle stays used", card: first, cycle: "2025-01", rupees: 300, reward: 3},
+		{name: "split at the cap of the next cycle", card: first, cycle: "2025-02", rupees: 500, reward: 400*0.05 + 100*0.01},
+		{name: "another card has its own cap", card: second, cycle: "2025-01", rupees: 1000, reward: 50},
+		{name: "refund earns nothing", card: first, cycle: "2025-03", rupees: -100},
+		{name: "refund does not use the cap", card: first, cycle: "2025-03", rupees: 1000, reward: 50},
+	}
+
+	e := NewEarner()
+
+	for _, s := range spends {
+		result := e.Earn(s.card, s.cycle, "", "dining", "", rupees(s.rupees))
+
+		var got float64
+		if result != nil {
+			got = result.RewardValue
+		}
+
+		if math.Abs(got-s.reward) > 1e-9 {
+			t.Errorf("%s: reward on ₹%d in %s = %v, want %v", s.name, s.rupees, s.cycle, got, s.reward)
+		}
+	}
+}
+
+func TestCapUsage(t *testing.T) {
+	card := &cards.Card{Key: "TEST"}
+	capped := &cards.Reward{Type: "Category", EntityName: "dining", SpendCap: rupees(1000)}
+	uncapped := &cards.Reward{Type: "Category", EntityName: "travel"}
+
+	tests := []struct {
+		name     string
+		rule     *cards.Reward
+		used     int64
+		amount   int64
+		eligible int64
+	}{
+		{name: "nothing used", rule: capped, amount: 600, eligible: 600},
+		{name: "crosses the cap", rule: capped, used: 600, amount: 600, eligible: 400},
+		{name: "reaches the cap", rule: capped, used: 400, amount: 600, eligible: 600},
+		{name: "cap used up", rule: capped, used: 1000, amount: 600},
+		{name: "cap overspent", rule: capped, used: 1200, amount: 600},
+		{name: "uncapped", rule: uncapped, used: 5000, amount: 600, eligible: 600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage := make(capUsage)
+			usage.consume(card, tt.rule, rupees(tt.used))
+
+			if got := usage.eligible(card, tt.rule, rupees(tt.amount)); got.Minor() != rupees(tt.eligible).Minor() {
+				t.Errorf("eligible = %v, want %v", got, rupees(tt.eligible))
+			}
+		})
+	}
+}
@@ -1,22 +1,42 @@
 package recommend
 
 import (
+	"cmp"
 	"github.com/pushkar-anand/build-with-go/http/request"
 	"github.com/pushkar-anand/build-with-go/http/response"
 	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/auth"
 	"github.com/pushkar-anand/cardmax/internal/cards"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"github.com/pushkar-anand/cardmax/internal/taxonomy"
 	"github.com/pushkar-anand/cardmax/web"
 	"log/slog"
 	"net/http"
 	"sort"
+	"time"
 )
 
 type (
 	// RecommendationRequest is the request body for recommendation API
 	RecommendationRequest struct {
-		Merchant string  `json:"merchant" validate:"required_without=Category"`
-		Category string  `json:"category" validate:"required_without=Merchant"`
-		Amount   float64 `json:"amount" validate:"required,min=1"`
+		Merchant string      `json:"merchant" validate:"required_without=Category"`
+		Category string      `json:"category" validate:"required_without=Merchant"`
+		Amount   money.Money `json:"amount" validate:"positive_money"`
+		// Currency is the ISO 4217 code of the amount, defaults to INR
+		Currency string `json:"currency,omitempty" validate:"omitempty,iso4217"`
+		// International marks a spend with a foreign merchant, even when billed in INR
+		International bool `json:"international,omitempty"`
+		// Channel is how the purchase is paid for, e.g. upi or contactless
+		Channel string `json:"channel,omitempty" validate:"omitempty,oneof=upi online offline contactless wallet"`
+		// PurchaseDate is the date of the purchase as YYYY-MM-DD, defaults to today
+		PurchaseDate string `json:"purchase_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+		// Strategy is what the ranking optimises for: rewards (default) or float
+		Strategy string `json:"strategy,omitempty" validate:"omitempty,oneof=rewards float"`
+		// OpportunityCostRate overrides the configured annual rate (in percent) used to value
+		// interest-free days in the float strategy
+		OpportunityCostRate *float64 `json:"opportunity_cost_rate,omitempty" validate:"omitempty,min=0,max=100"`
 	}
 
 	// RewardResult represents the calculated reward for a card
@@ -25,8 +45,36 @@ type (
 		RewardRate  float64       `json:"reward_rate"`
 		RewardType  string        `json:"reward_type"`
 		RewardValue float64       `json:"reward_value"`
-		CashValue   float64       `json:"cash_value"`
+		CashValue   money.Money   `json:"cash_value"`
 		Rule        *cards.Reward `json:"rule,omitempty"`
+		// CappedSpend is the part of the amount that fell back to the default rate
+		// because the rule's spend cap was exhausted
+		CappedSpend money.Money `json:"capped_spend,omitzero"`
+		// AmountINR is the purchase amount converted to INR
+		AmountINR money.Money `json:"amount_inr"`
+		// ForexMarkupCost is the markup charged by the issuer on an international purchase
+		ForexMarkupCost money.Money `json:"forex_markup_cost"`
+		// NetValue is the cash value of the reward minus the forex markup cost
+		NetValue money.Money `json:"net_value"`
+		// Components break the combined reward down into the base rate and the stacked rules
+		Components []*RewardComponent `json:"components"`
+		// RoundingAdjustment is the reward lost to the card's earning blocks and rounding
+		RoundingAdjustment float64 `json:"rounding_adjustment,omitempty"`
+		// Holder is whose card of the wallet this is, the user's own or one their household
+		// shares, only set when the wallet holds the card
+		Holder *Holder `json:"holder,omitempty"`
+		// InterestFreeDays is the number of days from the purchase until its payment is due,
+		// only set when the wallet holds the card
+		InterestFreeDays *int `json:"interest_free_days,omitempty"`
+		// DueDate is the payment due date of the purchase as YYYY-MM-DD
+		DueDate string `json:"due_date,omitempty"`
+		// FloatValue is the interest-free period valued at the opportunity-cost rate
+		FloatValue money.Money `json:"float_value,omitzero"`
+		// Utilisation is the credit utilisation in percent after the purchase, only set when the
+		// wallet holds the card and its credit limit is known
+		Utilisation *float64 `json:"utilisation,omitempty"`
+		// OverUtilised marks a card whose utilisation would exceed the configured threshold
+		OverUtilised bool `json:"over_utilised,omitempty"`
 	}
 )
 
@@ -35,6 +83,10 @@ func GetRecommendationHandler(
 	log *slog.Logger,
 	jw *response.JSONWriter,
 	reader *request.Reader,
+	cfg config.Recommend,
+	dbConn *db.DB,
+	cardList []*cards.Card,
+	normaliser *taxonomy.Normaliser,
 ) http.HandlerFunc {
 	type (
 		Request struct {
@@ -59,11 +111,34 @@ func GetRecommendationHandler(
 			return
 		}
 
-		var cardsToUse []*cards.Card
+		p, err := newPurchase(ctx, dbConn.Queries, cfg, normaliser, body.RecommendationRequest)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to convert purchase amount", logger.Error(err))
+			jw.WriteProblem(ctx, r, w, purchaseProblem(err))
+			return
+		}
 
-		//cardsToUse = cards.GetAll()
+		held, err := loadWallet(ctx, dbConn.Queries, auth.UserID(ctx))
+		if err != nil {
+			log.ErrorContext(ctx, "failed to load user cards", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
 
-		best, all := analyzeCards(cardsToUse, body.RecommendationRequest)
+		err = checkStrategy(p, held)
+		if err != nil {
+			jw.WriteProblem(ctx, r, w, purchaseProblem(err))
+			return
+		}
+
+		usage, err := held.capUsage(ctx, dbConn.Queries, cardList, p.Date)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get cap usage", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		best, all := analyzeCards(cardList, p, usage, held)
 
 		// Prepare a response with the best card and all cards
 		resp := Response{
@@ -80,6 +155,10 @@ func GetRecommendationHTMLHandler(
 	log *slog.Logger,
 	reader *request.Reader,
 	tr *web.Renderer,
+	cfg config.Recommend,
+	dbConn *db.DB,
+	cardList []*cards.Card,
+	normaliser *taxonomy.Normaliser,
 ) http.HandlerFunc {
 	type (
 		Request struct {
@@ -99,31 +178,54 @@ func GetRecommendationHTMLHandler(
 			return
 		}
 
-		var cardsToUse []*cards.Card
+		p, err := newPurchase(ctx, dbConn.Queries, cfg, normaliser, data.RecommendationRequest)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to convert purchase amount", logger.Error(err))
+			http.Error(w, purchaseProblem(err).Detail(), http.StatusBadRequest)
+			return
+		}
+
+		held, err := loadWallet(ctx, dbConn.Queries, auth.UserID(ctx))
+		if err != nil {
+			log.ErrorContext(ctx, "failed to load user cards", logger.Error(err))
+			http.Error(w, "Failed to load cards", http.StatusInternalServerError)
+			return
+		}
 
-		// For now, use all available cards (later can be based on user selection)
-		//cardsToUse := cards.GetAll()
+		err = checkStrategy(p, held)
+		if err != nil {
+			http.Error(w, purchaseProblem(err).Detail(), http.StatusBadRequest)
+			return
+		}
 
-		best, all := analyzeCards(cardsToUse, data.RecommendationRequest)
+		usage, err := held.capUsage(ctx, dbConn.Queries, cardList, p.Date)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get cap usage", logger.Error(err))
+			http.Error(w, "Failed to load cards", http.StatusInternalServerError)
+			return
+		}
+
+		// For now, use all available cards (later can be based on user selection)
+		best, all := analyzeCards(cardList, p, usage, held)
 
 		// Prepare template data
 		tmplData := map[string]interface{}{
-			"AllCards": all,
-			"Amount":   data.Amount,
-			"Merchant": data.Merchant,
-			"Category": data.Category,
-			"BestCard": best,
-		}
-
-		// Add the best card if there are results
-		if best != nil {
-			tmplData["BestCard"] = best
+			"AllCards":      all,
+			"Amount":        p.Amount,
+			"AmountINR":     p.AmountINR,
+			"Currency":      p.Currency,
+			"International": p.International,
+			"Channel":       p.Channel,
+			"Strategy":      p.Strategy,
+			"Merchant":      data.Merchant,
+			"Category":      data.Category,
+			"BestCard":      best,
 		}
 
 		log.DebugContext(ctx, "recommendation result", slog.Any("result", tmplData))
 
 		// Render the HTML template
-		err = tr.RenderPartial(w, web.PartialRecommendationResult, tmplData)
+		err = tr.RenderPartial(w, r, web.PartialRecommendationResult, tmplData)
 		if err != nil {
 			log.ErrorContext(ctx, "error rendering recommendation template", logger.Error(err))
 			http.Error(w, "Failed to render recommendation", http.StatusInternalServerError)
@@ -132,45 +234,60 @@ func GetRecommendationHTMLHandler(
 	}
 }
 
-func analyzeCards(cardsToUse []*cards.Card, rr RecommendationRequest) (best *RewardResult, all []*RewardResult) {
+// analyzeCards calculates the reward of every card for the purchase and ranks them.
+// usage holds the spend already consumed against capped rules, e.g. by the ledger in the current
+// statement cycle; it may be nil when caps should be evaluated from a fresh statement cycle. held are the cards of the user and those their
+// household shares, used to tell whose card each is and to work out the interest-free period of
+// the purchase and the credit utilisation after it.
+//
+// Cards whose utilisation would exceed the threshold are ranked last to protect the user's
+// credit score. Otherwise, cards are ranked by net value, ties going to the longest
+// interest-free period. The float strategy only considers the wallet's cards and ranks them by
+// net value plus float value, or by the interest-free period first when the opportunity-cost
+// rate is zero.
+func analyzeCards(cardsToUse []*cards.Card, p purchase, usage capUsage, held wallet) (best *RewardResult, all []*RewardResult) {
 	all = make([]*RewardResult, 0, len(cardsToUse))
 
 	for _, card := range cardsToUse {
-		bestRule := findBestRule(rr.Merchant, rr.Category, card)
-
-		// Calculate reward rate
-		rewardRate := card.DefaultRewardRate
-		rewardType := card.RewardType
-
-		if bestRule != nil {
-			rewardRate = bestRule.RewardRate
-			rewardType = bestRule.RewardType
+		result := evaluateRules(p, card, usage)
+
+		days, due, holder, ok := held.interestFree(card.Key, p.Date)
+		if ok {
+			result.Holder = &holder
+			result.InterestFreeDays = &days
+			result.DueDate = due.Format(time.DateOnly)
+			result.FloatValue = p.AmountINR.Mul(p.FloatRate/100*float64(days)/365, money.HalfEven)
 		}
 
-		// Calculate reward value
-		rewardValue := (rr.Amount * rewardRate) / 100
-
-		// Calculate cash value
-		cashValue := rewardValue
-		if rewardType == "Points" || rewardType == "Miles" {
-			cashValue = rewardValue * card.PointValue
+		if !ok && p.Strategy == StrategyFloat {
+			continue
 		}
 
-		result := &RewardResult{
-			Card:        card,
-			RewardRate:  rewardRate,
-			RewardType:  rewardType,
-			RewardValue: rewardValue,
-			CashValue:   cashValue,
-			Rule:        bestRule,
+		if u, ok := held.utilisation(card.Key, p.AmountINR); ok {
+			result.Utilisation = &u
+			result.OverUtilised = u > p.UtilisationThreshold
 		}
 
 		all = append(all, result)
 	}
 
-	// Sort by cash value (highest first)
-	sort.Slice(all, func(i, j int) bool {
-		return all[i].CashValue > all[j].CashValue
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].OverUtilised != all[j].OverUtilised {
+			return !all[i].OverUtilised
+		}
+
+		value := all[i].NetValue.Cmp(all[j].NetValue)
+		interestFree := cmp.Compare(all[i].interestFreeDays(), all[j].interestFreeDays())
+
+		if p.Strategy == StrategyFloat {
+			if p.FloatRate == 0 {
+				return cmp.Or(interestFree, value) > 0
+			}
+
+			value = all[i].NetValue.Add(all[i].FloatValue).Cmp(all[j].NetValue.Add(all[j].FloatValue))
+		}
+
+		return cmp.Or(value, interestFree) > 0
 	})
 
 	if len(all) == 0 {
@@ -180,20 +297,22 @@ func analyzeCards(cardsToUse []*cards.Card, rr RecommendationRequest) (best *Rew
 	return all[0], all
 }
 
-// findBestRule finds the best matching rule for a merchant and category
-func findBestRule(merchant, category string, card *cards.Card) *cards.Reward {
-	var bestRule *cards.Reward
-	var bestRate float64 = card.DefaultRewardRate
-
-	for _, rule := range card.RewardRules {
-		if (rule.Type == "Merchant" && rule.EntityName == merchant) ||
-			(rule.Type == "Category" && rule.EntityName == category) {
-			if rule.RewardRate > bestRate {
-				bestRule = &rule
-				bestRate = rule.RewardRate
-			}
-		}
+// interestFreeDays returns the interest-free period of the result, -1 when the wallet does not
+// hold the card
+func (r *RewardResult) interestFreeDays() int {
+	if r.InterestFreeDays == nil {
+		return -1
+	}
+
+	return *r.InterestFreeDays
+}
+
+// UtilisationPercent returns the utilisation after the purchase, 0 when it is not known.
+// It is meant for templates, which cannot format the pointer.
+func (r *RewardResult) UtilisationPercent() float64 {
+	if r.Utilisation == nil {
+		return 0
 	}
 
-	return bestRule
+	return *r.Utilisation
 }
@@ -0,0 +1,184 @@
+package recommend
+
+import (
+	"errors"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/billing"
+	"github.com/pushkar-anand/cardmax/internal/cards"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+// hold returns a card of the user's wallet whose statement is generated on the day, due 20 days
+// later, with the outstanding balance against the credit limit in rupees, 0 when it is not known
+func hold(statementDay int, limit, outstanding int64) heldCard {
+	return heldCard{
+		schedule: billing.Schedule{StatementDay: statementDay, PaymentDueDays: 20},
+		balance:  billing.NewBalance(rupees(limit), rupees(outstanding), rupees(0)),
+		holder:   Holder{Own: true},
+	}
+}
+
+// ranking returns the keys of the ranked cards
+func ranking(all []*RewardResult) []string {
+	keys := make([]string, 0, len(all))
+	for _, r := range all {
+		keys = append(keys, r.Card.Key)
+	}
+
+	return keys
+}
+
+func TestAnalyzeCardsFloat(t *testing.T) {
+	cardList := []*cards.Card{
+		{Key: "JUST-CLOSED", DefaultRewardRate: 1, RewardType: "Cashback"},
+		{Key: "CLOSING", DefaultRewardRate: 2, RewardType: "Cashback"},
+		{Key: "JUST-CLOSED-TOO", DefaultRewardRate: 2, RewardType: "Cashback"},
+		{Key: "NOT-HELD", DefaultRewardRate: 5, RewardType: "Cashback"},
+	}
+
+	// On 18 October, a spend on the cards whose statement was generated the day before is due in
+	// 50 days, on the card whose statement is generated on the 20th in 22 days
+	held := wallet{
+		"JUST-CLOSED":     {hold(17, 0, 0)},
+		"CLOSING":         {hold(20, 0, 0)},
+		"JUST-CLOSED-TOO": {hold(17, 0, 0)},
+	}
+
+	tests := []struct {
+		name     string
+		strategy string
+		rate     float64
+		want     []string
+		// float is the float value of a spend due in 50 days, then in 22 days
+		float [2]string
+	}{
+		{name: "rewards", strategy: StrategyRewards, want: []string{"NOT-HELD", "JUST-CLOSED-TOO", "CLOSING", "JUST-CLOSED"}, float: [2]string{"0", "0"}},
+		// Without an opportunity cost, the interest-free period ranks first and rewards break ties
+		{name: "float without a rate", strategy: StrategyFloat, want: []string{"JUST-CLOSED-TOO", "JUST-CLOSED", "CLOSING"}, float: [2]string{"0", "0"}},
+		// ₹200 and ₹72.33 of float beat ₹100 and ₹164.38
+		{name: "float at 12%", strategy: StrategyFloat, rate: 12, want: []string{"JUST-CLOSED-TOO", "CLOSING", "JUST-CLOSED"}, float: [2]string{"164.38", "72.33"}},
+		// ₹100 and ₹246.58 of float beat ₹200 and ₹108.49
+		{name: "float at 18%", strategy: StrategyFloat, rate: 18, want: []string{"JUST-CLOSED-TOO", "JUST-CLOSED", "CLOSING"}, float: [2]string{"246.58", "108.49"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := purchase{
+				RecommendationRequest: RecommendationRequest{Category: "dining", Strategy: tt.strategy},
+				AmountINR:             rupees(10000),
+				Date:                  time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC),
+				FloatRate:             tt.rate,
+				UtilisationThreshold:  30,
+			}
+
+			best, all := analyzeCards(cardList, p, make(capUsage), held)
+
+			if got := ranking(all); !slices.Equal(got, tt.want) {
+				t.Fatalf("ranking = %v, want %v", got, tt.want)
+			}
+
+			if best != all[0] {
+				t.Errorf("best = %s, want %s", best.Card.Key, all[0].Card.Key)
+			}
+
+			for _, r := range all {
+				if r.Card.Key == "NOT-HELD" {
+					if r.InterestFreeDays != nil || r.DueDate != "" {
+						t.Errorf("%s: interest-free days %v due %q, want none for a card not held", r.Card.Key, r.InterestFreeDays, r.DueDate)
+					}
+
+					continue
+				}
+
+				days, due, float := 50, "2026-12-07", tt.float[0]
+				if r.Card.Key == "CLOSING" {
+					days, due, float = 22, "2026-11-09", tt.float[1]
+				}
+
+				if r.InterestFreeDays == nil || *r.InterestFreeDays != days || r.DueDate != due {
+					t.Errorf("%s: interest-free days %v due %q, want %d due %s", r.Card.Key, r.InterestFreeDays, r.DueDate, days, due)
+				}
+
+				if want := money.MustParse(float, money.INR); r.FloatValue.Cmp(want) != 0 {
+					t.Errorf("%s: float value = %v, want %v", r.Card.Key, r.FloatValue, want)
+				}
+			}
+		})
+	}
+
+	t.Run("no wallet", func(t *testing.T) {
+		p := purchase{RecommendationRequest: RecommendationRequest{Strategy: StrategyFloat}, AmountINR: rupees(1000)}
+
+		if err := checkStrategy(p, wallet{}); !errors.Is(err, errNoUserCards) {
+			t.Errorf("checkStrategy() = %v, want %v", err, errNoUserCards)
+		}
+
+		if best, all := analyzeCards(cardList, p, make(capUsage), wallet{}); best != nil || len(all) != 0 {
+			t.Errorf("ranked %v, want no cards", ranking(all))
+		}
+	})
+}
+
+func TestAnalyzeCardsUtilisation(t *testing.T) {
+	cardList := []*cards.Card{
+		{Key: "HIGH", DefaultRewardRate: 5, RewardType: "Cashback"},
+		{Key: "LOW", DefaultRewardRate: 2, RewardType: "Cashback"},
+		{Key: "NO-LIMIT", DefaultRewardRate: 1, RewardType: "Cashback"},
+		{Key: "TWO-HELD", DefaultRewardRate: 3, RewardType: "Cashback"},
+	}
+
+	// After a spend of ₹10000, HIGH is 35% utilised, LOW 20% and the less utilised card of
+	// TWO-HELD 10%
+	held := wallet{
+		"HIGH":     {hold(5, 100000, 25000)},
+		"LOW":      {hold(5, 100000, 10000)},
+		"NO-LIMIT": {hold(5, 0, 50000)},
+		"TWO-HELD": {hold(5, 100000, 90000), hold(5, 100000, 0)},
+	}
+
+	utilisation := map[string]float64{"HIGH": 35, "LOW": 20, "TWO-HELD": 10}
+
+	tests := []struct {
+		threshold float64
+		want      []string
+	}{
+		{threshold: 40, want: []string{"HIGH", "TWO-HELD", "LOW", "NO-LIMIT"}},
+		// Utilisation at the threshold is within it
+		{threshold: 35, want: []string{"HIGH", "TWO-HELD", "LOW", "NO-LIMIT"}},
+		{threshold: 30, want: []string{"TWO-HELD", "LOW", "NO-LIMIT", "HIGH"}},
+		{threshold: 10, want: []string{"TWO-HELD", "NO-LIMIT", "HIGH", "LOW"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.threshold), func(t *testing.T) {
+			p := purchase{
+				RecommendationRequest: RecommendationRequest{Category: "dining"},
+				AmountINR:             rupees(10000),
+				Date:                  time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC),
+				UtilisationThreshold:  tt.threshold,
+			}
+
+			_, all := analyzeCards(cardList, p, make(capUsage), held)
+
+			if got := ranking(all); !slices.Equal(got, tt.want) {
+				t.Fatalf("ranking = %v, want %v", got, tt.want)
+			}
+
+			for _, r := range all {
+				want, known := utilisation[r.Card.Key]
+
+				if (r.Utilisation != nil) != known || (known && math.Abs(*r.Utilisation-want) > 1e-9) {
+					t.Errorf("%s: utilisation = %v, want %v", r.Card.Key, r.Utilisation, want)
+				}
+
+				if over := known && want > tt.threshold; r.OverUtilised != over {
+					t.Errorf("%s: over utilised = %t, want %t", r.Card.Key, r.OverUtilised, over)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package recommend
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/fx"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"github.com/pushkar-anand/cardmax/internal/taxonomy"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Recommendation strategies
+const (
+	// StrategyRewards ranks cards by the net value of their rewards
+	StrategyRewards = "rewards"
+	// StrategyFloat ranks the wallet's cards, the user's and those their household shares, by the
+	// interest-free credit they give on the purchase
+	StrategyFloat = "float"
+)
+
+var (
+	// errUnknownCurrency is returned when no exchange rate is stored for the requested currency
+	errUnknownCurrency = errors.New("no exchange rate for currency")
+	// errNoUserCards is returned when the float strategy is requested without any user card
+	// linked to a predefined card
+	errNoUserCards = errors.New("the float strategy needs user cards linked to a predefined card")
+)
+
+// purchase is a recommendation request with its amount converted to INR
+type purchase struct {
+	RecommendationRequest
+	AmountINR money.Money
+	Date      time.Time
+	// FloatRate is the annual opportunity-cost rate in percent used by the float strategy
+	FloatRate float64
+	// UtilisationThreshold is the credit utilisation in percent above which a card is ranked last
+	UtilisationThreshold float64
+}
+
+// newPurchase converts the request amount to INR using the stored exchange rates.
+// A purchase in a foreign currency is always international. The merchant is named as reward rules
+// refer to it by the normaliser, with the mappings learned from the user's corrections, which also
+// categorises a purchase without a category. A merchant the normaliser does not know is lower cased.
+func newPurchase(
+	ctx context.Context,
+	q *models.Queries,
+	cfg config.Recommend,
+	normaliser *taxonomy.Normaliser,
+	rr RecommendationRequest,
+) (purchase, error) {
+	rr.Currency = strings.ToUpper(rr.Currency)
+	rr.Channel = strings.ToLower(rr.Channel)
+
+	if m, ok := normaliser.Normalise(auth.UserID(ctx), rr.Merchant); ok {
+		rr.Merchant = m.Merchant
+		rr.Category = cmp.Or(rr.Category, m.Category)
+	} else {
+		// Reward rules name merchants in lower case
+		rr.Merchant = strings.ToLower(strings.TrimSpace(rr.Merchant))
+	}
+
+	if rr.Strategy == "" {
+		rr.Strategy = StrategyRewards
+	}
+
+	p := purchase{
+		FloatRate:            cfg.Float.Rate,
+		UtilisationThreshold: cfg.Utilisation.Threshold,
+		Date:                 time.Now(),
+	}
+	if rr.OpportunityCostRate != nil {
+		p.FloatRate = *rr.OpportunityCostRate
+	}
+
+	// The date format is validated with the request
+	if rr.PurchaseDate != "" {
+		p.Date, _ = time.Parse(time.DateOnly, rr.PurchaseDate)
+	}
+
+	if fx.IsBase(rr.Currency) {
+		rr.Currency = fx.BaseCurrency
+		rr.Amount = rr.Amount.In(money.INR)
+
+		p.RecommendationRequest, p.AmountINR = rr, rr.Amount
+
+		return p, nil
+	}
+
+	rate, err := q.GetExchangeRate(ctx, rr.Currency)
+	if errors.Is(err, sql.ErrNoRows) {
+		return purchase{}, fmt.Errorf("%w %s", errUnknownCurrency, rr.Currency)
+	}
+
+	if err != nil {
+		return purchase{}, fmt.Errorf("failed to get exchange rate for %s: %w", rr.Currency, err)
+	}
+
+	rr.International = true
+	rr.Amount = rr.Amount.In(money.Currency(rr.Currency))
+
+	conv := fx.Rate{Currency: rate.Currency, RateToINR: rate.RateToInr, AsOf: rate.AsOf}
+
+	p.RecommendationRequest, p.AmountINR = rr, conv.ToINR(rr.Amount)
+
+	return p, nil
+}
+
+// checkStrategy reports whether the purchase's strategy can be applied to the wallet's cards
+func checkStrategy(p purchase, held wallet) error {
+	if p.Strategy == StrategyFloat && len(held) == 0 {
+		return errNoUserCards
+	}
+
+	return nil
+}
+
+// purchaseProblem maps a newPurchase error to a problem response
+func purchaseProblem(err error) response.Problem {
+	if errors.Is(err, errUnknownCurrency) || errors.Is(err, errNoUserCards) {
+		return response.NewProblem().
+			WithStatus(http.StatusUnprocessableEntity).
+			WithDetail(err.Error()).
+			Build()
+	}
+
+	return response.NewProblem().Build()
+}
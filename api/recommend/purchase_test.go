@@ -0,0 +1,140 @@
+package recommend
+
+import (
+	"context"
+	"errors"
+	"github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/cards"
+	"github.com/pushkar-anand/cardmax/internal/fx"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"github.com/pushkar-anand/cardmax/internal/taxonomy"
+	"math"
+	"testing"
+)
+
+func TestNewPurchaseCurrency(t *testing.T) {
+	ctx := context.Background()
+
+	err := testDB.PopulateExchangeRates(ctx, discard, []*fx.Rate{
+		{Currency: "USD", RateToINR: 83.25, AsOf: "2026-10-01"},
+		{Currency: "EUR", RateToINR: 90.1, AsOf: "2026-10-01"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An empty taxonomy leaves the merchant as requested
+	normaliser, err := taxonomy.NewNormaliser(ctx, testDB, &taxonomy.Taxonomy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		amount        string
+		currency      string
+		international bool
+		// wantCurrency is the currency of the purchase, amountINR its amount converted to INR
+		wantCurrency      string
+		amountINR         string
+		wantInternational bool
+		err               error
+	}{
+		{name: "INR by default", amount: "1000", wantCurrency: "INR", amountINR: "1000"},
+		{name: "INR in lower case", amount: "1000", currency: "inr", wantCurrency: "INR", amountINR: "1000"},
+		{name: "international INR", amount: "1000", international: true, wantCurrency: "INR", amountINR: "1000", wantInternational: true},
+		{name: "USD rounded half to even", amount: "10.50", currency: "USD", wantCurrency: "USD", amountINR: "874.12", wantInternational: true},
+		{name: "EUR in lower case", amount: "20", currency: "eur", wantCurrency: "EUR", amountINR: "1802", wantInternational: true},
+		{name: "unknown currency", amount: "100", currency: "JPY", err: errUnknownCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := newPurchase(ctx, testDB.Queries, config.Recommend{}, normaliser, RecommendationRequest{
+				Category: "dining",
+				// Amounts are decoded before their currency is known
+				Amount:        money.MustParse(tt.amount, money.INR),
+				Currency:      tt.currency,
+				International: tt.international,
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+
+			if tt.err != nil {
+				return
+			}
+
+			if p.Currency != tt.wantCurrency || p.Amount.Currency() != money.Currency(tt.wantCurrency) {
+				t.Errorf("currency = %s, amount %v, want %s", p.Currency, p.Amount, tt.wantCurrency)
+			}
+
+			if want := money.MustParse(tt.amountINR, money.INR); p.AmountINR.Cmp(want) != 0 {
+				t.Errorf("amount in INR = %v, want %v", p.AmountINR, want)
+			}
+
+			if p.International != tt.wantInternational {
+				t.Errorf("international = %t, want %t", p.International, tt.wantInternational)
+			}
+		})
+	}
+}
+
+func TestInternationalRewards(t *testing.T) {
+	card := &cards.Card{
+		Key:               "TEST-FOREX",
+		DefaultRewardRate: 1,
+		RewardType:        "Cashback",
+		ForexMarkup:       3.5,
+		RewardRules: []cards.Reward{
+			{Type: "International", EntityName: cards.AllCurrencies, RewardRate: 2},
+			{Type: "International", EntityName: "USD", RewardRate: 4, Precedence: 1},
+			{Type: "Category", EntityName: "travel", RewardRate: 3},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		category      string
+		currency      string
+		international bool
+		// rate is the combined rate, markup the forex markup and net the reward minus the markup on ₹1000
+		rate   float64
+		markup string
+		net    string
+	}{
+		{name: "domestic", category: "dining", currency: "INR", rate: 1, markup: "0", net: "10"},
+		{name: "domestic category", category: "travel", currency: "INR", rate: 3, markup: "0", net: "30"},
+		{name: "international INR", category: "dining", currency: "INR", international: true, rate: 2, markup: "35", net: "-15"},
+		{name: "any currency", category: "dining", currency: "EUR", international: true, rate: 2, markup: "35", net: "-15"},
+		{name: "currency of a rule", category: "dining", currency: "USD", international: true, rate: 4, markup: "35", net: "5"},
+		{name: "category beats any currency", category: "travel", currency: "EUR", international: true, rate: 3, markup: "35", net: "-5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := purchase{
+				RecommendationRequest: RecommendationRequest{
+					Category:      tt.category,
+					Currency:      tt.currency,
+					International: tt.international,
+				},
+				AmountINR: rupees(1000),
+			}
+
+			result := evaluateRules(p, card, make(capUsage))
+
+			if math.Abs(result.RewardRate-tt.rate) > 1e-9 {
+				t.Errorf("reward rate = %v, want %v", result.RewardRate, tt.rate)
+			}
+
+			if want := money.MustParse(tt.markup, money.INR); result.ForexMarkupCost.Cmp(want) != 0 {
+				t.Errorf("forex markup = %v, want %v", result.ForexMarkupCost, want)
+			}
+
+			if want := money.MustParse(tt.net, money.INR); result.NetValue.Cmp(want) != 0 {
+				t.Errorf("net value = %v, want %v", result.NetValue, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package recommend
+
+import (
+	"cmp"
+	"github.com/pushkar-anand/cardmax/internal/cards"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"slices"
+)
+
+type (
+	// RewardComponent is one part of a combined reward: the base rate or a stacked rule
+	RewardComponent struct {
+		// Source is "default" for the card's default rate, otherwise the rule's type
+		Source   string        `json:"source"`
+		Stacking string        `json:"stacking"`
+		Rule     *cards.Reward `json:"rule,omitempty"`
+		// Rate is the percentage this component adds to the combined rate
+		Rate        float64 `json:"rate"`
+		RewardValue float64 `json:"reward_value"`
+	}
+
+	// matchedRule is a rule matching a purchase along with how specifically it matches
+	matchedRule struct {
+		rule        *cards.Reward
+		specificity int
+	}
+)
+
+// evaluateRules calculates the reward of a card for a purchase.
+//
+// The highest ranked matching replace rule sets the base rate, otherwise the card's default rate
+// applies. Matching additive rules are then added to the base rate and multiplier rules scale the
+// total as earned after cap splitting, spend beyond a cap counting at the rate it actually earns.
+// An exclusive base rule suppresses all stacking; when an exclusive stacking rule matches, only the
+// highest ranked exclusive stacking rule is applied.
+//
+// Rules are ranked by their explicit precedence, then by how specifically they match the purchase
+// (see ruleSpecificity) and finally by their reward rate.
+func evaluateRules(p purchase, card *cards.Card, usage capUsage) *RewardResult {
+	var (
+		base     *cards.Reward
+		stacking []*cards.Reward
+	)
+
+	for _, m := range matchRules(p, card) {
+		switch m.rule.StackingMode() {
+		case cards.StackingReplace:
+			if base == nil {
+				base = m.rule
+			}
+		default:
+			stacking = append(stacking, m.rule)
+		}
+	}
+
+	if base != nil && base.Exclusive {
+		stacking = nil
+	}
+
+	if i := slices.IndexFunc(stacking, func(r *cards.Reward) bool { return r.Exclusive }); i >= 0 {
+		stacking = stacking[i : i+1]
+	}
+
+	result := &RewardResult{
+		Card:       card,
+		RewardType: card.RewardType,
+		AmountINR:  p.AmountINR,
+		Rule:       base,
+	}
+
+	// The base component, spend beyond a capped base rule earns the default rate
+	baseComponent := &RewardComponent{
+		Source:   "default",
+		Stacking: cards.StackingReplace,
+		Rate:     card.DefaultRewardRate,
+	}
+
+	eligible := p.AmountINR
+
+	if base != nil {
+		baseComponent.Source = base.Type
+		baseComponent.Rule = base
+		baseComponent.Rate = base.RewardRate
+		result.RewardType = base.RewardType
+
+		eligible = usage.eligible(card, base, p.AmountINR)
+		result.CappedSpend = p.AmountINR.Sub(eligible)
+	}
+
+	baseComponent.RewardValue = (eligible.Float()*baseComponent.Rate + result.CappedSpend.Float()*card.DefaultRewardRate) / 100
+	result.Components = append(result.Components, baseComponent)
+
+	rate := baseComponent.Rate
+	// effective is the rate earned over the whole amount once capped spend is split off, which is
+	// what multipliers scale
+	effective := baseComponent.Rate
+	if p.AmountINR.IsPositive() {
+		effective = baseComponent.RewardValue * 100 / p.AmountINR.Float()
+	}
+
+	// Additive rules stack on the base rate first so that multipliers scale the whole
+	slices.SortStableFunc(stacking, func(a, b *cards.Reward) int {
+		return cmp.Compare(stackingOrder(a), stackingOrder(b))
+	})
+
+	for _, rule := range stacking {
+		ruleEligible := usage.eligible(card, rule, p.AmountINR)
+
+		contribution := rule.RewardRate
+		if rule.StackingMode() == cards.StackingMultiplier {
+			contribution = effective * (rule.RewardRate - 1)
+		}
+
+		rate += contribution
+
+		if p.AmountINR.IsPositive() {
+			effective += contribution * ruleEligible.Float() / p.AmountINR.Float()
+		}
+
+		result.Components = append(result.Components, &RewardComponent{
+			Source:      rule.Type,
+			Stacking:    rule.StackingMode(),
+			Rule:        rule,
+			Rate:        contribution,
+			RewardValue: (ruleEligible.Float() * contribution) / 100,
+		})
+	}
+
+	result.RewardRate = rate
+
+	for _, c := range result.Components {
+		result.RewardValue += c.RewardValue
+	}
+
+	// Convert the exact reward into what is credited to the statement
+	exact := result.RewardValue
+	result.RewardValue = card.Earn(p.AmountINR, exact)
+	result.RoundingAdjustment = result.RewardValue - exact
+
+	result.CashValue = cashValue(card, result.RewardType, result.RewardValue)
+
+	// International purchases carry the issuer's forex markup which eats into the reward
+	if p.International {
+		result.ForexMarkupCost = p.AmountINR.Percent(card.ForexMarkup, money.HalfEven)
+	}
+
+	result.NetValue = result.CashValue.Sub(result.ForexMarkupCost)
+
+	return result
+}
+
+// matchRules returns the card's rules matching the purchase, highest ranked first.
+// The chosen rules apply even when their rate is below the card's default rate, which lets the
+// catalog express exclusions such as wallet loads earning nothing.
+func matchRules(p purchase, card *cards.Card) []matchedRule {
+	var matches []matchedRule
+
+	for i := range card.RewardRules {
+		rule := &card.RewardRules[i]
+
+		specificity := ruleSpecificity(p, rule)
+		if specificity == 0 {
+			continue
+		}
+
+		matches = append(matches, matchedRule{rule: rule, specificity: specificity})
+	}
+
+	slices.SortStableFunc(matches, func(a, b matchedRule) int {
+		return cmp.Or(
+			cmp.Compare(b.rule.Precedence, a.rule.Precedence),
+			cmp.Compare(b.specificity, a.specificity),
+			cmp.Compare(b.rule.RewardRate, a.rule.RewardRate),
+		)
+	})
+
+	return matches
+}
+
+// ruleSpecificity ranks how specifically a rule matches a purchase, 0 means it does not match.
+// Merchant rules are more specific than Category rules, followed by International and then
+// Channel rules. Within a type, a rule restricted to the purchase's channel beats an
+// unrestricted one.
+func ruleSpecificity(p purchase, rule *cards.Reward) int {
+	if rule.Channel != "" && rule.Channel != p.Channel {
+		return 0
+	}
+
+	var rank int
+
+	switch {
+	case rule.Type == "Merchant" && rule.EntityName == p.Merchant:
+		rank = 4
+	case rule.Type == "Category" && rule.EntityName == p.Category:
+		rank = 3
+	case rule.Type == "International" && p.International &&
+		(rule.EntityName == cards.AllCurrencies || rule.EntityName == p.Currency):
+		rank = 2
+	case rule.Type == "Channel" && p.Channel != "" && rule.EntityName == p.Channel:
+		rank = 1
+	default:
+		return 0
+	}
+
+	// A rule restricted to the purchase's channel is more specific than one for any channel
+	specificity := rank * 2
+	if rule.Channel != "" {
+		specificity++
+	}
+
+	return specificity
+}
+
+func stackingOrder(rule *cards.Reward) int {
+	if rule.StackingMode() == cards.StackingMultiplier {
+		return 1
+	}
+
+	return 0
+}
+
+// cashValue converts a reward into INR, rounded half to even to the paisa
+func cashValue(card *cards.Card, rewardType string, reward float64) money.Money {
+	if rewardType == "Points" || rewardType == "Miles" {
+		reward *= card.PointValue
+	}
+
+	return money.FromFloat(reward, money.INR, money.HalfEven)
+}
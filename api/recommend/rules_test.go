@@ -0,0 +1,270 @@
+package recommend
+
+import (
+	"cmp"
+	"github.com/pushkar-anand/cardmax/internal/cards"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"math"
+	"slices"
+	"testing"
+)
+
+func rupees(r int64) money.Money {
+	return money.New(r*100, money.INR)
+}
+
+func TestEvaluateRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []cards.Reward
+		used     money.Money
+		merchant string
+		channel  string
+		// rate is the nominal combined rate, reward the reward on ₹1000
+		rate   float64
+		reward float64
+	}{
+		{
+			name:   "default rate",
+			rate:   1,
+			reward: 10,
+		},
+		{
+			name: "replace",
+			rules: []cards.Reward{
+				{Type: "Category", EntityName: "Dining", RewardRate: 5},
+			},
+			rate:   5,
+			reward: 50,
+		},
+		{
+			name: "most specific replace wins",
+			rules: []cards.Reward{
+				{Type: "Category", EntityName: "Dining", RewardRate: 5},
+				{Type: "Merchant", EntityName: "Swiggy", RewardRate: 3},
+			},
+			merchant: "Swiggy",
+			rate:     3,
+			reward:   30,
+		},
+		{
+			name: "precedence beats specificity",
+			rules: []cards.Reward{
+				{Type: "Category", EntityName: "Dining", RewardRate: 5, Precedence: 1},
+				{Type: "Merchant", EntityName: "Swiggy", RewardRate: 3},
+			},
+			merchant: "Swiggy",
+			rate:     5,
+			reward:   50,
+		},
+		{
+			name: "additive",
+			rules: []cards.Reward{
+				{Type: "Category", EntityName: "Dining", RewardRate: 5},
+				{Type: "Channel", EntityName: "upi", RewardRate: 1, Stacking: cards.StackingAdditive},
+			},
+			channel: "upi",
+			rate:    6,
+			reward:  60,
+		},
+		{
+			name: "multiplier scales additive rules",
+			rules: []cards.Reward{
+				{Type: "Merchant", EntityName: "Swiggy", RewardRate: 2, Stacking: cards.StackingMultiplier},
+				{Type: "Channel", EntityName: "upi", RewardRate: 1, Stacking: cards.StackingAdditive},
+			},
+			merchant: "Swiggy",
+			channel:  "upi",
+			rate:     4,
+			reward:   40,
+		},
+		{
+			name: "exclusive base suppresses stacking",
+			rules: []cards.Reward{
+				{Type: "Category", EntityName: "Dining", RewardRate: 5, Exclusive: true},
+				{Type: "Channel", EntityName: "upi", RewardRate: 1, Stacking: cards.StackingAdditive},
+			},
+			channel: "upi",
+			rate:    5,
+			reward:  50,
+		},
+		{
+			name: "exclusive stacking rule stands alone",
+			rules: []cards.Reward{
+				{Type: "Channel", EntityName: "upi", RewardRate: 1, Stacking: cards.StackingAdditive},
+				{Type: "Merchant", EntityName: "Swiggy", RewardRate: 3, Stacking: cards.StackingMultiplier, Exclusive: true},
+				{Type: "Channel", EntityName: "upi", RewardRate: 2, Stacking: cards.StackingAdditive, Exclusive: true},
+			},
+			merchant: "Swiggy",
+			channel:  "upi",
+			rate:     3,
+			reward:   30,
+		},
+		{
+			name: "capped base earns the default rate beyond the cap",
+			rules: []cards.Reward{
+				{Type: "Category", EntityName: "Dining", RewardRate: 5, SpendCap: rupees(1000)},
+			},
+			used:   rupees(600),
+			rate:   5,
+			reward: 26,
+		},
+		{
+			name: "multiplier scales the rate blended by the cap",
+			rules: []cards.Reward{
+				{Type: "Category", EntityName: "Dining", RewardRate: 5, SpendCap: rupees(1000)},
+				{Type: "Merchant", EntityName: "Swiggy", RewardRate: 2, Stacking: cards.StackingMultiplier},
+			},
+			used:     rupees(600),
+			merchant: "Swiggy",
+			rate:     7.6,
+			reward:   52,
+		},
+		{
+			name: "capped multiplier",
+			rules: []cards.Reward{
+				{Type: "Category", EntityName: "Dining", RewardRate: 5},
+				{Type: "Merchant", EntityName: "Swiggy", RewardRate: 3, Stacking: cards.StackingMultiplier, SpendCap: rupees(500)},
+			},
+			used:     rupees(250),
+			merchant: "Swiggy",
+			rate:     15,
+			reward:   75,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := &cards.Card{
+				Key:               "TEST",
+				DefaultRewardRate: 1,
+				RewardType:        "Cashback",
+				RewardRules:       tt.rules,
+			}
+
+			usage := make(capUsage)
+			for i := range card.RewardRules {
+				usage.consume(card, &card.RewardRules[i], tt.used)
+			}
+
+			p := purchase{
+				RecommendationRequest: RecommendationRequest{
+					Merchant: tt.merchant,
+					Category: "Dining",
+					Channel:  tt.channel,
+				},
+				AmountINR: rupees(1000),
+			}
+
+			result := evaluateRules(p, card, usage)
+
+			if math.Abs(result.RewardRate-tt.rate) > 1e-9 {
+				t.Errorf("reward rate = %v, want %v", result.RewardRate, tt.rate)
+			}
+
+			if math.Abs(result.RewardValue-tt.reward) > 1e-9 {
+				t.Errorf("reward value = %v, want %v", result.RewardValue, tt.reward)
+			}
+
+			if want := money.FromFloat(tt.reward, money.INR, money.HalfEven); result.CashValue != want {
+				t.Errorf("cash value = %v, want %v", result.CashValue, want)
+			}
+		})
+	}
+}
+
+func TestRuleSpecificity(t *testing.T) {
+	tests := []struct {
+		name          string
+		rule          cards.Reward
+		channel       string
+		international bool
+		want          int
+	}{
+		{name: "merchant", rule: cards.Reward{Type: "Merchant", EntityName: "swiggy"}, want: 8},
+		{name: "merchant on its channel", rule: cards.Reward{Type: "Merchant", EntityName: "swiggy", Channel: "upi"}, channel: "upi", want: 9},
+		{name: "merchant on another channel", rule: cards.Reward{Type: "Merchant", EntityName: "swiggy", Channel: "upi"}, channel: "online", want: 0},
+		{name: "merchant without a channel", rule: cards.Reward{Type: "Merchant", EntityName: "swiggy", Channel: "upi"}, want: 0},
+		{name: "another merchant", rule: cards.Reward{Type: "Merchant", EntityName: "zomato"}, want: 0},
+		{name: "category", rule: cards.Reward{Type: "Category", EntityName: "dining"}, channel: "upi", want: 6},
+		{name: "category on its channel", rule: cards.Reward{Type: "Category", EntityName: "dining", Channel: "contactless"}, channel: "contactless", want: 7},
+		{name: "international", rule: cards.Reward{Type: "International", EntityName: cards.AllCurrencies}, international: true, want: 4},
+		{name: "international on its channel", rule: cards.Reward{Type: "International", EntityName: cards.AllCurrencies, Channel: "online"}, channel: "online", international: true, want: 5},
+		{name: "domestic", rule: cards.Reward{Type: "International", EntityName: cards.AllCurrencies}, want: 0},
+		{name: "channel", rule: cards.Reward{Type: "Channel", EntityName: "upi"}, channel: "upi", want: 2},
+		{name: "another channel", rule: cards.Reward{Type: "Channel", EntityName: "upi"}, channel: "wallet", want: 0},
+		{name: "no channel", rule: cards.Reward{Type: "Channel", EntityName: "upi"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := purchase{
+				RecommendationRequest: RecommendationRequest{
+					Merchant:      "swiggy",
+					Category:      "dining",
+					Channel:       tt.channel,
+					International: tt.international,
+				},
+			}
+
+			if got := ruleSpecificity(p, &tt.rule); got != tt.want {
+				t.Errorf("ruleSpecificity() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchRulesByChannel(t *testing.T) {
+	card := &cards.Card{
+		Key:               "TEST-CHANNELS",
+		DefaultRewardRate: 1,
+		RewardType:        "Cashback",
+		RewardRules: []cards.Reward{
+			{Type: "Category", EntityName: "dining", RewardRate: 5},
+			{Type: "Category", EntityName: "dining", RewardRate: 2, Channel: "upi"},
+			{Type: "Merchant", EntityName: "swiggy", RewardRate: 10, Channel: "online"},
+			{Type: "Channel", EntityName: "upi", RewardRate: 1, Stacking: cards.StackingAdditive},
+			{Type: "Channel", EntityName: "wallet", RewardRate: 0, Precedence: 1},
+		},
+	}
+
+	tests := []struct {
+		channel string
+		// want are the indexes of the matching rules, highest ranked first, and rate the combined rate
+		want []int
+		rate float64
+	}{
+		{channel: "", want: []int{0}, rate: 5},
+		{channel: "upi", want: []int{1, 0, 3}, rate: 3},
+		{channel: "online", want: []int{2, 0}, rate: 10},
+		{channel: "contactless", want: []int{0}, rate: 5},
+		// Precedence makes wallet loads earn nothing whatever else matches
+		{channel: "wallet", want: []int{4, 0}, rate: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(cmp.Or(tt.channel, "none"), func(t *testing.T) {
+			p := purchase{
+				RecommendationRequest: RecommendationRequest{Merchant: "swiggy", Category: "dining", Channel: tt.channel},
+				AmountINR:             rupees(1000),
+			}
+
+			got := make([]int, 0, len(tt.want))
+			for _, m := range matchRules(p, card) {
+				for i := range card.RewardRules {
+					if m.rule == &card.RewardRules[i] {
+						got = append(got, i)
+					}
+				}
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("matched rules %v, want %v", got, tt.want)
+			}
+
+			if rate := evaluateRules(p, card, make(capUsage)).RewardRate; math.Abs(rate-tt.rate) > 1e-9 {
+				t.Errorf("reward rate = %v, want %v", rate, tt.rate)
+			}
+		})
+	}
+}
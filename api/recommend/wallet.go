@@ -0,0 +1,156 @@
+package recommend
+
+import (
+	"context"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/billing"
+	"github.com/pushkar-anand/cardmax/internal/cards"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"slices"
+	"time"
+)
+
+type (
+	// Holder is the household member holding a card of the wallet
+	Holder struct {
+		CardID   int64  `json:"card_id"`
+		CardName string `json:"card_name"`
+		UserID   int64  `json:"user_id"`
+		Username string `json:"username"`
+		// Own marks a card of the user, otherwise another member of their household shares it
+		Own bool `json:"own"`
+	}
+
+	// heldCard is a card of the wallet linked to a predefined card
+	heldCard struct {
+		schedule billing.Schedule
+		balance  billing.Balance
+		holder   Holder
+	}
+
+	// wallet holds the user's cards and the cards the other members of their household share,
+	// keyed by predefined card key. The wallet may hold more than one card of the same kind.
+	wallet map[string][]heldCard
+)
+
+// loadWallet loads the billing schedules and balances of the cards of the user, and of the cards
+// shared by their household, linked to a predefined card
+func loadWallet(ctx context.Context, q *models.Queries, userID *int64) (wallet, error) {
+	list, err := q.GetWalletBalances(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card balances: %w", err)
+	}
+
+	w := make(wallet)
+
+	for _, row := range list {
+		card := row.Card
+		// Cards without a holder are not in a wallet, the query joins their user
+		if card.PredefinedCardKey == nil || card.UserID == nil {
+			continue
+		}
+
+		w[*card.PredefinedCardKey] = append(w[*card.PredefinedCardKey], heldCard{
+			schedule: db.CardSchedule(&card),
+			balance: billing.NewBalance(
+				card.CreditLimitMinor,
+				money.New(row.Spent, money.INR),
+				money.New(row.Paid, money.INR),
+			),
+			holder: Holder{
+				CardID:   card.ID,
+				CardName: card.Name,
+				UserID:   *card.UserID,
+				Username: row.Username,
+				Own:      userID != nil && *card.UserID == *userID,
+			},
+		})
+	}
+
+	return w, nil
+}
+
+// interestFree returns the longest interest-free period of a spend on the date across the
+// wallet's cards of the given kind along with the holder of that card, the user's own card on a
+// tie, and whether the wallet holds such a card at all
+func (w wallet) interestFree(cardKey string, date time.Time) (days int, due time.Time, holder Holder, ok bool) {
+	held, days, ok := w.pick(cardKey, date)
+	if !ok {
+		return 0, time.Time{}, Holder{}, false
+	}
+
+	return days, held.schedule.CycleFor(date).DueDate, held.holder, true
+}
+
+// pick returns the wallet's card of the given kind a spend on the date goes on: the one with the
+// longest interest-free period, the user's own card on a tie
+func (w wallet) pick(cardKey string, date time.Time) (card heldCard, days int, ok bool) {
+	for _, held := range w[cardKey] {
+		d := held.schedule.InterestFreeDays(date)
+		if !ok || d > days || (d == days && held.holder.Own && !card.holder.Own) {
+			card, days, ok = held, d, true
+		}
+	}
+
+	return card, days, ok
+}
+
+// capUsage returns the spend the ledger already consumed against the capped rules of the cards
+// in the statement cycle of the date. The spends of a kind of card are those of the wallet's card a
+// purchase on the date goes on, as picked by pick.
+func (w wallet) capUsage(ctx context.Context, q *models.Queries, cardList []*cards.Card, date time.Time) (capUsage, error) {
+	usage := make(capUsage)
+
+	for _, card := range cardList {
+		if !slices.ContainsFunc(card.RewardRules, func(r cards.Reward) bool { return r.SpendCap.IsPositive() }) {
+			continue
+		}
+
+		held, _, ok := w.pick(card.Key, date)
+		if !ok {
+			continue
+		}
+
+		cycle := held.schedule.CycleFor(date)
+
+		txns, err := q.GetCardTransactionsBetween(ctx, models.GetCardTransactionsBetweenParams{
+			CardID:   held.holder.CardID,
+			FromDate: cycle.Start.Format(time.DateOnly),
+			ToDate:   cycle.StatementDate.Format(time.DateOnly),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transactions of card %d: %w", held.holder.CardID, err)
+		}
+
+		for _, txn := range txns {
+			if txn.AmountMinor.IsPositive() {
+				usage.earn(card, deref(txn.Merchant), deref(txn.Category), deref(txn.Channel), txn.AmountMinor)
+			}
+		}
+	}
+
+	return usage, nil
+}
+
+// utilisation returns the lowest utilisation after the spend across the wallet's cards of the
+// given kind, and false when none of them has a known credit limit
+func (w wallet) utilisation(cardKey string, spend money.Money) (lowest float64, ok bool) {
+	for _, held := range w[cardKey] {
+		u, known := held.balance.Utilisation(spend)
+		if known && (!ok || u < lowest) {
+			lowest, ok = u, true
+		}
+	}
+
+	return lowest, ok
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
@@ -0,0 +1,176 @@
+package recommend
+
+import (
+	"context"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/cards"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var (
+	discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// testDB is the database of the package's tests, db.New opens a single database per process
+	testDB *db.DB
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "cardmax")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	testDB, err = db.New(context.Background(), discard, &db.Config{Path: filepath.Join(dir, "test.db")})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// addCard stores the predefined card and a card of the user linked to it, with the statement
+// generated on the day of the month
+func addCard(t *testing.T, card *cards.Card, userID int64, statementDay int64) *models.Card {
+	t.Helper()
+
+	ctx := context.Background()
+
+	err := testDB.PopulatePredefinedCards(ctx, discard, []*cards.Card{card})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := testDB.Queries.CreateCard(ctx, models.CreateCardParams{
+		Name:              card.Key,
+		Issuer:            "Test Bank",
+		Last4Digits:       "1234",
+		ExpiryDate:        "12/30",
+		CardType:          "Credit",
+		StatementDay:      statementDay,
+		PaymentDueDays:    20,
+		PredefinedCardKey: &card.Key,
+		CreditLimitMinor:  rupees(100000),
+		UserID:            &userID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestWalletCapUsage(t *testing.T) {
+	ctx := context.Background()
+
+	card := &cards.Card{
+		Key:               "TEST-CAPPED",
+		Name:              "Capped",
+		Issuer:            "Test Bank",
+		CardType:          "Credit",
+		DefaultRewardRate: 1,
+		RewardType:        "Cashback",
+		RewardRules: []cards.Reward{
+			{Type: "Category", EntityName: "dining", RewardRate: 5, SpendCap: rupees(1000)},
+		},
+	}
+
+	user, err := testDB.RegisterUser(ctx, discard, "capped", "hash", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := addCard(t, card, user.ID, 15)
+
+	dining, travel := "dining", "travel"
+
+	// Only the spends of the cycle from 16 October to 15 November are under the cap
+	for _, txn := range []struct {
+		date     string
+		category *string
+		rupees   int64
+	}{
+		{date: "2026-10-10", category: &dining, rupees: 300},
+		{date: "2026-10-16", category: &dining, rupees: 400},
+		{date: "2026-10-20", category: &dining, rupees: 200},
+		{date: "2026-10-21", category: &dining, rupees: -100},
+		{date: "2026-10-22", category: &travel, rupees: 500},
+		{date: "2026-11-16", category: &dining, rupees: 700},
+	} {
+		_, err = testDB.Queries.CreateTransaction(ctx, models.CreateTransactionParams{
+			CardID:          c.ID,
+			Category:        txn.category,
+			AmountMinor:     rupees(txn.rupees),
+			TransactionDate: txn.date,
+			Source:          db.SourceManual,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	held, err := loadWallet(ctx, testDB.Queries, &user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+	usage, err := held.capUsage(ctx, testDB.Queries, []*cards.Card{card}, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := usage[capKey(card, &card.RewardRules[0])], rupees(600); got.Cmp(want) != 0 {
+		t.Errorf("cap usage = %v, want %v", got, want)
+	}
+
+	p := purchase{
+		RecommendationRequest: RecommendationRequest{Category: "dining", Amount: rupees(600)},
+		AmountINR:             rupees(600),
+		Date:                  date,
+	}
+
+	t.Run("single", func(t *testing.T) {
+		best, _ := analyzeCards([]*cards.Card{card}, p, usage, held)
+
+		if want := 400*0.05 + 200*0.01; math.Abs(best.RewardValue-want) > 1e-9 {
+			t.Errorf("reward = %v, want %v", best.RewardValue, want)
+		}
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		results, _ := analyzeBatch([]*cards.Card{card}, []purchase{p, p}, false, held, usage)
+
+		for i, want := range []float64{400*0.05 + 200*0.01, 6} {
+			if got := results[i].BestCard.RewardValue; math.Abs(got-want) > 1e-9 {
+				t.Errorf("item %d: reward = %v, want %v", i, got, want)
+			}
+		}
+	})
+
+	t.Run("card of another kind", func(t *testing.T) {
+		other := &cards.Card{Key: "TEST-NOT-HELD", RewardRules: card.RewardRules}
+
+		usage, err := held.capUsage(ctx, testDB.Queries, []*cards.Card{other}, date)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(usage) != 0 {
+			t.Errorf("cap usage = %v, want none for a card not in the wallet", usage)
+		}
+	})
+}
@@ -0,0 +1,281 @@
+package statements
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Statement statuses
+const (
+	StatusPaid        = "paid"
+	StatusMinimumPaid = "minimum_paid"
+	StatusUnpaid      = "unpaid"
+	StatusOverdue     = "overdue"
+)
+
+// Statement is a generated statement along with its payment status
+type Statement struct {
+	*models.Statement
+	// Remaining is the part of the total due that is yet to be paid
+	Remaining money.Money `json:"remaining"`
+	// Status is one of paid, minimum_paid, unpaid or overdue (the minimum due was missed)
+	Status string `json:"status"`
+}
+
+// NewStatement returns the statement with its payment status on the given date
+func NewStatement(s *models.Statement, now time.Time) *Statement {
+	st := &Statement{
+		Statement: s,
+		Remaining: money.Max(s.TotalDueMinor.Sub(s.PaidMinor), money.Money{}),
+	}
+
+	switch {
+	case s.PaidAt != nil:
+		st.Status = StatusPaid
+	case s.PaidMinor.Cmp(s.MinimumDueMinor) >= 0:
+		st.Status = StatusMinimumPaid
+	case now.Format(time.DateOnly) > s.DueDate:
+		st.Status = StatusOverdue
+	default:
+		st.Status = StatusUnpaid
+	}
+
+	return st
+}
+
+// GetAllHandler returns the generated statements of the user, optionally of a single card
+func GetAllHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type (
+		Query struct {
+			CardID int64 `schema:"card_id" validate:"omitempty,min=1"`
+		}
+
+		Response struct {
+			Statements []*Statement `json:"statements"`
+		}
+	)
+
+	typedReader := request.NewTypedReader[Query](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		query, err := typedReader.ReadAndValidateQueryParams(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse query params", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		var list []*models.Statement
+		if query.CardID != 0 {
+			list, err = dbConn.Queries.GetStatementsByCardID(ctx, models.GetStatementsByCardIDParams{
+				CardID: query.CardID,
+				UserID: auth.UserID(ctx),
+			})
+		} else {
+			list, err = dbConn.Queries.GetStatements(ctx, auth.UserID(ctx))
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get statements", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		now := time.Now()
+
+		resp := Response{Statements: make([]*Statement, 0, len(list))}
+		for _, s := range list {
+			resp.Statements = append(resp.Statements, NewStatement(s, now))
+		}
+
+		jw.Ok(ctx, w, resp)
+	}
+}
+
+// GetByIDHandler returns a statement with its payment status
+func GetByIDHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			jw.WriteProblem(ctx, r, w, invalidID())
+			return
+		}
+
+		s, err := dbConn.Queries.GetUserStatementByID(ctx, models.GetUserStatementByIDParams{
+			ID:     id,
+			UserID: auth.UserID(ctx),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			jw.WriteProblem(ctx, r, w, notFound())
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get statement", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, NewStatement(s, time.Now()))
+	}
+}
+
+// GenerateHandler generates the statements of the cycles that have closed, without waiting for
+// the reminder worker
+func GenerateHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type Response struct {
+		// Generated counts the new statements and the unpaid ones whose totals changed
+		Generated int `json:"generated"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		generated, err := dbConn.GenerateStatements(ctx, log, time.Now())
+		if err != nil {
+			log.ErrorContext(ctx, "failed to generate statements", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, Response{Generated: generated})
+	}
+}
+
+// PayHandler records a payment towards a statement
+func PayHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type (
+		Request struct {
+			// Amount is the amount paid, defaults to the remaining total due
+			Amount money.Money `json:"amount" validate:"nonnegative_money"`
+			// PaymentDate is the date of the payment as YYYY-MM-DD, defaults to today
+			PaymentDate string  `json:"payment_date" validate:"omitempty,datetime=2006-01-02"`
+			Notes       *string `json:"notes"`
+		}
+
+		Response struct {
+			Payment   *models.Payment `json:"payment"`
+			Statement *Statement      `json:"statement"`
+		}
+	)
+
+	typedReader := request.NewTypedReader[Request](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			jw.WriteProblem(ctx, r, w, invalidID())
+			return
+		}
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		s, err := dbConn.Queries.GetUserStatementByID(ctx, models.GetUserStatementByIDParams{
+			ID:     id,
+			UserID: auth.UserID(ctx),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			jw.WriteProblem(ctx, r, w, notFound())
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get statement", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		now := time.Now()
+
+		amount := body.Amount
+		if amount.IsZero() {
+			amount = NewStatement(s, now).Remaining
+		}
+
+		if !amount.IsPositive() {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusConflict).
+				WithDetail("statement is already paid").
+				Build())
+			return
+		}
+
+		date := body.PaymentDate
+		if date == "" {
+			date = now.Format(time.DateOnly)
+		}
+
+		payment, statement, err := dbConn.RecordPayment(ctx, log, db.RecordPaymentParams{
+			CardID:      s.CardID,
+			StatementID: &s.ID,
+			Amount:      amount,
+			PaymentDate: date,
+			Notes:       body.Notes,
+		})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to record payment", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Write(ctx, w, http.StatusCreated, Response{
+			Payment:   payment,
+			Statement: NewStatement(statement, now),
+		})
+	}
+}
+
+func invalidID() response.Problem {
+	return response.NewProblem().
+		WithStatus(http.StatusBadRequest).
+		WithDetail("statement id must be a number").
+		Build()
+}
+
+func notFound() response.Problem {
+	return response.NewProblem().
+		WithStatus(http.StatusNotFound).
+		WithDetail("statement not found").
+		Build()
+}
@@ -0,0 +1,175 @@
+package tokens
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// defaultExpiryDays is how long tokens are valid for when no expiry is given
+const defaultExpiryDays = 90
+
+type (
+	// TokenRequest is the request body to create a personal access token
+	TokenRequest struct {
+		Name   string   `json:"name" validate:"required,max=64"`
+		Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=read:cards write:transactions recommend"`
+		// ExpiresInDays is how many days the token is valid for, defaults to 90
+		ExpiresInDays int `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+	}
+
+	// Token is a personal access token as listed to its user, without the token itself
+	Token struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+		// Hint is the last characters of the token, to tell tokens apart
+		Hint       string     `json:"hint"`
+		Scopes     []string   `json:"scopes"`
+		ExpiresAt  time.Time  `json:"expires_at"`
+		LastUsedAt *time.Time `json:"last_used_at"`
+		CreatedAt  time.Time  `json:"created_at"`
+	}
+
+	// CreatedToken is a token that was just created, the only time the token itself is shown
+	CreatedToken struct {
+		*Token
+		// Secret is the token to send as "Authorization: Bearer <token>"
+		Secret string `json:"token"`
+	}
+)
+
+func newToken(record *models.AccessToken) *Token {
+	return &Token{
+		ID:         record.ID,
+		Name:       record.Name,
+		Hint:       record.Hint,
+		Scopes:     auth.TokenScopes(record),
+		ExpiresAt:  record.ExpiresAt,
+		LastUsedAt: record.LastUsedAt,
+		CreatedAt:  record.CreatedAt,
+	}
+}
+
+// GetAllHandler returns the personal access tokens of the user, most recent first
+func GetAllHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type Response struct {
+		Tokens []*Token `json:"tokens"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		records, err := dbConn.Queries.GetUserAccessTokens(ctx, auth.User(ctx).ID)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get access tokens", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		list := make([]*Token, 0, len(records))
+		for _, record := range records {
+			list = append(list, newToken(record))
+		}
+
+		jw.Ok(ctx, w, Response{Tokens: list})
+	}
+}
+
+// CreateHandler creates a personal access token of the user. The token is only returned here,
+// only its hash is stored.
+func CreateHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	authn *auth.Authenticator,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[TokenRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if body.ExpiresInDays == 0 {
+			body.ExpiresInDays = defaultExpiryDays
+		}
+
+		slices.Sort(body.Scopes)
+
+		record, token, err := authn.CreateToken(
+			ctx,
+			auth.User(ctx).ID,
+			body.Name,
+			slices.Compact(body.Scopes),
+			time.Now().AddDate(0, 0, body.ExpiresInDays),
+		)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to create access token", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Write(ctx, w, http.StatusCreated, CreatedToken{
+			Token:  newToken(record),
+			Secret: token,
+		})
+	}
+}
+
+// DeleteHandler revokes a personal access token of the user
+func DeleteHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusBadRequest).
+				WithDetail("token id must be a number").
+				Build())
+			return
+		}
+
+		n, err := dbConn.Queries.DeleteUserAccessToken(ctx, models.DeleteUserAccessTokenParams{
+			ID:     id,
+			UserID: auth.User(ctx).ID,
+		})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to delete access token", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if n == 0 {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusNotFound).
+				WithDetail("access token not found").
+				Build())
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
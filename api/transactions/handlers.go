@@ -0,0 +1,200 @@
+package transactions
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"github.com/pushkar-anand/cardmax/internal/taxonomy"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TransactionRequest is the request body to log a transaction
+type TransactionRequest struct {
+	CardID   int64   `json:"card_id" validate:"required,min=1"`
+	Merchant *string `json:"merchant" validate:"required_without=Category"`
+	Category *string `json:"category" validate:"required_without=Merchant"`
+	// Amount is the amount charged in INR, negative for a refund
+	Amount money.Money `json:"amount"`
+	// TransactionDate is the date of the transaction as YYYY-MM-DD, defaults to today
+	TransactionDate string  `json:"transaction_date" validate:"omitempty,datetime=2006-01-02"`
+	Channel         *string `json:"channel" validate:"omitempty,oneof=upi online offline contactless wallet"`
+	Notes           *string `json:"notes"`
+	// SpentBy is the user ID of the household member who made the spend, e.g. with an add-on
+	// card, defaults to the card's holder
+	SpentBy *int64 `json:"spent_by" validate:"omitempty,min=1"`
+}
+
+// GetAllHandler returns the logged transactions of the user, optionally of a single card
+func GetAllHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type (
+		Query struct {
+			CardID int64 `schema:"card_id" validate:"omitempty,min=1"`
+		}
+
+		Response struct {
+			Transactions []*models.Transaction `json:"transactions"`
+		}
+	)
+
+	typedReader := request.NewTypedReader[Query](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		query, err := typedReader.ReadAndValidateQueryParams(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse query params", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		var list []*models.Transaction
+		if query.CardID != 0 {
+			list, err = dbConn.Queries.GetTransactionsByCardID(ctx, models.GetTransactionsByCardIDParams{
+				CardID: query.CardID,
+				UserID: auth.UserID(ctx),
+			})
+		} else {
+			list, err = dbConn.Queries.GetTransactions(ctx, auth.UserID(ctx))
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get transactions", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, Response{Transactions: list})
+	}
+}
+
+// CreateHandler logs a transaction against a card of the user. The merchant is stored by the name the
+// normaliser gives it, which also categorises a transaction without a category, or in lower case
+// when the normaliser knows none.
+func CreateHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+	normaliser *taxonomy.Normaliser,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[TransactionRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		userID := auth.User(ctx).ID
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if body.Amount.IsZero() {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusUnprocessableEntity).
+				WithDetail("amount must not be zero").
+				Build())
+			return
+		}
+
+		_, err = dbConn.Queries.GetUserCardByID(ctx, models.GetUserCardByIDParams{
+			ID:     body.CardID,
+			UserID: &userID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusUnprocessableEntity).
+				WithDetail("card_id does not refer to a known card").
+				Build())
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get card", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if body.SpentBy != nil && *body.SpentBy == userID {
+			body.SpentBy = nil
+		}
+
+		if body.SpentBy != nil {
+			member, err := dbConn.Queries.IsHouseholdMember(ctx, models.IsHouseholdMemberParams{
+				UserID:   userID,
+				MemberID: *body.SpentBy,
+			})
+			if err != nil {
+				log.ErrorContext(ctx, "failed to check household member", logger.Error(err))
+				jw.WriteError(ctx, r, w, err)
+				return
+			}
+
+			if member == 0 {
+				jw.WriteProblem(ctx, r, w, response.NewProblem().
+					WithStatus(http.StatusUnprocessableEntity).
+					WithDetail("spent_by does not refer to a member of your household").
+					Build())
+				return
+			}
+		}
+
+		date := body.TransactionDate
+		if date == "" {
+			date = time.Now().Format(time.DateOnly)
+		}
+
+		if body.Channel != nil {
+			channel := strings.ToLower(*body.Channel)
+			body.Channel = &channel
+		}
+
+		if body.Merchant != nil {
+			if m, ok := normaliser.Normalise(&userID, *body.Merchant); ok {
+				body.Merchant = &m.Merchant
+				if body.Category == nil && m.Category != "" {
+					body.Category = &m.Category
+				}
+			} else {
+				// Merchants are stored as reward rules name them, in lower case
+				merchant := strings.ToLower(strings.TrimSpace(*body.Merchant))
+				body.Merchant = &merchant
+			}
+		}
+
+		txn, err := dbConn.Queries.CreateTransaction(ctx, models.CreateTransactionParams{
+			CardID:          body.CardID,
+			Merchant:        body.Merchant,
+			Category:        body.Category,
+			AmountMinor:     body.Amount,
+			TransactionDate: date,
+			Channel:         body.Channel,
+			Notes:           body.Notes,
+			Source:          db.SourceManual,
+			SpentBy:         body.SpentBy,
+		})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to create transaction", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Write(ctx, w, http.StatusCreated, txn)
+	}
+}
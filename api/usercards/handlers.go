@@ -0,0 +1,288 @@
+package usercards
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/billing"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultPaymentDueDays is the payment due offset used when a card does not specify one
+const defaultPaymentDueDays = 20
+
+type (
+	// CardRequest is the request body to create or update a user card
+	CardRequest struct {
+		Name              string   `json:"name" validate:"required"`
+		Issuer            string   `json:"issuer" validate:"required"`
+		Last4Digits       string   `json:"last4_digits" validate:"required,len=4,numeric"`
+		ExpiryDate        string   `json:"expiry_date" validate:"required,datetime=2006-01"`
+		DefaultRewardRate *float64 `json:"default_reward_rate" validate:"omitempty,min=0"`
+		CardType          string   `json:"card_type" validate:"required"`
+		StatementDay      int64    `json:"statement_day" validate:"required,min=1,max=31"`
+		// PaymentDueDays is the number of days between the statement and due dates, defaults to 20
+		PaymentDueDays *int64 `json:"payment_due_days" validate:"omitempty,min=0,max=60"`
+		// PredefinedCardKey links the card to a predefined card's reward rules
+		PredefinedCardKey *string `json:"predefined_card_key" validate:"omitempty"`
+		// CreditLimit is the card's credit limit in INR, zero when it is not known
+		CreditLimit money.Money `json:"credit_limit" validate:"nonnegative_money"`
+		// Shared lets the members of the user's household see the card, read-only, and have it
+		// ranked in their recommendations
+		Shared bool `json:"shared"`
+	}
+
+	// UserCard is a user's card along with its billing cycle
+	UserCard struct {
+		*models.Card
+		// CurrentCycle is the cycle today's spends are billed in
+		CurrentCycle billing.Cycle `json:"current_cycle"`
+		// NextDueDate is the earliest due date that has not passed yet, as YYYY-MM-DD
+		NextDueDate  string `json:"next_due_date"`
+		DaysUntilDue int    `json:"days_until_due"`
+	}
+)
+
+func newUserCard(card *models.Card, now time.Time) *UserCard {
+	s := db.CardSchedule(card)
+	due := s.NextDue(now)
+
+	return &UserCard{
+		Card:         card,
+		CurrentCycle: s.CycleFor(now),
+		NextDueDate:  due.DueDate.Format(time.DateOnly),
+		DaysUntilDue: due.DaysUntilDue(now),
+	}
+}
+
+// GetAllHandler returns the cards of the user with their billing cycles
+func GetAllHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type Response struct {
+		Cards []*UserCard `json:"cards"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		list, err := dbConn.Queries.GetUserCards(ctx, auth.UserID(ctx))
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get cards", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		now := time.Now()
+
+		resp := Response{Cards: make([]*UserCard, 0, len(list))}
+		for _, card := range list {
+			resp.Cards = append(resp.Cards, newUserCard(card, now))
+		}
+
+		jw.Ok(ctx, w, resp)
+	}
+}
+
+// GetByIDHandler returns a card of the user with its billing cycle
+func GetByIDHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, ok := cardID(ctx, r, w, jw)
+		if !ok {
+			return
+		}
+
+		card, err := dbConn.Queries.GetUserCardByID(ctx, models.GetUserCardByIDParams{
+			ID:     id,
+			UserID: auth.UserID(ctx),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			jw.WriteProblem(ctx, r, w, notFound())
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get card", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, newUserCard(card, time.Now()))
+	}
+}
+
+// CreateHandler adds a card of the user
+func CreateHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[CardRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if !predefinedCardExists(ctx, log, dbConn, body.PredefinedCardKey) {
+			jw.WriteProblem(ctx, r, w, unknownPredefinedCard())
+			return
+		}
+
+		card, err := dbConn.Queries.CreateCard(ctx, models.CreateCardParams{
+			Name:              body.Name,
+			Issuer:            body.Issuer,
+			Last4Digits:       body.Last4Digits,
+			ExpiryDate:        body.ExpiryDate,
+			DefaultRewardRate: body.DefaultRewardRate,
+			CardType:          body.CardType,
+			StatementDay:      body.StatementDay,
+			PaymentDueDays:    body.paymentDueDays(),
+			PredefinedCardKey: body.PredefinedCardKey,
+			CreditLimitMinor:  body.CreditLimit,
+			Shared:            body.Shared,
+			UserID:            auth.UserID(ctx),
+		})
+		if err != nil {
+			log.ErrorContext(ctx, "failed to create card", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Write(ctx, w, http.StatusCreated, newUserCard(card, time.Now()))
+	}
+}
+
+// UpdateHandler replaces the details of a card of the user
+func UpdateHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	reader *request.Reader,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	typedReader := request.NewTypedReader[CardRequest](reader)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, ok := cardID(ctx, r, w, jw)
+		if !ok {
+			return
+		}
+
+		body, err := typedReader.ReadAndValidateJSON(r)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to parse request body", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		if !predefinedCardExists(ctx, log, dbConn, body.PredefinedCardKey) {
+			jw.WriteProblem(ctx, r, w, unknownPredefinedCard())
+			return
+		}
+
+		card, err := dbConn.Queries.UpdateCard(ctx, models.UpdateCardParams{
+			ID:                id,
+			Name:              body.Name,
+			Issuer:            body.Issuer,
+			Last4Digits:       body.Last4Digits,
+			ExpiryDate:        body.ExpiryDate,
+			DefaultRewardRate: body.DefaultRewardRate,
+			CardType:          body.CardType,
+			StatementDay:      body.StatementDay,
+			PaymentDueDays:    body.paymentDueDays(),
+			PredefinedCardKey: body.PredefinedCardKey,
+			CreditLimitMinor:  body.CreditLimit,
+			Shared:            body.Shared,
+			UserID:            auth.UserID(ctx),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			jw.WriteProblem(ctx, r, w, notFound())
+			return
+		}
+
+		if err != nil {
+			log.ErrorContext(ctx, "failed to update card", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		jw.Ok(ctx, w, newUserCard(card, time.Now()))
+	}
+}
+
+func (c *CardRequest) paymentDueDays() int64 {
+	if c.PaymentDueDays == nil {
+		return defaultPaymentDueDays
+	}
+
+	return *c.PaymentDueDays
+}
+
+// cardID reads the card ID from the path, writing a problem response when it is invalid
+func cardID(ctx context.Context, r *http.Request, w http.ResponseWriter, jw *response.JSONWriter) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		jw.WriteProblem(ctx, r, w, response.NewProblem().
+			WithStatus(http.StatusBadRequest).
+			WithDetail("card id must be a number").
+			Build())
+		return 0, false
+	}
+
+	return id, true
+}
+
+// predefinedCardExists reports whether the key, if set, refers to a stored predefined card
+func predefinedCardExists(ctx context.Context, log *slog.Logger, dbConn *db.DB, key *string) bool {
+	if key == nil {
+		return true
+	}
+
+	_, err := dbConn.Queries.GetPredefinedCardByKey(ctx, *key)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.ErrorContext(ctx, "failed to get predefined card", logger.Error(err))
+	}
+
+	return err == nil
+}
+
+func notFound() response.Problem {
+	return response.NewProblem().
+		WithStatus(http.StatusNotFound).
+		WithDetail("card not found").
+		Build()
+}
+
+func unknownPredefinedCard() response.Problem {
+	return response.NewProblem().
+		WithStatus(http.StatusUnprocessableEntity).
+		WithDetail("predefined_card_key does not refer to a known card").
+		Build()
+}
@@ -0,0 +1,87 @@
+package usercards
+
+import (
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/billing"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"log/slog"
+	"net/http"
+)
+
+// CardUtilisation is the credit position of a user card
+type CardUtilisation struct {
+	CardID int64  `json:"card_id,omitempty"`
+	Name   string `json:"name"`
+	billing.Balance
+	Available money.Money `json:"available"`
+	// Utilisation is the outstanding balance as a percentage of the credit limit,
+	// only set when the limit is known
+	Utilisation *float64 `json:"utilisation"`
+}
+
+// UtilisationHandler returns the credit utilisation of every card of the user and overall.
+// The outstanding balance of a card is its logged transactions minus its recorded payments.
+func UtilisationHandler(
+	log *slog.Logger,
+	jw *response.JSONWriter,
+	dbConn *db.DB,
+) http.HandlerFunc {
+	type Response struct {
+		Cards []*CardUtilisation `json:"cards"`
+		// Overall is the utilisation across the cards with a known credit limit
+		Overall *CardUtilisation `json:"overall"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		rows, err := dbConn.Queries.GetCardBalances(ctx, auth.UserID(ctx))
+		if err != nil {
+			log.ErrorContext(ctx, "failed to get card balances", logger.Error(err))
+			jw.WriteError(ctx, r, w, err)
+			return
+		}
+
+		resp := Response{Cards: make([]*CardUtilisation, 0, len(rows))}
+
+		var total billing.Balance
+
+		for _, row := range rows {
+			balance := billing.NewBalance(
+				row.Card.CreditLimitMinor,
+				money.New(row.Spent, money.INR),
+				money.New(row.Paid, money.INR),
+			)
+
+			resp.Cards = append(resp.Cards, newCardUtilisation(row.Card.ID, row.Card.Name, balance))
+
+			// Cards without a known limit would distort the overall utilisation
+			if balance.Limit.IsPositive() {
+				total.Limit = total.Limit.Add(balance.Limit)
+				total.Outstanding = total.Outstanding.Add(balance.Outstanding)
+			}
+		}
+
+		resp.Overall = newCardUtilisation(0, "Overall", total)
+
+		jw.Ok(ctx, w, resp)
+	}
+}
+
+func newCardUtilisation(id int64, name string, balance billing.Balance) *CardUtilisation {
+	u := &CardUtilisation{
+		CardID:    id,
+		Name:      name,
+		Balance:   balance,
+		Available: balance.Available(),
+	}
+
+	if pct, ok := balance.Utilisation(money.Money{}); ok {
+		u.Utilisation = &pct
+	}
+
+	return u
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/api/exports"
+	"github.com/pushkar-anand/cardmax/api/imports"
+	"github.com/pushkar-anand/cardmax/internal/categoriser"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/exporter"
+	"github.com/pushkar-anand/cardmax/internal/importer"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// runCommand runs a command line subcommand instead of the server
+func runCommand(
+	ctx context.Context,
+	log *slog.Logger,
+	dbConn *db.DB,
+	im *imports.Importer,
+	ex *exports.Exporter,
+	suggester *categoriser.Categoriser,
+	args []string,
+) error {
+	switch args[0] {
+	case "import":
+		return runImport(ctx, log, dbConn, im, args[1:], os.Stdout)
+	case "export":
+		return runExport(ctx, dbConn, ex, args[1:], os.Stdout)
+	case "train":
+		return runTrain(ctx, dbConn, suggester, args[1:], os.Stdout)
+	default:
+		return fmt.Errorf("unknown command %q, expected import, export or train", args[0])
+	}
+}
+
+// runImport previews, and with -commit records as drafts to review, the transactions of a CSV,
+// OFX, QIF or PDF statement file or of alert text
+func runImport(
+	ctx context.Context,
+	log *slog.Logger,
+	dbConn *db.DB,
+	im *imports.Importer,
+	args []string,
+	out io.Writer,
+) error {
+	keys := make([]string, 0)
+	for _, m := range im.Mappings() {
+		keys = append(keys, m.Key)
+	}
+
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "", "format of the file: csv, ofx, qif, pdf or alert, defaults to the file's extension")
+	mappingKey := fs.String("mapping", "", "issuer mapping of a CSV file: "+strings.Join(keys, ", "))
+	templateKey := fs.String("template", "", "issuer template of a PDF statement or of alerts, detected when empty")
+	password := fs.String("password", "", "password of an encrypted PDF statement")
+	cardID := fs.Int64("card", 0, "ID of the user card rows without a matching card number are imported into")
+	username := fs.String("user", "", "username of the account whose cards the rows are imported into, defaults to the first account")
+	commit := fs.Bool("commit", false, "record the rows as drafts to review instead of only previewing them")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(),
+			"usage: cardmax import [-format csv|ofx|qif|pdf|alert] [-mapping <key>] [-template <key>] [-password <password>] [-card <id>] [-user <username>] [-commit] <file>")
+		fs.PrintDefaults()
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("a file is required")
+	}
+
+	userID, err := commandUser(ctx, dbConn, *username)
+	if err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = fileFormat(fs.Arg(0))
+	}
+
+	var parse func(io.Reader) (*importer.File, error)
+
+	switch *format {
+	case "csv":
+		if *mappingKey == "" {
+			fs.Usage()
+			return fmt.Errorf("a mapping is required to import a CSV file")
+		}
+
+		mapping, err := im.Mapping(*mappingKey)
+		if err != nil {
+			return err
+		}
+
+		parse = mapping.ParseCSV
+	case "ofx":
+		parse = importer.ParseOFX
+	case "qif":
+		parse = importer.ParseQIF
+	case "pdf":
+		parse = func(r io.Reader) (*importer.File, error) {
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read statement file: %w", err)
+			}
+
+			return im.ParsePDF(data, *password, *templateKey)
+		}
+	case "alert":
+		parse = func(r io.Reader) (*importer.File, error) {
+			text, err := io.ReadAll(r)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read alerts file: %w", err)
+			}
+
+			return im.ParseAlerts(string(text), *templateKey, time.Now())
+		}
+	default:
+		return fmt.Errorf("unknown format %q, expected csv, ofx, qif, pdf or alert", *format)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to open statement file: %w", err)
+	}
+	defer f.Close()
+
+	file, err := parse(f)
+	if err != nil {
+		return err
+	}
+
+	var defaultCard *int64
+	if *cardID != 0 {
+		defaultCard = cardID
+	}
+
+	preview, err := im.Preview(ctx, userID, file, defaultCard)
+	if err != nil {
+		return err
+	}
+
+	printPreview(out, preview)
+
+	if !*commit {
+		_, _ = fmt.Fprintln(out, "\nNothing was recorded, run again with -commit to import.")
+		return nil
+	}
+
+	_, drafts, err := im.SaveDrafts(ctx, log, preview)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(out, "\nAdded %d drafts to review.\n", len(drafts))
+
+	return nil
+}
+
+// runExport writes the transactions and payments of a date range as OFX or QIF
+func runExport(ctx context.Context, dbConn *db.DB, ex *exports.Exporter, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "ofx", "format of the export: ofx or qif")
+	version := fs.Int("version", exporter.OFXVersion1, "OFX version: 1 (SGML) or 2 (XML)")
+	cardID := fs.Int64("card", 0, "ID of the user card to export, defaults to every card")
+	from := fs.String("from", "", "first date to export as YYYY-MM-DD, defaults to a year before -to")
+	to := fs.String("to", "", "last date to export as YYYY-MM-DD, defaults to today")
+	output := fs.String("o", "", "file to write the export to, defaults to the standard output")
+	username := fs.String("user", "", "username of the account to export, defaults to the first account")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(),
+			"usage: cardmax export [-format ofx|qif] [-version 1|2] [-card <id>] [-from <date>] [-to <date>] [-o <file>] [-user <username>]")
+		fs.PrintDefaults()
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	userID, err := commandUser(ctx, dbConn, *username)
+	if err != nil {
+		return err
+	}
+
+	query := exports.Query{From: *from, To: *to}
+	if *cardID != 0 {
+		query.CardID = cardID
+	}
+
+	for _, date := range []string{*from, *to} {
+		if _, err := time.Parse(time.DateOnly, date); date != "" && err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+
+	start, end := query.Range(time.Now())
+
+	s, err := ex.Statement(ctx, userID, query.CardID, start, end)
+	if err != nil {
+		return err
+	}
+
+	out := stdout
+
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create export file: %w", err)
+		}
+		defer f.Close()
+
+		out = f
+	}
+
+	switch *format {
+	case "ofx":
+		return exporter.WriteOFX(out, s, *version)
+	case "qif":
+		return exporter.WriteQIF(out, s)
+	default:
+		return fmt.Errorf("unknown format %q, expected ofx or qif", *format)
+	}
+}
+
+// runTrain trains the category model of a user on the categorised spends of their ledger and
+// suggests the categories of their drafts waiting for review again
+func runTrain(
+	ctx context.Context,
+	dbConn *db.DB,
+	suggester *categoriser.Categoriser,
+	args []string,
+	out io.Writer,
+) error {
+	fs := flag.NewFlagSet("train", flag.ContinueOnError)
+	username := fs.String("user", "", "username of the account to train the model of, defaults to the first account")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(fs.Output(), "usage: cardmax train [-user <username>]")
+		fs.PrintDefaults()
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	userID, err := commandUser(ctx, dbConn, *username)
+	if err != nil {
+		return err
+	}
+
+	model, err := suggester.Train(ctx, *userID)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(out, "Trained on %d spends across %d categories.\n", model.Samples, model.Categories)
+
+	if model.Categories < 2 {
+		_, _ = fmt.Fprintln(out, "Categorise spends of at least two categories for suggestions to be made.")
+	}
+
+	suggested, err := suggester.Refresh(ctx, *userID)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(out, "Suggested categories for %d drafts.\n", suggested)
+
+	return nil
+}
+
+// commandUser returns the ID of the account a subcommand works on: the one with the username,
+// the first account when it is empty
+func commandUser(ctx context.Context, dbConn *db.DB, username string) (*int64, error) {
+	var (
+		user *models.User
+		err  error
+	)
+
+	if username != "" {
+		user, err = dbConn.Queries.GetUserByUsername(ctx, username)
+	} else {
+		user, err = dbConn.Queries.GetFirstUser(ctx)
+	}
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows) && username != "":
+		return nil, fmt.Errorf("unknown user %q", username)
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("no account exists yet, register one on the login page first")
+	case err != nil:
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return &user.ID, nil
+}
+
+// fileFormat returns the format of a statement file by its extension
+func fileFormat(path string) string {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".ofx", ".qfx":
+		return "ofx"
+	case ".qif":
+		return "qif"
+	case ".pdf":
+		return "pdf"
+	default:
+		return strings.TrimPrefix(ext, ".")
+	}
+}
+
+func printPreview(out io.Writer, preview *imports.Preview) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "LINE\tDATE\tKIND\tCARD\tAMOUNT\tREWARD\tCONFIDENCE\tSTATUS\tDESCRIPTION")
+
+	for _, row := range preview.Rows {
+		card, reward, status := "-", "", "new"
+
+		if row.CardID != nil {
+			card = fmt.Sprint(*row.CardID)
+		}
+
+		if row.RewardValue != nil {
+			reward = row.CashValue.Decimal()
+		}
+
+		switch {
+		case row.Error != "":
+			status = row.Error
+		case row.Duplicate:
+			status = "duplicate"
+		}
+
+		description := row.Description
+		if row.Merchant != "" {
+			description = fmt.Sprintf("%s (%s)", row.Description, row.Merchant)
+		}
+
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%.2f\t%s\t%s\n",
+			row.Line, row.Date, row.Kind, card, row.Amount.Decimal(), reward, row.Confidence, status, description)
+	}
+
+	_ = tw.Flush()
+
+	for _, e := range preview.Errors {
+		_, _ = fmt.Fprintf(out, "line %d: %s\n", e.Line, e.Error)
+	}
+
+	if st := preview.Statement; st != nil {
+		_, _ = fmt.Fprintf(out, "\nStatement %s, due %s: total %s, minimum %s\n",
+			cmp.Or(st.StatementDate, "-"), cmp.Or(st.DueDate, "-"), st.TotalDue.Decimal(), st.MinimumDue.Decimal())
+
+		if p := st.Points; p != nil {
+			_, _ = fmt.Fprintf(out, "Reward points: opening %g, earned %g, redeemed %g, expired %g, closing %g\n",
+				p.Opening, p.Earned, p.Redeemed, p.Expired, p.Closing)
+		}
+	}
+
+	s := preview.Summary
+	_, _ = fmt.Fprintf(out, "\n%d rows: %d spends (%s, rewards worth %s), %d refunds, %d payments, %d duplicates, %d invalid\n",
+		s.Rows, s.Spends, s.Spend.Decimal(), s.CashValue.Decimal(), s.Refunds, s.Payments, s.Duplicates, s.Invalid)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 type (
@@ -16,16 +17,218 @@ type (
 		Path string `env:"path"`
 	}
 
+	Float struct {
+		// Rate is the annual opportunity-cost rate (in percent) used to value interest-free days
+		// in the float strategy. Zero ranks cards by interest-free days alone.
+		Rate float64 `env:"rate"`
+	}
+
+	Utilisation struct {
+		// Threshold is the credit utilisation (in percent) above which cards are ranked last
+		Threshold float64 `env:"threshold"`
+	}
+
+	Recommend struct {
+		Float       Float       `env:"float"`
+		Utilisation Utilisation `env:"utilisation"`
+	}
+
+	Cap struct {
+		// Threshold is the share of a reward cap (in percent) spent in a cycle that raises a warning
+		Threshold float64 `env:"threshold"`
+	}
+
+	Reminder struct {
+		// Days is how many days before a due date a payment reminder is raised
+		Days int `env:"days"`
+		// Interval is how often statements are generated and reminders checked
+		Interval time.Duration `env:"interval"`
+		Cap      Cap           `env:"cap"`
+	}
+
+	Webhook struct {
+		// URL receives notifications as JSON, disabled when empty
+		URL string `env:"url"`
+		// Secret signs the request body with HMAC-SHA256
+		Secret string `env:"secret"`
+	}
+
+	SMTP struct {
+		// Host is the SMTP server, email is disabled when empty
+		Host     string `env:"host"`
+		Port     int    `env:"port"`
+		Username string `env:"username"`
+		Password string `env:"password"`
+		From     string `env:"from"`
+		To       string `env:"to"`
+	}
+
+	Ntfy struct {
+		// URL is the ntfy topic URL push notifications are published to, disabled when empty
+		URL string `env:"url"`
+		// Token is the access token of a protected topic, if any
+		Token string `env:"token"`
+	}
+
+	VAPID struct {
+		// Public and Private are the base64url VAPID key pair. A pair is generated and stored in
+		// the database when they are not set.
+		Public  string `env:"public"`
+		Private string `env:"private"`
+	}
+
+	Push struct {
+		// Subject is the mailto: or https: contact sent to push services
+		Subject string `env:"subject"`
+		// TTL is how long push services keep a message for a browser that is offline
+		TTL   time.Duration `env:"ttl"`
+		VAPID VAPID         `env:"vapid"`
+	}
+
+	Retry struct {
+		// Attempts is the number of times a delivery is tried before it is given up
+		Attempts int `env:"attempts"`
+		// Interval is the delay before the first retry, doubled on every further retry
+		Interval time.Duration `env:"interval"`
+	}
+
+	Notify struct {
+		Webhook Webhook `env:"webhook"`
+		SMTP    SMTP    `env:"smtp"`
+		Ntfy    Ntfy    `env:"ntfy"`
+		Push    Push    `env:"push"`
+		Retry   Retry   `env:"retry"`
+		// User is the username of the account the webhook, email and ntfy destinations belong to,
+		// defaults to the first account created. Other accounts are notified through Web Push only.
+		User string `env:"user"`
+	}
+
+	IMAP struct {
+		// Host is the IMAP server alert emails are read from, disabled when empty. Port 993 uses
+		// TLS, other ports STARTTLS when the server offers it.
+		Host     string `env:"host"`
+		Port     int    `env:"port"`
+		Username string `env:"username"`
+		Password string `env:"password"`
+		// Mailbox is the folder alert emails are read from
+		Mailbox string `env:"mailbox"`
+	}
+
+	Mail struct {
+		// Maildir is the path of a local Maildir alert emails are read from, disabled when empty
+		Maildir string `env:"maildir"`
+		IMAP    IMAP   `env:"imap"`
+		// Senders are the comma separated addresses or domains alert emails are accepted from,
+		// e.g. hdfcbank.net. Any sender is accepted when empty.
+		Senders string `env:"senders"`
+		// Interval is how often the mailboxes are polled
+		Interval time.Duration `env:"interval"`
+		// User is the username of the account alert emails are imported for, defaults to the first
+		// account created
+		User string `env:"user"`
+	}
+
+	Session struct {
+		// TTL is how long a login lasts
+		TTL time.Duration `env:"ttl"`
+		// Secure marks the session cookie as HTTPS only, to be set when served over HTTPS
+		Secure bool `env:"secure"`
+	}
+
+	Passkey struct {
+		// RPID is the domain passkeys are bound to, e.g. cardmax.example.com
+		RPID string `env:"rpid"`
+		// Origins are the comma separated origins the app is served from, e.g.
+		// https://cardmax.example.com
+		Origins string `env:"origins"`
+	}
+
+	Auth struct {
+		Session Session `env:"session"`
+		Passkey Passkey `env:"passkey"`
+		// Registration lets anyone create an account. The first account can always be created.
+		Registration bool `env:"registration"`
+	}
+
 	Config struct {
 		Server      Server      `env:"server"`
 		Environment Environment `env:"environment"`
 		DB          DB          `env:"db"`
+		Recommend   Recommend   `env:"recommend"`
+		Reminder    Reminder    `env:"reminder"`
+		Notify      Notify      `env:"notify"`
+		Mail        Mail        `env:"mail"`
+		Auth        Auth        `env:"auth"`
 	}
 )
 
 // SetDefaults sets default values for configuration fields if they are not already set.
-// Specifically, it sets a default database path if `DB.Path` is empty.
+// Specifically, it sets a default database path if `DB.Path` is empty, a default
+// utilisation threshold of 30%, payment reminders 3 days before due, checked hourly, cap warnings
+// at 80% of a cap, notification deliveries tried 5 times starting a minute apart, alert
+// emails polled every 5 minutes from the INBOX of an IMAP server on port 993, logins lasting
+// 30 days and passkeys bound to localhost on the server's port.
 func (c *Config) SetDefaults() error {
+	if c.Recommend.Utilisation.Threshold == 0 {
+		c.Recommend.Utilisation.Threshold = 30
+	}
+
+	if c.Reminder.Days == 0 {
+		c.Reminder.Days = 3
+	}
+
+	if c.Reminder.Interval == 0 {
+		c.Reminder.Interval = time.Hour
+	}
+
+	if c.Reminder.Cap.Threshold == 0 {
+		c.Reminder.Cap.Threshold = 80
+	}
+
+	if c.Notify.Push.Subject == "" {
+		c.Notify.Push.Subject = "mailto:cardmax@localhost"
+	}
+
+	if c.Notify.Push.TTL == 0 {
+		c.Notify.Push.TTL = 24 * time.Hour
+	}
+
+	if c.Notify.SMTP.Port == 0 {
+		c.Notify.SMTP.Port = 587
+	}
+
+	if c.Notify.Retry.Attempts == 0 {
+		c.Notify.Retry.Attempts = 5
+	}
+
+	if c.Notify.Retry.Interval == 0 {
+		c.Notify.Retry.Interval = time.Minute
+	}
+
+	if c.Mail.IMAP.Port == 0 {
+		c.Mail.IMAP.Port = 993
+	}
+
+	if c.Mail.IMAP.Mailbox == "" {
+		c.Mail.IMAP.Mailbox = "INBOX"
+	}
+
+	if c.Mail.Interval == 0 {
+		c.Mail.Interval = 5 * time.Minute
+	}
+
+	if c.Auth.Session.TTL == 0 {
+		c.Auth.Session.TTL = 30 * 24 * time.Hour
+	}
+
+	if c.Auth.Passkey.RPID == "" {
+		c.Auth.Passkey.RPID = "localhost"
+	}
+
+	if c.Auth.Passkey.Origins == "" {
+		c.Auth.Passkey.Origins = fmt.Sprintf("http://%s:%d", c.Auth.Passkey.RPID, c.Server.Port)
+	}
+
 	if c.DB.Path == "" {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
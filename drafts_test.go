@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// saveDraft saves a draft of the kind on the card for review
+func saveDraft(t *testing.T, cardID int64, kind, description, amount, externalID string) *models.DraftTransaction {
+	t.Helper()
+
+	d := models.CreateDraftTransactionParams{
+		CardID:          cardID,
+		Source:          db.SourceCSV,
+		Kind:            kind,
+		TransactionDate: "2026-10-03",
+		Description:     description,
+		AmountMinor:     money.MustParse(amount, money.INR),
+		Parser:          "test",
+		Confidence:      1,
+	}
+
+	if externalID != "" {
+		d.ExternalID = &externalID
+	}
+
+	_, saved, err := srv.db.SaveDrafts(context.Background(), discard, nil, []models.CreateDraftTransactionParams{d})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return saved[0]
+}
+
+// approve approves the drafts, returning the status, the approved ledger rows and the drafts
+// listed as duplicates
+func approve(c *testClient, ids ...int64) (int, *approved, []int64) {
+	c.t.Helper()
+
+	w := c.send(c.request(http.MethodPost, "/api/drafts/approve", map[string]any{"ids": ids}))
+
+	var resp struct {
+		approved
+		DraftIDs []int64 `json:"draft_ids"`
+	}
+
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	if err != nil {
+		c.t.Fatalf("failed to decode response %s: %v", w.Body, err)
+	}
+
+	return w.Code, &resp.approved, resp.DraftIDs
+}
+
+// approved is the ledger rows approved drafts became
+type approved struct {
+	Transactions []*models.Transaction `json:"transactions"`
+	Payments     []*models.Payment     `json:"payments"`
+}
+
+// draftIDs returns the IDs of the client's drafts
+func draftIDs(c *testClient) []int64 {
+	c.t.Helper()
+
+	var resp struct {
+		Drafts []*models.DraftTransaction `json:"drafts"`
+	}
+
+	if status := c.do(http.MethodGet, "/api/drafts", nil, &resp); status != http.StatusOK {
+		c.t.Fatalf("listing drafts responded with %d", status)
+	}
+
+	ids := make([]int64, 0, len(resp.Drafts))
+	for _, d := range resp.Drafts {
+		ids = append(ids, d.ID)
+	}
+
+	slices.Sort(ids)
+
+	return ids
+}
+
+func TestApproveDrafts(t *testing.T) {
+	ctx := context.Background()
+
+	c := register(t, "approve-drafts")
+	card := addCard(t, c, "Approved Regalia", "HDFC-REGALIA-GOLD")
+
+	statement, err := srv.db.Queries.CreateStatement(ctx, models.CreateStatementParams{
+		CardID:          card.ID,
+		CycleStart:      "2026-08-06",
+		StatementDate:   "2026-09-05",
+		DueDate:         "2026-09-25",
+		TotalDueMinor:   money.MustParse("1000", money.INR),
+		MinimumDueMinor: money.MustParse("200", money.INR),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merchant, suggested, externalID := "swiggy", "food_delivery", "spend-1"
+
+	spendDraft, err := srv.db.Queries.CreateDraftTransaction(ctx, models.CreateDraftTransactionParams{
+		CardID:            card.ID,
+		Source:            db.SourceCSV,
+		Kind:              db.DraftKindSpend,
+		TransactionDate:   "2026-10-03",
+		Description:       "PAYU*SWIGGY BANGALORE",
+		AmountMinor:       money.MustParse("450", money.INR),
+		ExternalID:        &externalID,
+		Merchant:          &merchant,
+		Parser:            "test",
+		Confidence:        1,
+		SuggestedCategory: &suggested,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refund := saveDraft(t, card.ID, db.DraftKindRefund, "AMAZON REFUND", "200", "refund-1")
+	payment := saveDraft(t, card.ID, db.DraftKindPayment, "PAYMENT RECEIVED", "1000", "payment-1")
+
+	t.Run("approve", func(t *testing.T) {
+		status, got, _ := approve(c, spendDraft.ID, refund.ID, payment.ID)
+		if status != http.StatusOK {
+			t.Fatalf("approving responded with %d", status)
+		}
+
+		if len(got.Transactions) != 2 || len(got.Payments) != 1 {
+			t.Fatalf("approved %d transactions and %d payments, want 2 and 1", len(got.Transactions), len(got.Payments))
+		}
+
+		spend, refunded := got.Transactions[0], got.Transactions[1]
+
+		if spend.AmountMinor.Cmp(money.MustParse("450", money.INR)) != 0 || deref(spend.Merchant) != "swiggy" ||
+			deref(spend.Notes) != "PAYU*SWIGGY BANGALORE" || deref(spend.Category) != suggested ||
+			deref(spend.ExternalID) != "spend-1" || spend.Source != db.SourceCSV {
+			t.Errorf("spend = %+v", spend)
+		}
+
+		if refunded.AmountMinor.Cmp(money.MustParse("-200", money.INR)) != 0 || deref(refunded.Merchant) != "AMAZON REFUND" {
+			t.Errorf("refund = %+v, want a transaction of -200", refunded)
+		}
+
+		paid := got.Payments[0]
+		if paid.AmountMinor.Cmp(money.MustParse("1000", money.INR)) != 0 || paid.StatementID == nil ||
+			*paid.StatementID != statement.ID || deref(paid.ExternalID) != "payment-1" {
+			t.Errorf("payment = %+v, want 1000 towards statement %d", paid, statement.ID)
+		}
+
+		settled, err := srv.db.Queries.GetStatementByID(ctx, statement.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if settled.PaidAt == nil || settled.PaidMinor.Cmp(money.MustParse("1000", money.INR)) != 0 {
+			t.Errorf("statement = %+v, want paid", settled)
+		}
+
+		if ids := draftIDs(c); len(ids) != 0 {
+			t.Errorf("drafts %v left after approving", ids)
+		}
+	})
+
+	t.Run("duplicate external IDs", func(t *testing.T) {
+		fresh := saveDraft(t, card.ID, db.DraftKindSpend, "ZOMATO", "620", "spend-2")
+		spendAgain := saveDraft(t, card.ID, db.DraftKindSpend, "PAYU*SWIGGY BANGALORE", "450", "spend-1")
+		paymentAgain := saveDraft(t, card.ID, db.DraftKindPayment, "PAYMENT RECEIVED", "1000", "payment-1")
+		// A transaction of the batch carries the same external ID
+		twice := saveDraft(t, card.ID, db.DraftKindRefund, "ZOMATO REFUND", "620", "spend-2")
+		// A payment may share the external ID of a transaction
+		paymentOfSpend := saveDraft(t, card.ID, db.DraftKindPayment, "AUTOPAY", "500", "spend-2")
+
+		status, _, duplicates := approve(c, fresh.ID, spendAgain.ID, paymentAgain.ID, twice.ID, paymentOfSpend.ID)
+		if status != http.StatusConflict {
+			t.Fatalf("approving duplicates responded with %d, want %d", status, http.StatusConflict)
+		}
+
+		if want := []int64{spendAgain.ID, paymentAgain.ID, twice.ID}; !slices.Equal(duplicates, want) {
+			t.Errorf("duplicates = %v, want %v", duplicates, want)
+		}
+
+		// None of the drafts is approved
+		want := []int64{fresh.ID, spendAgain.ID, paymentAgain.ID, twice.ID, paymentOfSpend.ID}
+		if ids := draftIDs(c); !slices.Equal(ids, want) {
+			t.Errorf("drafts = %v, want %v", ids, want)
+		}
+
+		status, got, _ := approve(c, fresh.ID, paymentOfSpend.ID)
+		if status != http.StatusOK || len(got.Transactions) != 1 || len(got.Payments) != 1 {
+			t.Errorf("approving the rest responded with %d and %+v", status, got)
+		}
+	})
+
+	t.Run("draft of another user", func(t *testing.T) {
+		other := register(t, "approve-drafts-other")
+		draft := saveDraft(t, card.ID, db.DraftKindSpend, "MYNTRA", "999", "")
+
+		if status, _, _ := approve(other, draft.ID); status != http.StatusNotFound {
+			t.Errorf("approving another user's draft responded with %d, want %d", status, http.StatusNotFound)
+		}
+	})
+}
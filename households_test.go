@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"maps"
+	"net/http"
+	"testing"
+)
+
+// ledger is the household ledger of a period as the API returns it
+type ledger struct {
+	Members []struct {
+		UserID int64       `json:"user_id"`
+		Spend  money.Money `json:"spend"`
+	} `json:"members"`
+	Transactions []struct {
+		CardID int64 `json:"card_id"`
+	} `json:"transactions"`
+	Total money.Money `json:"total"`
+}
+
+// getLedger returns the client's household ledger of October 2026
+func getLedger(t *testing.T, c *testClient) *ledger {
+	t.Helper()
+
+	var l ledger
+
+	status := c.do(http.MethodGet, "/api/household/ledger?from=2026-10-01&to=2026-10-31", nil, &l)
+	if status != http.StatusOK {
+		t.Fatalf("getting the ledger responded with %d", status)
+	}
+
+	return &l
+}
+
+// spends returns the spend of each member in the ledger along with the cards of its transactions
+func (l *ledger) spends() (map[int64]string, map[int64]int) {
+	members := make(map[int64]string, len(l.Members))
+	for _, m := range l.Members {
+		members[m.UserID] = m.Spend.Decimal()
+	}
+
+	cards := make(map[int64]int)
+	for _, txn := range l.Transactions {
+		cards[txn.CardID]++
+	}
+
+	return members, cards
+}
+
+func TestHousehold(t *testing.T) {
+	owner := register(t, "household-owner")
+	member := register(t, "household-member")
+	outsider := register(t, "household-outsider")
+
+	var invite struct {
+		ID int64 `json:"id"`
+	}
+
+	if status := owner.do(http.MethodPost, "/api/household", map[string]string{"name": "Home"}, nil); status != http.StatusCreated {
+		t.Fatalf("creating the household responded with %d", status)
+	}
+
+	status := owner.do(http.MethodPost, "/api/household/invites", map[string]string{"username": member.User.Username}, &invite)
+	if status != http.StatusCreated {
+		t.Fatalf("inviting responded with %d", status)
+	}
+
+	if status := outsider.do(http.MethodPost, fmt.Sprintf("/api/household/invites/%d/accept", invite.ID), nil, nil); status != http.StatusNotFound {
+		t.Errorf("accepting another user's invite responded with %d, want %d", status, http.StatusNotFound)
+	}
+
+	if status := member.do(http.MethodPost, fmt.Sprintf("/api/household/invites/%d/accept", invite.ID), nil, nil); status != http.StatusOK {
+		t.Fatalf("accepting the invite responded with %d", status)
+	}
+
+	var shared models.Card
+
+	status = owner.do(http.MethodPost, "/api/user-cards", map[string]any{
+		"name":                "Family Regalia",
+		"issuer":              "HDFC Bank",
+		"last4_digits":        "4321",
+		"expiry_date":         "2030-12",
+		"card_type":           "credit",
+		"statement_day":       5,
+		"predefined_card_key": "HDFC-REGALIA-GOLD",
+		"credit_limit":        "300000",
+		"shared":              true,
+	}, &shared)
+	if status != http.StatusCreated {
+		t.Fatalf("creating the shared card responded with %d", status)
+	}
+
+	private := addCard(t, owner, "Owner's Regalia", "HDFC-REGALIA-GOLD")
+	own := addCard(t, member, "Member's Regalia", "HDFC-REGALIA-GOLD")
+
+	t.Run("household", func(t *testing.T) {
+		var household struct {
+			Members []struct {
+				UserID int64 `json:"user_id"`
+			} `json:"members"`
+			Cards []struct {
+				ID     int64  `json:"id"`
+				Holder string `json:"holder"`
+			} `json:"cards"`
+		}
+
+		if status := member.do(http.MethodGet, "/api/household", nil, &household); status != http.StatusOK {
+			t.Fatalf("getting the household responded with %d", status)
+		}
+
+		if len(household.Members) != 2 {
+			t.Errorf("%d members, want 2", len(household.Members))
+		}
+
+		if len(household.Cards) != 1 || household.Cards[0].ID != shared.ID || household.Cards[0].Holder != owner.User.Username {
+			t.Errorf("shared cards = %+v, want only card %d of %s", household.Cards, shared.ID, owner.User.Username)
+		}
+
+		if status := outsider.do(http.MethodGet, "/api/household", nil, nil); status != http.StatusNotFound {
+			t.Errorf("an outsider getting the household responded with %d, want %d", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("spent by", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			client  *testClient
+			cardID  int64
+			amount  string
+			spentBy *int64
+			status  int
+			// want is the member the spend is recorded as made by, nil for the holder
+			want *int64
+		}{
+			{name: "by a member", client: owner, cardID: shared.ID, amount: "450", spentBy: &member.User.ID, status: http.StatusCreated, want: &member.User.ID},
+			{name: "by the holder", client: owner, cardID: shared.ID, amount: "100", spentBy: &owner.User.ID, status: http.StatusCreated},
+			{name: "on a private card", client: owner, cardID: private.ID, amount: "300", status: http.StatusCreated},
+			{name: "on the member's card", client: member, cardID: own.ID, amount: "200", status: http.StatusCreated},
+			{name: "by an outsider", client: owner, cardID: shared.ID, amount: "50", spentBy: &outsider.User.ID, status: http.StatusUnprocessableEntity},
+			{name: "by an unknown user", client: owner, cardID: shared.ID, amount: "50", spentBy: new(int64), status: http.StatusUnprocessableEntity},
+			// Shared cards are read-only to the other members
+			{name: "on a shared card", client: member, cardID: shared.ID, amount: "50", status: http.StatusUnprocessableEntity},
+			{name: "on another household's card", client: outsider, cardID: shared.ID, amount: "50", status: http.StatusUnprocessableEntity},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var txn models.Transaction
+
+				status := tt.client.do(http.MethodPost, "/api/transactions", map[string]any{
+					"card_id":          tt.cardID,
+					"merchant":         "Swiggy",
+					"amount":           tt.amount,
+					"transaction_date": "2026-10-10",
+					"spent_by":         tt.spentBy,
+				}, &txn)
+				if status != tt.status {
+					t.Fatalf("logging responded with %d, want %d", status, tt.status)
+				}
+
+				if status != http.StatusCreated {
+					return
+				}
+
+				if (txn.SpentBy == nil) != (tt.want == nil) || (tt.want != nil && *txn.SpentBy != *tt.want) {
+					t.Errorf("spent_by = %v, want %v", txn.SpentBy, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("ledger", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			client *testClient
+			// members is the spend of each member, cards the number of transactions of each card
+			members map[int64]string
+			cards   map[int64]int
+			total   string
+		}{
+			{
+				name:    "owner",
+				client:  owner,
+				members: map[int64]string{owner.User.ID: "400.00", member.User.ID: "450.00"},
+				cards:   map[int64]int{shared.ID: 2, private.ID: 1},
+				total:   "850.00",
+			},
+			{
+				name:    "member",
+				client:  member,
+				members: map[int64]string{owner.User.ID: "100.00", member.User.ID: "650.00"},
+				cards:   map[int64]int{shared.ID: 2, own.ID: 1},
+				total:   "750.00",
+			},
+			{
+				name:    "outsider",
+				client:  outsider,
+				members: map[int64]string{},
+				cards:   map[int64]int{},
+				total:   "0.00",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				l := getLedger(t, tt.client)
+				members, cards := l.spends()
+
+				if !maps.Equal(members, tt.members) {
+					t.Errorf("member spends = %v, want %v", members, tt.members)
+				}
+
+				if !maps.Equal(cards, tt.cards) {
+					t.Errorf("transactions by card = %v, want %v", cards, tt.cards)
+				}
+
+				if l.Total.Decimal() != tt.total {
+					t.Errorf("total = %s, want %s", l.Total.Decimal(), tt.total)
+				}
+			})
+		}
+	})
+
+	t.Run("after leaving", func(t *testing.T) {
+		status := member.do(http.MethodDelete, fmt.Sprintf("/api/household/members/%d", member.User.ID), nil, nil)
+		if status != http.StatusNoContent {
+			t.Fatalf("leaving responded with %d", status)
+		}
+
+		if _, cards := getLedger(t, member).spends(); !maps.Equal(cards, map[int64]int{own.ID: 1}) {
+			t.Errorf("transactions by card = %v, want only the member's card", cards)
+		}
+
+		status = owner.do(http.MethodPost, "/api/transactions", map[string]any{
+			"card_id":  shared.ID,
+			"amount":   "80",
+			"spent_by": member.User.ID,
+		}, nil)
+		if status != http.StatusUnprocessableEntity {
+			t.Errorf("logging a spend by a former member responded with %d, want %d", status, http.StatusUnprocessableEntity)
+		}
+	})
+}
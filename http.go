@@ -7,19 +7,33 @@ import (
 	"github.com/pushkar-anand/build-with-go/http/request"
 	"github.com/pushkar-anand/build-with-go/http/response"
 	"github.com/pushkar-anand/build-with-go/http/server"
+	"github.com/pushkar-anand/cardmax/api/exports"
+	"github.com/pushkar-anand/cardmax/api/imports"
 	projectconfig "github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/cards"
 	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/notify"
+	"github.com/pushkar-anand/cardmax/internal/taxonomy"
+	"github.com/pushkar-anand/cardmax/internal/webpush"
 	"github.com/pushkar-anand/cardmax/web"
 	"log/slog"
 )
 
 func NewServer(
-	cfg projectconfig.Server,
+	cfg *projectconfig.Config,
 	logger *slog.Logger,
 	tr *web.Renderer,
 	jsonWriter *response.JSONWriter,
 	reader *request.Reader,
 	db *db.DB,
+	cardList []*cards.Card,
+	dispatcher *notify.Dispatcher,
+	vapid *webpush.VAPID,
+	im *imports.Importer,
+	ex *exports.Exporter,
+	normaliser *taxonomy.Normaliser,
+	authn *auth.Authenticator,
 ) *server.Server {
 	h := mux.NewRouter()
 
@@ -29,11 +43,20 @@ func NewServer(
 		tr,
 		jsonWriter,
 		reader,
+		cfg,
+		db,
+		cardList,
+		dispatcher,
+		vapid,
+		im,
+		ex,
+		normaliser,
+		authn,
 	)
 
 	s := server.New(
 		h,
-		server.WithHostPort(cfg.Host, cfg.Port),
+		server.WithHostPort(cfg.Server.Host, cfg.Server.Port),
 		server.WithLogger(logger),
 	)
 
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pushkar-anand/cardmax/api/imports"
+	"net/http"
+	"testing"
+)
+
+// TestExportImport checks that an export imports into another account as it was exported, and that
+// importing it again, or into the account it was exported from, only finds duplicates by the FITID
+// or QIF N field of its rows
+func TestExportImport(t *testing.T) {
+	owner := register(t, "roundtrip-owner")
+	card := addCard(t, owner, "Regalia", "HDFC-REGALIA-GOLD")
+
+	for _, txn := range []map[string]any{
+		{"merchant": "Swiggy", "amount": "450", "transaction_date": "2026-10-02"},
+		{"merchant": "Amazon", "amount": "1299.50", "transaction_date": "2026-10-05"},
+		{"merchant": "Amazon", "amount": "-120.50", "transaction_date": "2026-10-07"},
+	} {
+		txn["card_id"] = card.ID
+
+		if status := owner.do(http.MethodPost, "/api/transactions", txn, nil); status != http.StatusCreated {
+			t.Fatalf("logging %v responded with %d", txn, status)
+		}
+	}
+
+	status := owner.do(http.MethodPost, "/api/payments", map[string]any{
+		"card_id": card.ID, "amount": "10000", "payment_date": "2026-10-06",
+	}, nil)
+	if status != http.StatusCreated {
+		t.Fatalf("recording the payment responded with %d", status)
+	}
+
+	tests := []struct {
+		name   string
+		export string
+		path   string
+	}{
+		{name: "ofx-v1", export: "/api/exports/ofx?version=1&from=2026-10-01&to=2026-10-31", path: "/api/imports/ofx"},
+		{name: "ofx-v2", export: "/api/exports/ofx?version=2&from=2026-10-01&to=2026-10-31", path: "/api/imports/ofx"},
+		{name: "qif", export: "/api/exports/qif?from=2026-10-01&to=2026-10-31", path: "/api/imports/qif"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := owner.send(owner.request(http.MethodGet, tt.export, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("exporting responded with %d", w.Code)
+			}
+
+			file := w.Body.Bytes()
+
+			into := register(t, "roundtrip-"+tt.name)
+			theirs := addCard(t, into, "Regalia", "HDFC-REGALIA-GOLD")
+			fields := map[string]string{"card_id": fmt.Sprint(theirs.ID), "commit": "true"}
+
+			var imported imports.Result
+
+			if status := into.upload(tt.path, fields, file, &imported); status != http.StatusOK {
+				t.Fatalf("importing responded with %d", status)
+			}
+
+			want := imports.Summary{Rows: 4, Spends: 2, Refunds: 1, Payments: 1}
+			if s := imported.Summary; s.Rows != want.Rows || s.Spends != want.Spends || s.Refunds != want.Refunds ||
+				s.Payments != want.Payments || s.Duplicates != 0 || s.Invalid != 0 {
+				t.Errorf("import summary = %+v, want %+v", s, want)
+			}
+
+			if len(imported.Drafts) != 4 {
+				t.Fatalf("import recorded %d drafts, want 4", len(imported.Drafts))
+			}
+
+			for _, row := range imported.Rows {
+				if row.ExternalID == "" || *row.CardID != theirs.ID {
+					t.Errorf("row %+v imported without its ID or into another card", row.Row)
+				}
+			}
+
+			// Rows are found again in the drafts under review
+			var again imports.Result
+
+			if status := into.upload(tt.path, fields, file, &again); status != http.StatusOK {
+				t.Fatalf("importing again responded with %d", status)
+			}
+
+			if again.Summary.Duplicates != 4 {
+				t.Errorf("importing again found %d duplicates, want 4", again.Summary.Duplicates)
+			}
+
+			// Rows are found in the ledger they were exported from
+			var back imports.Result
+
+			fields = map[string]string{"card_id": fmt.Sprint(card.ID)}
+			if status := owner.upload(tt.path, fields, file, &back); status != http.StatusOK {
+				t.Fatalf("importing into the exporting account responded with %d", status)
+			}
+
+			if back.Summary.Duplicates != 4 {
+				t.Errorf("importing into the exporting account found %d duplicates, want 4", back.Summary.Duplicates)
+			}
+		})
+	}
+}
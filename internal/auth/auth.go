@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CookieName is the name of the cookie carrying the session token
+const CookieName = "cardmax_session"
+
+// minPasswordLength is the length of the shortest password accepted on registration
+const minPasswordLength = 8
+
+var (
+	// ErrInvalidCredentials is returned when logging in with an unknown username or a wrong password
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrWeakPassword is returned when registering with a password shorter than minPasswordLength
+	ErrWeakPassword = fmt.Errorf("password must be at least %d characters", minPasswordLength)
+)
+
+// dummyHash is verified against when logging in as an unknown user, so that the response takes
+// as long as for a wrong password and does not tell which usernames exist
+var dummyHash = sync.OnceValue(func() string {
+	hash, _ := HashPassword("cardmax")
+	return hash
+})
+
+// Authenticator registers users, logs them in and out with their password or a passkey and tells
+// who is logged in from the session cookie. Sessions are stored in the database by the hash of
+// their token.
+type Authenticator struct {
+	log      *slog.Logger
+	db       *db.DB
+	webauthn *webauthn.WebAuthn
+	cfg      config.Auth
+}
+
+// NewAuthenticator creates an authenticator, with passkeys bound to the configured domain
+func NewAuthenticator(log *slog.Logger, dbConn *db.DB, cfg config.Auth) (*Authenticator, error) {
+	w, err := newWebAuthn(cfg.Passkey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Authenticator{
+		log:      log,
+		db:       dbConn,
+		webauthn: w,
+		cfg:      cfg,
+	}, nil
+}
+
+// CanRegister reports whether an account can be created: always while there is none, and
+// afterwards only when registration is open
+func (a *Authenticator) CanRegister(ctx context.Context) (bool, error) {
+	if a.cfg.Registration {
+		return true, nil
+	}
+
+	count, err := a.db.Queries.CountUsers(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	return count == 0, nil
+}
+
+// Register creates a user with the password hashed with argon2id
+func (a *Authenticator) Register(ctx context.Context, username, password string) (*models.User, error) {
+	username = strings.TrimSpace(username)
+
+	if len(password) < minPasswordLength {
+		return nil, ErrWeakPassword
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.db.RegisterUser(ctx, a.log, username, hash, a.cfg.Registration)
+}
+
+// Login checks the username and password and starts a session, setting its cookie
+func (a *Authenticator) Login(
+	ctx context.Context,
+	w http.ResponseWriter,
+	username, password string,
+) (*models.User, error) {
+	user, err := a.db.Queries.GetUserByUsername(ctx, strings.TrimSpace(username))
+	if errors.Is(err, sql.ErrNoRows) {
+		_, _ = VerifyPassword(password, dummyHash())
+		return nil, ErrInvalidCredentials
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	ok, err := VerifyPassword(password, user.PasswordHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify password of user %d: %w", user.ID, err)
+	}
+
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	err = a.StartSession(ctx, w, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// StartSession creates a session for the user and sets its cookie, along with a new CSRF token so
+// that a token set before logging in, e.g. by another site planting the cookie, is not the
+// session's. Expired sessions of every user are removed on the way.
+func (a *Authenticator) StartSession(ctx context.Context, w http.ResponseWriter, userID int64) error {
+	now := time.Now().UTC()
+
+	_, err := a.db.Queries.DeleteExpiredSessions(ctx, now)
+	if err != nil {
+		a.log.ErrorContext(ctx, "failed to delete expired sessions", logger.Error(err))
+	}
+
+	b := make([]byte, 32)
+
+	_, err = rand.Read(b)
+	if err != nil {
+		return fmt.Errorf("failed to generate session token: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	session, err := a.db.Queries.CreateSession(ctx, models.CreateSessionParams{
+		ID:        sessionID(token),
+		UserID:    userID,
+		ExpiresAt: now.Add(a.cfg.Session.TTL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	http.SetCookie(w, a.cookie(token, session.ExpiresAt))
+
+	_, err = setCSRFToken(w, a.cfg.Session.Secure)
+
+	return err
+}
+
+// Logout ends the session of the request, if any, and clears its cookie
+func (a *Authenticator) Logout(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	http.SetCookie(w, a.cookie("", time.Unix(0, 0)))
+
+	c, err := r.Cookie(CookieName)
+	if err != nil {
+		return nil
+	}
+
+	err = a.db.Queries.DeleteSession(ctx, sessionID(c.Value))
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	return nil
+}
+
+// SessionUser returns the user logged in with the session cookie of the request, and nil when
+// there is no cookie or its session is unknown or expired
+func (a *Authenticator) SessionUser(ctx context.Context, r *http.Request) (*models.User, error) {
+	c, err := r.Cookie(CookieName)
+	if err != nil || c.Value == "" {
+		return nil, nil
+	}
+
+	row, err := a.db.Queries.GetSessionUser(ctx, models.GetSessionUserParams{
+		ID:  sessionID(c.Value),
+		Now: time.Now().UTC(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return &row.User, nil
+}
+
+func (a *Authenticator) cookie(token string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     CookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		Secure:   a.cfg.Session.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// sessionID is the ID a session is stored by: the SHA-256 of its token, so that the tokens of
+// a leaked database cannot be used to log in
+func sessionID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+const (
+	// CSRFCookieName is the name of the cookie carrying the CSRF token
+	CSRFCookieName = "cardmax_csrf"
+	// CSRFHeader is the request header the CSRF token is sent in by htmx and scripts. Responses
+	// carry the token in it too, for API clients to send it back.
+	CSRFHeader = "X-CSRF-Token"
+	// CSRFField is the form field the CSRF token is sent in by plain HTML forms
+	CSRFField = "csrf_token"
+)
+
+// csrfKey is the context key of the CSRF token
+type csrfKey struct{}
+
+// CSRFToken returns the CSRF token of a request context, to be sent back with the requests that
+// change something. It is empty outside of CSRFMiddleware.
+func CSRFToken(ctx context.Context) string {
+	token, _ := ctx.Value(csrfKey{}).(string)
+
+	return token
+}
+
+// CSRFMiddleware protects against cross-site request forgery with a double-submit token: the token
+// is set in a cookie and requests other than GET, HEAD and OPTIONS must repeat it in the
+// X-CSRF-Token header or the csrf_token form field, which another site cannot do as it cannot read
+// the cookie. Requests carrying neither the session cookie nor the CSRF cookie are let through, as
+// only cookies are sent by the browser on its own, e.g. for an API client logging in, and so are
+// requests authenticated with an access token, which the browser never sends on its own either.
+func CSRFMiddleware(log *slog.Logger, jw *response.JSONWriter, secure bool) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			token := cookieValue(r, CSRFCookieName)
+			if token == "" {
+				var err error
+
+				token, err = setCSRFToken(w, secure)
+				if err != nil {
+					jw.WriteError(ctx, r, w, err)
+					return
+				}
+			} else {
+				w.Header().Set(CSRFHeader, token)
+			}
+
+			if !safeMethod(r.Method) && !validCSRF(r) {
+				log.WarnContext(ctx, "rejected request without a valid CSRF token",
+					slog.String("method", r.Method), slog.String("path", r.URL.Path))
+
+				if !strings.HasPrefix(r.URL.Path, "/api/") {
+					http.Error(w, "The form has expired, reload the page and try again.", http.StatusForbidden)
+					return
+				}
+
+				jw.WriteProblem(ctx, r, w, response.NewProblem().
+					WithStatus(http.StatusForbidden).
+					WithDetail("missing or invalid CSRF token").
+					Build())
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, csrfKey{}, token)))
+		})
+	}
+}
+
+// setCSRFToken generates a CSRF token, setting it in the cookie and the header of the response
+func setCSRFToken(w http.ResponseWriter, secure bool) (string, error) {
+	b := make([]byte, 32)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate CSRF token: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     CSRFCookieName,
+		Value:    token,
+		Path:     "/",
+		Secure:   secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	w.Header().Set(CSRFHeader, token)
+
+	return token, nil
+}
+
+// validCSRF reports whether the request repeats the token of its CSRF cookie, or is authenticated
+// with nothing a forged request could rely on
+func validCSRF(r *http.Request) bool {
+	if _, ok := bearerToken(r); ok {
+		return true
+	}
+
+	cookie := cookieValue(r, CSRFCookieName)
+	if cookie == "" {
+		return cookieValue(r, CookieName) == ""
+	}
+
+	token := r.Header.Get(CSRFHeader)
+	if token == "" {
+		token = r.PostFormValue(CSRFField)
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(cookie)) == 1
+}
+
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+func cookieValue(r *http.Request, name string) string {
+	c, err := r.Cookie(name)
+	if err != nil {
+		return ""
+	}
+
+	return c.Value
+}
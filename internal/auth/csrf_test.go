@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	const token = "csrf-token-of-the-cookie"
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	handler := CSRFMiddleware(log, response.NewJSONWriter(log), false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := CSRFToken(r.Context()); got == "" {
+			t.Error("no CSRF token in the request context")
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		cookies map[string]string
+		header  map[string]string
+		// form is sent as the body of a form post when not empty
+		form   url.Values
+		status int
+	}{
+		{name: "safe method", method: http.MethodGet, path: "/api/user-cards", status: http.StatusNoContent},
+		{
+			name:    "safe method of a session",
+			method:  http.MethodGet,
+			path:    "/api/user-cards",
+			cookies: map[string]string{CookieName: "session", CSRFCookieName: token},
+			status:  http.StatusNoContent,
+		},
+		// Nothing the browser sends on its own, e.g. an API client logging in
+		{name: "no cookie", method: http.MethodPost, path: "/api/auth/login", status: http.StatusNoContent},
+		{
+			name:    "session without a CSRF cookie",
+			method:  http.MethodPost,
+			path:    "/api/transactions",
+			cookies: map[string]string{CookieName: "session"},
+			status:  http.StatusForbidden,
+		},
+		{
+			name:    "missing header",
+			method:  http.MethodPost,
+			path:    "/api/transactions",
+			cookies: map[string]string{CookieName: "session", CSRFCookieName: token},
+			status:  http.StatusForbidden,
+		},
+		{
+			name:    "missing header without a session",
+			method:  http.MethodPost,
+			path:    "/api/auth/login",
+			cookies: map[string]string{CSRFCookieName: token},
+			status:  http.StatusForbidden,
+		},
+		{
+			name:    "wrong header",
+			method:  http.MethodDelete,
+			path:    "/api/drafts/1",
+			cookies: map[string]string{CookieName: "session", CSRFCookieName: token},
+			header:  map[string]string{CSRFHeader: "csrf-token-of-another"},
+			status:  http.StatusForbidden,
+		},
+		{
+			name:    "header",
+			method:  http.MethodDelete,
+			path:    "/api/drafts/1",
+			cookies: map[string]string{CookieName: "session", CSRFCookieName: token},
+			header:  map[string]string{CSRFHeader: token},
+			status:  http.StatusNoContent,
+		},
+		{
+			name:    "form field",
+			method:  http.MethodPost,
+			path:    "/logout",
+			cookies: map[string]string{CookieName: "session", CSRFCookieName: token},
+			form:    url.Values{CSRFField: {token}},
+			status:  http.StatusNoContent,
+		},
+		{
+			name:    "wrong form field",
+			method:  http.MethodPost,
+			path:    "/logout",
+			cookies: map[string]string{CookieName: "session", CSRFCookieName: token},
+			form:    url.Values{CSRFField: {"csrf-token-of-another"}},
+			status:  http.StatusForbidden,
+		},
+		{
+			name:    "wrong header over the form field",
+			method:  http.MethodPost,
+			path:    "/logout",
+			cookies: map[string]string{CookieName: "session", CSRFCookieName: token},
+			header:  map[string]string{CSRFHeader: "csrf-token-of-another"},
+			form:    url.Values{CSRFField: {token}},
+			status:  http.StatusForbidden,
+		},
+		{
+			name:    "bearer token",
+			method:  http.MethodPost,
+			path:    "/api/transactions",
+			cookies: map[string]string{CookieName: "session", CSRFCookieName: token},
+			header:  map[string]string{"Authorization": "Bearer cmx_token"},
+			status:  http.StatusNoContent,
+		},
+		{
+			name:    "other authorization scheme",
+			method:  http.MethodPost,
+			path:    "/api/transactions",
+			cookies: map[string]string{CookieName: "session", CSRFCookieName: token},
+			header:  map[string]string{"Authorization": "Basic YWxpY2U6c2VjcmV0"},
+			status:  http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if len(tt.form) > 0 {
+				r = httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.form.Encode()))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+
+			for name, value := range tt.cookies {
+				r.AddCookie(&http.Cookie{Name: name, Value: value})
+			}
+
+			for name, value := range tt.header {
+				r.Header.Set(name, value)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+
+			// The token of the cookie is sent back, a new one is set without a cookie
+			want := tt.cookies[CSRFCookieName]
+			if want == "" {
+				cookies := w.Result().Cookies()
+				if len(cookies) != 1 || cookies[0].Name != CSRFCookieName || cookies[0].Value == "" || !cookies[0].HttpOnly {
+					t.Fatalf("cookies = %v, want a new CSRF cookie", cookies)
+				}
+
+				want = cookies[0].Value
+			}
+
+			if got := w.Header().Get(CSRFHeader); got != want {
+				t.Errorf("%s = %q, want %q", CSRFHeader, got, want)
+			}
+
+			if tt.status == http.StatusForbidden && strings.HasPrefix(tt.path, "/api/") &&
+				!strings.HasPrefix(w.Header().Get("Content-Type"), "application/problem+json") {
+				t.Errorf("Content-Type = %q, want a problem", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
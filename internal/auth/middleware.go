@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// LoginPath is the page users are sent to when they are not logged in
+const LoginPath = "/login"
+
+// publicPaths are served without logging in. Paths ending in a slash match every path under them.
+var publicPaths = []string{
+	"/static/",
+	LoginPath,
+	"/register",
+	"/logout",
+	"/api/auth/login",
+	"/api/auth/register",
+	"/api/auth/logout",
+	"/api/auth/passkeys/login/begin",
+	"/api/auth/passkeys/login/finish",
+}
+
+// userKey is the context key of the logged-in user
+type userKey struct{}
+
+// WithUser returns a copy of the context carrying the logged-in user
+func WithUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// User returns the logged-in user of a request context, nil when no one is logged in
+func User(ctx context.Context) *models.User {
+	user, _ := ctx.Value(userKey{}).(*models.User)
+
+	return user
+}
+
+// UserID returns the ID of the logged-in user of a request context, nil when no one is logged
+// in. Queries scoped to a user match nothing for nil.
+func UserID(ctx context.Context) *int64 {
+	if user := User(ctx); user != nil {
+		return &user.ID
+	}
+
+	return nil
+}
+
+// Middleware makes the user logged in with the session cookie, or authenticated with the personal
+// access token of the Authorization header, available to the handlers through User and UserID.
+// Requests for anything but the public paths require a login: pages redirect to the login page,
+// and API requests are refused with 401 Unauthorized, which htmx is told to handle by loading the
+// login page. Tokens are only accepted for the API routes their scopes grant.
+func (a *Authenticator) Middleware(jw *response.JSONWriter) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			if bearer, ok := bearerToken(r); ok {
+				a.tokenMiddleware(jw, next, w, r, bearer)
+				return
+			}
+
+			user, err := a.SessionUser(ctx, r)
+			if err != nil {
+				a.log.ErrorContext(ctx, "failed to get session user", logger.Error(err))
+				jw.WriteError(ctx, r, w, err)
+				return
+			}
+
+			if user != nil {
+				next.ServeHTTP(w, r.WithContext(WithUser(ctx, user)))
+				return
+			}
+
+			if public(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			login := LoginPath + "?next=" + url.QueryEscape(r.URL.RequestURI())
+
+			if !strings.HasPrefix(r.URL.Path, "/api/") {
+				http.Redirect(w, r, login, http.StatusSeeOther)
+				return
+			}
+
+			if r.Header.Get("HX-Request") == "true" {
+				w.Header().Set("HX-Redirect", LoginPath)
+			}
+
+			jw.WriteProblem(ctx, r, w, response.NewProblem().
+				WithStatus(http.StatusUnauthorized).
+				WithDetail("log in to continue").
+				Build())
+		})
+	}
+}
+
+// tokenMiddleware serves a request authenticated with a personal access token. Unknown or expired
+// tokens are refused with 401 Unauthorized, and tokens without the scope of the route with 403
+// Forbidden.
+func (a *Authenticator) tokenMiddleware(
+	jw *response.JSONWriter,
+	next http.Handler,
+	w http.ResponseWriter,
+	r *http.Request,
+	bearer string,
+) {
+	ctx := r.Context()
+
+	user, token, err := a.TokenUser(ctx, bearer)
+	if err != nil {
+		a.log.ErrorContext(ctx, "failed to get access token user", logger.Error(err))
+		jw.WriteError(ctx, r, w, err)
+		return
+	}
+
+	if user == nil {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		jw.WriteProblem(ctx, r, w, response.NewProblem().
+			WithStatus(http.StatusUnauthorized).
+			WithDetail("invalid or expired access token").
+			Build())
+		return
+	}
+
+	if !allowsRoute(token, r) {
+		w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope"`)
+		jw.WriteProblem(ctx, r, w, response.NewProblem().
+			WithStatus(http.StatusForbidden).
+			WithDetail("the access token does not grant this request").
+			Build())
+		return
+	}
+
+	ctx = context.WithValue(WithUser(ctx, user), tokenKey{}, token)
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// public reports whether the path is served without logging in
+func public(path string) bool {
+	for _, p := range publicPaths {
+		if path == p || strings.HasSuffix(p, "/") && strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,418 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CeremonyCookieName is the name of the cookie carrying the token of a passkey registration or
+// login in progress
+const CeremonyCookieName = "cardmax_webauthn"
+
+// ceremonyTTL is how long a passkey registration or login can take, as long as browsers wait for
+// the authenticator by default
+const ceremonyTTL = 5 * time.Minute
+
+// Ceremonies, the kinds of passkey requests
+const (
+	ceremonyRegistration = "registration"
+	ceremonyLogin        = "login"
+)
+
+var (
+	// ErrCeremonyExpired is returned when finishing a passkey registration or login that was not
+	// begun by the browser, has expired or was already finished
+	ErrCeremonyExpired = errors.New("the passkey request expired, try again")
+	// ErrPasskeyRejected is returned when the response of the authenticator does not verify
+	ErrPasskeyRejected = errors.New("passkey rejected")
+)
+
+// passkeyUser is a user along with their passkeys, as WebAuthn ceremonies see them
+type passkeyUser struct {
+	user        *models.User
+	passkeys    []*models.Passkey
+	credentials []webauthn.Credential
+}
+
+// WebAuthnID is the user handle, the ID of the user as 8 big-endian bytes
+func (u *passkeyUser) WebAuthnID() []byte {
+	return userHandle(u.user.ID)
+}
+
+func (u *passkeyUser) WebAuthnName() string {
+	return u.user.Username
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	return u.user.Username
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+// newWebAuthn creates the WebAuthn relying party passkeys are registered with and verified by
+func newWebAuthn(cfg config.Passkey) (*webauthn.WebAuthn, error) {
+	var origins []string
+	for _, origin := range strings.Split(cfg.Origins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.RPID,
+		RPDisplayName: "CardMax",
+		RPOrigins:     origins,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create WebAuthn relying party: %w", err)
+	}
+
+	return w, nil
+}
+
+// BeginPasskeyRegistration starts registering a passkey of the user, setting the ceremony cookie.
+// The options are passed to navigator.credentials.create(), and exclude the authenticators the
+// user already has a passkey on.
+func (a *Authenticator) BeginPasskeyRegistration(
+	ctx context.Context,
+	w http.ResponseWriter,
+	user *models.User,
+) (*protocol.CredentialCreation, error) {
+	pu, err := a.passkeyUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	creation, session, err := a.webauthn.BeginRegistration(pu,
+		webauthn.WithExclusions(webauthn.Credentials(pu.credentials).CredentialDescriptors()),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired))
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin passkey registration: %w", err)
+	}
+
+	err = a.startCeremony(ctx, w, &user.ID, ceremonyRegistration, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return creation, nil
+}
+
+// FinishPasskeyRegistration verifies the credential created by the authenticator, the JSON
+// encoded PublicKeyCredential read from body, and stores it as a passkey of the user
+func (a *Authenticator) FinishPasskeyRegistration(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	user *models.User,
+	name string,
+	body io.Reader,
+) (*models.Passkey, error) {
+	ceremony, session, err := a.finishCeremony(ctx, w, r, ceremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+
+	if ceremony.UserID == nil || *ceremony.UserID != user.ID {
+		return nil, ErrCeremonyExpired
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(body)
+	if err != nil {
+		return nil, rejected(err)
+	}
+
+	pu, err := a.passkeyUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := a.webauthn.CreateCredential(pu, *session, parsed)
+	if err != nil {
+		return nil, rejected(err)
+	}
+
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode credential: %w", err)
+	}
+
+	passkey, err := a.db.Queries.CreatePasskey(ctx, models.CreatePasskeyParams{
+		UserID:       user.ID,
+		Name:         strings.TrimSpace(name),
+		CredentialID: credential.ID,
+		Credential:   string(data),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create passkey: %w", err)
+	}
+
+	return passkey, nil
+}
+
+// BeginPasskeyLogin starts logging in with a passkey, setting the ceremony cookie. The options are
+// passed to navigator.credentials.get(), which lets the user pick any passkey of this site, so
+// that no username is needed.
+func (a *Authenticator) BeginPasskeyLogin(ctx context.Context, w http.ResponseWriter) (*protocol.CredentialAssertion, error) {
+	assertion, session, err := a.webauthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationPreferred))
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin passkey login: %w", err)
+	}
+
+	err = a.startCeremony(ctx, w, nil, ceremonyLogin, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return assertion, nil
+}
+
+// FinishPasskeyLogin verifies the assertion of the authenticator, the JSON encoded
+// PublicKeyCredential read from body, against the passkey it was made with and starts a session
+// for its user. Assertions whose signature counter went backwards are rejected, as the passkey
+// may have been cloned.
+func (a *Authenticator) FinishPasskeyLogin(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	body io.Reader,
+) (*models.User, error) {
+	_, session, err := a.finishCeremony(ctx, w, r, ceremonyLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(body)
+	if err != nil {
+		return nil, rejected(err)
+	}
+
+	var pu *passkeyUser
+
+	discover := func(rawID, handle []byte) (webauthn.User, error) {
+		if len(handle) != 8 {
+			return nil, fmt.Errorf("invalid user handle")
+		}
+
+		user, err := a.db.Queries.GetUserByID(ctx, int64(binary.BigEndian.Uint64(handle)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+
+		pu, err = a.passkeyUser(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+
+		return pu, nil
+	}
+
+	_, credential, err := a.webauthn.ValidatePasskeyLogin(discover, *session, parsed)
+	if err != nil {
+		return nil, rejected(err)
+	}
+
+	if credential.Authenticator.CloneWarning {
+		a.log.WarnContext(ctx, "rejected passkey with a signature counter that went backwards",
+			slog.Int64("user_id", pu.user.ID))
+
+		return nil, fmt.Errorf("%w: the passkey may have been cloned", ErrPasskeyRejected)
+	}
+
+	err = a.recordPasskeyUse(ctx, pu, credential)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.StartSession(ctx, w, pu.user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pu.user, nil
+}
+
+// recordPasskeyUse stores the credential as updated by a login along with when it was used
+func (a *Authenticator) recordPasskeyUse(ctx context.Context, pu *passkeyUser, credential *webauthn.Credential) error {
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return fmt.Errorf("failed to encode credential: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	for _, passkey := range pu.passkeys {
+		if string(passkey.CredentialID) != string(credential.ID) {
+			continue
+		}
+
+		err = a.db.Queries.UpdatePasskeyUse(ctx, models.UpdatePasskeyUseParams{
+			Credential: string(data),
+			Now:        &now,
+			ID:         passkey.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update passkey %d: %w", passkey.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// passkeyUser loads the passkeys of the user
+func (a *Authenticator) passkeyUser(ctx context.Context, user *models.User) (*passkeyUser, error) {
+	passkeys, err := a.db.Queries.GetUserPasskeys(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passkeys: %w", err)
+	}
+
+	pu := &passkeyUser{
+		user:        user,
+		passkeys:    passkeys,
+		credentials: make([]webauthn.Credential, 0, len(passkeys)),
+	}
+
+	for _, passkey := range passkeys {
+		var credential webauthn.Credential
+
+		err = json.Unmarshal([]byte(passkey.Credential), &credential)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode passkey %d: %w", passkey.ID, err)
+		}
+
+		pu.credentials = append(pu.credentials, credential)
+	}
+
+	return pu, nil
+}
+
+// startCeremony stores the session data of a ceremony by the hash of a token set in the ceremony
+// cookie. Expired ceremonies of every user are removed on the way.
+func (a *Authenticator) startCeremony(
+	ctx context.Context,
+	w http.ResponseWriter,
+	userID *int64,
+	kind string,
+	session *webauthn.SessionData,
+) error {
+	now := time.Now().UTC()
+
+	_, err := a.db.Queries.DeleteExpiredWebauthnCeremonies(ctx, now)
+	if err != nil {
+		a.log.ErrorContext(ctx, "failed to delete expired passkey ceremonies", logger.Error(err))
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode passkey session: %w", err)
+	}
+
+	b := make([]byte, 32)
+
+	_, err = rand.Read(b)
+	if err != nil {
+		return fmt.Errorf("failed to generate ceremony token: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+	expires := now.Add(ceremonyTTL)
+
+	err = a.db.Queries.CreateWebauthnCeremony(ctx, models.CreateWebauthnCeremonyParams{
+		ID:        sessionID(token),
+		UserID:    userID,
+		Kind:      kind,
+		Session:   string(data),
+		ExpiresAt: expires,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create passkey ceremony: %w", err)
+	}
+
+	http.SetCookie(w, a.ceremonyCookie(token, expires))
+
+	return nil
+}
+
+// finishCeremony removes the ceremony of the request's ceremony cookie, clearing the cookie, and
+// returns it along with its session data
+func (a *Authenticator) finishCeremony(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	kind string,
+) (*models.WebauthnCeremony, *webauthn.SessionData, error) {
+	token := cookieValue(r, CeremonyCookieName)
+	if token == "" {
+		return nil, nil, ErrCeremonyExpired
+	}
+
+	http.SetCookie(w, a.ceremonyCookie("", time.Unix(0, 0)))
+
+	ceremony, err := a.db.Queries.TakeWebauthnCeremony(ctx, models.TakeWebauthnCeremonyParams{
+		ID:   sessionID(token),
+		Kind: kind,
+		Now:  time.Now().UTC(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrCeremonyExpired
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get passkey ceremony: %w", err)
+	}
+
+	var session webauthn.SessionData
+
+	err = json.Unmarshal([]byte(ceremony.Session), &session)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode passkey session: %w", err)
+	}
+
+	return ceremony, &session, nil
+}
+
+func (a *Authenticator) ceremonyCookie(token string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     CeremonyCookieName,
+		Value:    token,
+		Path:     "/api/auth/passkeys",
+		Expires:  expires,
+		Secure:   a.cfg.Session.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	}
+}
+
+// rejected wraps the error of a response that does not verify, with the details WebAuthn gives
+func rejected(err error) error {
+	var perr *protocol.Error
+	if errors.As(err, &perr) && perr.Details != "" {
+		return fmt.Errorf("%w: %s", ErrPasskeyRejected, perr.Details)
+	}
+
+	return fmt.Errorf("%w: %w", ErrPasskeyRejected, err)
+}
+
+// userHandle is the WebAuthn user handle of the user with the ID
+func userHandle(id int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(id))
+}
@@ -0,0 +1,334 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "http://localhost:9000"
+)
+
+// softAuthenticator is a software passkey authenticator holding a single P-256 credential
+type softAuthenticator struct {
+	key     *ecdsa.PrivateKey
+	id      []byte
+	counter uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return &softAuthenticator{key: key, id: id}
+}
+
+// authData is the authenticator data for the relying party, with the credential attested when
+// attested is set
+func (s *softAuthenticator) authData(t *testing.T, attested bool) []byte {
+	t.Helper()
+
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+
+	// User present and user verified
+	flags := byte(0x05)
+	if attested {
+		flags |= 0x40
+	}
+
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, s.counter)
+
+	if !attested {
+		return data
+	}
+
+	pub, err := s.key.PublicKey.ECDH()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Uncompressed point: 0x04 || X || Y
+	point := pub.Bytes()
+
+	coseKey, err := cbor.Marshal(map[int]any{
+		1:  2,  // kty: EC2
+		3:  -7, // alg: ES256
+		-1: 1,  // crv: P-256
+		-2: point[1:33],
+		-3: point[33:],
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data = append(data, make([]byte, 16)...) // AAGUID
+	data = binary.BigEndian.AppendUint16(data, uint16(len(s.id)))
+	data = append(data, s.id...)
+
+	return append(data, coseKey...)
+}
+
+// create answers navigator.credentials.create() with the options
+func (s *softAuthenticator) create(t *testing.T, creation *protocol.CredentialCreation) []byte {
+	t.Helper()
+
+	clientData := clientDataJSON(t, "webauthn.create", creation.Response.Challenge)
+
+	attestation, err := cbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": s.authData(t, true),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return credentialJSON(t, s.id, map[string]string{
+		"clientDataJSON":    b64url(clientData),
+		"attestationObject": b64url(attestation),
+	})
+}
+
+// get answers navigator.credentials.get() with the options, signed as the user
+func (s *softAuthenticator) get(t *testing.T, assertion *protocol.CredentialAssertion, userID int64) []byte {
+	t.Helper()
+
+	clientData := clientDataJSON(t, "webauthn.get", assertion.Response.Challenge)
+	authData := s.authData(t, false)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(bytes.Clone(authData), clientDataHash[:]...))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, s.key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return credentialJSON(t, s.id, map[string]string{
+		"clientDataJSON":    b64url(clientData),
+		"authenticatorData": b64url(authData),
+		"signature":         b64url(signature),
+		"userHandle":        b64url(userHandle(userID)),
+	})
+}
+
+func clientDataJSON(t *testing.T, typ string, challenge protocol.URLEncodedBase64) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]string{
+		"type":      typ,
+		"challenge": b64url(challenge),
+		"origin":    testOrigin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func credentialJSON(t *testing.T, id []byte, response map[string]string) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{
+		"id":       b64url(id),
+		"rawId":    b64url(id),
+		"type":     "public-key",
+		"response": response,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func b64url(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ceremonyRequest is the request finishing the ceremony begun with the recorded response
+func ceremonyRequest(begun *httptest.ResponseRecorder) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/auth/passkeys", nil)
+	for _, c := range begun.Result().Cookies() {
+		r.AddCookie(c)
+	}
+
+	return r
+}
+
+func TestPasskeyCeremony(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	dbConn, err := db.New(ctx, log, &db.Config{Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := NewAuthenticator(log, dbConn, config.Auth{
+		Session: config.Session{TTL: time.Hour},
+		Passkey: config.Passkey{RPID: testRPID, Origins: testOrigin},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := a.Register(ctx, "alice", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sa := newSoftAuthenticator(t)
+
+	// login begins a passkey login and answers it with the authenticator at the counter
+	login := func(counter uint32) (*httptest.ResponseRecorder, []byte) {
+		t.Helper()
+
+		sa.counter = counter
+
+		begun := httptest.NewRecorder()
+
+		assertion, err := a.BeginPasskeyLogin(ctx, begun)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return begun, sa.get(t, assertion, user.ID)
+	}
+
+	t.Run("register", func(t *testing.T) {
+		sa.counter = 1
+
+		begun := httptest.NewRecorder()
+
+		creation, err := a.BeginPasskeyRegistration(ctx, begun, user)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		passkey, err := a.FinishPasskeyRegistration(ctx, httptest.NewRecorder(), ceremonyRequest(begun),
+			user, " laptop ", bytes.NewReader(sa.create(t, creation)))
+		if err != nil {
+			t.Fatalf("registration failed: %v", err)
+		}
+
+		if passkey.Name != "laptop" || !bytes.Equal(passkey.CredentialID, sa.id) {
+			t.Errorf("passkey = %q %x, want laptop %x", passkey.Name, passkey.CredentialID, sa.id)
+		}
+	})
+
+	t.Run("login", func(t *testing.T) {
+		begun, body := login(2)
+
+		w := httptest.NewRecorder()
+
+		got, err := a.FinishPasskeyLogin(ctx, w, ceremonyRequest(begun), bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("login failed: %v", err)
+		}
+
+		if got.ID != user.ID {
+			t.Errorf("logged in user %d, want %d", got.ID, user.ID)
+		}
+
+		var session bool
+		for _, c := range w.Result().Cookies() {
+			session = session || (c.Name == CookieName && c.Value != "")
+		}
+
+		if !session {
+			t.Error("login did not set the session cookie")
+		}
+	})
+
+	t.Run("sign count is stored", func(t *testing.T) {
+		passkeys, err := dbConn.Queries.GetUserPasskeys(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(passkeys) != 1 {
+			t.Fatalf("got %d passkeys, want 1", len(passkeys))
+		}
+
+		var credential webauthn.Credential
+
+		err = json.Unmarshal([]byte(passkeys[0].Credential), &credential)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if credential.Authenticator.SignCount != 2 {
+			t.Errorf("sign count = %d, want 2", credential.Authenticator.SignCount)
+		}
+
+		if passkeys[0].LastUsedAt == nil {
+			t.Error("last use of the passkey not recorded")
+		}
+	})
+
+	t.Run("replayed response is rejected", func(t *testing.T) {
+		_, body := login(3)
+		begun, _ := login(3)
+
+		_, err := a.FinishPasskeyLogin(ctx, httptest.NewRecorder(), ceremonyRequest(begun), bytes.NewReader(body))
+		if !errors.Is(err, ErrPasskeyRejected) {
+			t.Errorf("err = %v, want %v", err, ErrPasskeyRejected)
+		}
+	})
+
+	t.Run("finished ceremony cannot be reused", func(t *testing.T) {
+		begun, body := login(3)
+
+		_, err := a.FinishPasskeyLogin(ctx, httptest.NewRecorder(), ceremonyRequest(begun), bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("login failed: %v", err)
+		}
+
+		_, err = a.FinishPasskeyLogin(ctx, httptest.NewRecorder(), ceremonyRequest(begun), bytes.NewReader(body))
+		if !errors.Is(err, ErrCeremonyExpired) {
+			t.Errorf("err = %v, want %v", err, ErrCeremonyExpired)
+		}
+	})
+
+	for _, counter := range []uint32{3, 1} {
+		t.Run("counter not advanced is rejected", func(t *testing.T) {
+			begun, body := login(counter)
+
+			_, err := a.FinishPasskeyLogin(ctx, httptest.NewRecorder(), ceremonyRequest(begun), bytes.NewReader(body))
+			if !errors.Is(err, ErrPasskeyRejected) || !strings.Contains(err.Error(), "cloned") {
+				t.Errorf("counter %d: err = %v, want the passkey rejected as cloned", counter, err)
+			}
+		})
+	}
+}
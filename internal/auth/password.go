@@ -0,0 +1,84 @@
+// Package auth authenticates users with their password or a passkey, keeps them logged in with
+// sessions stored in the database and protects their sessions against cross-site request forgery
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"strings"
+)
+
+// Argon2id parameters, the second recommended option of RFC 9106 scaled down to 64 MiB of memory
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 2
+	argonKeyLen  = 32
+	argonSaltLen = 16
+)
+
+// ErrInvalidHash is returned when a stored password hash is not an argon2id PHC string
+var ErrInvalidHash = errors.New("invalid password hash")
+
+// b64 encodes the salt and key of PHC strings, which are base64 without padding
+var b64 = base64.RawStdEncoding
+
+// HashPassword hashes the password with argon2id and a random salt, returned as a PHC string,
+// e.g. "$argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>"
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argonSaltLen)
+
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, argonMemory, argonTime, argonThreads, b64.EncodeToString(salt), b64.EncodeToString(key)), nil
+}
+
+// VerifyPassword reports whether the password matches the PHC string, with the parameters the
+// hash was made with so that hashes made before they changed still verify
+func VerifyPassword(password, hash string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, ErrInvalidHash
+	}
+
+	var version int
+
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return false, fmt.Errorf("%w: unsupported version %q", ErrInvalidHash, parts[2])
+	}
+
+	var (
+		memory, time uint32
+		threads      uint8
+	)
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidHash, err)
+	}
+
+	salt, err := b64.DecodeString(parts[4])
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidHash, err)
+	}
+
+	key, err := b64.DecodeString(parts[5])
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrInvalidHash, err)
+	}
+
+	other := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
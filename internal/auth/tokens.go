@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TokenPrefix starts every personal access token, so that leaked tokens are easy to spot
+const TokenPrefix = "cmx_"
+
+// Scopes of personal access tokens
+const (
+	// ScopeReadCards reads the predefined cards, the user's cards and their utilisation
+	ScopeReadCards = "read:cards"
+	// ScopeWriteTransactions records transactions
+	ScopeWriteTransactions = "write:transactions"
+	// ScopeRecommend asks for card recommendations
+	ScopeRecommend = "recommend"
+)
+
+// tokenRoutes are the scopes the API routes available to tokens require, keyed by method and path
+// template. Every other route, e.g. managing tokens, is only available to logged-in users.
+var tokenRoutes = map[string]string{
+	"GET /api/cards":            ScopeReadCards,
+	"GET /api/cards/{key}":      ScopeReadCards,
+	"GET /api/user-cards":       ScopeReadCards,
+	"GET /api/user-cards/{id}":  ScopeReadCards,
+	"GET /api/utilisation":      ScopeReadCards,
+	"POST /api/transactions":    ScopeWriteTransactions,
+	"POST /api/recommend":       ScopeRecommend,
+	"POST /api/recommend/batch": ScopeRecommend,
+}
+
+// tokenKey is the context key of the access token a request is authenticated with
+type tokenKey struct{}
+
+// AccessToken returns the access token a request context is authenticated with, nil when it is
+// authenticated with a session
+func AccessToken(ctx context.Context) *models.AccessToken {
+	token, _ := ctx.Value(tokenKey{}).(*models.AccessToken)
+
+	return token
+}
+
+// CreateToken creates a personal access token of the user granting the scopes until it expires.
+// The token is returned along with its record, which only holds its hash.
+func (a *Authenticator) CreateToken(
+	ctx context.Context,
+	userID int64,
+	name string,
+	scopes []string,
+	expiresAt time.Time,
+) (*models.AccessToken, string, error) {
+	b := make([]byte, 32)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	token := TokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	record, err := a.db.Queries.CreateAccessToken(ctx, models.CreateAccessTokenParams{
+		UserID:    userID,
+		Name:      strings.TrimSpace(name),
+		TokenHash: sessionID(token),
+		Hint:      token[len(token)-4:],
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: expiresAt.UTC(),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create access token: %w", err)
+	}
+
+	return record, token, nil
+}
+
+// TokenUser returns the user of a personal access token along with its record, recording that it
+// was used, and nil when the token is unknown or expired
+func (a *Authenticator) TokenUser(ctx context.Context, token string) (*models.User, *models.AccessToken, error) {
+	now := time.Now().UTC()
+
+	row, err := a.db.Queries.GetAccessTokenUser(ctx, models.GetAccessTokenUserParams{
+		TokenHash: sessionID(token),
+		Now:       now,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, nil
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	err = a.db.Queries.TouchAccessToken(ctx, models.TouchAccessTokenParams{
+		Now: &now,
+		ID:  row.AccessToken.ID,
+	})
+	if err != nil {
+		a.log.ErrorContext(ctx, "failed to record access token use", logger.Error(err))
+	}
+
+	row.AccessToken.LastUsedAt = &now
+
+	return &row.User, &row.AccessToken, nil
+}
+
+// TokenScopes returns the scopes a token grants
+func TokenScopes(token *models.AccessToken) []string {
+	return strings.Fields(token.Scopes)
+}
+
+// routeScope returns the scope the route of the request requires of tokens, empty when it is not
+// available to tokens
+func routeScope(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+
+	path, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+
+	return tokenRoutes[r.Method+" "+path]
+}
+
+// allowsRoute reports whether the token grants the scope the route of the request requires
+func allowsRoute(token *models.AccessToken, r *http.Request) bool {
+	scope := routeScope(r)
+
+	return scope != "" && slices.Contains(TokenScopes(token), scope)
+}
+
+// bearerToken returns the token of the Authorization header of the request
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
@@ -0,0 +1,51 @@
+package billing
+
+import "github.com/pushkar-anand/cardmax/internal/money"
+
+// Balance is the credit position of a card
+type Balance struct {
+	// Limit is the card's credit limit, zero when it is not known
+	Limit money.Money `json:"credit_limit"`
+	// Outstanding is the logged spend minus the recorded payments
+	Outstanding money.Money `json:"outstanding"`
+}
+
+// NewBalance returns the balance of a card from its limit and the totals of its spends and payments
+func NewBalance(limit, spent, paid money.Money) Balance {
+	return Balance{Limit: limit, Outstanding: spent.Sub(paid)}
+}
+
+// Available returns the credit left to spend, zero when the limit is not known
+func (b Balance) Available() money.Money {
+	if !b.Limit.IsPositive() {
+		return money.Money{}
+	}
+
+	return money.Max(b.Limit.Sub(b.Outstanding), money.Money{})
+}
+
+// Utilisation returns the outstanding balance after a further spend as a percentage of the
+// credit limit, and false when the limit is not known
+func (b Balance) Utilisation(spend money.Money) (float64, bool) {
+	if !b.Limit.IsPositive() {
+		return 0, false
+	}
+
+	return b.Outstanding.Add(spend).Float() * 100 / b.Limit.Float(), true
+}
+
+// minimumDuePercent is the share of the total due that must be paid by the due date
+const minimumDuePercent = 5
+
+// minimumDueFloor is the least minimum due of a statement, unless the total due is smaller
+var minimumDueFloor = money.New(200_00, money.INR)
+
+// MinimumDue returns the minimum amount due on a statement: 5% of the total due, at least ₹200,
+// and never more than the total due
+func MinimumDue(total money.Money) money.Money {
+	if !total.IsPositive() {
+		return money.Money{}
+	}
+
+	return money.Min(total, money.Max(total.Percent(minimumDuePercent, money.Ceil), minimumDueFloor))
+}
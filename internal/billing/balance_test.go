@@ -0,0 +1,33 @@
+package billing
+
+import (
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"testing"
+)
+
+func TestMinimumDue(t *testing.T) {
+	tests := []struct {
+		total string
+		want  string
+	}{
+		{total: "0", want: "0"},
+		{total: "-500", want: "0"},
+		{total: "150", want: "150"},
+		{total: "200", want: "200"},
+		{total: "3000", want: "200"},
+		{total: "4000", want: "200"},
+		{total: "4000.20", want: "200.01"},
+		{total: "4500", want: "225"},
+		{total: "12345.67", want: "617.29"},
+		{total: "100000", want: "5000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.total, func(t *testing.T) {
+			got := MinimumDue(money.MustParse(tt.total, money.INR))
+			if want := money.MustParse(tt.want, money.INR); got.Cmp(want) != 0 {
+				t.Errorf("MinimumDue(%s) = %v, want %v", tt.total, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package billing
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type (
+	// Schedule is when a card's statements are generated and paid
+	Schedule struct {
+		// StatementDay is the day of the month the statement is generated on (1-31).
+		// Months shorter than the day generate the statement on their last day.
+		StatementDay int
+		// PaymentDueDays is the number of days between the statement date and the due date
+		PaymentDueDays int
+	}
+
+	// Cycle is a billing cycle of a card. Spends from Start to StatementDate (both inclusive)
+	// are billed on the statement generated on StatementDate and due on DueDate.
+	Cycle struct {
+		Start         time.Time
+		StatementDate time.Time
+		DueDate       time.Time
+	}
+)
+
+// Date returns the calendar date of t, dropping the time of day
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// DaysBetween returns the number of calendar days from a to b
+func DaysBetween(a, b time.Time) int {
+	return int(Date(b).Sub(Date(a)).Hours() / 24)
+}
+
+// CycleFor returns the billing cycle a spend made on the given date falls in
+func (s Schedule) CycleFor(date time.Time) Cycle {
+	date = Date(date)
+
+	statement := s.statementDate(date.Year(), date.Month())
+	if date.After(statement) {
+		statement = s.statementDate(date.Year(), date.Month()+1)
+	}
+
+	previous := s.statementDate(statement.Year(), statement.Month()-1)
+
+	return Cycle{
+		Start:         previous.AddDate(0, 0, 1),
+		StatementDate: statement,
+		DueDate:       statement.AddDate(0, 0, s.PaymentDueDays),
+	}
+}
+
+// InterestFreeDays returns the number of days between a spend on the given date and its due date
+func (s Schedule) InterestFreeDays(date time.Time) int {
+	return DaysBetween(date, s.CycleFor(date).DueDate)
+}
+
+// NextDue returns the earliest cycle that is not yet due on the given date. That is the previous
+// cycle while its statement is awaiting payment, otherwise the current cycle.
+func (s Schedule) NextDue(now time.Time) Cycle {
+	current := s.CycleFor(now)
+
+	previous := s.CycleFor(current.Start.AddDate(0, 0, -1))
+	if !Date(now).After(previous.DueDate) {
+		return previous
+	}
+
+	return current
+}
+
+// statementDate returns the statement date in the given month, clamped to the month's last day
+func (s Schedule) statementDate(year int, month time.Month) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+
+	return first.AddDate(0, 0, min(s.StatementDay, last)-1)
+}
+
+// Contains reports whether a spend on the given date falls in the cycle
+func (c Cycle) Contains(date time.Time) bool {
+	date = Date(date)
+
+	return !date.Before(c.Start) && !date.After(c.StatementDate)
+}
+
+// DaysUntilDue returns the number of days from the given date until the cycle's due date,
+// negative once the due date has passed
+func (c Cycle) DaysUntilDue(now time.Time) int {
+	return DaysBetween(now, c.DueDate)
+}
+
+// MarshalJSON encodes the cycle's dates as YYYY-MM-DD
+func (c Cycle) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{
+		"start":          c.Start.Format(time.DateOnly),
+		"statement_date": c.StatementDate.Format(time.DateOnly),
+		"due_date":       c.DueDate.Format(time.DateOnly),
+	})
+}
@@ -0,0 +1,87 @@
+package billing
+
+import (
+	"testing"
+	"time"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+func TestCycleFor(t *testing.T) {
+	tests := []struct {
+		name          string
+		statementDay  int
+		date          string
+		start         string
+		statementDate string
+	}{
+		{name: "31st in February", statementDay: 31, date: "2025-02-10", start: "2025-02-01", statementDate: "2025-02-28"},
+		{name: "31st in a leap February", statementDay: 31, date: "2024-02-10", start: "2024-02-01", statementDate: "2024-02-29"},
+		{name: "31st after February", statementDay: 31, date: "2025-03-01", start: "2025-03-01", statementDate: "2025-03-31"},
+		{name: "31st in a 30 day month", statementDay: 31, date: "2025-04-30", start: "2025-04-01", statementDate: "2025-04-30"},
+		{name: "31st after a 30 day month", statementDay: 31, date: "2025-05-01", start: "2025-05-01", statementDate: "2025-05-31"},
+		{name: "31st across the year", statementDay: 31, date: "2026-01-01", start: "2026-01-01", statementDate: "2026-01-31"},
+		{name: "30th on the last day of February", statementDay: 30, date: "2025-02-28", start: "2025-01-31", statementDate: "2025-02-28"},
+		{name: "30th after February", statementDay: 30, date: "2025-03-01", start: "2025-03-01", statementDate: "2025-03-30"},
+		{name: "30th in March", statementDay: 30, date: "2025-03-31", start: "2025-03-31", statementDate: "2025-04-30"},
+		{name: "29th in February", statementDay: 29, date: "2025-02-28", start: "2025-01-30", statementDate: "2025-02-28"},
+		{name: "29th in a leap February", statementDay: 29, date: "2024-02-29", start: "2024-01-30", statementDate: "2024-02-29"},
+		{name: "29th after February", statementDay: 29, date: "2025-03-01", start: "2025-03-01", statementDate: "2025-03-29"},
+		{name: "28th in February", statementDay: 28, date: "2025-02-28", start: "2025-01-29", statementDate: "2025-02-28"},
+		{name: "28th after February", statementDay: 28, date: "2025-03-01", start: "2025-03-01", statementDate: "2025-03-28"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Schedule{StatementDay: tt.statementDay, PaymentDueDays: 20}
+
+			c := s.CycleFor(day(tt.date))
+
+			if got := c.Start.Format(time.DateOnly); got != tt.start {
+				t.Errorf("start = %s, want %s", got, tt.start)
+			}
+
+			if got := c.StatementDate.Format(time.DateOnly); got != tt.statementDate {
+				t.Errorf("statement date = %s, want %s", got, tt.statementDate)
+			}
+
+			if want := day(tt.statementDate).AddDate(0, 0, 20); !c.DueDate.Equal(want) {
+				t.Errorf("due date = %s, want %s", c.DueDate.Format(time.DateOnly), want.Format(time.DateOnly))
+			}
+
+			if !c.Contains(day(tt.date)) {
+				t.Errorf("cycle %s to %s does not contain %s", tt.start, tt.statementDate, tt.date)
+			}
+		})
+	}
+}
+
+// TestCycleForContiguous checks that consecutive cycles leave no day out and bill none twice
+func TestCycleForContiguous(t *testing.T) {
+	for statementDay := 28; statementDay <= 31; statementDay++ {
+		s := Schedule{StatementDay: statementDay, PaymentDueDays: 20}
+
+		prev := s.CycleFor(day("2023-12-31"))
+
+		for d := day("2024-01-01"); d.Year() < 2026; d = d.AddDate(0, 0, 1) {
+			c := s.CycleFor(d)
+			if c == prev {
+				continue
+			}
+
+			if !c.Start.Equal(prev.StatementDate.AddDate(0, 0, 1)) {
+				t.Fatalf("day %d: cycle starting %s follows statement of %s", statementDay,
+					c.Start.Format(time.DateOnly), prev.StatementDate.Format(time.DateOnly))
+			}
+
+			prev = c
+		}
+	}
+}
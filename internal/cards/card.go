@@ -4,33 +4,106 @@ import (
 	"embed"
 	"encoding/json"
 	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"strings"
+)
+
+// AllCurrencies is the entity name of an International reward rule that applies to every foreign currency
+const AllCurrencies = "all"
+
+// Stacking modes of a reward rule
+const (
+	// StackingReplace rules replace the card's default rate
+	StackingReplace = "replace"
+	// StackingAdditive rules add their rate on top of the base rate
+	StackingAdditive = "additive"
+	// StackingMultiplier rules multiply the combined rate by their reward rate
+	StackingMultiplier = "multiplier"
+)
+
+// Payment channels a purchase can be made through
+const (
+	ChannelUPI         = "upi"
+	ChannelOnline      = "online"
+	ChannelOffline     = "offline"
+	ChannelContactless = "contactless"
+	ChannelWallet      = "wallet"
 )
 
 type (
-	// Reward represents rewards on a card
+	// Reward represents rewards on a card.
+	// Type is one of Merchant, Category, International or Channel. International rules apply to
+	// international spends only, EntityName is either a currency code or AllCurrencies.
+	// Channel rules apply to every spend made through the payment channel named by EntityName.
 	Reward struct {
 		Type       string  `json:"type"`
 		EntityName string  `json:"entity_name"`
 		RewardRate float64 `json:"reward_rate"`
 		RewardType string  `json:"reward_type"`
+		// SpendCap is the maximum spend per statement cycle that earns this rule's rate.
+		// Spend beyond the cap earns the card's default rate. Zero means uncapped.
+		SpendCap money.Money `json:"spend_cap,omitzero"`
+		// Channel restricts the rule to spends made through a payment channel. Empty matches any channel.
+		Channel string `json:"channel,omitempty"`
+		// Precedence ranks the rule above others matching the same purchase, higher wins
+		Precedence int `json:"precedence,omitempty"`
+		// Stacking is how the rule combines with the base rate: replace (default), additive or multiplier.
+		// The rate of a multiplier rule is a factor, e.g. 5 for 5X.
+		Stacking string `json:"stacking,omitempty"`
+		// Exclusive rules do not combine with any other stacking rule
+		Exclusive bool `json:"exclusive,omitempty"`
+		// PointsPerBlock is the reward per block of the card's earning, overriding the reward rate
+		PointsPerBlock float64 `json:"points_per_block,omitempty"`
 	}
 
 	// Card represents a credit card in the system
 	Card struct {
-		Key               string   `json:"card_key"`
-		Name              string   `json:"name"`
-		Issuer            string   `json:"issuer"`
-		CardType          string   `json:"card_type"`
-		DefaultRewardRate float64  `json:"default_reward_rate"`
-		RewardType        string   `json:"reward_type"`
-		PointValue        float64  `json:"point_value"`
-		AnnualFee         int      `json:"annual_fee"`
-		AnnualFeeWaiver   string   `json:"annual_fee_waiver"`
-		RewardRules       []Reward `json:"reward_rules"`
-		Benefits          []string `json:"benefits"`
+		Key               string      `json:"card_key"`
+		Name              string      `json:"name"`
+		Issuer            string      `json:"issuer"`
+		CardType          string      `json:"card_type"`
+		DefaultRewardRate float64     `json:"default_reward_rate"`
+		RewardType        string      `json:"reward_type"`
+		PointValue        float64     `json:"point_value"`
+		AnnualFee         money.Money `json:"annual_fee"`
+		AnnualFeeWaiver   string      `json:"annual_fee_waiver"`
+		RewardRules       []Reward    `json:"reward_rules"`
+		Benefits          []string    `json:"benefits"`
+		// ForexMarkup is the markup (in percent) charged on international transactions
+		ForexMarkup float64 `json:"forex_markup"`
+		// Earning describes earning units and rounding, nil earns the exact rate on every rupee
+		Earning *Earning `json:"earning,omitempty"`
 	}
 )
 
+// StackingMode returns the rule's stacking mode, defaulting to StackingReplace
+func (r *Reward) StackingMode() string {
+	if r.Stacking == "" {
+		return StackingReplace
+	}
+
+	return r.Stacking
+}
+
+// AppliesToSpend reports whether a Merchant, Category or Channel rule applies to a domestic spend,
+// such as a logged transaction. Names are compared case-insensitively.
+func (r *Reward) AppliesToSpend(merchant, category, channel string) bool {
+	if r.Channel != "" && !strings.EqualFold(r.Channel, channel) {
+		return false
+	}
+
+	switch r.Type {
+	case "Merchant":
+		return merchant != "" && strings.EqualFold(r.EntityName, merchant)
+	case "Category":
+		return category != "" && strings.EqualFold(r.EntityName, category)
+	case "Channel":
+		return channel != "" && strings.EqualFold(r.EntityName, channel)
+	}
+
+	return false
+}
+
 // Parse parses all predefined card definitions from the data/cards directory
 func Parse(data embed.FS) ([]*Card, error) {
 	entries, err := data.ReadDir("data/cards")
@@ -55,6 +128,19 @@ func Parse(data embed.FS) ([]*Card, error) {
 			return nil, fmt.Errorf("error unmarshalling card data %s: %w", fn, err)
 		}
 
+		for _, rule := range card.RewardRules {
+			switch rule.StackingMode() {
+			case StackingReplace, StackingAdditive, StackingMultiplier:
+			default:
+				return nil, fmt.Errorf("invalid stacking mode %q for rule %s in %s", rule.Stacking, rule.EntityName, fn)
+			}
+		}
+
+		err = card.normaliseEarning()
+		if err != nil {
+			return nil, fmt.Errorf("error in card earning %s: %w", fn, err)
+		}
+
 		cards = append(cards, &card)
 	}
 
@@ -0,0 +1,104 @@
+package cards
+
+import (
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"math"
+)
+
+// Rounding modes of a card's rewards
+const (
+	// RoundingExact credits the exact reward, without any rounding
+	RoundingExact = "exact"
+	// RoundingFloorTransaction earns per whole block of each transaction and truncates the points
+	RoundingFloorTransaction = "floor_transaction"
+	// RoundingFloorStatement earns on the statement total, the remainder is truncated once per cycle
+	RoundingFloorStatement = "floor_statement"
+)
+
+// epsilon absorbs float error when truncating rewards, e.g. 3.9999999999999996 points is 4
+const epsilon = 1e-9
+
+// Earning describes how a card turns spend into rewards, e.g. 4 points per ₹150
+type Earning struct {
+	// BlockSize is the spend that earns one block of rewards. Zero earns on every rupee.
+	BlockSize money.Money `json:"block_size,omitzero"`
+	// PointsPerBlock is the reward per block at the default rate. When set, it overrides the
+	// card's default reward rate, which is only an approximation of block based earning.
+	PointsPerBlock float64 `json:"points_per_block,omitempty"`
+	// Rounding is one of exact (default), floor_transaction or floor_statement
+	Rounding string `json:"rounding,omitempty"`
+}
+
+// RoundingMode returns the card's rounding mode, defaulting to RoundingExact
+func (c *Card) RoundingMode() string {
+	if c.Earning == nil || c.Earning.Rounding == "" {
+		return RoundingExact
+	}
+
+	return c.Earning.Rounding
+}
+
+// Earn converts the exact reward calculated on the amount into the reward credited to the
+// statement for a single transaction
+func (c *Card) Earn(amount money.Money, exact float64) float64 {
+	if c.RoundingMode() != RoundingFloorTransaction {
+		return exact
+	}
+
+	if block := c.Earning.BlockSize; block.IsPositive() && amount.IsPositive() {
+		// Only whole blocks of spend earn rewards
+		eligible := (amount.Minor() / block.Minor()) * block.Minor()
+		exact = exact * float64(eligible) / float64(amount.Minor())
+	}
+
+	return math.Floor(exact + epsilon)
+}
+
+// EarnStatement truncates the exact reward accumulated over a statement cycle
+func (c *Card) EarnStatement(exact float64) float64 {
+	if c.RoundingMode() != RoundingFloorStatement {
+		return exact
+	}
+
+	return math.Floor(exact + epsilon)
+}
+
+// normaliseEarning validates the card's earning and derives the reward rates of
+// block based earning from the points per block
+func (c *Card) normaliseEarning() error {
+	if c.Earning == nil {
+		return nil
+	}
+
+	switch c.RoundingMode() {
+	case RoundingExact, RoundingFloorTransaction, RoundingFloorStatement:
+	default:
+		return fmt.Errorf("invalid rounding mode %q", c.Earning.Rounding)
+	}
+
+	block := c.Earning.BlockSize.Float()
+
+	if block <= 0 {
+		if c.Earning.PointsPerBlock > 0 {
+			return fmt.Errorf("points_per_block requires a block_size")
+		}
+
+		return nil
+	}
+
+	if c.Earning.PointsPerBlock > 0 {
+		c.DefaultRewardRate = c.Earning.PointsPerBlock * 100 / block
+	}
+
+	for i := range c.RewardRules {
+		rule := &c.RewardRules[i]
+
+		// The rate of a multiplier is a factor, not a percentage of spend
+		if rule.PointsPerBlock > 0 && rule.StackingMode() != StackingMultiplier {
+			rule.RewardRate = rule.PointsPerBlock * 100 / block
+		}
+	}
+
+	return nil
+}
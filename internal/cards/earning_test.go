@@ -0,0 +1,112 @@
+package cards
+
+import (
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"math"
+	"testing"
+)
+
+// blockCard earns 4 points per ₹150 with the given rounding
+func blockCard(t *testing.T, rounding string) *Card {
+	t.Helper()
+
+	c := &Card{
+		Key:               "TEST",
+		DefaultRewardRate: 1,
+		Earning: &Earning{
+			BlockSize:      money.New(15000, money.INR),
+			PointsPerBlock: 4,
+			Rounding:       rounding,
+		},
+	}
+
+	err := c.normaliseEarning()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestEarn(t *testing.T) {
+	tests := []struct {
+		name     string
+		rounding string
+		rupees   float64
+		want     float64
+	}{
+		{name: "part block", rounding: RoundingFloorTransaction, rupees: 299, want: 4},
+		{name: "below a block", rounding: RoundingFloorTransaction, rupees: 149, want: 0},
+		{name: "whole blocks", rounding: RoundingFloorTransaction, rupees: 300, want: 8},
+		{name: "paise short of a block", rounding: RoundingFloorTransaction, rupees: 449.99, want: 8},
+		{name: "many blocks", rounding: RoundingFloorTransaction, rupees: 1000, want: 24},
+		{name: "exact", rounding: RoundingExact, rupees: 299, want: 299 * 4 / 150.0},
+		{name: "statement rounding leaves transactions exact", rounding: RoundingFloorStatement, rupees: 299, want: 299 * 4 / 150.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := blockCard(t, tt.rounding)
+			amount := money.FromFloat(tt.rupees, money.INR, money.HalfEven)
+
+			got := c.Earn(amount, tt.rupees*c.DefaultRewardRate/100)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Earn(₹%v) = %v, want %v", tt.rupees, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEarnStatement(t *testing.T) {
+	tests := []struct {
+		name     string
+		rounding string
+		// spends of the cycle in rupees
+		spends []float64
+		want   float64
+	}{
+		{name: "remainder truncated once", rounding: RoundingFloorStatement, spends: []float64{299, 299}, want: 15},
+		{name: "blocks across transactions", rounding: RoundingFloorStatement, spends: []float64{100, 50}, want: 4},
+		{name: "below a block", rounding: RoundingFloorStatement, spends: []float64{149}, want: 3},
+		{name: "exact", rounding: RoundingExact, spends: []float64{299, 299}, want: 598 * 4 / 150.0},
+		{name: "per transaction rounding is already whole", rounding: RoundingFloorTransaction, spends: []float64{299, 299}, want: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := blockCard(t, tt.rounding)
+
+			var total float64
+			for _, rupees := range tt.spends {
+				amount := money.FromFloat(rupees, money.INR, money.HalfEven)
+				total += c.Earn(amount, rupees*c.DefaultRewardRate/100)
+			}
+
+			got := c.EarnStatement(total)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("EarnStatement(%v) = %v, want %v", tt.spends, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormaliseEarning(t *testing.T) {
+	c := blockCard(t, RoundingFloorTransaction)
+
+	if want := 4 * 100 / 150.0; math.Abs(c.DefaultRewardRate-want) > 1e-9 {
+		t.Errorf("default reward rate = %v, want %v", c.DefaultRewardRate, want)
+	}
+
+	invalid := []*Earning{
+		{Rounding: "ceil"},
+		{PointsPerBlock: 4},
+	}
+
+	for _, e := range invalid {
+		c := &Card{Key: "TEST", Earning: e}
+
+		if err := c.normaliseEarning(); err == nil {
+			t.Errorf("normaliseEarning(%+v) = nil, want an error", *e)
+		}
+	}
+}
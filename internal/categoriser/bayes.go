@@ -0,0 +1,164 @@
+// Package categoriser suggests the categories of transactions from how the user categorised the
+// transactions of their ledger, with a naive Bayes classifier over the words of the merchant and
+// description and the size of the amount
+package categoriser
+
+import (
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// amountFeature prefixes the feature of the size of an amount, which no word can be mistaken for
+// as words are letters and digits only
+const amountFeature = "amount:"
+
+type (
+	// Sample is a categorised transaction to train on
+	Sample struct {
+		// Text is the merchant and description of the transaction
+		Text     string
+		Amount   money.Money
+		Category string
+	}
+
+	// Model is what a classifier learned from its samples. It is stored as JSON, whose maps are
+	// written in key order, so that the same samples always give the same model.
+	Model struct {
+		// Samples is the number of samples the model was trained on
+		Samples int `json:"samples"`
+		// Vocabulary is the number of distinct features across the samples
+		Vocabulary int `json:"vocabulary"`
+		// Categories are the feature counts of each category, keyed by category
+		Categories map[string]*Class `json:"categories"`
+	}
+
+	// Class is what the model learned of a category
+	Class struct {
+		// Samples is the number of samples of the category
+		Samples int `json:"samples"`
+		// Features is the total of the feature counts
+		Features int `json:"features"`
+		// Counts is the number of samples of the category with each feature
+		Counts map[string]int `json:"counts"`
+	}
+)
+
+// Train learns the categories of the samples
+func Train(samples []Sample) *Model {
+	m := &Model{Categories: make(map[string]*Class)}
+	vocabulary := make(map[string]bool)
+
+	for _, s := range samples {
+		category := strings.ToLower(strings.TrimSpace(s.Category))
+		if category == "" {
+			continue
+		}
+
+		c := m.Categories[category]
+		if c == nil {
+			c = &Class{Counts: make(map[string]int)}
+			m.Categories[category] = c
+		}
+
+		m.Samples++
+		c.Samples++
+
+		for _, f := range features(s.Text, s.Amount) {
+			c.Counts[f]++
+			c.Features++
+			vocabulary[f] = true
+		}
+	}
+
+	m.Vocabulary = len(vocabulary)
+
+	return m
+}
+
+// Predict returns the most likely category of a transaction and its probability, rounded to two
+// decimals, with add-one smoothing of the feature counts. Features no sample had are ignored, and
+// ties go to the category first in alphabetical order. It reports false when the model knows fewer
+// than two categories or none of the words of the text, as the amount alone says too little.
+func (m *Model) Predict(text string, amount money.Money) (string, float64, bool) {
+	if m == nil || len(m.Categories) < 2 {
+		return "", 0, false
+	}
+
+	// Features no sample had say nothing of any category
+	fs := slices.DeleteFunc(features(text, amount), func(f string) bool {
+		return !m.knows(f)
+	})
+
+	known := slices.ContainsFunc(fs, func(f string) bool {
+		return !strings.HasPrefix(f, amountFeature)
+	})
+	if !known {
+		return "", 0, false
+	}
+
+	names := slices.Sorted(maps.Keys(m.Categories))
+	scores := make([]float64, len(names))
+
+	best := 0
+	for i, name := range names {
+		c := m.Categories[name]
+
+		scores[i] = math.Log(float64(c.Samples) / float64(m.Samples))
+		for _, f := range fs {
+			scores[i] += math.Log(float64(c.Counts[f]+1) / float64(c.Features+m.Vocabulary))
+		}
+
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+
+	// The probability of the best category is its likelihood over the sum of all likelihoods,
+	// computed relative to the best to keep the exponents from underflowing
+	var total float64
+	for _, s := range scores {
+		total += math.Exp(s - scores[best])
+	}
+
+	return names[best], math.Round(100/total) / 100, true
+}
+
+// knows reports whether a sample of any category had the feature
+func (m *Model) knows(feature string) bool {
+	for _, c := range m.Categories {
+		if c.Counts[feature] > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// features returns the distinct words of the text, lower case and without the reference numbers,
+// and the size of the amount as its order of magnitude in rupees
+func features(text string, amount money.Money) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+
+	fs := make([]string, 0, len(words)+1)
+
+	for _, word := range words {
+		if len(word) < 2 || strings.ContainsAny(word, "0123456789") || slices.Contains(fs, word) {
+			continue
+		}
+
+		fs = append(fs, word)
+	}
+
+	magnitude := 0
+	if rupees := math.Abs(amount.Float()); rupees >= 1 {
+		magnitude = int(math.Log10(rupees))
+	}
+
+	return append(fs, amountFeature+strconv.Itoa(magnitude))
+}
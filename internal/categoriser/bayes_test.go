@@ -0,0 +1,186 @@
+package categoriser
+
+import (
+	"encoding/json"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// ledger is a categorised ledger the model is trained on
+var ledger = []Sample{
+	{Text: "SWIGGY BANGALORE", Amount: money.MustParse("450", money.INR), Category: "Dining"},
+	{Text: "ZOMATO ORDER 8812", Amount: money.MustParse("620", money.INR), Category: "dining"},
+	{Text: "STARBUCKS COFFEE INDIRANAGAR", Amount: money.MustParse("380", money.INR), Category: "dining"},
+	{Text: "BIGBASKET GROCERY", Amount: money.MustParse("2150.50", money.INR), Category: "groceries"},
+	{Text: "DMART AVENUE SUPERMARTS", Amount: money.MustParse("3400", money.INR), Category: "groceries"},
+	{Text: "ZEPTO GROCERY ORDER", Amount: money.MustParse("640", money.INR), Category: "groceries"},
+	{Text: "INDIAN OIL FUEL STATION", Amount: money.MustParse("2500", money.INR), Category: "fuel"},
+	{Text: "HP PETROL PUMP FUEL", Amount: money.MustParse("3000", money.INR), Category: "Fuel "},
+	{Text: "AMAZON PAY INDIA", Amount: money.MustParse("1499", money.INR), Category: "shopping"},
+	{Text: "FLIPKART INTERNET", Amount: money.MustParse("5299", money.INR), Category: "shopping"},
+	{Text: "UNCATEGORISED TRANSFER", Amount: money.MustParse("100", money.INR)},
+}
+
+// shuffled returns the samples in a random order of the seed
+func shuffled(samples []Sample, seed uint64) []Sample {
+	s := slices.Clone(samples)
+	rand.New(rand.NewPCG(seed, seed)).Shuffle(len(s), func(i, j int) {
+		s[i], s[j] = s[j], s[i]
+	})
+
+	return s
+}
+
+// stored round trips a model through the JSON it is stored as
+func stored(t *testing.T, m *Model) *Model {
+	t.Helper()
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var loaded Model
+
+	err = json.Unmarshal(b, &loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &loaded
+}
+
+func TestTrainDeterministic(t *testing.T) {
+	want, err := json.Marshal(Train(ledger))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for seed := range uint64(20) {
+		got, err := json.Marshal(Train(shuffled(ledger, seed)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != string(want) {
+			t.Fatalf("model trained on the ledger shuffled with seed %d differs:\n%s\nwant\n%s", seed, got, want)
+		}
+	}
+}
+
+func TestPredict(t *testing.T) {
+	tests := []struct {
+		text       string
+		amount     string
+		category   string
+		confidence float64
+		ok         bool
+	}{
+		{text: "SWIGGY INSTAMART BANGALORE", amount: "520", category: "dining", confidence: 0.83, ok: true},
+		{text: "BIGBASKET", amount: "1800", category: "groceries", confidence: 0.51, ok: true},
+		{text: "SHELL FUEL", amount: "2000", category: "fuel", confidence: 0.49, ok: true},
+		{text: "AMAZON", amount: "1299", category: "shopping", confidence: 0.45, ok: true},
+		// Words of both dining and groceries
+		{text: "ZOMATO GROCERY ORDER", amount: "600", category: "dining", confidence: 0.56, ok: true},
+		// The amount alone says too little
+		{text: "UNKNOWN MERCHANT 42", amount: "450", ok: false},
+		{text: "", amount: "450", ok: false},
+	}
+
+	models := []*Model{Train(ledger), stored(t, Train(ledger)), Train(shuffled(ledger, 7))}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			amount := money.MustParse(tt.amount, money.INR)
+
+			// The same suggestion whichever model of the ledger and however many times asked
+			for i, m := range models {
+				for range 50 {
+					category, confidence, ok := m.Predict(tt.text, amount)
+
+					if category != tt.category || confidence != tt.confidence || ok != tt.ok {
+						t.Fatalf("model %d predicted %q at %v (%v), want %q at %v (%v)", i,
+							category, confidence, ok, tt.category, tt.confidence, tt.ok)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPredictTies(t *testing.T) {
+	inr := func(s string) money.Money {
+		return money.MustParse(s, money.INR)
+	}
+
+	tests := []struct {
+		name       string
+		samples    []Sample
+		text       string
+		category   string
+		confidence float64
+	}{
+		{
+			name: "two categories",
+			samples: []Sample{
+				{Text: "CAFE COFFEE DAY", Amount: inr("300"), Category: "groceries"},
+				{Text: "CAFE COFFEE DAY", Amount: inr("300"), Category: "dining"},
+			},
+			text:       "CAFE COFFEE DAY",
+			category:   "dining",
+			confidence: 0.5,
+		},
+		{
+			name: "three categories",
+			samples: []Sample{
+				{Text: "RELIANCE SMART", Amount: inr("800"), Category: "shopping"},
+				{Text: "RELIANCE PETROL", Amount: inr("800"), Category: "fuel"},
+				{Text: "RELIANCE FRESH", Amount: inr("800"), Category: "groceries"},
+			},
+			text:       "RELIANCE",
+			category:   "fuel",
+			confidence: 0.33,
+		},
+		{
+			// The categories are named as the user typed them, but compared in lower case
+			name: "mixed case",
+			samples: []Sample{
+				{Text: "UBER TRIP", Amount: inr("250"), Category: "Travel"},
+				{Text: "UBER EATS", Amount: inr("250"), Category: "dining"},
+			},
+			text:       "UBER",
+			category:   "dining",
+			confidence: 0.5,
+		},
+		{
+			// Only the tied categories are ordered, a more likely one still wins
+			name: "tie below the best",
+			samples: []Sample{
+				{Text: "METRO CASH CARRY", Amount: inr("1200"), Category: "bills"},
+				{Text: "METRO CASH CARRY", Amount: inr("1200"), Category: "groceries"},
+				{Text: "METRO CASH CARRY", Amount: inr("1200"), Category: "groceries"},
+				{Text: "METRO RAIL", Amount: inr("60"), Category: "travel"},
+				{Text: "METRO RAIL", Amount: inr("60"), Category: "commute"},
+			},
+			text:       "METRO CASH",
+			category:   "groceries",
+			confidence: 0.59,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := range uint64(20) {
+				m := stored(t, Train(shuffled(tt.samples, seed)))
+
+				category, confidence, ok := m.Predict(tt.text, tt.samples[0].Amount)
+				if !ok || category != tt.category || confidence != tt.confidence {
+					t.Fatalf("samples shuffled with seed %d: predicted %q at %v (%v), want %q at %v", seed,
+						category, confidence, ok, tt.category, tt.confidence)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package categoriser
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"strings"
+	"sync"
+)
+
+// minConfidence is the lowest probability of a category for it to be suggested, under which the
+// suggestion is more likely wrong than right
+const minConfidence = 0.5
+
+type (
+	// Suggestion is the category suggested for a transaction
+	Suggestion struct {
+		Category   string  `json:"category"`
+		Confidence float64 `json:"confidence"`
+	}
+
+	// Categoriser suggests the categories of a user's transactions with the latest model trained
+	// on their ledger
+	Categoriser struct {
+		db *db.DB
+
+		mu sync.Mutex
+		// loaded are the models in use by user
+		loaded map[int64]*loadedModel
+	}
+
+	// loadedModel is a model in use along with its ID, to tell when a newer one was trained
+	loadedModel struct {
+		id    int64
+		model *Model
+	}
+)
+
+// NewCategoriser creates a categoriser, which loads the latest model of a user when first asked
+// for a suggestion for them
+func NewCategoriser(dbConn *db.DB) *Categoriser {
+	return &Categoriser{db: dbConn, loaded: make(map[int64]*loadedModel)}
+}
+
+// Text joins the merchant and the description of a transaction into the text it is categorised by
+func Text(merchant, description *string) string {
+	parts := make([]string, 0, 2)
+
+	for _, part := range []*string{merchant, description} {
+		if part != nil && *part != "" {
+			parts = append(parts, *part)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Suggest returns the category of a transaction of the user, or nil when no model was trained on
+// their ledger or it cannot tell with a probability of at least minConfidence. A model trained
+// since the last suggestion, e.g. from the command line, is loaded first.
+func (c *Categoriser) Suggest(
+	ctx context.Context,
+	userID int64,
+	text string,
+	amount money.Money,
+) (*Suggestion, error) {
+	model, err := c.latest(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	category, confidence, ok := model.Predict(text, amount)
+	if !ok || confidence < minConfidence {
+		return nil, nil
+	}
+
+	return &Suggestion{Category: category, Confidence: confidence}, nil
+}
+
+// Train trains a model on the categorised spends of the user's ledger and puts it in use for them
+// in place of the previous one
+func (c *Categoriser) Train(ctx context.Context, userID int64) (*models.CategoryModel, error) {
+	spends, err := c.db.Queries.GetCategorisedSpends(ctx, &userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categorised spends: %w", err)
+	}
+
+	samples := make([]Sample, 0, len(spends))
+	for _, txn := range spends {
+		samples = append(samples, Sample{
+			Text:     Text(txn.Merchant, txn.Notes),
+			Amount:   txn.AmountMinor,
+			Category: *txn.Category,
+		})
+	}
+
+	model := Train(samples)
+
+	data, err := json.Marshal(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal category model: %w", err)
+	}
+
+	stored, err := c.db.Queries.CreateCategoryModel(ctx, models.CreateCategoryModelParams{
+		UserID:     userID,
+		Samples:    int64(model.Samples),
+		Categories: int64(len(model.Categories)),
+		Model:      string(data),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create category model: %w", err)
+	}
+
+	err = c.db.Queries.DeleteCategoryModelsBefore(ctx, models.DeleteCategoryModelsBeforeParams{
+		UserID: userID,
+		ID:     stored.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete previous category models: %w", err)
+	}
+
+	c.mu.Lock()
+	c.loaded[userID] = &loadedModel{id: stored.ID, model: model}
+	c.mu.Unlock()
+
+	return stored, nil
+}
+
+// Refresh suggests the categories of the user's spends and refunds waiting for review again, e.g.
+// after training, and returns the number of drafts with a suggestion
+func (c *Categoriser) Refresh(ctx context.Context, userID int64) (int, error) {
+	drafts, err := c.db.Queries.GetUserDraftTransactions(ctx, &userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get draft transactions: %w", err)
+	}
+
+	suggested := 0
+
+	for _, draft := range drafts {
+		if draft.Kind == db.DraftKindPayment {
+			continue
+		}
+
+		s, err := c.Suggest(ctx, userID, Text(draft.Merchant, &draft.Description), draft.AmountMinor)
+		if err != nil {
+			return 0, err
+		}
+
+		params := models.UpdateDraftSuggestionParams{ID: draft.ID}
+		if s != nil {
+			params.SuggestedCategory, params.SuggestionConfidence = &s.Category, &s.Confidence
+			suggested++
+		}
+
+		err = c.db.Queries.UpdateDraftSuggestion(ctx, params)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update suggestion of draft %d: %w", draft.ID, err)
+		}
+	}
+
+	return suggested, nil
+}
+
+// latest returns the latest model trained on the user's ledger, loading it when it is not the one
+// in use, and nil when none was trained
+func (c *Categoriser) latest(ctx context.Context, userID int64) (*Model, error) {
+	id, err := c.db.Queries.GetLatestCategoryModelID(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest category model: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if loaded := c.loaded[userID]; loaded != nil && loaded.id == id {
+		return loaded.model, nil
+	}
+
+	stored, err := c.db.Queries.GetLatestCategoryModel(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest category model: %w", err)
+	}
+
+	var model Model
+
+	err = json.Unmarshal([]byte(stored.Model), &model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal category model %d: %w", stored.ID, err)
+	}
+
+	c.loaded[userID] = &loadedModel{id: stored.ID, model: &model}
+
+	return &model, nil
+}
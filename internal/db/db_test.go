@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var (
+	discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// testDB is the database of the package's tests, New opens a single database per process
+	testDB *DB
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "cardmax")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	testDB, err = New(context.Background(), discard, &Config{Path: filepath.Join(dir, "test.db")})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func inr(s string) money.Money {
+	return money.MustParse(s, money.INR)
+}
+
+// newCard registers a user with a card whose statement is generated on the 5th, due 20 days later
+func newCard(t *testing.T, username string) *models.Card {
+	t.Helper()
+
+	ctx := context.Background()
+
+	user, err := testDB.RegisterUser(ctx, discard, username, "hash", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	card, err := testDB.Queries.CreateCard(ctx, models.CreateCardParams{
+		Name:             username + "'s card",
+		Issuer:           "HDFC Bank",
+		Last4Digits:      "1234",
+		ExpiryDate:       "2030-12",
+		CardType:         "credit",
+		StatementDay:     5,
+		PaymentDueDays:   20,
+		CreditLimitMinor: inr("300000"),
+		UserID:           &user.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return card
+}
+
+// spend logs a transaction of the amount, negative for a refund, on the card
+func spend(t *testing.T, card *models.Card, date, amount string) {
+	t.Helper()
+
+	_, err := testDB.Queries.CreateTransaction(context.Background(), models.CreateTransactionParams{
+		CardID:          card.ID,
+		AmountMinor:     inr(amount),
+		TransactionDate: date,
+		Source:          SourceManual,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,378 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"log/slog"
+)
+
+// Kinds of draft transactions
+const (
+	DraftKindSpend   = "spend"
+	DraftKindRefund  = "refund"
+	DraftKindPayment = "payment"
+)
+
+var (
+	// ErrDraftNotFound is returned when a draft to approve, discard or merge does not exist
+	ErrDraftNotFound = errors.New("draft transaction not found")
+	// ErrDraftsMismatch is returned when drafts to merge are not of the same card, kind and amount
+	ErrDraftsMismatch = errors.New("only drafts of the same card, kind and amount can be merged")
+)
+
+// DuplicateDraftsError is returned when drafts to approve carry the external ID of a row already
+// in their card's ledger, or of another draft approved with them
+type DuplicateDraftsError struct {
+	// IDs are the drafts that would be imported twice
+	IDs []int64
+}
+
+func (e *DuplicateDraftsError) Error() string {
+	return fmt.Sprintf("drafts %v would import transactions or payments twice", e.IDs)
+}
+
+// SaveDrafts records draft transactions for review, along with the statement they were read
+// from when statement is not nil, in a single database transaction
+func (d *DB) SaveDrafts(
+	ctx context.Context,
+	log *slog.Logger,
+	statement *models.CreateStatementImportParams,
+	drafts []models.CreateDraftTransactionParams,
+) (imported *models.StatementImport, saved []*models.DraftTransaction, err error) {
+	tx, err := d.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := d.Queries.WithTx(tx)
+
+	// If we return with an error, rollback the transaction
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.ErrorContext(ctx, "failed to rollback transaction", logger.Error(rbErr))
+			}
+		}
+	}()
+
+	if statement != nil {
+		imported, err = q.CreateStatementImport(ctx, *statement)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create statement import: %w", err)
+		}
+	}
+
+	for _, params := range drafts {
+		if imported != nil {
+			params.StatementImportID = &imported.ID
+		}
+
+		draft, err := q.CreateDraftTransaction(ctx, params)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create draft transaction: %w", err)
+		}
+
+		saved = append(saved, draft)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return imported, saved, nil
+}
+
+// ApproveDrafts moves draft transactions of the user to the ledger in a single database
+// transaction, so that either every draft is approved or none is. Spends and refunds become
+// transactions, categorised with the suggested category when they have none, and bill payments are
+// recorded and applied to statements the same way as by RecordPayment.
+// A DuplicateDraftsError lists the drafts whose external ID was already imported, none of the
+// drafts being approved then.
+func (d *DB) ApproveDrafts(
+	ctx context.Context,
+	log *slog.Logger,
+	userID *int64,
+	ids []int64,
+) (created []*models.Transaction, paid []*models.Payment, err error) {
+	tx, err := d.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := d.Queries.WithTx(tx)
+
+	// If we return with an error, rollback the transaction
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.ErrorContext(ctx, "failed to rollback transaction", logger.Error(rbErr))
+			}
+		}
+	}()
+
+	var (
+		txns       []models.CreateTransactionParams
+		payments   []RecordPaymentParams
+		duplicates []int64
+	)
+
+	// External IDs of the batch by card and kind, as they are unique per card in each table
+	seen := make(map[string]bool)
+
+	for _, id := range ids {
+		draft, err := q.GetDraftTransactionByID(ctx, models.GetDraftTransactionByIDParams{ID: id, UserID: userID})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, fmt.Errorf("%w: %d", ErrDraftNotFound, id)
+		}
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get draft transaction %d: %w", id, err)
+		}
+
+		duplicate, err := importedBefore(ctx, q, seen, draft)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if duplicate {
+			duplicates = append(duplicates, id)
+			continue
+		}
+
+		_, err = q.DeleteDraftTransaction(ctx, models.DeleteDraftTransactionParams{ID: id, UserID: userID})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to delete draft transaction %d: %w", id, err)
+		}
+
+		if draft.Kind == DraftKindPayment {
+			payments = append(payments, RecordPaymentParams{
+				CardID:      draft.CardID,
+				Amount:      draft.AmountMinor,
+				PaymentDate: draft.TransactionDate,
+				Notes:       &draft.Description,
+				Source:      draft.Source,
+				ExternalID:  draft.ExternalID,
+			})
+
+			continue
+		}
+
+		amount := draft.AmountMinor
+		if draft.Kind == DraftKindRefund {
+			amount = amount.Neg()
+		}
+
+		// The description is kept as a note when the merchant was matched to a taxonomy name
+		merchant, notes := &draft.Description, (*string)(nil)
+		if draft.Merchant != nil {
+			merchant, notes = draft.Merchant, &draft.Description
+		}
+
+		txns = append(txns, models.CreateTransactionParams{
+			CardID:          draft.CardID,
+			Merchant:        merchant,
+			Category:        cmp.Or(draft.Category, draft.SuggestedCategory),
+			Notes:           notes,
+			AmountMinor:     amount,
+			TransactionDate: draft.TransactionDate,
+			Source:          draft.Source,
+			ExternalID:      draft.ExternalID,
+		})
+	}
+
+	if len(duplicates) > 0 {
+		return nil, nil, &DuplicateDraftsError{IDs: duplicates}
+	}
+
+	created, paid, err = createLedger(ctx, q, txns, payments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return created, paid, nil
+}
+
+// importedBefore reports whether the external ID of the draft is already in its card's ledger or
+// was seen on another draft of the batch, recording it as seen
+func importedBefore(
+	ctx context.Context,
+	q *models.Queries,
+	seen map[string]bool,
+	draft *models.DraftTransaction,
+) (bool, error) {
+	if draft.ExternalID == nil {
+		return false, nil
+	}
+
+	table := "transactions"
+	if draft.Kind == DraftKindPayment {
+		table = "payments"
+	}
+
+	key := fmt.Sprintf("%s|%d|%s", table, draft.CardID, *draft.ExternalID)
+	if seen[key] {
+		return true, nil
+	}
+
+	seen[key] = true
+
+	var (
+		exists int64
+		err    error
+	)
+
+	if draft.Kind == DraftKindPayment {
+		exists, err = q.PaymentExternalIDExists(ctx, models.PaymentExternalIDExistsParams{
+			CardID:     draft.CardID,
+			ExternalID: draft.ExternalID,
+		})
+	} else {
+		exists, err = q.TransactionExternalIDExists(ctx, models.TransactionExternalIDExistsParams{
+			CardID:     draft.CardID,
+			ExternalID: draft.ExternalID,
+		})
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to look up external ID of draft transaction %d: %w", draft.ID, err)
+	}
+
+	return exists != 0, nil
+}
+
+// DiscardDrafts deletes drafts of the user in a single database transaction, so that either
+// every draft is discarded or none is
+func (d *DB) DiscardDrafts(ctx context.Context, log *slog.Logger, userID *int64, ids []int64) (err error) {
+	tx, err := d.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := d.Queries.WithTx(tx)
+
+	// If we return with an error, rollback the transaction
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.ErrorContext(ctx, "failed to rollback transaction", logger.Error(rbErr))
+			}
+		}
+	}()
+
+	for _, id := range ids {
+		n, err := q.DeleteDraftTransaction(ctx, models.DeleteDraftTransactionParams{ID: id, UserID: userID})
+		if err != nil {
+			return fmt.Errorf("failed to delete draft transaction %d: %w", id, err)
+		}
+
+		if n == 0 {
+			return fmt.Errorf("%w: %d", ErrDraftNotFound, id)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// MergeDrafts merges drafts of the user of the same transaction, e.g. read from both an alert and the
+// statement, into the draft keep. The drafts must be of the same card, kind and amount, though
+// their dates may differ. It takes the merchant, category and external ID of the first
+// duplicate that has them when keep does not, and the highest confidence of them all, as the
+// sources agree. The duplicates are deleted.
+func (d *DB) MergeDrafts(
+	ctx context.Context,
+	log *slog.Logger,
+	userID *int64,
+	keep int64,
+	duplicates []int64,
+) (merged *models.DraftTransaction, err error) {
+	tx, err := d.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := d.Queries.WithTx(tx)
+
+	// If we return with an error, rollback the transaction
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.ErrorContext(ctx, "failed to rollback transaction", logger.Error(rbErr))
+			}
+		}
+	}()
+
+	get := func(id int64) (*models.DraftTransaction, error) {
+		draft, err := q.GetDraftTransactionByID(ctx, models.GetDraftTransactionByIDParams{ID: id, UserID: userID})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %d", ErrDraftNotFound, id)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get draft transaction %d: %w", id, err)
+		}
+
+		return draft, nil
+	}
+
+	kept, err := get(keep)
+	if err != nil {
+		return nil, err
+	}
+
+	params := models.MergeDraftTransactionParams{
+		ID:         kept.ID,
+		Merchant:   kept.Merchant,
+		Category:   kept.Category,
+		ExternalID: kept.ExternalID,
+		Confidence: kept.Confidence,
+	}
+
+	for _, id := range duplicates {
+		if id == keep {
+			continue
+		}
+
+		draft, err := get(id)
+		if err != nil {
+			return nil, err
+		}
+
+		if draft.CardID != kept.CardID || draft.Kind != kept.Kind || draft.AmountMinor != kept.AmountMinor {
+			return nil, fmt.Errorf("%w: %d does not match %d", ErrDraftsMismatch, id, keep)
+		}
+
+		params.Merchant = cmp.Or(params.Merchant, draft.Merchant)
+		params.Category = cmp.Or(params.Category, draft.Category)
+		params.ExternalID = cmp.Or(params.ExternalID, draft.ExternalID)
+		params.Confidence = max(params.Confidence, draft.Confidence)
+
+		_, err = q.DeleteDraftTransaction(ctx, models.DeleteDraftTransactionParams{ID: id, UserID: userID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete draft transaction %d: %w", id, err)
+		}
+	}
+
+	merged, err = q.MergeDraftTransaction(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge draft transaction %d: %w", keep, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/fx"
+	"log/slog"
+)
+
+// PopulateExchangeRates stores the exchange rates shipped with the binary.
+// Rates already in the database that are newer than the shipped ones are kept.
+func (d *DB) PopulateExchangeRates(ctx context.Context, log *slog.Logger, rates []*fx.Rate) error {
+	log.InfoContext(ctx, "populating exchange rates from static data", slog.Int("count", len(rates)))
+
+	for _, rate := range rates {
+		_, err := d.Queries.UpsertExchangeRate(ctx, models.UpsertExchangeRateParams{
+			Currency:  rate.Currency,
+			RateToInr: rate.RateToINR,
+			AsOf:      rate.AsOf,
+		})
+
+		// No row is returned when the stored rate is newer than the shipped one
+		if errors.Is(err, sql.ErrNoRows) {
+			log.DebugContext(ctx, "kept newer exchange rate", slog.String("currency", rate.Currency))
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to upsert exchange rate %s: %w", rate.Currency, err)
+		}
+	}
+
+	log.InfoContext(ctx, "finished populating exchange rates from static data")
+	return nil
+}
@@ -0,0 +1,191 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"log/slog"
+)
+
+var (
+	// ErrInHousehold is returned when creating or joining a household while being a member of one,
+	// as a user belongs to a single household
+	ErrInHousehold = errors.New("already a member of a household")
+	// ErrInviteNotFound is returned when accepting an invite that was not sent to the user
+	ErrInviteNotFound = errors.New("household invite not found")
+)
+
+// CreateHousehold creates a household owned by the user, with the user as its first member, in a
+// single database transaction
+func (d *DB) CreateHousehold(
+	ctx context.Context,
+	log *slog.Logger,
+	userID int64,
+	name string,
+) (household *models.Household, err error) {
+	tx, err := d.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := d.Queries.WithTx(tx)
+
+	// If we return with an error, rollback the transaction
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.ErrorContext(ctx, "failed to rollback transaction", logger.Error(rbErr))
+			}
+		}
+	}()
+
+	err = notInHousehold(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	household, err = q.CreateHousehold(ctx, models.CreateHouseholdParams{
+		Name:    name,
+		OwnerID: userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create household: %w", err)
+	}
+
+	err = q.AddHouseholdMember(ctx, models.AddHouseholdMemberParams{
+		HouseholdID: household.ID,
+		UserID:      userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add household member: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return household, nil
+}
+
+// JoinHousehold accepts an invite sent to the user, making them a member of its household, in a
+// single database transaction. The other invites of the user are dropped.
+func (d *DB) JoinHousehold(
+	ctx context.Context,
+	log *slog.Logger,
+	userID, inviteID int64,
+) (household *models.Household, err error) {
+	tx, err := d.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := d.Queries.WithTx(tx)
+
+	// If we return with an error, rollback the transaction
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.ErrorContext(ctx, "failed to rollback transaction", logger.Error(rbErr))
+			}
+		}
+	}()
+
+	invite, err := q.GetUserHouseholdInvite(ctx, models.GetUserHouseholdInviteParams{
+		ID:     inviteID,
+		UserID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInviteNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get household invite: %w", err)
+	}
+
+	err = notInHousehold(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.AddHouseholdMember(ctx, models.AddHouseholdMemberParams{
+		HouseholdID: invite.HouseholdID,
+		UserID:      userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add household member: %w", err)
+	}
+
+	err = q.DeleteUserHouseholdInvites(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete household invites: %w", err)
+	}
+
+	household, err = q.GetHousehold(ctx, invite.HouseholdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get household: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return household, nil
+}
+
+// DeleteHousehold removes a household along with its members and invites in a single database
+// transaction. The cards its members shared are no longer seen by the others.
+func (d *DB) DeleteHousehold(ctx context.Context, log *slog.Logger, id int64) (err error) {
+	tx, err := d.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := d.Queries.WithTx(tx)
+
+	// If we return with an error, rollback the transaction
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.ErrorContext(ctx, "failed to rollback transaction", logger.Error(rbErr))
+			}
+		}
+	}()
+
+	err = q.DeleteHouseholdInvites(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete household invites: %w", err)
+	}
+
+	err = q.DeleteHouseholdMembers(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete household members: %w", err)
+	}
+
+	err = q.DeleteHousehold(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete household: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// notInHousehold returns ErrInHousehold when the user is a member of a household
+func notInHousehold(ctx context.Context, q *models.Queries, userID int64) error {
+	_, err := q.GetUserHousehold(ctx, userID)
+	if err == nil {
+		return ErrInHousehold
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get household: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+)
+
+// Sources of ledger rows
+const (
+	// SourceManual rows were entered by the user
+	SourceManual = "manual"
+	// SourceCSV rows were imported from a CSV statement export
+	SourceCSV = "csv"
+	// SourceOFX rows were imported from an OFX or QFX file
+	SourceOFX = "ofx"
+	// SourceQIF rows were imported from a QIF file
+	SourceQIF = "qif"
+	// SourcePDF rows were imported from a PDF statement
+	SourcePDF = "pdf"
+	// SourceAlert rows were read from SMS or email transaction alerts
+	SourceAlert = "alert"
+)
+
+// createLedger records the transactions and payments with the queries of a database transaction
+func createLedger(
+	ctx context.Context,
+	q *models.Queries,
+	txns []models.CreateTransactionParams,
+	payments []RecordPaymentParams,
+) (created []*models.Transaction, paid []*models.Payment, err error) {
+	for _, params := range txns {
+		txn, err := q.CreateTransaction(ctx, params)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create transaction: %w", err)
+		}
+
+		created = append(created, txn)
+	}
+
+	for _, params := range payments {
+		payment, _, err := recordPayment(ctx, q, params)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		paid = append(paid, payment)
+	}
+
+	return created, paid, nil
+}
+
+// TransactionExternalID returns the ID a transaction is exported with: the ID it was imported
+// with, if any, or one derived from its ledger ID so that re-importing the export is a no-op
+func TransactionExternalID(txn *models.Transaction) string {
+	if txn.ExternalID != nil {
+		return *txn.ExternalID
+	}
+
+	return fmt.Sprintf("cardmax-t%d", txn.ID)
+}
+
+// PaymentExternalID returns the ID a payment is exported with, as TransactionExternalID does
+func PaymentExternalID(payment *models.Payment) string {
+	if payment.ExternalID != nil {
+		return *payment.ExternalID
+	}
+
+	return fmt.Sprintf("cardmax-p%d", payment.ID)
+}
@@ -17,7 +17,7 @@ const (
 	migrationDir = "migrations"
 
 	// version is the current database migration version
-	version = 2
+	version = 28
 )
 
 // migrationFiles is populated when building the binary
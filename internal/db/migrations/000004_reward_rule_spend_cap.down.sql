@@ -0,0 +1 @@
+ALTER TABLE predefined_reward_rules DROP COLUMN spend_cap;
@@ -0,0 +1,3 @@
+-- SpendCap: The maximum spend per statement cycle that earns the rule's reward rate.
+-- Spend beyond the cap falls back to the card's default reward rate. 0 means uncapped.
+ALTER TABLE predefined_reward_rules ADD COLUMN spend_cap REAL NOT NULL DEFAULT 0.0;
@@ -0,0 +1,17 @@
+-- ForexMarkup: The markup (in percent) charged by the issuer on international transactions.
+ALTER TABLE predefined_cards ADD COLUMN forex_markup REAL NOT NULL DEFAULT 0.0;
+
+CREATE TABLE exchange_rates
+(
+    -- Currency: The ISO 4217 code of the foreign currency (e.g., 'USD').
+    currency    TEXT PRIMARY KEY,
+
+    -- RateToINR: The value of one unit of the currency in INR.
+    rate_to_inr REAL NOT NULL CHECK (rate_to_inr > 0),
+
+    -- AsOf: The date ('YYYY-MM-DD') the rate was published. Newer rates replace older ones.
+    as_of       TEXT NOT NULL,
+
+    -- Updated at timestamp
+    updated_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_unique_reward_rule;
+DELETE FROM predefined_reward_rules WHERE channel != '';
+CREATE UNIQUE INDEX idx_unique_reward_rule ON predefined_reward_rules (predefined_card_id, type, entity_name);
+
+ALTER TABLE predefined_reward_rules DROP COLUMN channel;
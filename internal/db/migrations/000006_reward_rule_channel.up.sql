@@ -0,0 +1,7 @@
+-- Channel: The payment channel (e.g., 'upi', 'online', 'contactless') the rule is restricted to.
+-- An empty channel means the rule applies to every channel.
+ALTER TABLE predefined_reward_rules ADD COLUMN channel TEXT NOT NULL DEFAULT '';
+
+-- A card can have the same merchant or category rule with a different rate per channel
+DROP INDEX IF EXISTS idx_unique_reward_rule;
+CREATE UNIQUE INDEX idx_unique_reward_rule ON predefined_reward_rules (predefined_card_id, type, entity_name, channel);
@@ -0,0 +1,3 @@
+ALTER TABLE predefined_reward_rules DROP COLUMN exclusive;
+ALTER TABLE predefined_reward_rules DROP COLUMN stacking;
+ALTER TABLE predefined_reward_rules DROP COLUMN precedence;
@@ -0,0 +1,9 @@
+-- Precedence: Ranks the rule above other rules matching the same purchase, higher wins.
+ALTER TABLE predefined_reward_rules ADD COLUMN precedence INTEGER NOT NULL DEFAULT 0;
+
+-- Stacking: How the rule combines with the base rate ('replace', 'additive' or 'multiplier').
+ALTER TABLE predefined_reward_rules ADD COLUMN stacking TEXT NOT NULL DEFAULT 'replace'
+    CHECK (stacking IN ('replace', 'additive', 'multiplier'));
+
+-- Exclusive: Exclusive rules do not combine with any other stacking rule.
+ALTER TABLE predefined_reward_rules ADD COLUMN exclusive BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,5 @@
+ALTER TABLE predefined_reward_rules DROP COLUMN points_per_block;
+
+ALTER TABLE predefined_cards DROP COLUMN earning_rounding;
+ALTER TABLE predefined_cards DROP COLUMN earning_points_per_block;
+ALTER TABLE predefined_cards DROP COLUMN earning_block_size;
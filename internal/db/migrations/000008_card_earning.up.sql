@@ -0,0 +1,13 @@
+-- EarningBlockSize: The spend that earns one block of rewards (e.g., 150 for 4 points per ₹150).
+-- 0 earns on every rupee.
+ALTER TABLE predefined_cards ADD COLUMN earning_block_size REAL NOT NULL DEFAULT 0.0;
+
+-- EarningPointsPerBlock: The reward per block at the default rate, 0 if not block based.
+ALTER TABLE predefined_cards ADD COLUMN earning_points_per_block REAL NOT NULL DEFAULT 0.0;
+
+-- EarningRounding: How rewards are rounded ('exact', 'floor_transaction' or 'floor_statement').
+ALTER TABLE predefined_cards ADD COLUMN earning_rounding TEXT NOT NULL DEFAULT 'exact'
+    CHECK (earning_rounding IN ('exact', 'floor_transaction', 'floor_statement'));
+
+-- PointsPerBlock: The reward per block of the card's earning for this rule, 0 if not set.
+ALTER TABLE predefined_reward_rules ADD COLUMN points_per_block REAL NOT NULL DEFAULT 0.0;
@@ -0,0 +1,11 @@
+ALTER TABLE predefined_reward_rules ADD COLUMN spend_cap REAL NOT NULL DEFAULT 0.0;
+UPDATE predefined_reward_rules SET spend_cap = spend_cap_minor / 100.0;
+ALTER TABLE predefined_reward_rules DROP COLUMN spend_cap_minor;
+
+ALTER TABLE predefined_cards ADD COLUMN earning_block_size REAL NOT NULL DEFAULT 0.0;
+UPDATE predefined_cards SET earning_block_size = earning_block_size_minor / 100.0;
+ALTER TABLE predefined_cards DROP COLUMN earning_block_size_minor;
+
+ALTER TABLE predefined_cards ADD COLUMN annual_fee INTEGER NOT NULL DEFAULT 0;
+UPDATE predefined_cards SET annual_fee = annual_fee_minor / 100;
+ALTER TABLE predefined_cards DROP COLUMN annual_fee_minor;
@@ -0,0 +1,16 @@
+-- Money is stored as integer minor units (paise) to avoid float rounding drift.
+
+-- AnnualFeeMinor: The annual fee for the card in paise.
+ALTER TABLE predefined_cards ADD COLUMN annual_fee_minor INTEGER NOT NULL DEFAULT 0;
+UPDATE predefined_cards SET annual_fee_minor = annual_fee * 100;
+ALTER TABLE predefined_cards DROP COLUMN annual_fee;
+
+-- EarningBlockSizeMinor: The spend that earns one block of rewards in paise, 0 earns on every rupee.
+ALTER TABLE predefined_cards ADD COLUMN earning_block_size_minor INTEGER NOT NULL DEFAULT 0;
+UPDATE predefined_cards SET earning_block_size_minor = CAST(ROUND(earning_block_size * 100) AS INTEGER);
+ALTER TABLE predefined_cards DROP COLUMN earning_block_size;
+
+-- SpendCapMinor: The maximum spend per statement cycle that earns the rule's rate in paise, 0 means uncapped.
+ALTER TABLE predefined_reward_rules ADD COLUMN spend_cap_minor INTEGER NOT NULL DEFAULT 0;
+UPDATE predefined_reward_rules SET spend_cap_minor = CAST(ROUND(spend_cap * 100) AS INTEGER);
+ALTER TABLE predefined_reward_rules DROP COLUMN spend_cap;
@@ -0,0 +1,3 @@
+ALTER TABLE cards DROP COLUMN predefined_card_key;
+ALTER TABLE cards DROP COLUMN payment_due_days;
+ALTER TABLE cards DROP COLUMN statement_day;
@@ -0,0 +1,10 @@
+-- StatementDay: The day of the month the statement is generated on (1-31).
+-- Months shorter than the day generate the statement on their last day.
+ALTER TABLE cards ADD COLUMN statement_day INTEGER NOT NULL DEFAULT 1 CHECK (statement_day BETWEEN 1 AND 31);
+
+-- PaymentDueDays: The number of days after the statement date the payment is due.
+ALTER TABLE cards ADD COLUMN payment_due_days INTEGER NOT NULL DEFAULT 20 CHECK (payment_due_days BETWEEN 0 AND 60);
+
+-- PredefinedCardKey: The key of the predefined card this card is an instance of, if any.
+-- It links the user's card to the reward rules used for recommendations.
+ALTER TABLE cards ADD COLUMN predefined_card_key TEXT REFERENCES predefined_cards (card_key);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS transactions;
+ALTER TABLE cards DROP COLUMN credit_limit_minor;
@@ -0,0 +1,57 @@
+-- CreditLimitMinor: The credit limit of the card in paise, 0 if it is not known.
+ALTER TABLE cards ADD COLUMN credit_limit_minor INTEGER NOT NULL DEFAULT 0 CHECK (credit_limit_minor >= 0);
+
+CREATE TABLE transactions
+(
+    -- ID: Unique identifier for each transaction.
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- CardID: The user card the transaction was charged to.
+    card_id          INTEGER NOT NULL REFERENCES cards (id) ON DELETE CASCADE,
+
+    -- Merchant: The merchant the purchase was made with, if known.
+    merchant         TEXT,
+
+    -- Category: The spend category of the purchase (e.g., 'dining'), if known.
+    category         TEXT,
+
+    -- AmountMinor: The amount charged in paise. Refunds are negative.
+    amount_minor     INTEGER NOT NULL,
+
+    -- TransactionDate: The date of the transaction as 'YYYY-MM-DD'.
+    transaction_date TEXT NOT NULL,
+
+    -- Channel: How the purchase was paid for (e.g., 'upi', 'online'), if known.
+    channel          TEXT,
+
+    -- Notes: Free-form notes about the transaction.
+    notes            TEXT,
+
+    -- Created at timestamp
+    created_at       DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_transactions_card_date ON transactions (card_id, transaction_date);
+
+CREATE TABLE payments
+(
+    -- ID: Unique identifier for each payment.
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- CardID: The user card the payment was made towards.
+    card_id      INTEGER NOT NULL REFERENCES cards (id) ON DELETE CASCADE,
+
+    -- AmountMinor: The amount paid in paise.
+    amount_minor INTEGER NOT NULL CHECK (amount_minor > 0),
+
+    -- PaymentDate: The date of the payment as 'YYYY-MM-DD'.
+    payment_date TEXT NOT NULL,
+
+    -- Notes: Free-form notes about the payment.
+    notes        TEXT,
+
+    -- Created at timestamp
+    created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payments_card_date ON payments (card_id, payment_date);
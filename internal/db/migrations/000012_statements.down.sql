@@ -0,0 +1,2 @@
+ALTER TABLE payments DROP COLUMN statement_id;
+DROP TABLE IF EXISTS statements;
@@ -0,0 +1,42 @@
+CREATE TABLE statements
+(
+    -- ID: Unique identifier for each statement.
+    id                INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- CardID: The user card the statement was generated for.
+    card_id           INTEGER NOT NULL REFERENCES cards (id) ON DELETE CASCADE,
+
+    -- CycleStart: The first day of the billing cycle as 'YYYY-MM-DD'.
+    cycle_start       TEXT NOT NULL,
+
+    -- StatementDate: The day the statement was generated on as 'YYYY-MM-DD', the last day of the cycle.
+    statement_date    TEXT NOT NULL,
+
+    -- DueDate: The date the payment is due as 'YYYY-MM-DD'.
+    due_date          TEXT NOT NULL,
+
+    -- TotalDueMinor: The outstanding balance on the statement date in paise.
+    total_due_minor   INTEGER NOT NULL CHECK (total_due_minor >= 0),
+
+    -- MinimumDueMinor: The minimum amount to pay by the due date in paise.
+    minimum_due_minor INTEGER NOT NULL CHECK (minimum_due_minor >= 0),
+
+    -- PaidMinor: The amount paid towards the statement in paise.
+    paid_minor        INTEGER NOT NULL DEFAULT 0 CHECK (paid_minor >= 0),
+
+    -- PaidAt: The date the statement was paid in full as 'YYYY-MM-DD', NULL while it is not.
+    paid_at           TEXT,
+
+    -- RemindedAt: When a due date reminder was raised for the statement, NULL if none was.
+    reminded_at       DATETIME,
+
+    -- Created at timestamp
+    created_at        DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (card_id, statement_date)
+);
+
+CREATE INDEX idx_statements_due_date ON statements (due_date);
+
+-- StatementID: The statement a payment was made towards, if any.
+ALTER TABLE payments ADD COLUMN statement_id INTEGER REFERENCES statements (id) ON DELETE SET NULL;
@@ -0,0 +1,54 @@
+CREATE TABLE notification_preferences
+(
+    -- Kind: The kind of notification (e.g., 'payment_due'), '*' applies to every kind.
+    kind    TEXT    NOT NULL,
+
+    -- Channel: The delivery channel (e.g., 'webhook', 'email').
+    channel TEXT    NOT NULL,
+
+    -- Enabled: Whether notifications of the kind are delivered through the channel.
+    enabled BOOLEAN NOT NULL,
+
+    PRIMARY KEY (kind, channel)
+);
+
+CREATE TABLE notification_deliveries
+(
+    -- ID: Unique identifier for each delivery.
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- Kind: The kind of notification.
+    kind            TEXT     NOT NULL,
+
+    -- Channel: The channel the notification is delivered through.
+    channel         TEXT     NOT NULL,
+
+    -- Title: The title of the notification.
+    title           TEXT     NOT NULL,
+
+    -- Body: The body of the notification.
+    body            TEXT     NOT NULL,
+
+    -- Data: The structured details of the notification as JSON, if any.
+    data            TEXT,
+
+    -- Status: 'pending' until delivered ('sent') or out of attempts ('failed').
+    status          TEXT     NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+
+    -- Attempts: The number of delivery attempts made.
+    attempts        INTEGER  NOT NULL DEFAULT 0,
+
+    -- LastError: The error of the last failed attempt, if any.
+    last_error      TEXT,
+
+    -- NextAttemptAt: When the next delivery attempt is due.
+    next_attempt_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- DeliveredAt: When the notification was delivered.
+    delivered_at    DATETIME,
+
+    -- Created at timestamp
+    created_at      DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_notification_deliveries_pending ON notification_deliveries (status, next_attempt_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS cap_alerts;
+DROP TABLE IF EXISTS vapid_keys;
+DROP TABLE IF EXISTS push_subscriptions;
@@ -0,0 +1,48 @@
+CREATE TABLE push_subscriptions
+(
+    -- ID: Unique identifier for each subscription.
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- Endpoint: The push service URL messages for the browser are posted to.
+    endpoint   TEXT     NOT NULL UNIQUE,
+
+    -- P256dh: The browser's public key as base64url.
+    p256dh     TEXT     NOT NULL,
+
+    -- Auth: The browser's authentication secret as base64url.
+    auth       TEXT     NOT NULL,
+
+    -- Created at timestamp
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- VAPID keys generated when none are configured. Subscriptions are bound to the key they were
+-- created with, so the generated pair is kept for good.
+CREATE TABLE vapid_keys
+(
+    id          INTEGER PRIMARY KEY CHECK (id = 1),
+    public_key  TEXT     NOT NULL,
+    private_key TEXT     NOT NULL,
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE cap_alerts
+(
+    -- CardID: The user card whose spend approached a reward cap.
+    card_id    INTEGER  NOT NULL,
+
+    -- Rule: The capped reward rule, as "<type>|<entity name>|<channel>".
+    rule       TEXT     NOT NULL,
+
+    -- CycleStart: The start of the billing cycle the cap applies to (YYYY-MM-DD).
+    cycle_start TEXT     NOT NULL,
+
+    -- Level: 'warning' once the spend crosses the alert threshold, 'reached' once it hits the cap.
+    level      TEXT     NOT NULL CHECK (level IN ('warning', 'reached')),
+
+    -- Created at timestamp
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (card_id, rule, cycle_start, level),
+    FOREIGN KEY (card_id) REFERENCES cards (id) ON DELETE CASCADE
+);
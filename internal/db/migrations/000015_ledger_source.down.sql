@@ -0,0 +1,2 @@
+ALTER TABLE payments DROP COLUMN source;
+ALTER TABLE transactions DROP COLUMN source;
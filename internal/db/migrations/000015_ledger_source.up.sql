@@ -0,0 +1,3 @@
+-- Source: Where a ledger row came from: 'manual' for rows entered by the user, 'csv' for imports.
+ALTER TABLE transactions ADD COLUMN source TEXT NOT NULL DEFAULT 'manual';
+ALTER TABLE payments ADD COLUMN source TEXT NOT NULL DEFAULT 'manual';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_payments_external_id;
+DROP INDEX IF EXISTS idx_transactions_external_id;
+
+ALTER TABLE payments DROP COLUMN external_id;
+ALTER TABLE transactions DROP COLUMN external_id;
@@ -0,0 +1,6 @@
+-- ExternalID: The ID of an imported row in its source, e.g. the FITID of an OFX transaction.
+ALTER TABLE transactions ADD COLUMN external_id TEXT;
+ALTER TABLE payments ADD COLUMN external_id TEXT;
+
+CREATE UNIQUE INDEX idx_transactions_external_id ON transactions (card_id, external_id) WHERE external_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_payments_external_id ON payments (card_id, external_id) WHERE external_id IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS draft_transactions;
+DROP TABLE IF EXISTS statement_imports;
@@ -0,0 +1,74 @@
+CREATE TABLE statement_imports
+(
+    -- ID: Unique identifier for each imported statement.
+    id                INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- CardID: The user card the statement is of, NULL when no card could be matched.
+    card_id           INTEGER REFERENCES cards (id) ON DELETE SET NULL,
+
+    -- Template: The key of the issuer template the statement was parsed with (e.g., 'hdfc').
+    template          TEXT     NOT NULL,
+
+    -- StatementDate: The statement date as 'YYYY-MM-DD', NULL when the statement does not show it.
+    statement_date    TEXT,
+
+    -- DueDate: The payment due date as 'YYYY-MM-DD', NULL when the statement does not show it.
+    due_date          TEXT,
+
+    -- TotalDueMinor: The total amount due in paise, 0 when the statement does not show it.
+    total_due_minor   INTEGER  NOT NULL DEFAULT 0,
+
+    -- MinimumDueMinor: The minimum amount due in paise, 0 when the statement does not show it.
+    minimum_due_minor INTEGER  NOT NULL DEFAULT 0,
+
+    -- Points*: The reward points summary, NULL when the statement has none.
+    points_opening    REAL,
+    points_earned     REAL,
+    points_redeemed   REAL,
+    points_expired    REAL,
+    points_closing    REAL,
+
+    -- Created at timestamp
+    created_at        DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE draft_transactions
+(
+    -- ID: Unique identifier for each draft.
+    id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- CardID: The user card the draft is assigned to.
+    card_id             INTEGER  NOT NULL REFERENCES cards (id) ON DELETE CASCADE,
+
+    -- StatementImportID: The imported statement the draft was read from, if any.
+    statement_import_id INTEGER REFERENCES statement_imports (id) ON DELETE CASCADE,
+
+    -- Source: Where the draft was read from (e.g., 'pdf').
+    source              TEXT     NOT NULL,
+
+    -- Kind: Whether the draft is a spend, a refund or a bill payment.
+    kind                TEXT     NOT NULL CHECK (kind IN ('spend', 'refund', 'payment')),
+
+    -- TransactionDate: The date of the transaction as 'YYYY-MM-DD'.
+    transaction_date    TEXT     NOT NULL,
+
+    -- Description: The description of the transaction, usually the merchant.
+    description         TEXT     NOT NULL,
+
+    -- Category: The category of the transaction, if known.
+    category            TEXT,
+
+    -- AmountMinor: The unsigned amount in paise.
+    amount_minor        INTEGER  NOT NULL CHECK (amount_minor > 0),
+
+    -- ExternalID: The ID of the transaction in its source, if any.
+    external_id         TEXT,
+
+    -- Duplicate: Whether the draft matched a ledger row when it was created.
+    duplicate           BOOLEAN  NOT NULL DEFAULT FALSE,
+
+    -- Created at timestamp
+    created_at          DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_draft_transactions_card_id ON draft_transactions (card_id);
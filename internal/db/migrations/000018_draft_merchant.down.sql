@@ -0,0 +1 @@
+ALTER TABLE draft_transactions DROP COLUMN merchant;
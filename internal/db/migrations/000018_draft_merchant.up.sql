@@ -0,0 +1,2 @@
+-- Merchant: The taxonomy name of the merchant the description names (e.g., 'swiggy'), if known.
+ALTER TABLE draft_transactions ADD COLUMN merchant TEXT;
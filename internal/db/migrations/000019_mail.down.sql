@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS mail_messages;
+DROP TABLE IF EXISTS mail_checkpoints;
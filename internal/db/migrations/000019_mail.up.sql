@@ -0,0 +1,44 @@
+CREATE TABLE mail_checkpoints
+(
+    -- Source: The mailbox polled for alert emails (e.g., 'imap://alerts@example.com/INBOX').
+    source     TEXT PRIMARY KEY,
+
+    -- Cursor: Where the next poll of the mailbox resumes, in the form the mailbox defines.
+    cursor     TEXT     NOT NULL,
+
+    -- Updated at timestamp
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE mail_messages
+(
+    -- ID: Unique identifier for each processed message.
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- Source: The mailbox the message was read from.
+    source       TEXT     NOT NULL,
+
+    -- MessageID: The Message-ID header of the message, or an ID derived from the mailbox.
+    message_id   TEXT     NOT NULL UNIQUE,
+
+    -- Sender: The address the message was sent from.
+    sender       TEXT     NOT NULL,
+
+    -- Subject: The subject of the message.
+    subject      TEXT     NOT NULL,
+
+    -- ReceivedAt: The date of the message.
+    received_at  DATETIME NOT NULL,
+
+    -- Status: 'imported' when drafts were read from the message, 'ignored' otherwise.
+    status       TEXT     NOT NULL CHECK (status IN ('imported', 'ignored')),
+
+    -- Drafts: The number of draft transactions read from the message.
+    drafts       INTEGER  NOT NULL DEFAULT 0,
+
+    -- Reason: Why the message was ignored, if it was.
+    reason       TEXT,
+
+    -- Processed at timestamp
+    processed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
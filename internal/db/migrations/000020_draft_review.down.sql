@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_draft_transactions_confidence;
+ALTER TABLE draft_transactions DROP COLUMN confidence;
+ALTER TABLE draft_transactions DROP COLUMN parser;
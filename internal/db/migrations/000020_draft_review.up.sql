@@ -0,0 +1,10 @@
+-- Parser: What the draft was read with (e.g., 'csv:hdfc', 'pdf:icici', 'alert:sbi').
+ALTER TABLE draft_transactions ADD COLUMN parser TEXT NOT NULL DEFAULT '';
+
+-- Confidence: How likely the draft was read right, from 0 to 1, or 1 once edited by the reviewer.
+ALTER TABLE draft_transactions ADD COLUMN confidence REAL NOT NULL DEFAULT 1 CHECK (confidence BETWEEN 0 AND 1);
+
+UPDATE draft_transactions
+SET parser = source || COALESCE(':' || (SELECT template FROM statement_imports WHERE id = statement_import_id), '');
+
+CREATE INDEX idx_draft_transactions_confidence ON draft_transactions (confidence);
@@ -0,0 +1,17 @@
+CREATE TABLE merchant_mappings
+(
+    -- Descriptor: The cleaned statement descriptor the mapping is for (e.g., 'bundl instamart').
+    descriptor TEXT PRIMARY KEY,
+
+    -- Merchant: The merchant the descriptor names, as reward rules refer to it (e.g., 'swiggy').
+    merchant   TEXT     NOT NULL,
+
+    -- Category: The category of the merchant, if known (e.g., 'food_delivery').
+    category   TEXT,
+
+    -- Created at timestamp
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Updated at timestamp
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
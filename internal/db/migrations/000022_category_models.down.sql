@@ -0,0 +1,4 @@
+ALTER TABLE draft_transactions DROP COLUMN suggestion_confidence;
+ALTER TABLE draft_transactions DROP COLUMN suggested_category;
+
+DROP TABLE IF EXISTS category_models;
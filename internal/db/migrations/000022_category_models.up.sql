@@ -0,0 +1,23 @@
+CREATE TABLE category_models
+(
+    -- ID: Unique identifier for each trained model, the latest being in use.
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- Samples: The number of ledger transactions the model was trained on.
+    samples    INTEGER  NOT NULL,
+
+    -- Categories: The number of categories the model tells apart.
+    categories INTEGER  NOT NULL,
+
+    -- Model: The token and amount counts of each category, as JSON.
+    model      TEXT     NOT NULL,
+
+    -- Trained at timestamp
+    trained_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- SuggestedCategory: The category the model trained on the user's ledger suggests for the draft.
+ALTER TABLE draft_transactions ADD COLUMN suggested_category TEXT;
+
+-- SuggestionConfidence: How likely the suggested category is, from 0 to 1.
+ALTER TABLE draft_transactions ADD COLUMN suggestion_confidence REAL;
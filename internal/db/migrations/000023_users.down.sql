@@ -0,0 +1,26 @@
+ALTER TABLE push_subscriptions DROP COLUMN user_id;
+ALTER TABLE notification_deliveries DROP COLUMN user_id;
+
+CREATE TABLE notification_preferences_global
+(
+    kind    TEXT    NOT NULL,
+    channel TEXT    NOT NULL,
+    enabled BOOLEAN NOT NULL,
+
+    PRIMARY KEY (kind, channel)
+);
+
+INSERT OR IGNORE INTO notification_preferences_global (kind, channel, enabled)
+SELECT kind, channel, enabled
+FROM notification_preferences
+ORDER BY user_id;
+
+DROP TABLE notification_preferences;
+
+ALTER TABLE notification_preferences_global RENAME TO notification_preferences;
+
+DROP INDEX IF EXISTS idx_cards_user_id;
+ALTER TABLE cards DROP COLUMN user_id;
+
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,70 @@
+CREATE TABLE users
+(
+    -- ID: Unique identifier for each user.
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- Username: The name the user logs in with, unique regardless of case.
+    username      TEXT     NOT NULL UNIQUE COLLATE NOCASE,
+
+    -- PasswordHash: The argon2id hash of the password in PHC string format.
+    password_hash TEXT     NOT NULL,
+
+    -- Created at timestamp
+    created_at    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE sessions
+(
+    -- ID: The SHA-256 of the session token as hex, the token itself is only known to the browser.
+    id         TEXT PRIMARY KEY,
+
+    -- UserID: The user the session is logged in as.
+    user_id    INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    -- ExpiresAt: When the session stops being accepted.
+    expires_at DATETIME NOT NULL,
+
+    -- Created at timestamp
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sessions_user_id ON sessions (user_id);
+
+-- UserID: The user owning the card, NULL for cards added before the first user registered, who
+-- claims them.
+ALTER TABLE cards ADD COLUMN user_id INTEGER REFERENCES users (id) ON DELETE CASCADE;
+
+CREATE INDEX idx_cards_user_id ON cards (user_id);
+
+-- Preferences are kept per user, those set before the first user registered are claimed by them.
+CREATE TABLE notification_preferences_by_user
+(
+    -- UserID: The user the preference is of, NULL until the first user registers.
+    user_id INTEGER REFERENCES users (id) ON DELETE CASCADE,
+
+    -- Kind: The kind of notification (e.g., 'payment_due'), '*' applies to every kind.
+    kind    TEXT    NOT NULL,
+
+    -- Channel: The delivery channel (e.g., 'webhook', 'email').
+    channel TEXT    NOT NULL,
+
+    -- Enabled: Whether notifications of the kind are delivered through the channel.
+    enabled BOOLEAN NOT NULL,
+
+    UNIQUE (user_id, kind, channel)
+);
+
+INSERT INTO notification_preferences_by_user (kind, channel, enabled)
+SELECT kind, channel, enabled
+FROM notification_preferences;
+
+DROP TABLE notification_preferences;
+
+ALTER TABLE notification_preferences_by_user RENAME TO notification_preferences;
+
+-- UserID: The user the notification is for, NULL when it is for no one in particular.
+ALTER TABLE notification_deliveries ADD COLUMN user_id INTEGER REFERENCES users (id) ON DELETE CASCADE;
+
+-- UserID: The user the browser subscribed for, NULL for subscriptions made before the first user
+-- registered, who claims them.
+ALTER TABLE push_subscriptions ADD COLUMN user_id INTEGER REFERENCES users (id) ON DELETE CASCADE;
@@ -0,0 +1,31 @@
+CREATE TABLE access_tokens
+(
+    -- ID: Unique identifier for each token.
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- UserID: The user the token acts as.
+    user_id      INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    -- Name: What the token is for, as named by the user (e.g., 'Shortcuts').
+    name         TEXT     NOT NULL,
+
+    -- TokenHash: The SHA-256 of the token as hex, the token itself is only shown when created.
+    token_hash   TEXT     NOT NULL UNIQUE,
+
+    -- Hint: The last characters of the token, to tell tokens apart.
+    hint         TEXT     NOT NULL,
+
+    -- Scopes: The space separated scopes the token grants (e.g., 'read:cards recommend').
+    scopes       TEXT     NOT NULL,
+
+    -- ExpiresAt: When the token stops being accepted.
+    expires_at   DATETIME NOT NULL,
+
+    -- LastUsedAt: When the token was last used, NULL when it never was.
+    last_used_at DATETIME,
+
+    -- Created at timestamp
+    created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_access_tokens_user_id ON access_tokens (user_id);
@@ -0,0 +1,47 @@
+CREATE TABLE passkeys
+(
+    -- ID: Unique identifier for each passkey.
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- UserID: The user logging in with the passkey.
+    user_id       INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    -- Name: What the passkey is on, as named by the user (e.g., 'Phone').
+    name          TEXT     NOT NULL,
+
+    -- CredentialID: The ID of the WebAuthn credential, as chosen by the authenticator.
+    credential_id BLOB     NOT NULL UNIQUE,
+
+    -- Credential: The WebAuthn credential as JSON: its public key, flags, transports and the
+    -- authenticator's signature counter.
+    credential    TEXT     NOT NULL,
+
+    -- LastUsedAt: When the passkey was last logged in with, NULL when it never was.
+    last_used_at  DATETIME,
+
+    -- Created at timestamp
+    created_at    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_passkeys_user_id ON passkeys (user_id);
+
+CREATE TABLE webauthn_ceremonies
+(
+    -- ID: The SHA-256 of the ceremony token as hex, the token itself is only known to the browser.
+    id         TEXT PRIMARY KEY,
+
+    -- UserID: The user registering a passkey, NULL when logging in.
+    user_id    INTEGER REFERENCES users (id) ON DELETE CASCADE,
+
+    -- Kind: The ceremony, 'registration' or 'login'.
+    kind       TEXT     NOT NULL,
+
+    -- Session: The WebAuthn session data as JSON, holding the challenge.
+    session    TEXT     NOT NULL,
+
+    -- ExpiresAt: When the ceremony can no longer be finished.
+    expires_at DATETIME NOT NULL,
+
+    -- Created at timestamp
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
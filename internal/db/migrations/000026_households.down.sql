@@ -0,0 +1,7 @@
+ALTER TABLE transactions DROP COLUMN spent_by;
+ALTER TABLE cards DROP COLUMN shared;
+
+DROP INDEX IF EXISTS idx_household_invites_user_id;
+DROP TABLE household_invites;
+DROP TABLE household_members;
+DROP TABLE households;
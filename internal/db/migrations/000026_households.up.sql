@@ -0,0 +1,57 @@
+CREATE TABLE households
+(
+    -- ID: Unique identifier for each household.
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- Name: The name of the household (e.g., 'Home').
+    name       TEXT     NOT NULL,
+
+    -- OwnerID: The user who created the household, who invites and removes members.
+    owner_id   INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    -- Created at timestamp
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE household_members
+(
+    -- HouseholdID: The household the user is a member of.
+    household_id INTEGER  NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+
+    -- UserID: The member, who belongs to a single household.
+    user_id      INTEGER  NOT NULL UNIQUE REFERENCES users (id) ON DELETE CASCADE,
+
+    -- Joined at timestamp
+    joined_at    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (household_id, user_id)
+);
+
+CREATE TABLE household_invites
+(
+    -- ID: Unique identifier for each invite.
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- HouseholdID: The household the user is invited to.
+    household_id INTEGER  NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+
+    -- UserID: The invited user, who accepts or declines the invite.
+    user_id      INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    -- InvitedBy: The member who sent the invite.
+    invited_by   INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    -- Created at timestamp
+    created_at   DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (household_id, user_id)
+);
+
+CREATE INDEX idx_household_invites_user_id ON household_invites (user_id);
+
+-- Shared: Whether the members of the owner's household see the card, read-only, and have it
+-- ranked in their recommendations.
+ALTER TABLE cards ADD COLUMN shared BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- SpentBy: The household member who made the spend, NULL when it was the card's holder.
+ALTER TABLE transactions ADD COLUMN spent_by INTEGER REFERENCES users (id) ON DELETE SET NULL;
@@ -0,0 +1,17 @@
+CREATE TABLE merchant_mappings_global
+(
+    descriptor TEXT PRIMARY KEY,
+    merchant   TEXT     NOT NULL,
+    category   TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT OR IGNORE INTO merchant_mappings_global (descriptor, merchant, category, created_at, updated_at)
+SELECT descriptor, merchant, category, created_at, updated_at
+FROM merchant_mappings
+ORDER BY user_id;
+
+DROP TABLE merchant_mappings;
+
+ALTER TABLE merchant_mappings_global RENAME TO merchant_mappings;
@@ -0,0 +1,33 @@
+-- Mappings are learned per user, those learned before there were users belong to the first user
+-- or, when no one registered yet, are claimed by them.
+CREATE TABLE merchant_mappings_by_user
+(
+    -- UserID: The user whose corrections the mapping was learned from, NULL until the first user
+    -- registers.
+    user_id    INTEGER REFERENCES users (id) ON DELETE CASCADE,
+
+    -- Descriptor: The cleaned statement descriptor the mapping is for (e.g., 'bundl instamart').
+    descriptor TEXT     NOT NULL,
+
+    -- Merchant: The merchant the descriptor names, as reward rules refer to it (e.g., 'swiggy').
+    merchant   TEXT     NOT NULL,
+
+    -- Category: The category of the merchant, if known (e.g., 'food_delivery').
+    category   TEXT,
+
+    -- Created at timestamp
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Updated at timestamp
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (user_id, descriptor)
+);
+
+INSERT INTO merchant_mappings_by_user (user_id, descriptor, merchant, category, created_at, updated_at)
+SELECT (SELECT MIN(id) FROM users), descriptor, merchant, category, created_at, updated_at
+FROM merchant_mappings;
+
+DROP TABLE merchant_mappings;
+
+ALTER TABLE merchant_mappings_by_user RENAME TO merchant_mappings;
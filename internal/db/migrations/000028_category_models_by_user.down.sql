@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_category_models_user_id;
+DROP TABLE category_models;
+
+CREATE TABLE category_models
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    samples    INTEGER  NOT NULL,
+    categories INTEGER  NOT NULL,
+    model      TEXT     NOT NULL,
+    trained_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,30 @@
+-- A model is trained per user on their own ledger. Models trained on every user's ledger are
+-- dropped along with their suggestions, each user's is trained anew.
+DROP TABLE category_models;
+
+CREATE TABLE category_models
+(
+    -- ID: Unique identifier for each trained model, the latest of a user being in use.
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+
+    -- UserID: The user whose ledger the model was trained on.
+    user_id    INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+
+    -- Samples: The number of ledger transactions the model was trained on.
+    samples    INTEGER  NOT NULL,
+
+    -- Categories: The number of categories the model tells apart.
+    categories INTEGER  NOT NULL,
+
+    -- Model: The token and amount counts of each category, as JSON.
+    model      TEXT     NOT NULL,
+
+    -- Trained at timestamp
+    trained_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_category_models_user_id ON category_models (user_id);
+
+UPDATE draft_transactions
+SET suggested_category    = NULL,
+    suggestion_confidence = NULL;
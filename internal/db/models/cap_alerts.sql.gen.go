@@ -0,0 +1,61 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: cap_alerts.sql
+
+package models
+
+import (
+	"context"
+)
+
+const capAlertExists = `-- name: CapAlertExists :one
+SELECT EXISTS (SELECT 1
+               FROM cap_alerts
+               WHERE card_id = ?
+                 AND rule = ?
+                 AND cycle_start = ?
+                 AND level = ?)
+`
+
+type CapAlertExistsParams struct {
+	CardID     int64  `json:"card_id"`
+	Rule       string `json:"rule"`
+	CycleStart string `json:"cycle_start"`
+	Level      string `json:"level"`
+}
+
+func (q *Queries) CapAlertExists(ctx context.Context, arg CapAlertExistsParams) (int64, error) {
+	row := q.queryRow(ctx, q.capAlertExistsStmt, capAlertExists,
+		arg.CardID,
+		arg.Rule,
+		arg.CycleStart,
+		arg.Level,
+	)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const createCapAlert = `-- name: CreateCapAlert :exec
+INSERT INTO cap_alerts (card_id, rule, cycle_start, level)
+VALUES (?, ?, ?, ?)
+ON CONFLICT DO NOTHING
+`
+
+type CreateCapAlertParams struct {
+	CardID     int64  `json:"card_id"`
+	Rule       string `json:"rule"`
+	CycleStart string `json:"cycle_start"`
+	Level      string `json:"level"`
+}
+
+func (q *Queries) CreateCapAlert(ctx context.Context, arg CreateCapAlertParams) error {
+	_, err := q.exec(ctx, q.createCapAlertStmt, createCapAlert,
+		arg.CardID,
+		arg.Rule,
+		arg.CycleStart,
+		arg.Level,
+	)
+	return err
+}
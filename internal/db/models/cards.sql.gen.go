@@ -7,6 +7,8 @@ package models
 
 import (
 	"context"
+
+	"github.com/pushkar-anand/cardmax/internal/money"
 )
 
 const createCard = `-- name: CreateCard :one
@@ -15,24 +17,42 @@ INSERT INTO cards (name, -- The name given to the card
                    last4_digits, -- The last four digits of the card number
                    expiry_date, -- The expiration date (e.g., 'MM/YY' or 'YYYY-MM')
                    default_reward_rate, -- The default reward rate (e.g., 1.5 for 1.5%)
-                   card_type -- The type of card (e.g., 'Visa', 'Mastercard')
+                   card_type, -- The type of card (e.g., 'Visa', 'Mastercard')
+                   statement_day, -- The day of the month the statement is generated on
+                   payment_due_days, -- The days between the statement date and the due date
+                   predefined_card_key, -- The predefined card this card is an instance of
+                   credit_limit_minor, -- The credit limit in paise
+                   user_id, -- The user owning the card
+                   shared -- Whether the card is shared with the user's household
 )
 VALUES (?, -- Placeholder for Name
         ?, -- Placeholder for Issuer
         ?, -- Placeholder for Last4Digits (ensure the provided value is 4 characters)
         ?, -- Placeholder for ExpiryDate
         ?, -- Placeholder for DefaultRewardRate
-        ? -- Placeholder for CardType
-       ) RETURNING id, name, issuer, last4_digits, expiry_date, default_reward_rate, card_type
+        ?, -- Placeholder for CardType
+        ?, -- Placeholder for StatementDay
+        ?, -- Placeholder for PaymentDueDays
+        ?, -- Placeholder for PredefinedCardKey
+        ?, -- Placeholder for CreditLimitMinor
+        ?, -- Placeholder for UserID
+        ? -- Placeholder for Shared
+       ) RETURNING id, name, issuer, last4_digits, expiry_date, default_reward_rate, card_type, statement_day, payment_due_days, predefined_card_key, credit_limit_minor, user_id, shared
 `
 
 type CreateCardParams struct {
-	Name              string   `json:"name"`
-	Issuer            string   `json:"issuer"`
-	Last4Digits       string   `json:"last4_digits"`
-	ExpiryDate        string   `json:"expiry_date"`
-	DefaultRewardRate *float64 `json:"default_reward_rate"`
-	CardType          string   `json:"card_type"`
+	Name              string      `json:"name"`
+	Issuer            string      `json:"issuer"`
+	Last4Digits       string      `json:"last4_digits"`
+	ExpiryDate        string      `json:"expiry_date"`
+	DefaultRewardRate *float64    `json:"default_reward_rate"`
+	CardType          string      `json:"card_type"`
+	StatementDay      int64       `json:"statement_day"`
+	PaymentDueDays    int64       `json:"payment_due_days"`
+	PredefinedCardKey *string     `json:"predefined_card_key"`
+	CreditLimitMinor  money.Money `json:"credit_limit"`
+	UserID            *int64      `json:"user_id"`
+	Shared            bool        `json:"shared"`
 }
 
 func (q *Queries) CreateCard(ctx context.Context, arg CreateCardParams) (*Card, error) {
@@ -43,6 +63,12 @@ func (q *Queries) CreateCard(ctx context.Context, arg CreateCardParams) (*Card,
 		arg.ExpiryDate,
 		arg.DefaultRewardRate,
 		arg.CardType,
+		arg.StatementDay,
+		arg.PaymentDueDays,
+		arg.PredefinedCardKey,
+		arg.CreditLimitMinor,
+		arg.UserID,
+		arg.Shared,
 	)
 	var i Card
 	err := row.Scan(
@@ -53,12 +79,18 @@ func (q *Queries) CreateCard(ctx context.Context, arg CreateCardParams) (*Card,
 		&i.ExpiryDate,
 		&i.DefaultRewardRate,
 		&i.CardType,
+		&i.StatementDay,
+		&i.PaymentDueDays,
+		&i.PredefinedCardKey,
+		&i.CreditLimitMinor,
+		&i.UserID,
+		&i.Shared,
 	)
 	return &i, err
 }
 
 const getAllCards = `-- name: GetAllCards :many
-SELECT id, name, issuer, last4_digits, expiry_date, default_reward_rate, card_type FROM cards
+SELECT id, name, issuer, last4_digits, expiry_date, default_reward_rate, card_type, statement_day, payment_due_days, predefined_card_key, credit_limit_minor, user_id, shared FROM cards
 ORDER BY name ASC
 `
 
@@ -79,6 +111,67 @@ func (q *Queries) GetAllCards(ctx context.Context) ([]*Card, error) {
 			&i.ExpiryDate,
 			&i.DefaultRewardRate,
 			&i.CardType,
+			&i.StatementDay,
+			&i.PaymentDueDays,
+			&i.PredefinedCardKey,
+			&i.CreditLimitMinor,
+			&i.UserID,
+			&i.Shared,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCardBalances = `-- name: GetCardBalances :many
+SELECT cards.id, cards.name, cards.issuer, cards.last4_digits, cards.expiry_date, cards.default_reward_rate, cards.card_type, cards.statement_day, cards.payment_due_days, cards.predefined_card_key, cards.credit_limit_minor, cards.user_id, cards.shared,
+       CAST(COALESCE((SELECT SUM(t.amount_minor) FROM transactions t WHERE t.card_id = cards.id), 0) AS INTEGER) AS spent,
+       CAST(COALESCE((SELECT SUM(p.amount_minor) FROM payments p WHERE p.card_id = cards.id), 0) AS INTEGER) AS paid
+FROM cards
+WHERE cards.user_id = ?
+ORDER BY cards.name ASC
+`
+
+type GetCardBalancesRow struct {
+	Card  Card  `json:"card"`
+	Spent int64 `json:"spent"`
+	Paid  int64 `json:"paid"`
+}
+
+// Outstanding balance of every card of a user: logged transactions minus recorded payments.
+func (q *Queries) GetCardBalances(ctx context.Context, userID *int64) ([]*GetCardBalancesRow, error) {
+	rows, err := q.query(ctx, q.getCardBalancesStmt, getCardBalances, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetCardBalancesRow
+	for rows.Next() {
+		var i GetCardBalancesRow
+		if err := rows.Scan(
+			&i.Card.ID,
+			&i.Card.Name,
+			&i.Card.Issuer,
+			&i.Card.Last4Digits,
+			&i.Card.ExpiryDate,
+			&i.Card.DefaultRewardRate,
+			&i.Card.CardType,
+			&i.Card.StatementDay,
+			&i.Card.PaymentDueDays,
+			&i.Card.PredefinedCardKey,
+			&i.Card.CreditLimitMinor,
+			&i.Card.UserID,
+			&i.Card.Shared,
+			&i.Spent,
+			&i.Paid,
 		); err != nil {
 			return nil, err
 		}
@@ -93,8 +186,35 @@ func (q *Queries) GetAllCards(ctx context.Context) ([]*Card, error) {
 	return items, nil
 }
 
+const getCardByID = `-- name: GetCardByID :one
+SELECT id, name, issuer, last4_digits, expiry_date, default_reward_rate, card_type, statement_day, payment_due_days, predefined_card_key, credit_limit_minor, user_id, shared FROM cards
+WHERE id = ?
+LIMIT 1
+`
+
+func (q *Queries) GetCardByID(ctx context.Context, id int64) (*Card, error) {
+	row := q.queryRow(ctx, q.getCardByIDStmt, getCardByID, id)
+	var i Card
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Issuer,
+		&i.Last4Digits,
+		&i.ExpiryDate,
+		&i.DefaultRewardRate,
+		&i.CardType,
+		&i.StatementDay,
+		&i.PaymentDueDays,
+		&i.PredefinedCardKey,
+		&i.CreditLimitMinor,
+		&i.UserID,
+		&i.Shared,
+	)
+	return &i, err
+}
+
 const getCardByNameAndIssuer = `-- name: GetCardByNameAndIssuer :one
-SELECT id, name, issuer, last4_digits, expiry_date, default_reward_rate, card_type FROM cards
+SELECT id, name, issuer, last4_digits, expiry_date, default_reward_rate, card_type, statement_day, payment_due_days, predefined_card_key, credit_limit_minor, user_id, shared FROM cards
 WHERE name = ? AND issuer = ?
 LIMIT 1
 `
@@ -115,10 +235,156 @@ func (q *Queries) GetCardByNameAndIssuer(ctx context.Context, arg GetCardByNameA
 		&i.ExpiryDate,
 		&i.DefaultRewardRate,
 		&i.CardType,
+		&i.StatementDay,
+		&i.PaymentDueDays,
+		&i.PredefinedCardKey,
+		&i.CreditLimitMinor,
+		&i.UserID,
+		&i.Shared,
 	)
 	return &i, err
 }
 
+const getUserCardByID = `-- name: GetUserCardByID :one
+SELECT id, name, issuer, last4_digits, expiry_date, default_reward_rate, card_type, statement_day, payment_due_days, predefined_card_key, credit_limit_minor, user_id, shared FROM cards
+WHERE id = ? AND user_id = ?
+LIMIT 1
+`
+
+type GetUserCardByIDParams struct {
+	ID     int64  `json:"id"`
+	UserID *int64 `json:"user_id"`
+}
+
+func (q *Queries) GetUserCardByID(ctx context.Context, arg GetUserCardByIDParams) (*Card, error) {
+	row := q.queryRow(ctx, q.getUserCardByIDStmt, getUserCardByID, arg.ID, arg.UserID)
+	var i Card
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Issuer,
+		&i.Last4Digits,
+		&i.ExpiryDate,
+		&i.DefaultRewardRate,
+		&i.CardType,
+		&i.StatementDay,
+		&i.PaymentDueDays,
+		&i.PredefinedCardKey,
+		&i.CreditLimitMinor,
+		&i.UserID,
+		&i.Shared,
+	)
+	return &i, err
+}
+
+const getUserCards = `-- name: GetUserCards :many
+SELECT id, name, issuer, last4_digits, expiry_date, default_reward_rate, card_type, statement_day, payment_due_days, predefined_card_key, credit_limit_minor, user_id, shared FROM cards
+WHERE user_id = ?
+ORDER BY name ASC
+`
+
+func (q *Queries) GetUserCards(ctx context.Context, userID *int64) ([]*Card, error) {
+	rows, err := q.query(ctx, q.getUserCardsStmt, getUserCards, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*Card
+	for rows.Next() {
+		var i Card
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Issuer,
+			&i.Last4Digits,
+			&i.ExpiryDate,
+			&i.DefaultRewardRate,
+			&i.CardType,
+			&i.StatementDay,
+			&i.PaymentDueDays,
+			&i.PredefinedCardKey,
+			&i.CreditLimitMinor,
+			&i.UserID,
+			&i.Shared,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWalletBalances = `-- name: GetWalletBalances :many
+SELECT cards.id, cards.name, cards.issuer, cards.last4_digits, cards.expiry_date, cards.default_reward_rate, cards.card_type, cards.statement_day, cards.payment_due_days, cards.predefined_card_key, cards.credit_limit_minor, cards.user_id, cards.shared,
+       users.username,
+       CAST(COALESCE((SELECT SUM(t.amount_minor) FROM transactions t WHERE t.card_id = cards.id), 0) AS INTEGER) AS spent,
+       CAST(COALESCE((SELECT SUM(p.amount_minor) FROM payments p WHERE p.card_id = cards.id), 0) AS INTEGER) AS paid
+FROM cards
+         JOIN users ON users.id = cards.user_id
+WHERE cards.user_id = ?1
+   OR (cards.shared AND cards.user_id IN (SELECT m.user_id
+                                          FROM household_members m
+                                          WHERE m.household_id = (SELECT household_id
+                                                                  FROM household_members
+                                                                  WHERE household_members.user_id = ?1)))
+ORDER BY cards.name ASC
+`
+
+type GetWalletBalancesRow struct {
+	Card     Card   `json:"card"`
+	Username string `json:"username"`
+	Spent    int64  `json:"spent"`
+	Paid     int64  `json:"paid"`
+}
+
+// Outstanding balance of every card of a user and of the cards the other members of their
+// household share, along with the username of the card's holder.
+func (q *Queries) GetWalletBalances(ctx context.Context, userID *int64) ([]*GetWalletBalancesRow, error) {
+	rows, err := q.query(ctx, q.getWalletBalancesStmt, getWalletBalances, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*GetWalletBalancesRow
+	for rows.Next() {
+		var i GetWalletBalancesRow
+		if err := rows.Scan(
+			&i.Card.ID,
+			&i.Card.Name,
+			&i.Card.Issuer,
+			&i.Card.Last4Digits,
+			&i.Card.ExpiryDate,
+			&i.Card.DefaultRewardRate,
+			&i.Card.CardType,
+			&i.Card.StatementDay,
+			&i.Card.PaymentDueDays,
+			&i.Card.PredefinedCardKey,
+			&i.Card.CreditLimitMinor,
+			&i.Card.UserID,
+			&i.Card.Shared,
+			&i.Username,
+			&i.Spent,
+			&i.Paid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateCard = `-- name: UpdateCard :one
 UPDATE cards
 SET name = ?,
@@ -126,19 +392,30 @@ SET name = ?,
     last4_digits = ?,
     expiry_date = ?,
     default_reward_rate = ?,
-    card_type = ?
-WHERE id = ?
-RETURNING id, name, issuer, last4_digits, expiry_date, default_reward_rate, card_type
+    card_type = ?,
+    statement_day = ?,
+    payment_due_days = ?,
+    predefined_card_key = ?,
+    credit_limit_minor = ?,
+    shared = ?
+WHERE id = ? AND user_id = ?
+RETURNING id, name, issuer, last4_digits, expiry_date, default_reward_rate, card_type, statement_day, payment_due_days, predefined_card_key, credit_limit_minor, user_id, shared
 `
 
 type UpdateCardParams struct {
-	Name              string   `json:"name"`
-	Issuer            string   `json:"issuer"`
-	Last4Digits       string   `json:"last4_digits"`
-	ExpiryDate        string   `json:"expiry_date"`
-	DefaultRewardRate *float64 `json:"default_reward_rate"`
-	CardType          string   `json:"card_type"`
-	ID                int64    `json:"id"`
+	Name              string      `json:"name"`
+	Issuer            string      `json:"issuer"`
+	Last4Digits       string      `json:"last4_digits"`
+	ExpiryDate        string      `json:"expiry_date"`
+	DefaultRewardRate *float64    `json:"default_reward_rate"`
+	CardType          string      `json:"card_type"`
+	StatementDay      int64       `json:"statement_day"`
+	PaymentDueDays    int64       `json:"payment_due_days"`
+	PredefinedCardKey *string     `json:"predefined_card_key"`
+	CreditLimitMinor  money.Money `json:"credit_limit"`
+	Shared            bool        `json:"shared"`
+	ID                int64       `json:"id"`
+	UserID            *int64      `json:"user_id"`
 }
 
 func (q *Queries) UpdateCard(ctx context.Context, arg UpdateCardParams) (*Card, error) {
@@ -149,7 +426,13 @@ func (q *Queries) UpdateCard(ctx context.Context, arg UpdateCardParams) (*Card,
 		arg.ExpiryDate,
 		arg.DefaultRewardRate,
 		arg.CardType,
+		arg.StatementDay,
+		arg.PaymentDueDays,
+		arg.PredefinedCardKey,
+		arg.CreditLimitMinor,
+		arg.Shared,
 		arg.ID,
+		arg.UserID,
 	)
 	var i Card
 	err := row.Scan(
@@ -160,6 +443,12 @@ func (q *Queries) UpdateCard(ctx context.Context, arg UpdateCardParams) (*Card,
 		&i.ExpiryDate,
 		&i.DefaultRewardRate,
 		&i.CardType,
+		&i.StatementDay,
+		&i.PaymentDueDays,
+		&i.PredefinedCardKey,
+		&i.CreditLimitMinor,
+		&i.UserID,
+		&i.Shared,
 	)
 	return &i, err
 }
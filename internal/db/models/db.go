@@ -24,43 +24,486 @@ func New(db DBTX) *Queries {
 func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	q := Queries{db: db}
 	var err error
+	if q.addHouseholdMemberStmt, err = db.PrepareContext(ctx, addHouseholdMember); err != nil {
+		return nil, fmt.Errorf("error preparing query AddHouseholdMember: %w", err)
+	}
+	if q.addStatementPaymentStmt, err = db.PrepareContext(ctx, addStatementPayment); err != nil {
+		return nil, fmt.Errorf("error preparing query AddStatementPayment: %w", err)
+	}
+	if q.capAlertExistsStmt, err = db.PrepareContext(ctx, capAlertExists); err != nil {
+		return nil, fmt.Errorf("error preparing query CapAlertExists: %w", err)
+	}
+	if q.claimUnownedCardsStmt, err = db.PrepareContext(ctx, claimUnownedCards); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimUnownedCards: %w", err)
+	}
+	if q.claimUnownedMerchantMappingsStmt, err = db.PrepareContext(ctx, claimUnownedMerchantMappings); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimUnownedMerchantMappings: %w", err)
+	}
+	if q.claimUnownedNotificationPreferencesStmt, err = db.PrepareContext(ctx, claimUnownedNotificationPreferences); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimUnownedNotificationPreferences: %w", err)
+	}
+	if q.claimUnownedPushSubscriptionsStmt, err = db.PrepareContext(ctx, claimUnownedPushSubscriptions); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimUnownedPushSubscriptions: %w", err)
+	}
+	if q.countPushSubscriptionsStmt, err = db.PrepareContext(ctx, countPushSubscriptions); err != nil {
+		return nil, fmt.Errorf("error preparing query CountPushSubscriptions: %w", err)
+	}
+	if q.countUsersStmt, err = db.PrepareContext(ctx, countUsers); err != nil {
+		return nil, fmt.Errorf("error preparing query CountUsers: %w", err)
+	}
+	if q.createAccessTokenStmt, err = db.PrepareContext(ctx, createAccessToken); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAccessToken: %w", err)
+	}
+	if q.createCapAlertStmt, err = db.PrepareContext(ctx, createCapAlert); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateCapAlert: %w", err)
+	}
 	if q.createCardStmt, err = db.PrepareContext(ctx, createCard); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateCard: %w", err)
 	}
+	if q.createCategoryModelStmt, err = db.PrepareContext(ctx, createCategoryModel); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateCategoryModel: %w", err)
+	}
+	if q.createDraftTransactionStmt, err = db.PrepareContext(ctx, createDraftTransaction); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateDraftTransaction: %w", err)
+	}
+	if q.createHouseholdStmt, err = db.PrepareContext(ctx, createHousehold); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateHousehold: %w", err)
+	}
+	if q.createHouseholdInviteStmt, err = db.PrepareContext(ctx, createHouseholdInvite); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateHouseholdInvite: %w", err)
+	}
+	if q.createMailMessageStmt, err = db.PrepareContext(ctx, createMailMessage); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateMailMessage: %w", err)
+	}
+	if q.createNotificationDeliveryStmt, err = db.PrepareContext(ctx, createNotificationDelivery); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateNotificationDelivery: %w", err)
+	}
+	if q.createPasskeyStmt, err = db.PrepareContext(ctx, createPasskey); err != nil {
+		return nil, fmt.Errorf("error preparing query CreatePasskey: %w", err)
+	}
+	if q.createPaymentStmt, err = db.PrepareContext(ctx, createPayment); err != nil {
+		return nil, fmt.Errorf("error preparing query CreatePayment: %w", err)
+	}
 	if q.createPredefinedCardStmt, err = db.PrepareContext(ctx, createPredefinedCard); err != nil {
 		return nil, fmt.Errorf("error preparing query CreatePredefinedCard: %w", err)
 	}
 	if q.createPredefinedRewardRuleStmt, err = db.PrepareContext(ctx, createPredefinedRewardRule); err != nil {
 		return nil, fmt.Errorf("error preparing query CreatePredefinedRewardRule: %w", err)
 	}
+	if q.createSessionStmt, err = db.PrepareContext(ctx, createSession); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateSession: %w", err)
+	}
+	if q.createStatementStmt, err = db.PrepareContext(ctx, createStatement); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateStatement: %w", err)
+	}
+	if q.createStatementImportStmt, err = db.PrepareContext(ctx, createStatementImport); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateStatementImport: %w", err)
+	}
+	if q.createTransactionStmt, err = db.PrepareContext(ctx, createTransaction); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateTransaction: %w", err)
+	}
+	if q.createUserStmt, err = db.PrepareContext(ctx, createUser); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateUser: %w", err)
+	}
+	if q.createVAPIDKeysStmt, err = db.PrepareContext(ctx, createVAPIDKeys); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateVAPIDKeys: %w", err)
+	}
+	if q.createWebauthnCeremonyStmt, err = db.PrepareContext(ctx, createWebauthnCeremony); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateWebauthnCeremony: %w", err)
+	}
+	if q.deleteCategoryModelsBeforeStmt, err = db.PrepareContext(ctx, deleteCategoryModelsBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteCategoryModelsBefore: %w", err)
+	}
+	if q.deleteDraftTransactionStmt, err = db.PrepareContext(ctx, deleteDraftTransaction); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteDraftTransaction: %w", err)
+	}
+	if q.deleteExpiredSessionsStmt, err = db.PrepareContext(ctx, deleteExpiredSessions); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExpiredSessions: %w", err)
+	}
+	if q.deleteExpiredWebauthnCeremoniesStmt, err = db.PrepareContext(ctx, deleteExpiredWebauthnCeremonies); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteExpiredWebauthnCeremonies: %w", err)
+	}
+	if q.deleteHouseholdStmt, err = db.PrepareContext(ctx, deleteHousehold); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteHousehold: %w", err)
+	}
+	if q.deleteHouseholdInviteStmt, err = db.PrepareContext(ctx, deleteHouseholdInvite); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteHouseholdInvite: %w", err)
+	}
+	if q.deleteHouseholdInvitesStmt, err = db.PrepareContext(ctx, deleteHouseholdInvites); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteHouseholdInvites: %w", err)
+	}
+	if q.deleteHouseholdMemberStmt, err = db.PrepareContext(ctx, deleteHouseholdMember); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteHouseholdMember: %w", err)
+	}
+	if q.deleteHouseholdMembersStmt, err = db.PrepareContext(ctx, deleteHouseholdMembers); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteHouseholdMembers: %w", err)
+	}
+	if q.deleteMerchantMappingStmt, err = db.PrepareContext(ctx, deleteMerchantMapping); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteMerchantMapping: %w", err)
+	}
+	if q.deletePushSubscriptionStmt, err = db.PrepareContext(ctx, deletePushSubscription); err != nil {
+		return nil, fmt.Errorf("error preparing query DeletePushSubscription: %w", err)
+	}
+	if q.deleteSessionStmt, err = db.PrepareContext(ctx, deleteSession); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteSession: %w", err)
+	}
+	if q.deleteUserAccessTokenStmt, err = db.PrepareContext(ctx, deleteUserAccessToken); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteUserAccessToken: %w", err)
+	}
+	if q.deleteUserHouseholdInvitesStmt, err = db.PrepareContext(ctx, deleteUserHouseholdInvites); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteUserHouseholdInvites: %w", err)
+	}
+	if q.deleteUserPasskeyStmt, err = db.PrepareContext(ctx, deleteUserPasskey); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteUserPasskey: %w", err)
+	}
+	if q.deleteUserPushSubscriptionStmt, err = db.PrepareContext(ctx, deleteUserPushSubscription); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteUserPushSubscription: %w", err)
+	}
+	if q.getAccessTokenUserStmt, err = db.PrepareContext(ctx, getAccessTokenUser); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAccessTokenUser: %w", err)
+	}
 	if q.getAllCardsStmt, err = db.PrepareContext(ctx, getAllCards); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAllCards: %w", err)
 	}
+	if q.getAllExchangeRatesStmt, err = db.PrepareContext(ctx, getAllExchangeRates); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAllExchangeRates: %w", err)
+	}
 	if q.getAllPredefinedCardsStmt, err = db.PrepareContext(ctx, getAllPredefinedCards); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAllPredefinedCards: %w", err)
 	}
+	if q.getCardBalanceAsOfStmt, err = db.PrepareContext(ctx, getCardBalanceAsOf); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCardBalanceAsOf: %w", err)
+	}
+	if q.getCardBalancesStmt, err = db.PrepareContext(ctx, getCardBalances); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCardBalances: %w", err)
+	}
+	if q.getCardByIDStmt, err = db.PrepareContext(ctx, getCardByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCardByID: %w", err)
+	}
 	if q.getCardByNameAndIssuerStmt, err = db.PrepareContext(ctx, getCardByNameAndIssuer); err != nil {
 		return nil, fmt.Errorf("error preparing query GetCardByNameAndIssuer: %w", err)
 	}
+	if q.getCardDraftTransactionsBetweenStmt, err = db.PrepareContext(ctx, getCardDraftTransactionsBetween); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCardDraftTransactionsBetween: %w", err)
+	}
+	if q.getCardPaymentsBetweenStmt, err = db.PrepareContext(ctx, getCardPaymentsBetween); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCardPaymentsBetween: %w", err)
+	}
+	if q.getCardTransactionsBetweenStmt, err = db.PrepareContext(ctx, getCardTransactionsBetween); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCardTransactionsBetween: %w", err)
+	}
+	if q.getCategorisedSpendsStmt, err = db.PrepareContext(ctx, getCategorisedSpends); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCategorisedSpends: %w", err)
+	}
+	if q.getDraftTransactionByIDStmt, err = db.PrepareContext(ctx, getDraftTransactionByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDraftTransactionByID: %w", err)
+	}
+	if q.getDraftTransactionsStmt, err = db.PrepareContext(ctx, getDraftTransactions); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDraftTransactions: %w", err)
+	}
+	if q.getDraftTransactionsByStatementImportIDStmt, err = db.PrepareContext(ctx, getDraftTransactionsByStatementImportID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDraftTransactionsByStatementImportID: %w", err)
+	}
+	if q.getDueNotificationDeliveriesStmt, err = db.PrepareContext(ctx, getDueNotificationDeliveries); err != nil {
+		return nil, fmt.Errorf("error preparing query GetDueNotificationDeliveries: %w", err)
+	}
+	if q.getExchangeRateStmt, err = db.PrepareContext(ctx, getExchangeRate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetExchangeRate: %w", err)
+	}
+	if q.getFirstTransactionDateStmt, err = db.PrepareContext(ctx, getFirstTransactionDate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFirstTransactionDate: %w", err)
+	}
+	if q.getFirstUserStmt, err = db.PrepareContext(ctx, getFirstUser); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFirstUser: %w", err)
+	}
+	if q.getHouseholdStmt, err = db.PrepareContext(ctx, getHousehold); err != nil {
+		return nil, fmt.Errorf("error preparing query GetHousehold: %w", err)
+	}
+	if q.getHouseholdCardsStmt, err = db.PrepareContext(ctx, getHouseholdCards); err != nil {
+		return nil, fmt.Errorf("error preparing query GetHouseholdCards: %w", err)
+	}
+	if q.getHouseholdInvitesStmt, err = db.PrepareContext(ctx, getHouseholdInvites); err != nil {
+		return nil, fmt.Errorf("error preparing query GetHouseholdInvites: %w", err)
+	}
+	if q.getHouseholdLedgerStmt, err = db.PrepareContext(ctx, getHouseholdLedger); err != nil {
+		return nil, fmt.Errorf("error preparing query GetHouseholdLedger: %w", err)
+	}
+	if q.getHouseholdMembersStmt, err = db.PrepareContext(ctx, getHouseholdMembers); err != nil {
+		return nil, fmt.Errorf("error preparing query GetHouseholdMembers: %w", err)
+	}
+	if q.getLatestCategoryModelStmt, err = db.PrepareContext(ctx, getLatestCategoryModel); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLatestCategoryModel: %w", err)
+	}
+	if q.getLatestCategoryModelIDStmt, err = db.PrepareContext(ctx, getLatestCategoryModelID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLatestCategoryModelID: %w", err)
+	}
+	if q.getLatestStatementStmt, err = db.PrepareContext(ctx, getLatestStatement); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLatestStatement: %w", err)
+	}
+	if q.getMailCheckpointStmt, err = db.PrepareContext(ctx, getMailCheckpoint); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMailCheckpoint: %w", err)
+	}
+	if q.getMailMessagesStmt, err = db.PrepareContext(ctx, getMailMessages); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMailMessages: %w", err)
+	}
+	if q.getMerchantMappingsStmt, err = db.PrepareContext(ctx, getMerchantMappings); err != nil {
+		return nil, fmt.Errorf("error preparing query GetMerchantMappings: %w", err)
+	}
+	if q.getNotificationDeliveriesStmt, err = db.PrepareContext(ctx, getNotificationDeliveries); err != nil {
+		return nil, fmt.Errorf("error preparing query GetNotificationDeliveries: %w", err)
+	}
+	if q.getNotificationPreferencesStmt, err = db.PrepareContext(ctx, getNotificationPreferences); err != nil {
+		return nil, fmt.Errorf("error preparing query GetNotificationPreferences: %w", err)
+	}
+	if q.getOldestUnpaidStatementStmt, err = db.PrepareContext(ctx, getOldestUnpaidStatement); err != nil {
+		return nil, fmt.Errorf("error preparing query GetOldestUnpaidStatement: %w", err)
+	}
+	if q.getPaymentsStmt, err = db.PrepareContext(ctx, getPayments); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPayments: %w", err)
+	}
+	if q.getPaymentsByCardIDStmt, err = db.PrepareContext(ctx, getPaymentsByCardID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPaymentsByCardID: %w", err)
+	}
 	if q.getPredefinedCardByKeyStmt, err = db.PrepareContext(ctx, getPredefinedCardByKey); err != nil {
 		return nil, fmt.Errorf("error preparing query GetPredefinedCardByKey: %w", err)
 	}
 	if q.getPredefinedRewardRulesByCardIDStmt, err = db.PrepareContext(ctx, getPredefinedRewardRulesByCardID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetPredefinedRewardRulesByCardID: %w", err)
 	}
+	if q.getPushSubscriptionsStmt, err = db.PrepareContext(ctx, getPushSubscriptions); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPushSubscriptions: %w", err)
+	}
+	if q.getSessionUserStmt, err = db.PrepareContext(ctx, getSessionUser); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSessionUser: %w", err)
+	}
+	if q.getStatementByIDStmt, err = db.PrepareContext(ctx, getStatementByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetStatementByID: %w", err)
+	}
+	if q.getStatementImportByIDStmt, err = db.PrepareContext(ctx, getStatementImportByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetStatementImportByID: %w", err)
+	}
+	if q.getStatementImportsStmt, err = db.PrepareContext(ctx, getStatementImports); err != nil {
+		return nil, fmt.Errorf("error preparing query GetStatementImports: %w", err)
+	}
+	if q.getStatementsStmt, err = db.PrepareContext(ctx, getStatements); err != nil {
+		return nil, fmt.Errorf("error preparing query GetStatements: %w", err)
+	}
+	if q.getStatementsByCardIDStmt, err = db.PrepareContext(ctx, getStatementsByCardID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetStatementsByCardID: %w", err)
+	}
+	if q.getStatementsToRemindStmt, err = db.PrepareContext(ctx, getStatementsToRemind); err != nil {
+		return nil, fmt.Errorf("error preparing query GetStatementsToRemind: %w", err)
+	}
+	if q.getTransactionsStmt, err = db.PrepareContext(ctx, getTransactions); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransactions: %w", err)
+	}
+	if q.getTransactionsByCardIDStmt, err = db.PrepareContext(ctx, getTransactionsByCardID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetTransactionsByCardID: %w", err)
+	}
+	if q.getUserAccessTokensStmt, err = db.PrepareContext(ctx, getUserAccessTokens); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserAccessTokens: %w", err)
+	}
+	if q.getUserByIDStmt, err = db.PrepareContext(ctx, getUserByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserByID: %w", err)
+	}
+	if q.getUserByUsernameStmt, err = db.PrepareContext(ctx, getUserByUsername); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserByUsername: %w", err)
+	}
+	if q.getUserCardByIDStmt, err = db.PrepareContext(ctx, getUserCardByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserCardByID: %w", err)
+	}
+	if q.getUserCardsStmt, err = db.PrepareContext(ctx, getUserCards); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserCards: %w", err)
+	}
+	if q.getUserDraftTransactionsStmt, err = db.PrepareContext(ctx, getUserDraftTransactions); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserDraftTransactions: %w", err)
+	}
+	if q.getUserHouseholdStmt, err = db.PrepareContext(ctx, getUserHousehold); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserHousehold: %w", err)
+	}
+	if q.getUserHouseholdInviteStmt, err = db.PrepareContext(ctx, getUserHouseholdInvite); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserHouseholdInvite: %w", err)
+	}
+	if q.getUserHouseholdInvitesStmt, err = db.PrepareContext(ctx, getUserHouseholdInvites); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserHouseholdInvites: %w", err)
+	}
+	if q.getUserMerchantMappingsStmt, err = db.PrepareContext(ctx, getUserMerchantMappings); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserMerchantMappings: %w", err)
+	}
+	if q.getUserPasskeysStmt, err = db.PrepareContext(ctx, getUserPasskeys); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserPasskeys: %w", err)
+	}
+	if q.getUserStatementByIDStmt, err = db.PrepareContext(ctx, getUserStatementByID); err != nil {
+		return nil, fmt.Errorf("error preparing query GetUserStatementByID: %w", err)
+	}
+	if q.getVAPIDKeysStmt, err = db.PrepareContext(ctx, getVAPIDKeys); err != nil {
+		return nil, fmt.Errorf("error preparing query GetVAPIDKeys: %w", err)
+	}
+	if q.getWalletBalancesStmt, err = db.PrepareContext(ctx, getWalletBalances); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWalletBalances: %w", err)
+	}
+	if q.isHouseholdMemberStmt, err = db.PrepareContext(ctx, isHouseholdMember); err != nil {
+		return nil, fmt.Errorf("error preparing query IsHouseholdMember: %w", err)
+	}
+	if q.isMailMessageProcessedStmt, err = db.PrepareContext(ctx, isMailMessageProcessed); err != nil {
+		return nil, fmt.Errorf("error preparing query IsMailMessageProcessed: %w", err)
+	}
+	if q.markNotificationAttemptFailedStmt, err = db.PrepareContext(ctx, markNotificationAttemptFailed); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkNotificationAttemptFailed: %w", err)
+	}
+	if q.markNotificationDeliveredStmt, err = db.PrepareContext(ctx, markNotificationDelivered); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkNotificationDelivered: %w", err)
+	}
+	if q.markStatementRemindedStmt, err = db.PrepareContext(ctx, markStatementReminded); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkStatementReminded: %w", err)
+	}
+	if q.mergeDraftTransactionStmt, err = db.PrepareContext(ctx, mergeDraftTransaction); err != nil {
+		return nil, fmt.Errorf("error preparing query MergeDraftTransaction: %w", err)
+	}
+	if q.paymentExternalIDExistsStmt, err = db.PrepareContext(ctx, paymentExternalIDExists); err != nil {
+		return nil, fmt.Errorf("error preparing query PaymentExternalIDExists: %w", err)
+	}
+	if q.takeWebauthnCeremonyStmt, err = db.PrepareContext(ctx, takeWebauthnCeremony); err != nil {
+		return nil, fmt.Errorf("error preparing query TakeWebauthnCeremony: %w", err)
+	}
+	if q.touchAccessTokenStmt, err = db.PrepareContext(ctx, touchAccessToken); err != nil {
+		return nil, fmt.Errorf("error preparing query TouchAccessToken: %w", err)
+	}
+	if q.transactionExternalIDExistsStmt, err = db.PrepareContext(ctx, transactionExternalIDExists); err != nil {
+		return nil, fmt.Errorf("error preparing query TransactionExternalIDExists: %w", err)
+	}
 	if q.updateCardStmt, err = db.PrepareContext(ctx, updateCard); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateCard: %w", err)
 	}
+	if q.updateDraftSuggestionStmt, err = db.PrepareContext(ctx, updateDraftSuggestion); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateDraftSuggestion: %w", err)
+	}
+	if q.updateDraftTransactionStmt, err = db.PrepareContext(ctx, updateDraftTransaction); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateDraftTransaction: %w", err)
+	}
+	if q.updatePasskeyUseStmt, err = db.PrepareContext(ctx, updatePasskeyUse); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdatePasskeyUse: %w", err)
+	}
+	if q.upsertExchangeRateStmt, err = db.PrepareContext(ctx, upsertExchangeRate); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertExchangeRate: %w", err)
+	}
+	if q.upsertMailCheckpointStmt, err = db.PrepareContext(ctx, upsertMailCheckpoint); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertMailCheckpoint: %w", err)
+	}
+	if q.upsertMerchantMappingStmt, err = db.PrepareContext(ctx, upsertMerchantMapping); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertMerchantMapping: %w", err)
+	}
+	if q.upsertNotificationPreferenceStmt, err = db.PrepareContext(ctx, upsertNotificationPreference); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertNotificationPreference: %w", err)
+	}
+	if q.upsertPushSubscriptionStmt, err = db.PrepareContext(ctx, upsertPushSubscription); err != nil {
+		return nil, fmt.Errorf("error preparing query UpsertPushSubscription: %w", err)
+	}
 	return &q, nil
 }
 
 func (q *Queries) Close() error {
 	var err error
+	if q.addHouseholdMemberStmt != nil {
+		if cerr := q.addHouseholdMemberStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addHouseholdMemberStmt: %w", cerr)
+		}
+	}
+	if q.addStatementPaymentStmt != nil {
+		if cerr := q.addStatementPaymentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing addStatementPaymentStmt: %w", cerr)
+		}
+	}
+	if q.capAlertExistsStmt != nil {
+		if cerr := q.capAlertExistsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing capAlertExistsStmt: %w", cerr)
+		}
+	}
+	if q.claimUnownedCardsStmt != nil {
+		if cerr := q.claimUnownedCardsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimUnownedCardsStmt: %w", cerr)
+		}
+	}
+	if q.claimUnownedMerchantMappingsStmt != nil {
+		if cerr := q.claimUnownedMerchantMappingsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimUnownedMerchantMappingsStmt: %w", cerr)
+		}
+	}
+	if q.claimUnownedNotificationPreferencesStmt != nil {
+		if cerr := q.claimUnownedNotificationPreferencesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimUnownedNotificationPreferencesStmt: %w", cerr)
+		}
+	}
+	if q.claimUnownedPushSubscriptionsStmt != nil {
+		if cerr := q.claimUnownedPushSubscriptionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimUnownedPushSubscriptionsStmt: %w", cerr)
+		}
+	}
+	if q.countPushSubscriptionsStmt != nil {
+		if cerr := q.countPushSubscriptionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countPushSubscriptionsStmt: %w", cerr)
+		}
+	}
+	if q.countUsersStmt != nil {
+		if cerr := q.countUsersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing countUsersStmt: %w", cerr)
+		}
+	}
+	if q.createAccessTokenStmt != nil {
+		if cerr := q.createAccessTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAccessTokenStmt: %w", cerr)
+		}
+	}
+	if q.createCapAlertStmt != nil {
+		if cerr := q.createCapAlertStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createCapAlertStmt: %w", cerr)
+		}
+	}
 	if q.createCardStmt != nil {
 		if cerr := q.createCardStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createCardStmt: %w", cerr)
 		}
 	}
+	if q.createCategoryModelStmt != nil {
+		if cerr := q.createCategoryModelStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createCategoryModelStmt: %w", cerr)
+		}
+	}
+	if q.createDraftTransactionStmt != nil {
+		if cerr := q.createDraftTransactionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createDraftTransactionStmt: %w", cerr)
+		}
+	}
+	if q.createHouseholdStmt != nil {
+		if cerr := q.createHouseholdStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createHouseholdStmt: %w", cerr)
+		}
+	}
+	if q.createHouseholdInviteStmt != nil {
+		if cerr := q.createHouseholdInviteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createHouseholdInviteStmt: %w", cerr)
+		}
+	}
+	if q.createMailMessageStmt != nil {
+		if cerr := q.createMailMessageStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createMailMessageStmt: %w", cerr)
+		}
+	}
+	if q.createNotificationDeliveryStmt != nil {
+		if cerr := q.createNotificationDeliveryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createNotificationDeliveryStmt: %w", cerr)
+		}
+	}
+	if q.createPasskeyStmt != nil {
+		if cerr := q.createPasskeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createPasskeyStmt: %w", cerr)
+		}
+	}
+	if q.createPaymentStmt != nil {
+		if cerr := q.createPaymentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createPaymentStmt: %w", cerr)
+		}
+	}
 	if q.createPredefinedCardStmt != nil {
 		if cerr := q.createPredefinedCardStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createPredefinedCardStmt: %w", cerr)
@@ -71,21 +514,296 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createPredefinedRewardRuleStmt: %w", cerr)
 		}
 	}
+	if q.createSessionStmt != nil {
+		if cerr := q.createSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createSessionStmt: %w", cerr)
+		}
+	}
+	if q.createStatementStmt != nil {
+		if cerr := q.createStatementStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createStatementStmt: %w", cerr)
+		}
+	}
+	if q.createStatementImportStmt != nil {
+		if cerr := q.createStatementImportStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createStatementImportStmt: %w", cerr)
+		}
+	}
+	if q.createTransactionStmt != nil {
+		if cerr := q.createTransactionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createTransactionStmt: %w", cerr)
+		}
+	}
+	if q.createUserStmt != nil {
+		if cerr := q.createUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createUserStmt: %w", cerr)
+		}
+	}
+	if q.createVAPIDKeysStmt != nil {
+		if cerr := q.createVAPIDKeysStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createVAPIDKeysStmt: %w", cerr)
+		}
+	}
+	if q.createWebauthnCeremonyStmt != nil {
+		if cerr := q.createWebauthnCeremonyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createWebauthnCeremonyStmt: %w", cerr)
+		}
+	}
+	if q.deleteCategoryModelsBeforeStmt != nil {
+		if cerr := q.deleteCategoryModelsBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteCategoryModelsBeforeStmt: %w", cerr)
+		}
+	}
+	if q.deleteDraftTransactionStmt != nil {
+		if cerr := q.deleteDraftTransactionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteDraftTransactionStmt: %w", cerr)
+		}
+	}
+	if q.deleteExpiredSessionsStmt != nil {
+		if cerr := q.deleteExpiredSessionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExpiredSessionsStmt: %w", cerr)
+		}
+	}
+	if q.deleteExpiredWebauthnCeremoniesStmt != nil {
+		if cerr := q.deleteExpiredWebauthnCeremoniesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteExpiredWebauthnCeremoniesStmt: %w", cerr)
+		}
+	}
+	if q.deleteHouseholdStmt != nil {
+		if cerr := q.deleteHouseholdStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteHouseholdStmt: %w", cerr)
+		}
+	}
+	if q.deleteHouseholdInviteStmt != nil {
+		if cerr := q.deleteHouseholdInviteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteHouseholdInviteStmt: %w", cerr)
+		}
+	}
+	if q.deleteHouseholdInvitesStmt != nil {
+		if cerr := q.deleteHouseholdInvitesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteHouseholdInvitesStmt: %w", cerr)
+		}
+	}
+	if q.deleteHouseholdMemberStmt != nil {
+		if cerr := q.deleteHouseholdMemberStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteHouseholdMemberStmt: %w", cerr)
+		}
+	}
+	if q.deleteHouseholdMembersStmt != nil {
+		if cerr := q.deleteHouseholdMembersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteHouseholdMembersStmt: %w", cerr)
+		}
+	}
+	if q.deleteMerchantMappingStmt != nil {
+		if cerr := q.deleteMerchantMappingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteMerchantMappingStmt: %w", cerr)
+		}
+	}
+	if q.deletePushSubscriptionStmt != nil {
+		if cerr := q.deletePushSubscriptionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deletePushSubscriptionStmt: %w", cerr)
+		}
+	}
+	if q.deleteSessionStmt != nil {
+		if cerr := q.deleteSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteSessionStmt: %w", cerr)
+		}
+	}
+	if q.deleteUserAccessTokenStmt != nil {
+		if cerr := q.deleteUserAccessTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteUserAccessTokenStmt: %w", cerr)
+		}
+	}
+	if q.deleteUserHouseholdInvitesStmt != nil {
+		if cerr := q.deleteUserHouseholdInvitesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteUserHouseholdInvitesStmt: %w", cerr)
+		}
+	}
+	if q.deleteUserPasskeyStmt != nil {
+		if cerr := q.deleteUserPasskeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteUserPasskeyStmt: %w", cerr)
+		}
+	}
+	if q.deleteUserPushSubscriptionStmt != nil {
+		if cerr := q.deleteUserPushSubscriptionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteUserPushSubscriptionStmt: %w", cerr)
+		}
+	}
+	if q.getAccessTokenUserStmt != nil {
+		if cerr := q.getAccessTokenUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAccessTokenUserStmt: %w", cerr)
+		}
+	}
 	if q.getAllCardsStmt != nil {
 		if cerr := q.getAllCardsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getAllCardsStmt: %w", cerr)
 		}
 	}
+	if q.getAllExchangeRatesStmt != nil {
+		if cerr := q.getAllExchangeRatesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAllExchangeRatesStmt: %w", cerr)
+		}
+	}
 	if q.getAllPredefinedCardsStmt != nil {
 		if cerr := q.getAllPredefinedCardsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getAllPredefinedCardsStmt: %w", cerr)
 		}
 	}
+	if q.getCardBalanceAsOfStmt != nil {
+		if cerr := q.getCardBalanceAsOfStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCardBalanceAsOfStmt: %w", cerr)
+		}
+	}
+	if q.getCardBalancesStmt != nil {
+		if cerr := q.getCardBalancesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCardBalancesStmt: %w", cerr)
+		}
+	}
+	if q.getCardByIDStmt != nil {
+		if cerr := q.getCardByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCardByIDStmt: %w", cerr)
+		}
+	}
 	if q.getCardByNameAndIssuerStmt != nil {
 		if cerr := q.getCardByNameAndIssuerStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getCardByNameAndIssuerStmt: %w", cerr)
 		}
 	}
+	if q.getCardDraftTransactionsBetweenStmt != nil {
+		if cerr := q.getCardDraftTransactionsBetweenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCardDraftTransactionsBetweenStmt: %w", cerr)
+		}
+	}
+	if q.getCardPaymentsBetweenStmt != nil {
+		if cerr := q.getCardPaymentsBetweenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCardPaymentsBetweenStmt: %w", cerr)
+		}
+	}
+	if q.getCardTransactionsBetweenStmt != nil {
+		if cerr := q.getCardTransactionsBetweenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCardTransactionsBetweenStmt: %w", cerr)
+		}
+	}
+	if q.getCategorisedSpendsStmt != nil {
+		if cerr := q.getCategorisedSpendsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCategorisedSpendsStmt: %w", cerr)
+		}
+	}
+	if q.getDraftTransactionByIDStmt != nil {
+		if cerr := q.getDraftTransactionByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDraftTransactionByIDStmt: %w", cerr)
+		}
+	}
+	if q.getDraftTransactionsStmt != nil {
+		if cerr := q.getDraftTransactionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDraftTransactionsStmt: %w", cerr)
+		}
+	}
+	if q.getDraftTransactionsByStatementImportIDStmt != nil {
+		if cerr := q.getDraftTransactionsByStatementImportIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDraftTransactionsByStatementImportIDStmt: %w", cerr)
+		}
+	}
+	if q.getDueNotificationDeliveriesStmt != nil {
+		if cerr := q.getDueNotificationDeliveriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getDueNotificationDeliveriesStmt: %w", cerr)
+		}
+	}
+	if q.getExchangeRateStmt != nil {
+		if cerr := q.getExchangeRateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getExchangeRateStmt: %w", cerr)
+		}
+	}
+	if q.getFirstTransactionDateStmt != nil {
+		if cerr := q.getFirstTransactionDateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFirstTransactionDateStmt: %w", cerr)
+		}
+	}
+	if q.getFirstUserStmt != nil {
+		if cerr := q.getFirstUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFirstUserStmt: %w", cerr)
+		}
+	}
+	if q.getHouseholdStmt != nil {
+		if cerr := q.getHouseholdStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getHouseholdStmt: %w", cerr)
+		}
+	}
+	if q.getHouseholdCardsStmt != nil {
+		if cerr := q.getHouseholdCardsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getHouseholdCardsStmt: %w", cerr)
+		}
+	}
+	if q.getHouseholdInvitesStmt != nil {
+		if cerr := q.getHouseholdInvitesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getHouseholdInvitesStmt: %w", cerr)
+		}
+	}
+	if q.getHouseholdLedgerStmt != nil {
+		if cerr := q.getHouseholdLedgerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getHouseholdLedgerStmt: %w", cerr)
+		}
+	}
+	if q.getHouseholdMembersStmt != nil {
+		if cerr := q.getHouseholdMembersStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getHouseholdMembersStmt: %w", cerr)
+		}
+	}
+	if q.getLatestCategoryModelStmt != nil {
+		if cerr := q.getLatestCategoryModelStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLatestCategoryModelStmt: %w", cerr)
+		}
+	}
+	if q.getLatestCategoryModelIDStmt != nil {
+		if cerr := q.getLatestCategoryModelIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLatestCategoryModelIDStmt: %w", cerr)
+		}
+	}
+	if q.getLatestStatementStmt != nil {
+		if cerr := q.getLatestStatementStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLatestStatementStmt: %w", cerr)
+		}
+	}
+	if q.getMailCheckpointStmt != nil {
+		if cerr := q.getMailCheckpointStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMailCheckpointStmt: %w", cerr)
+		}
+	}
+	if q.getMailMessagesStmt != nil {
+		if cerr := q.getMailMessagesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMailMessagesStmt: %w", cerr)
+		}
+	}
+	if q.getMerchantMappingsStmt != nil {
+		if cerr := q.getMerchantMappingsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getMerchantMappingsStmt: %w", cerr)
+		}
+	}
+	if q.getNotificationDeliveriesStmt != nil {
+		if cerr := q.getNotificationDeliveriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getNotificationDeliveriesStmt: %w", cerr)
+		}
+	}
+	if q.getNotificationPreferencesStmt != nil {
+		if cerr := q.getNotificationPreferencesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getNotificationPreferencesStmt: %w", cerr)
+		}
+	}
+	if q.getOldestUnpaidStatementStmt != nil {
+		if cerr := q.getOldestUnpaidStatementStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getOldestUnpaidStatementStmt: %w", cerr)
+		}
+	}
+	if q.getPaymentsStmt != nil {
+		if cerr := q.getPaymentsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPaymentsStmt: %w", cerr)
+		}
+	}
+	if q.getPaymentsByCardIDStmt != nil {
+		if cerr := q.getPaymentsByCardIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPaymentsByCardIDStmt: %w", cerr)
+		}
+	}
 	if q.getPredefinedCardByKeyStmt != nil {
 		if cerr := q.getPredefinedCardByKeyStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getPredefinedCardByKeyStmt: %w", cerr)
@@ -96,11 +814,221 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getPredefinedRewardRulesByCardIDStmt: %w", cerr)
 		}
 	}
+	if q.getPushSubscriptionsStmt != nil {
+		if cerr := q.getPushSubscriptionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPushSubscriptionsStmt: %w", cerr)
+		}
+	}
+	if q.getSessionUserStmt != nil {
+		if cerr := q.getSessionUserStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSessionUserStmt: %w", cerr)
+		}
+	}
+	if q.getStatementByIDStmt != nil {
+		if cerr := q.getStatementByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getStatementByIDStmt: %w", cerr)
+		}
+	}
+	if q.getStatementImportByIDStmt != nil {
+		if cerr := q.getStatementImportByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getStatementImportByIDStmt: %w", cerr)
+		}
+	}
+	if q.getStatementImportsStmt != nil {
+		if cerr := q.getStatementImportsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getStatementImportsStmt: %w", cerr)
+		}
+	}
+	if q.getStatementsStmt != nil {
+		if cerr := q.getStatementsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getStatementsStmt: %w", cerr)
+		}
+	}
+	if q.getStatementsByCardIDStmt != nil {
+		if cerr := q.getStatementsByCardIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getStatementsByCardIDStmt: %w", cerr)
+		}
+	}
+	if q.getStatementsToRemindStmt != nil {
+		if cerr := q.getStatementsToRemindStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getStatementsToRemindStmt: %w", cerr)
+		}
+	}
+	if q.getTransactionsStmt != nil {
+		if cerr := q.getTransactionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransactionsStmt: %w", cerr)
+		}
+	}
+	if q.getTransactionsByCardIDStmt != nil {
+		if cerr := q.getTransactionsByCardIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getTransactionsByCardIDStmt: %w", cerr)
+		}
+	}
+	if q.getUserAccessTokensStmt != nil {
+		if cerr := q.getUserAccessTokensStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserAccessTokensStmt: %w", cerr)
+		}
+	}
+	if q.getUserByIDStmt != nil {
+		if cerr := q.getUserByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserByIDStmt: %w", cerr)
+		}
+	}
+	if q.getUserByUsernameStmt != nil {
+		if cerr := q.getUserByUsernameStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserByUsernameStmt: %w", cerr)
+		}
+	}
+	if q.getUserCardByIDStmt != nil {
+		if cerr := q.getUserCardByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserCardByIDStmt: %w", cerr)
+		}
+	}
+	if q.getUserCardsStmt != nil {
+		if cerr := q.getUserCardsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserCardsStmt: %w", cerr)
+		}
+	}
+	if q.getUserDraftTransactionsStmt != nil {
+		if cerr := q.getUserDraftTransactionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserDraftTransactionsStmt: %w", cerr)
+		}
+	}
+	if q.getUserHouseholdStmt != nil {
+		if cerr := q.getUserHouseholdStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserHouseholdStmt: %w", cerr)
+		}
+	}
+	if q.getUserHouseholdInviteStmt != nil {
+		if cerr := q.getUserHouseholdInviteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserHouseholdInviteStmt: %w", cerr)
+		}
+	}
+	if q.getUserHouseholdInvitesStmt != nil {
+		if cerr := q.getUserHouseholdInvitesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserHouseholdInvitesStmt: %w", cerr)
+		}
+	}
+	if q.getUserMerchantMappingsStmt != nil {
+		if cerr := q.getUserMerchantMappingsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserMerchantMappingsStmt: %w", cerr)
+		}
+	}
+	if q.getUserPasskeysStmt != nil {
+		if cerr := q.getUserPasskeysStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserPasskeysStmt: %w", cerr)
+		}
+	}
+	if q.getUserStatementByIDStmt != nil {
+		if cerr := q.getUserStatementByIDStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getUserStatementByIDStmt: %w", cerr)
+		}
+	}
+	if q.getVAPIDKeysStmt != nil {
+		if cerr := q.getVAPIDKeysStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getVAPIDKeysStmt: %w", cerr)
+		}
+	}
+	if q.getWalletBalancesStmt != nil {
+		if cerr := q.getWalletBalancesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWalletBalancesStmt: %w", cerr)
+		}
+	}
+	if q.isHouseholdMemberStmt != nil {
+		if cerr := q.isHouseholdMemberStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing isHouseholdMemberStmt: %w", cerr)
+		}
+	}
+	if q.isMailMessageProcessedStmt != nil {
+		if cerr := q.isMailMessageProcessedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing isMailMessageProcessedStmt: %w", cerr)
+		}
+	}
+	if q.markNotificationAttemptFailedStmt != nil {
+		if cerr := q.markNotificationAttemptFailedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markNotificationAttemptFailedStmt: %w", cerr)
+		}
+	}
+	if q.markNotificationDeliveredStmt != nil {
+		if cerr := q.markNotificationDeliveredStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markNotificationDeliveredStmt: %w", cerr)
+		}
+	}
+	if q.markStatementRemindedStmt != nil {
+		if cerr := q.markStatementRemindedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markStatementRemindedStmt: %w", cerr)
+		}
+	}
+	if q.mergeDraftTransactionStmt != nil {
+		if cerr := q.mergeDraftTransactionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing mergeDraftTransactionStmt: %w", cerr)
+		}
+	}
+	if q.paymentExternalIDExistsStmt != nil {
+		if cerr := q.paymentExternalIDExistsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing paymentExternalIDExistsStmt: %w", cerr)
+		}
+	}
+	if q.takeWebauthnCeremonyStmt != nil {
+		if cerr := q.takeWebauthnCeremonyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing takeWebauthnCeremonyStmt: %w", cerr)
+		}
+	}
+	if q.touchAccessTokenStmt != nil {
+		if cerr := q.touchAccessTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing touchAccessTokenStmt: %w", cerr)
+		}
+	}
+	if q.transactionExternalIDExistsStmt != nil {
+		if cerr := q.transactionExternalIDExistsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing transactionExternalIDExistsStmt: %w", cerr)
+		}
+	}
 	if q.updateCardStmt != nil {
 		if cerr := q.updateCardStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateCardStmt: %w", cerr)
 		}
 	}
+	if q.updateDraftSuggestionStmt != nil {
+		if cerr := q.updateDraftSuggestionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateDraftSuggestionStmt: %w", cerr)
+		}
+	}
+	if q.updateDraftTransactionStmt != nil {
+		if cerr := q.updateDraftTransactionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateDraftTransactionStmt: %w", cerr)
+		}
+	}
+	if q.updatePasskeyUseStmt != nil {
+		if cerr := q.updatePasskeyUseStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updatePasskeyUseStmt: %w", cerr)
+		}
+	}
+	if q.upsertExchangeRateStmt != nil {
+		if cerr := q.upsertExchangeRateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertExchangeRateStmt: %w", cerr)
+		}
+	}
+	if q.upsertMailCheckpointStmt != nil {
+		if cerr := q.upsertMailCheckpointStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertMailCheckpointStmt: %w", cerr)
+		}
+	}
+	if q.upsertMerchantMappingStmt != nil {
+		if cerr := q.upsertMerchantMappingStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertMerchantMappingStmt: %w", cerr)
+		}
+	}
+	if q.upsertNotificationPreferenceStmt != nil {
+		if cerr := q.upsertNotificationPreferenceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertNotificationPreferenceStmt: %w", cerr)
+		}
+	}
+	if q.upsertPushSubscriptionStmt != nil {
+		if cerr := q.upsertPushSubscriptionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing upsertPushSubscriptionStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -138,31 +1066,263 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                                   DBTX
-	tx                                   *sql.Tx
-	createCardStmt                       *sql.Stmt
-	createPredefinedCardStmt             *sql.Stmt
-	createPredefinedRewardRuleStmt       *sql.Stmt
-	getAllCardsStmt                      *sql.Stmt
-	getAllPredefinedCardsStmt            *sql.Stmt
-	getCardByNameAndIssuerStmt           *sql.Stmt
-	getPredefinedCardByKeyStmt           *sql.Stmt
-	getPredefinedRewardRulesByCardIDStmt *sql.Stmt
-	updateCardStmt                       *sql.Stmt
+	db                                          DBTX
+	tx                                          *sql.Tx
+	addHouseholdMemberStmt                      *sql.Stmt
+	addStatementPaymentStmt                     *sql.Stmt
+	capAlertExistsStmt                          *sql.Stmt
+	claimUnownedCardsStmt                       *sql.Stmt
+	claimUnownedMerchantMappingsStmt            *sql.Stmt
+	claimUnownedNotificationPreferencesStmt     *sql.Stmt
+	claimUnownedPushSubscriptionsStmt           *sql.Stmt
+	countPushSubscriptionsStmt                  *sql.Stmt
+	countUsersStmt                              *sql.Stmt
+	createAccessTokenStmt                       *sql.Stmt
+	createCapAlertStmt                          *sql.Stmt
+	createCardStmt                              *sql.Stmt
+	createCategoryModelStmt                     *sql.Stmt
+	createDraftTransactionStmt                  *sql.Stmt
+	createHouseholdStmt                         *sql.Stmt
+	createHouseholdInviteStmt                   *sql.Stmt
+	createMailMessageStmt                       *sql.Stmt
+	createNotificationDeliveryStmt              *sql.Stmt
+	createPasskeyStmt                           *sql.Stmt
+	createPaymentStmt                           *sql.Stmt
+	createPredefinedCardStmt                    *sql.Stmt
+	createPredefinedRewardRuleStmt              *sql.Stmt
+	createSessionStmt                           *sql.Stmt
+	createStatementStmt                         *sql.Stmt
+	createStatementImportStmt                   *sql.Stmt
+	createTransactionStmt                       *sql.Stmt
+	createUserStmt                              *sql.Stmt
+	createVAPIDKeysStmt                         *sql.Stmt
+	createWebauthnCeremonyStmt                  *sql.Stmt
+	deleteCategoryModelsBeforeStmt              *sql.Stmt
+	deleteDraftTransactionStmt                  *sql.Stmt
+	deleteExpiredSessionsStmt                   *sql.Stmt
+	deleteExpiredWebauthnCeremoniesStmt         *sql.Stmt
+	deleteHouseholdStmt                         *sql.Stmt
+	deleteHouseholdInviteStmt                   *sql.Stmt
+	deleteHouseholdInvitesStmt                  *sql.Stmt
+	deleteHouseholdMemberStmt                   *sql.Stmt
+	deleteHouseholdMembersStmt                  *sql.Stmt
+	deleteMerchantMappingStmt                   *sql.Stmt
+	deletePushSubscriptionStmt                  *sql.Stmt
+	deleteSessionStmt                           *sql.Stmt
+	deleteUserAccessTokenStmt                   *sql.Stmt
+	deleteUserHouseholdInvitesStmt              *sql.Stmt
+	deleteUserPasskeyStmt                       *sql.Stmt
+	deleteUserPushSubscriptionStmt              *sql.Stmt
+	getAccessTokenUserStmt                      *sql.Stmt
+	getAllCardsStmt                             *sql.Stmt
+	getAllExchangeRatesStmt                     *sql.Stmt
+	getAllPredefinedCardsStmt                   *sql.Stmt
+	getCardBalanceAsOfStmt                      *sql.Stmt
+	getCardBalancesStmt                         *sql.Stmt
+	getCardByIDStmt                             *sql.Stmt
+	getCardByNameAndIssuerStmt                  *sql.Stmt
+	getCardDraftTransactionsBetweenStmt         *sql.Stmt
+	getCardPaymentsBetweenStmt                  *sql.Stmt
+	getCardTransactionsBetweenStmt              *sql.Stmt
+	getCategorisedSpendsStmt                    *sql.Stmt
+	getDraftTransactionByIDStmt                 *sql.Stmt
+	getDraftTransactionsStmt                    *sql.Stmt
+	getDraftTransactionsByStatementImportIDStmt *sql.Stmt
+	getDueNotificationDeliveriesStmt            *sql.Stmt
+	getExchangeRateStmt                         *sql.Stmt
+	getFirstTransactionDateStmt                 *sql.Stmt
+	getFirstUserStmt                            *sql.Stmt
+	getHouseholdStmt                            *sql.Stmt
+	getHouseholdCardsStmt                       *sql.Stmt
+	getHouseholdInvitesStmt                     *sql.Stmt
+	getHouseholdLedgerStmt                      *sql.Stmt
+	getHouseholdMembersStmt                     *sql.Stmt
+	getLatestCategoryModelStmt                  *sql.Stmt
+	getLatestCategoryModelIDStmt                *sql.Stmt
+	getLatestStatementStmt                      *sql.Stmt
+	getMailCheckpointStmt                       *sql.Stmt
+	getMailMessagesStmt                         *sql.Stmt
+	getMerchantMappingsStmt                     *sql.Stmt
+	getNotificationDeliveriesStmt               *sql.Stmt
+	getNotificationPreferencesStmt              *sql.Stmt
+	getOldestUnpaidStatementStmt                *sql.Stmt
+	getPaymentsStmt                             *sql.Stmt
+	getPaymentsByCardIDStmt                     *sql.Stmt
+	getPredefinedCardByKeyStmt                  *sql.Stmt
+	getPredefinedRewardRulesByCardIDStmt        *sql.Stmt
+	getPushSubscriptionsStmt                    *sql.Stmt
+	getSessionUserStmt                          *sql.Stmt
+	getStatementByIDStmt                        *sql.Stmt
+	getStatementImportByIDStmt                  *sql.Stmt
+	getStatementImportsStmt                     *sql.Stmt
+	getStatementsStmt                           *sql.Stmt
+	getStatementsByCardIDStmt                   *sql.Stmt
+	getStatementsToRemindStmt                   *sql.Stmt
+	getTransactionsStmt                         *sql.Stmt
+	getTransactionsByCardIDStmt                 *sql.Stmt
+	getUserAccessTokensStmt                     *sql.Stmt
+	getUserByIDStmt                             *sql.Stmt
+	getUserByUsernameStmt                       *sql.Stmt
+	getUserCardByIDStmt                         *sql.Stmt
+	getUserCardsStmt                            *sql.Stmt
+	getUserDraftTransactionsStmt                *sql.Stmt
+	getUserHouseholdStmt                        *sql.Stmt
+	getUserHouseholdInviteStmt                  *sql.Stmt
+	getUserHouseholdInvitesStmt                 *sql.Stmt
+	getUserMerchantMappingsStmt                 *sql.Stmt
+	getUserPasskeysStmt                         *sql.Stmt
+	getUserStatementByIDStmt                    *sql.Stmt
+	getVAPIDKeysStmt                            *sql.Stmt
+	getWalletBalancesStmt                       *sql.Stmt
+	isHouseholdMemberStmt                       *sql.Stmt
+	isMailMessageProcessedStmt                  *sql.Stmt
+	markNotificationAttemptFailedStmt           *sql.Stmt
+	markNotificationDeliveredStmt               *sql.Stmt
+	markStatementRemindedStmt                   *sql.Stmt
+	mergeDraftTransactionStmt                   *sql.Stmt
+	paymentExternalIDExistsStmt                 *sql.Stmt
+	takeWebauthnCeremonyStmt                    *sql.Stmt
+	touchAccessTokenStmt                        *sql.Stmt
+	transactionExternalIDExistsStmt             *sql.Stmt
+	updateCardStmt                              *sql.Stmt
+	updateDraftSuggestionStmt                   *sql.Stmt
+	updateDraftTransactionStmt                  *sql.Stmt
+	updatePasskeyUseStmt                        *sql.Stmt
+	upsertExchangeRateStmt                      *sql.Stmt
+	upsertMailCheckpointStmt                    *sql.Stmt
+	upsertMerchantMappingStmt                   *sql.Stmt
+	upsertNotificationPreferenceStmt            *sql.Stmt
+	upsertPushSubscriptionStmt                  *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                                   tx,
-		tx:                                   tx,
-		createCardStmt:                       q.createCardStmt,
-		createPredefinedCardStmt:             q.createPredefinedCardStmt,
-		createPredefinedRewardRuleStmt:       q.createPredefinedRewardRuleStmt,
-		getAllCardsStmt:                      q.getAllCardsStmt,
-		getAllPredefinedCardsStmt:            q.getAllPredefinedCardsStmt,
-		getCardByNameAndIssuerStmt:           q.getCardByNameAndIssuerStmt,
-		getPredefinedCardByKeyStmt:           q.getPredefinedCardByKeyStmt,
-		getPredefinedRewardRulesByCardIDStmt: q.getPredefinedRewardRulesByCardIDStmt,
-		updateCardStmt:                       q.updateCardStmt,
+		db:                                          tx,
+		tx:                                          tx,
+		addHouseholdMemberStmt:                      q.addHouseholdMemberStmt,
+		addStatementPaymentStmt:                     q.addStatementPaymentStmt,
+		capAlertExistsStmt:                          q.capAlertExistsStmt,
+		claimUnownedCardsStmt:                       q.claimUnownedCardsStmt,
+		claimUnownedMerchantMappingsStmt:            q.claimUnownedMerchantMappingsStmt,
+		claimUnownedNotificationPreferencesStmt:     q.claimUnownedNotificationPreferencesStmt,
+		claimUnownedPushSubscriptionsStmt:           q.claimUnownedPushSubscriptionsStmt,
+		countPushSubscriptionsStmt:                  q.countPushSubscriptionsStmt,
+		countUsersStmt:                              q.countUsersStmt,
+		createAccessTokenStmt:                       q.createAccessTokenStmt,
+		createCapAlertStmt:                          q.createCapAlertStmt,
+		createCardStmt:                              q.createCardStmt,
+		createCategoryModelStmt:                     q.createCategoryModelStmt,
+		createDraftTransactionStmt:                  q.createDraftTransactionStmt,
+		createHouseholdStmt:                         q.createHouseholdStmt,
+		createHouseholdInviteStmt:                   q.createHouseholdInviteStmt,
+		createMailMessageStmt:                       q.createMailMessageStmt,
+		createNotificationDeliveryStmt:              q.createNotificationDeliveryStmt,
+		createPasskeyStmt:                           q.createPasskeyStmt,
+		createPaymentStmt:                           q.createPaymentStmt,
+		createPredefinedCardStmt:                    q.createPredefinedCardStmt,
+		createPredefinedRewardRuleStmt:              q.createPredefinedRewardRuleStmt,
+		createSessionStmt:                           q.createSessionStmt,
+		createStatementStmt:                         q.createStatementStmt,
+		createStatementImportStmt:                   q.createStatementImportStmt,
+		createTransactionStmt:                       q.createTransactionStmt,
+		createUserStmt:                              q.createUserStmt,
+		createVAPIDKeysStmt:                         q.createVAPIDKeysStmt,
+		createWebauthnCeremonyStmt:                  q.createWebauthnCeremonyStmt,
+		deleteCategoryModelsBeforeStmt:              q.deleteCategoryModelsBeforeStmt,
+		deleteDraftTransactionStmt:                  q.deleteDraftTransactionStmt,
+		deleteExpiredSessionsStmt:                   q.deleteExpiredSessionsStmt,
+		deleteExpiredWebauthnCeremoniesStmt:         q.deleteExpiredWebauthnCeremoniesStmt,
+		deleteHouseholdStmt:                         q.deleteHouseholdStmt,
+		deleteHouseholdInviteStmt:                   q.deleteHouseholdInviteStmt,
+		deleteHouseholdInvitesStmt:                  q.deleteHouseholdInvitesStmt,
+		deleteHouseholdMemberStmt:                   q.deleteHouseholdMemberStmt,
+		deleteHouseholdMembersStmt:                  q.deleteHouseholdMembersStmt,
+		deleteMerchantMappingStmt:                   q.deleteMerchantMappingStmt,
+		deletePushSubscriptionStmt:                  q.deletePushSubscriptionStmt,
+		deleteSessionStmt:                           q.deleteSessionStmt,
+		deleteUserAccessTokenStmt:                   q.deleteUserAccessTokenStmt,
+		deleteUserHouseholdInvitesStmt:              q.deleteUserHouseholdInvitesStmt,
+		deleteUserPasskeyStmt:                       q.deleteUserPasskeyStmt,
+		deleteUserPushSubscriptionStmt:              q.deleteUserPushSubscriptionStmt,
+		getAccessTokenUserStmt:                      q.getAccessTokenUserStmt,
+		getAllCardsStmt:                             q.getAllCardsStmt,
+		getAllExchangeRatesStmt:                     q.getAllExchangeRatesStmt,
+		getAllPredefinedCardsStmt:                   q.getAllPredefinedCardsStmt,
+		getCardBalanceAsOfStmt:                      q.getCardBalanceAsOfStmt,
+		getCardBalancesStmt:                         q.getCardBalancesStmt,
+		getCardByIDStmt:                             q.getCardByIDStmt,
+		getCardByNameAndIssuerStmt:                  q.getCardByNameAndIssuerStmt,
+		getCardDraftTransactionsBetweenStmt:         q.getCardDraftTransactionsBetweenStmt,
+		getCardPaymentsBetweenStmt:                  q.getCardPaymentsBetweenStmt,
+		getCardTransactionsBetweenStmt:              q.getCardTransactionsBetweenStmt,
+		getCategorisedSpendsStmt:                    q.getCategorisedSpendsStmt,
+		getDraftTransactionByIDStmt:                 q.getDraftTransactionByIDStmt,
+		getDraftTransactionsStmt:                    q.getDraftTransactionsStmt,
+		getDraftTransactionsByStatementImportIDStmt: q.getDraftTransactionsByStatementImportIDStmt,
+		getDueNotificationDeliveriesStmt:            q.getDueNotificationDeliveriesStmt,
+		getExchangeRateStmt:                         q.getExchangeRateStmt,
+		getFirstTransactionDateStmt:                 q.getFirstTransactionDateStmt,
+		getFirstUserStmt:                            q.getFirstUserStmt,
+		getHouseholdStmt:                            q.getHouseholdStmt,
+		getHouseholdCardsStmt:                       q.getHouseholdCardsStmt,
+		getHouseholdInvitesStmt:                     q.getHouseholdInvitesStmt,
+		getHouseholdLedgerStmt:                      q.getHouseholdLedgerStmt,
+		getHouseholdMembersStmt:                     q.getHouseholdMembersStmt,
+		getLatestCategoryModelStmt:                  q.getLatestCategoryModelStmt,
+		getLatestCategoryModelIDStmt:                q.getLatestCategoryModelIDStmt,
+		getLatestStatementStmt:                      q.getLatestStatementStmt,
+		getMailCheckpointStmt:                       q.getMailCheckpointStmt,
+		getMailMessagesStmt:                         q.getMailMessagesStmt,
+		getMerchantMappingsStmt:                     q.getMerchantMappingsStmt,
+		getNotificationDeliveriesStmt:               q.getNotificationDeliveriesStmt,
+		getNotificationPreferencesStmt:              q.getNotificationPreferencesStmt,
+		getOldestUnpaidStatementStmt:                q.getOldestUnpaidStatementStmt,
+		getPaymentsStmt:                             q.getPaymentsStmt,
+		getPaymentsByCardIDStmt:                     q.getPaymentsByCardIDStmt,
+		getPredefinedCardByKeyStmt:                  q.getPredefinedCardByKeyStmt,
+		getPredefinedRewardRulesByCardIDStmt:        q.getPredefinedRewardRulesByCardIDStmt,
+		getPushSubscriptionsStmt:                    q.getPushSubscriptionsStmt,
+		getSessionUserStmt:                          q.getSessionUserStmt,
+		getStatementByIDStmt:                        q.getStatementByIDStmt,
+		getStatementImportByIDStmt:                  q.getStatementImportByIDStmt,
+		getStatementImportsStmt:                     q.getStatementImportsStmt,
+		getStatementsStmt:                           q.getStatementsStmt,
+		getStatementsByCardIDStmt:                   q.getStatementsByCardIDStmt,
+		getStatementsToRemindStmt:                   q.getStatementsToRemindStmt,
+		getTransactionsStmt:                         q.getTransactionsStmt,
+		getTransactionsByCardIDStmt:                 q.getTransactionsByCardIDStmt,
+		getUserAccessTokensStmt:                     q.getUserAccessTokensStmt,
+		getUserByIDStmt:                             q.getUserByIDStmt,
+		getUserByUsernameStmt:                       q.getUserByUsernameStmt,
+		getUserCardByIDStmt:                         q.getUserCardByIDStmt,
+		getUserCardsStmt:                            q.getUserCardsStmt,
+		getUserDraftTransactionsStmt:                q.getUserDraftTransactionsStmt,
+		getUserHouseholdStmt:                        q.getUserHouseholdStmt,
+		getUserHouseholdInviteStmt:                  q.getUserHouseholdInviteStmt,
+		getUserHouseholdInvitesStmt:                 q.getUserHouseholdInvitesStmt,
+		getUserMerchantMappingsStmt:                 q.getUserMerchantMappingsStmt,
+		getUserPasskeysStmt:                         q.getUserPasskeysStmt,
+		getUserStatementByIDStmt:                    q.getUserStatementByIDStmt,
+		getVAPIDKeysStmt:                            q.getVAPIDKeysStmt,
+		getWalletBalancesStmt:                       q.getWalletBalancesStmt,
+		isHouseholdMemberStmt:                       q.isHouseholdMemberStmt,
+		isMailMessageProcessedStmt:                  q.isMailMessageProcessedStmt,
+		markNotificationAttemptFailedStmt:           q.markNotificationAttemptFailedStmt,
+		markNotificationDeliveredStmt:               q.markNotificationDeliveredStmt,
+		markStatementRemindedStmt:                   q.markStatementRemindedStmt,
+		mergeDraftTransactionStmt:                   q.mergeDraftTransactionStmt,
+		paymentExternalIDExistsStmt:                 q.paymentExternalIDExistsStmt,
+		takeWebauthnCeremonyStmt:                    q.takeWebauthnCeremonyStmt,
+		touchAccessTokenStmt:                        q.touchAccessTokenStmt,
+		transactionExternalIDExistsStmt:             q.transactionExternalIDExistsStmt,
+		updateCardStmt:                              q.updateCardStmt,
+		updateDraftSuggestionStmt:                   q.updateDraftSuggestionStmt,
+		updateDraftTransactionStmt:                  q.updateDraftTransactionStmt,
+		updatePasskeyUseStmt:                        q.updatePasskeyUseStmt,
+		upsertExchangeRateStmt:                      q.upsertExchangeRateStmt,
+		upsertMailCheckpointStmt:                    q.upsertMailCheckpointStmt,
+		upsertMerchantMappingStmt:                   q.upsertMerchantMappingStmt,
+		upsertNotificationPreferenceStmt:            q.upsertNotificationPreferenceStmt,
+		upsertPushSubscriptionStmt:                  q.upsertPushSubscriptionStmt,
 	}
 }
@@ -6,40 +6,292 @@ package models
 
 import (
 	"time"
+
+	"github.com/pushkar-anand/cardmax/internal/money"
 )
 
+type AccessToken struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	Name       string     `json:"name"`
+	TokenHash  string     `json:"token_hash"`
+	Hint       string     `json:"hint"`
+	Scopes     string     `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type CapAlert struct {
+	CardID     int64     `json:"card_id"`
+	Rule       string    `json:"rule"`
+	CycleStart string    `json:"cycle_start"`
+	Level      string    `json:"level"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 type Card struct {
-	ID                int64    `json:"id"`
-	Name              string   `json:"name"`
-	Issuer            string   `json:"issuer"`
-	Last4Digits       string   `json:"last4_digits"`
-	ExpiryDate        string   `json:"expiry_date"`
-	DefaultRewardRate *float64 `json:"default_reward_rate"`
-	CardType          string   `json:"card_type"`
+	ID                int64       `json:"id"`
+	Name              string      `json:"name"`
+	Issuer            string      `json:"issuer"`
+	Last4Digits       string      `json:"last4_digits"`
+	ExpiryDate        string      `json:"expiry_date"`
+	DefaultRewardRate *float64    `json:"default_reward_rate"`
+	CardType          string      `json:"card_type"`
+	StatementDay      int64       `json:"statement_day"`
+	PaymentDueDays    int64       `json:"payment_due_days"`
+	PredefinedCardKey *string     `json:"predefined_card_key"`
+	CreditLimitMinor  money.Money `json:"credit_limit"`
+	UserID            *int64      `json:"user_id"`
+	Shared            bool        `json:"shared"`
+}
+
+type CategoryModel struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	Samples    int64     `json:"samples"`
+	Categories int64     `json:"categories"`
+	Model      string    `json:"model"`
+	TrainedAt  time.Time `json:"trained_at"`
+}
+
+type DraftTransaction struct {
+	ID                   int64       `json:"id"`
+	CardID               int64       `json:"card_id"`
+	StatementImportID    *int64      `json:"statement_import_id"`
+	Source               string      `json:"source"`
+	Kind                 string      `json:"kind"`
+	TransactionDate      string      `json:"transaction_date"`
+	Description          string      `json:"description"`
+	Category             *string     `json:"category"`
+	AmountMinor          money.Money `json:"amount"`
+	ExternalID           *string     `json:"external_id"`
+	Duplicate            bool        `json:"duplicate"`
+	CreatedAt            time.Time   `json:"created_at"`
+	Merchant             *string     `json:"merchant"`
+	Parser               string      `json:"parser"`
+	Confidence           float64     `json:"confidence"`
+	SuggestedCategory    *string     `json:"suggested_category"`
+	SuggestionConfidence *float64    `json:"suggestion_confidence"`
+}
+
+type ExchangeRate struct {
+	Currency  string    `json:"currency"`
+	RateToInr float64   `json:"rate_to_inr"`
+	AsOf      string    `json:"as_of"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Household struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	OwnerID   int64     `json:"owner_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type HouseholdInvite struct {
+	ID          int64     `json:"id"`
+	HouseholdID int64     `json:"household_id"`
+	UserID      int64     `json:"user_id"`
+	InvitedBy   int64     `json:"invited_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type HouseholdMember struct {
+	HouseholdID int64     `json:"household_id"`
+	UserID      int64     `json:"user_id"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+type MailCheckpoint struct {
+	Source    string    `json:"source"`
+	Cursor    string    `json:"cursor"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type MailMessage struct {
+	ID          int64     `json:"id"`
+	Source      string    `json:"source"`
+	MessageID   string    `json:"message_id"`
+	Sender      string    `json:"sender"`
+	Subject     string    `json:"subject"`
+	ReceivedAt  time.Time `json:"received_at"`
+	Status      string    `json:"status"`
+	Drafts      int64     `json:"drafts"`
+	Reason      *string   `json:"reason"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+type MerchantMapping struct {
+	UserID     *int64    `json:"user_id"`
+	Descriptor string    `json:"descriptor"`
+	Merchant   string    `json:"merchant"`
+	Category   *string   `json:"category"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type NotificationDelivery struct {
+	ID            int64      `json:"id"`
+	Kind          string     `json:"kind"`
+	Channel       string     `json:"channel"`
+	Title         string     `json:"title"`
+	Body          string     `json:"body"`
+	Data          *string    `json:"data"`
+	Status        string     `json:"status"`
+	Attempts      int64      `json:"attempts"`
+	LastError     *string    `json:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UserID        *int64     `json:"user_id"`
+}
+
+type NotificationPreference struct {
+	UserID  *int64 `json:"user_id"`
+	Kind    string `json:"kind"`
+	Channel string `json:"channel"`
+	Enabled bool   `json:"enabled"`
+}
+
+type Passkey struct {
+	ID           int64      `json:"id"`
+	UserID       int64      `json:"user_id"`
+	Name         string     `json:"name"`
+	CredentialID []byte     `json:"credential_id"`
+	Credential   string     `json:"credential"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type Payment struct {
+	ID          int64       `json:"id"`
+	CardID      int64       `json:"card_id"`
+	AmountMinor money.Money `json:"amount"`
+	PaymentDate string      `json:"payment_date"`
+	Notes       *string     `json:"notes"`
+	CreatedAt   time.Time   `json:"created_at"`
+	StatementID *int64      `json:"statement_id"`
+	Source      string      `json:"source"`
+	ExternalID  *string     `json:"external_id"`
 }
 
 type PredefinedCard struct {
-	ID                int64     `json:"id"`
-	CardKey           string    `json:"card_key"`
-	Name              string    `json:"name"`
-	Issuer            string    `json:"issuer"`
-	CardType          string    `json:"card_type"`
-	DefaultRewardRate float64   `json:"default_reward_rate"`
-	RewardType        string    `json:"reward_type"`
-	PointValue        float64   `json:"point_value"`
-	AnnualFee         int64     `json:"annual_fee"`
-	AnnualFeeWaiver   *string   `json:"annual_fee_waiver"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	ID                    int64       `json:"id"`
+	CardKey               string      `json:"card_key"`
+	Name                  string      `json:"name"`
+	Issuer                string      `json:"issuer"`
+	CardType              string      `json:"card_type"`
+	DefaultRewardRate     float64     `json:"default_reward_rate"`
+	RewardType            string      `json:"reward_type"`
+	PointValue            float64     `json:"point_value"`
+	AnnualFeeWaiver       *string     `json:"annual_fee_waiver"`
+	CreatedAt             time.Time   `json:"created_at"`
+	UpdatedAt             time.Time   `json:"updated_at"`
+	ForexMarkup           float64     `json:"forex_markup"`
+	EarningPointsPerBlock float64     `json:"earning_points_per_block"`
+	EarningRounding       string      `json:"earning_rounding"`
+	AnnualFeeMinor        money.Money `json:"annual_fee_minor"`
+	EarningBlockSizeMinor money.Money `json:"earning_block_size_minor"`
 }
 
 type PredefinedRewardRule struct {
-	ID               int64     `json:"id"`
-	PredefinedCardID int64     `json:"predefined_card_id"`
-	Type             string    `json:"type"`
-	EntityName       string    `json:"entity_name"`
-	RewardRate       float64   `json:"reward_rate"`
-	RewardType       string    `json:"reward_type"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	ID               int64       `json:"id"`
+	PredefinedCardID int64       `json:"predefined_card_id"`
+	Type             string      `json:"type"`
+	EntityName       string      `json:"entity_name"`
+	RewardRate       float64     `json:"reward_rate"`
+	RewardType       string      `json:"reward_type"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	Channel          string      `json:"channel"`
+	Precedence       int64       `json:"precedence"`
+	Stacking         string      `json:"stacking"`
+	Exclusive        bool        `json:"exclusive"`
+	PointsPerBlock   float64     `json:"points_per_block"`
+	SpendCapMinor    money.Money `json:"spend_cap_minor"`
+}
+
+type PushSubscription struct {
+	ID        int64     `json:"id"`
+	Endpoint  string    `json:"endpoint"`
+	P256dh    string    `json:"p256dh"`
+	Auth      string    `json:"auth"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    *int64    `json:"user_id"`
+}
+
+type Session struct {
+	ID        string    `json:"id"`
+	UserID    int64     `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Statement struct {
+	ID              int64       `json:"id"`
+	CardID          int64       `json:"card_id"`
+	CycleStart      string      `json:"cycle_start"`
+	StatementDate   string      `json:"statement_date"`
+	DueDate         string      `json:"due_date"`
+	TotalDueMinor   money.Money `json:"total_due"`
+	MinimumDueMinor money.Money `json:"minimum_due"`
+	PaidMinor       money.Money `json:"paid"`
+	PaidAt          *string     `json:"paid_at"`
+	RemindedAt      *time.Time  `json:"reminded_at"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
+type StatementImport struct {
+	ID              int64       `json:"id"`
+	CardID          *int64      `json:"card_id"`
+	Template        string      `json:"template"`
+	StatementDate   *string     `json:"statement_date"`
+	DueDate         *string     `json:"due_date"`
+	TotalDueMinor   money.Money `json:"total_due"`
+	MinimumDueMinor money.Money `json:"minimum_due"`
+	PointsOpening   *float64    `json:"points_opening"`
+	PointsEarned    *float64    `json:"points_earned"`
+	PointsRedeemed  *float64    `json:"points_redeemed"`
+	PointsExpired   *float64    `json:"points_expired"`
+	PointsClosing   *float64    `json:"points_closing"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
+type Transaction struct {
+	ID              int64       `json:"id"`
+	CardID          int64       `json:"card_id"`
+	Merchant        *string     `json:"merchant"`
+	Category        *string     `json:"category"`
+	AmountMinor     money.Money `json:"amount"`
+	TransactionDate string      `json:"transaction_date"`
+	Channel         *string     `json:"channel"`
+	Notes           *string     `json:"notes"`
+	CreatedAt       time.Time   `json:"created_at"`
+	Source          string      `json:"source"`
+	ExternalID      *string     `json:"external_id"`
+	SpentBy         *int64      `json:"spent_by"`
+}
+
+type User struct {
+	ID           int64     `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"password_hash"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type VapidKey struct {
+	ID         int64     `json:"id"`
+	PublicKey  string    `json:"public_key"`
+	PrivateKey string    `json:"private_key"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebauthnCeremony struct {
+	ID        string    `json:"id"`
+	UserID    *int64    `json:"user_id"`
+	Kind      string    `json:"kind"`
+	Session   string    `json:"session"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
 }
@@ -7,6 +7,8 @@ package models
 
 import (
 	"context"
+
+	"github.com/pushkar-anand/cardmax/internal/money"
 )
 
 const createPredefinedCard = `-- name: CreatePredefinedCard :one
@@ -18,8 +20,12 @@ INSERT INTO predefined_cards (
     default_reward_rate,
     reward_type,
     point_value,
-    annual_fee,
-    annual_fee_waiver
+    annual_fee_minor,
+    annual_fee_waiver,
+    forex_markup,
+    earning_block_size_minor,
+    earning_points_per_block,
+    earning_rounding
 ) VALUES (
     ?, -- card_key
     ?, -- name
@@ -28,8 +34,12 @@ INSERT INTO predefined_cards (
     ?, -- default_reward_rate
     ?, -- reward_type
     ?, -- point_value
-    ?, -- annual_fee
-    ? -- annual_fee_waiver
+    ?, -- annual_fee_minor
+    ?, -- annual_fee_waiver
+    ?, -- forex_markup
+    ?, -- earning_block_size_minor
+    ?, -- earning_points_per_block
+    ? -- earning_rounding
 )
 ON CONFLICT (card_key) DO UPDATE SET
     name = excluded.name,
@@ -38,21 +48,29 @@ ON CONFLICT (card_key) DO UPDATE SET
     default_reward_rate = excluded.default_reward_rate,
     reward_type = excluded.reward_type,
     point_value = excluded.point_value,
-    annual_fee = excluded.annual_fee,
-    annual_fee_waiver = excluded.annual_fee_waiver
-RETURNING id, card_key, name, issuer, card_type, default_reward_rate, reward_type, point_value, annual_fee, annual_fee_waiver, created_at, updated_at
+    annual_fee_minor = excluded.annual_fee_minor,
+    annual_fee_waiver = excluded.annual_fee_waiver,
+    forex_markup = excluded.forex_markup,
+    earning_block_size_minor = excluded.earning_block_size_minor,
+    earning_points_per_block = excluded.earning_points_per_block,
+    earning_rounding = excluded.earning_rounding
+RETURNING id, card_key, name, issuer, card_type, default_reward_rate, reward_type, point_value, annual_fee_waiver, created_at, updated_at, forex_markup, earning_points_per_block, earning_rounding, annual_fee_minor, earning_block_size_minor
 `
 
 type CreatePredefinedCardParams struct {
-	CardKey           string  `json:"card_key"`
-	Name              string  `json:"name"`
-	Issuer            string  `json:"issuer"`
-	CardType          string  `json:"card_type"`
-	DefaultRewardRate float64 `json:"default_reward_rate"`
-	RewardType        string  `json:"reward_type"`
-	PointValue        float64 `json:"point_value"`
-	AnnualFee         int64   `json:"annual_fee"`
-	AnnualFeeWaiver   *string `json:"annual_fee_waiver"`
+	CardKey               string      `json:"card_key"`
+	Name                  string      `json:"name"`
+	Issuer                string      `json:"issuer"`
+	CardType              string      `json:"card_type"`
+	DefaultRewardRate     float64     `json:"default_reward_rate"`
+	RewardType            string      `json:"reward_type"`
+	PointValue            float64     `json:"point_value"`
+	AnnualFeeMinor        money.Money `json:"annual_fee_minor"`
+	AnnualFeeWaiver       *string     `json:"annual_fee_waiver"`
+	ForexMarkup           float64     `json:"forex_markup"`
+	EarningBlockSizeMinor money.Money `json:"earning_block_size_minor"`
+	EarningPointsPerBlock float64     `json:"earning_points_per_block"`
+	EarningRounding       string      `json:"earning_rounding"`
 }
 
 func (q *Queries) CreatePredefinedCard(ctx context.Context, arg CreatePredefinedCardParams) (*PredefinedCard, error) {
@@ -64,8 +82,12 @@ func (q *Queries) CreatePredefinedCard(ctx context.Context, arg CreatePredefined
 		arg.DefaultRewardRate,
 		arg.RewardType,
 		arg.PointValue,
-		arg.AnnualFee,
+		arg.AnnualFeeMinor,
 		arg.AnnualFeeWaiver,
+		arg.ForexMarkup,
+		arg.EarningBlockSizeMinor,
+		arg.EarningPointsPerBlock,
+		arg.EarningRounding,
 	)
 	var i PredefinedCard
 	err := row.Scan(
@@ -77,10 +99,14 @@ func (q *Queries) CreatePredefinedCard(ctx context.Context, arg CreatePredefined
 		&i.DefaultRewardRate,
 		&i.RewardType,
 		&i.PointValue,
-		&i.AnnualFee,
 		&i.AnnualFeeWaiver,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ForexMarkup,
+		&i.EarningPointsPerBlock,
+		&i.EarningRounding,
+		&i.AnnualFeeMinor,
+		&i.EarningBlockSizeMinor,
 	)
 	return &i, err
 }
@@ -91,26 +117,49 @@ INSERT INTO predefined_reward_rules (
     type,
     entity_name,
     reward_rate,
-    reward_type
+    reward_type,
+    spend_cap_minor,
+    channel,
+    precedence,
+    stacking,
+    exclusive,
+    points_per_block
 ) VALUES (
     ?, -- predefined_card_id
     ?, -- type
     ?, -- entity_name
     ?, -- reward_rate
-    ? -- reward_type
+    ?, -- reward_type
+    ?, -- spend_cap_minor
+    ?, -- channel
+    ?, -- precedence
+    ?, -- stacking
+    ?, -- exclusive
+    ? -- points_per_block
 )
-ON CONFLICT (predefined_card_id, type, entity_name) DO UPDATE SET
+ON CONFLICT (predefined_card_id, type, entity_name, channel) DO UPDATE SET
     reward_rate = excluded.reward_rate,
-    reward_type = excluded.reward_type
-RETURNING id, predefined_card_id, type, entity_name, reward_rate, reward_type, created_at, updated_at
+    reward_type = excluded.reward_type,
+    spend_cap_minor = excluded.spend_cap_minor,
+    precedence = excluded.precedence,
+    stacking = excluded.stacking,
+    exclusive = excluded.exclusive,
+    points_per_block = excluded.points_per_block
+RETURNING id, predefined_card_id, type, entity_name, reward_rate, reward_type, created_at, updated_at, channel, precedence, stacking, "exclusive", points_per_block, spend_cap_minor
 `
 
 type CreatePredefinedRewardRuleParams struct {
-	PredefinedCardID int64   `json:"predefined_card_id"`
-	Type             string  `json:"type"`
-	EntityName       string  `json:"entity_name"`
-	RewardRate       float64 `json:"reward_rate"`
-	RewardType       string  `json:"reward_type"`
+	PredefinedCardID int64       `json:"predefined_card_id"`
+	Type             string      `json:"type"`
+	EntityName       string      `json:"entity_name"`
+	RewardRate       float64     `json:"reward_rate"`
+	RewardType       string      `json:"reward_type"`
+	SpendCapMinor    money.Money `json:"spend_cap_minor"`
+	Channel          string      `json:"channel"`
+	Precedence       int64       `json:"precedence"`
+	Stacking         string      `json:"stacking"`
+	Exclusive        bool        `json:"exclusive"`
+	PointsPerBlock   float64     `json:"points_per_block"`
 }
 
 func (q *Queries) CreatePredefinedRewardRule(ctx context.Context, arg CreatePredefinedRewardRuleParams) (*PredefinedRewardRule, error) {
@@ -120,6 +169,12 @@ func (q *Queries) CreatePredefinedRewardRule(ctx context.Context, arg CreatePred
 		arg.EntityName,
 		arg.RewardRate,
 		arg.RewardType,
+		arg.SpendCapMinor,
+		arg.Channel,
+		arg.Precedence,
+		arg.Stacking,
+		arg.Exclusive,
+		arg.PointsPerBlock,
 	)
 	var i PredefinedRewardRule
 	err := row.Scan(
@@ -131,12 +186,18 @@ func (q *Queries) CreatePredefinedRewardRule(ctx context.Context, arg CreatePred
 		&i.RewardType,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Channel,
+		&i.Precedence,
+		&i.Stacking,
+		&i.Exclusive,
+		&i.PointsPerBlock,
+		&i.SpendCapMinor,
 	)
 	return &i, err
 }
 
 const getAllPredefinedCards = `-- name: GetAllPredefinedCards :many
-SELECT id, card_key, name, issuer, card_type, default_reward_rate, reward_type, point_value, annual_fee, annual_fee_waiver, created_at, updated_at FROM predefined_cards
+SELECT id, card_key, name, issuer, card_type, default_reward_rate, reward_type, point_value, annual_fee_waiver, created_at, updated_at, forex_markup, earning_points_per_block, earning_rounding, annual_fee_minor, earning_block_size_minor FROM predefined_cards
 ORDER BY issuer, name
 `
 
@@ -158,10 +219,14 @@ func (q *Queries) GetAllPredefinedCards(ctx context.Context) ([]*PredefinedCard,
 			&i.DefaultRewardRate,
 			&i.RewardType,
 			&i.PointValue,
-			&i.AnnualFee,
 			&i.AnnualFeeWaiver,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ForexMarkup,
+			&i.EarningPointsPerBlock,
+			&i.EarningRounding,
+			&i.AnnualFeeMinor,
+			&i.EarningBlockSizeMinor,
 		); err != nil {
 			return nil, err
 		}
@@ -177,7 +242,7 @@ func (q *Queries) GetAllPredefinedCards(ctx context.Context) ([]*PredefinedCard,
 }
 
 const getPredefinedCardByKey = `-- name: GetPredefinedCardByKey :one
-SELECT id, card_key, name, issuer, card_type, default_reward_rate, reward_type, point_value, annual_fee, annual_fee_waiver, created_at, updated_at FROM predefined_cards
+SELECT id, card_key, name, issuer, card_type, default_reward_rate, reward_type, point_value, annual_fee_waiver, created_at, updated_at, forex_markup, earning_points_per_block, earning_rounding, annual_fee_minor, earning_block_size_minor FROM predefined_cards
 WHERE card_key = ?
 LIMIT 1
 `
@@ -194,18 +259,22 @@ func (q *Queries) GetPredefinedCardByKey(ctx context.Context, cardKey string) (*
 		&i.DefaultRewardRate,
 		&i.RewardType,
 		&i.PointValue,
-		&i.AnnualFee,
 		&i.AnnualFeeWaiver,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ForexMarkup,
+		&i.EarningPointsPerBlock,
+		&i.EarningRounding,
+		&i.AnnualFeeMinor,
+		&i.EarningBlockSizeMinor,
 	)
 	return &i, err
 }
 
 const getPredefinedRewardRulesByCardID = `-- name: GetPredefinedRewardRulesByCardID :many
-SELECT id, predefined_card_id, type, entity_name, reward_rate, reward_type, created_at, updated_at FROM predefined_reward_rules
+SELECT id, predefined_card_id, type, entity_name, reward_rate, reward_type, created_at, updated_at, channel, precedence, stacking, "exclusive", points_per_block, spend_cap_minor FROM predefined_reward_rules
 WHERE predefined_card_id = ?
-ORDER BY type, entity_name
+ORDER BY type, entity_name, channel
 `
 
 func (q *Queries) GetPredefinedRewardRulesByCardID(ctx context.Context, predefinedCardID int64) ([]*PredefinedRewardRule, error) {
@@ -226,6 +295,12 @@ func (q *Queries) GetPredefinedRewardRulesByCardID(ctx context.Context, predefin
 			&i.RewardType,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Channel,
+			&i.Precedence,
+			&i.Stacking,
+			&i.Exclusive,
+			&i.PointsPerBlock,
+			&i.SpendCapMinor,
 		); err != nil {
 			return nil, err
 		}
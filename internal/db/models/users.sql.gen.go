@@ -0,0 +1,225 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: users.sql
+
+package models
+
+import (
+	"context"
+	"time"
+)
+
+const claimUnownedCards = `-- name: ClaimUnownedCards :execrows
+UPDATE cards
+SET user_id = ?
+WHERE user_id IS NULL
+`
+
+func (q *Queries) ClaimUnownedCards(ctx context.Context, userID *int64) (int64, error) {
+	result, err := q.exec(ctx, q.claimUnownedCardsStmt, claimUnownedCards, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const claimUnownedMerchantMappings = `-- name: ClaimUnownedMerchantMappings :exec
+UPDATE merchant_mappings
+SET user_id = ?
+WHERE user_id IS NULL
+`
+
+func (q *Queries) ClaimUnownedMerchantMappings(ctx context.Context, userID *int64) error {
+	_, err := q.exec(ctx, q.claimUnownedMerchantMappingsStmt, claimUnownedMerchantMappings, userID)
+	return err
+}
+
+const claimUnownedNotificationPreferences = `-- name: ClaimUnownedNotificationPreferences :exec
+UPDATE notification_preferences
+SET user_id = ?
+WHERE user_id IS NULL
+`
+
+func (q *Queries) ClaimUnownedNotificationPreferences(ctx context.Context, userID *int64) error {
+	_, err := q.exec(ctx, q.claimUnownedNotificationPreferencesStmt, claimUnownedNotificationPreferences, userID)
+	return err
+}
+
+const claimUnownedPushSubscriptions = `-- name: ClaimUnownedPushSubscriptions :exec
+UPDATE push_subscriptions
+SET user_id = ?
+WHERE user_id IS NULL
+`
+
+func (q *Queries) ClaimUnownedPushSubscriptions(ctx context.Context, userID *int64) error {
+	_, err := q.exec(ctx, q.claimUnownedPushSubscriptionsStmt, claimUnownedPushSubscriptions, userID)
+	return err
+}
+
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	row := q.queryRow(ctx, q.countUsersStmt, countUsers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createSession = `-- name: CreateSession :one
+INSERT INTO sessions (id, user_id, expires_at)
+VALUES (?, ?, ?)
+RETURNING id, user_id, expires_at, created_at
+`
+
+type CreateSessionParams struct {
+	ID        string    `json:"id"`
+	UserID    int64     `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (*Session, error) {
+	row := q.queryRow(ctx, q.createSessionStmt, createSession, arg.ID, arg.UserID, arg.ExpiresAt)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const createUser = `-- name: CreateUser :one
+INSERT INTO users (username, password_hash)
+VALUES (?, ?)
+RETURNING id, username, password_hash, created_at
+`
+
+type CreateUserParams struct {
+	Username     string `json:"username"`
+	PasswordHash string `json:"password_hash"`
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (*User, error) {
+	row := q.queryRow(ctx, q.createUserStmt, createUser, arg.Username, arg.PasswordHash)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const deleteExpiredSessions = `-- name: DeleteExpiredSessions :execrows
+DELETE FROM sessions
+WHERE expires_at <= ?
+`
+
+func (q *Queries) DeleteExpiredSessions(ctx context.Context, expiresAt time.Time) (int64, error) {
+	result, err := q.exec(ctx, q.deleteExpiredSessionsStmt, deleteExpiredSessions, expiresAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteSession = `-- name: DeleteSession :exec
+DELETE FROM sessions
+WHERE id = ?
+`
+
+func (q *Queries) DeleteSession(ctx context.Context, id string) error {
+	_, err := q.exec(ctx, q.deleteSessionStmt, deleteSession, id)
+	return err
+}
+
+const getFirstUser = `-- name: GetFirstUser :one
+SELECT id, username, password_hash, created_at FROM users
+ORDER BY id ASC
+LIMIT 1
+`
+
+// The first user to register, who owns what was added before there were users.
+func (q *Queries) GetFirstUser(ctx context.Context) (*User, error) {
+	row := q.queryRow(ctx, q.getFirstUserStmt, getFirstUser)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const getSessionUser = `-- name: GetSessionUser :one
+SELECT users.id, users.username, users.password_hash, users.created_at
+FROM sessions
+         JOIN users ON users.id = sessions.user_id
+WHERE sessions.id = ?1
+  AND sessions.expires_at > ?2
+LIMIT 1
+`
+
+type GetSessionUserParams struct {
+	ID  string    `json:"id"`
+	Now time.Time `json:"now"`
+}
+
+type GetSessionUserRow struct {
+	User User `json:"user"`
+}
+
+// The user logged in with a session that has not expired.
+func (q *Queries) GetSessionUser(ctx context.Context, arg GetSessionUserParams) (*GetSessionUserRow, error) {
+	row := q.queryRow(ctx, q.getSessionUserStmt, getSessionUser, arg.ID, arg.Now)
+	var i GetSessionUserRow
+	err := row.Scan(
+		&i.User.ID,
+		&i.User.Username,
+		&i.User.PasswordHash,
+		&i.User.CreatedAt,
+	)
+	return &i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, username, password_hash, created_at FROM users
+WHERE id = ?
+LIMIT 1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id int64) (*User, error) {
+	row := q.queryRow(ctx, q.getUserByIDStmt, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const getUserByUsername = `-- name: GetUserByUsername :one
+SELECT id, username, password_hash, created_at FROM users
+WHERE username = ?
+LIMIT 1
+`
+
+func (q *Queries) GetUserByUsername(ctx context.Context, username string) (*User, error) {
+	row := q.queryRow(ctx, q.getUserByUsernameStmt, getUserByUsername, username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
@@ -43,6 +43,11 @@ func (d *DB) PopulatePredefinedCards(ctx context.Context, log *slog.Logger, card
 			annualFeeWaiver = &card.AnnualFeeWaiver
 		}
 
+		var earning cards.Earning
+		if card.Earning != nil {
+			earning = *card.Earning
+		}
+
 		dbCard, err = q.CreatePredefinedCard(ctx, models.CreatePredefinedCardParams{
 			CardKey:           card.Key,
 			Name:              card.Name,
@@ -51,8 +56,12 @@ func (d *DB) PopulatePredefinedCards(ctx context.Context, log *slog.Logger, card
 			DefaultRewardRate: card.DefaultRewardRate,
 			RewardType:        card.RewardType,
 			PointValue:        card.PointValue,
-			AnnualFee:         int64(card.AnnualFee),
+			AnnualFeeMinor:    card.AnnualFee,
 			AnnualFeeWaiver:   annualFeeWaiver,
+			ForexMarkup:           card.ForexMarkup,
+			EarningBlockSizeMinor: earning.BlockSize,
+			EarningPointsPerBlock: earning.PointsPerBlock,
+			EarningRounding:       card.RoundingMode(),
 		})
 
 		if err != nil {
@@ -72,6 +81,12 @@ func (d *DB) PopulatePredefinedCards(ctx context.Context, log *slog.Logger, card
 				EntityName:       rule.EntityName,
 				RewardRate:       rule.RewardRate,
 				RewardType:       rule.RewardType,
+				SpendCapMinor:    rule.SpendCap,
+				Channel:          rule.Channel,
+				Precedence:       int64(rule.Precedence),
+				Stacking:         rule.StackingMode(),
+				Exclusive:        rule.Exclusive,
+				PointsPerBlock:   rule.PointsPerBlock,
 			})
 
 			if err != nil {
@@ -0,0 +1,28 @@
+-- name: CreateAccessToken :one
+INSERT INTO access_tokens (user_id, name, token_hash, hint, scopes, expires_at)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetUserAccessTokens :many
+SELECT * FROM access_tokens
+WHERE user_id = ?
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetAccessTokenUser :one
+-- The user of a token that has not expired, along with the token.
+SELECT sqlc.embed(users), sqlc.embed(access_tokens)
+FROM access_tokens
+         JOIN users ON users.id = access_tokens.user_id
+WHERE access_tokens.token_hash = @token_hash
+  AND access_tokens.expires_at > @now
+LIMIT 1;
+
+-- name: TouchAccessToken :exec
+UPDATE access_tokens
+SET last_used_at = @now
+WHERE id = @id;
+
+-- name: DeleteUserAccessToken :execrows
+DELETE FROM access_tokens
+WHERE id = ?
+  AND user_id = ?;
\ No newline at end of file
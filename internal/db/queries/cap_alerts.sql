@@ -0,0 +1,12 @@
+-- name: CapAlertExists :one
+SELECT EXISTS (SELECT 1
+               FROM cap_alerts
+               WHERE card_id = ?
+                 AND rule = ?
+                 AND cycle_start = ?
+                 AND level = ?);
+
+-- name: CreateCapAlert :exec
+INSERT INTO cap_alerts (card_id, rule, cycle_start, level)
+VALUES (?, ?, ?, ?)
+ON CONFLICT DO NOTHING;
\ No newline at end of file
@@ -4,16 +4,38 @@ INSERT INTO cards (name, -- The name given to the card
                    last4_digits, -- The last four digits of the card number
                    expiry_date, -- The expiration date (e.g., 'MM/YY' or 'YYYY-MM')
                    default_reward_rate, -- The default reward rate (e.g., 1.5 for 1.5%)
-                   card_type -- The type of card (e.g., 'Visa', 'Mastercard')
+                   card_type, -- The type of card (e.g., 'Visa', 'Mastercard')
+                   statement_day, -- The day of the month the statement is generated on
+                   payment_due_days, -- The days between the statement date and the due date
+                   predefined_card_key, -- The predefined card this card is an instance of
+                   credit_limit_minor, -- The credit limit in paise
+                   user_id, -- The user owning the card
+                   shared -- Whether the card is shared with the user's household
 )
 VALUES (?, -- Placeholder for Name
         ?, -- Placeholder for Issuer
         ?, -- Placeholder for Last4Digits (ensure the provided value is 4 characters)
         ?, -- Placeholder for ExpiryDate
         ?, -- Placeholder for DefaultRewardRate
-        ? -- Placeholder for CardType
+        ?, -- Placeholder for CardType
+        ?, -- Placeholder for StatementDay
+        ?, -- Placeholder for PaymentDueDays
+        ?, -- Placeholder for PredefinedCardKey
+        ?, -- Placeholder for CreditLimitMinor
+        ?, -- Placeholder for UserID
+        ? -- Placeholder for Shared
        ) RETURNING *;
 
+-- name: GetCardByID :one
+SELECT * FROM cards
+WHERE id = ?
+LIMIT 1;
+
+-- name: GetUserCardByID :one
+SELECT * FROM cards
+WHERE id = ? AND user_id = ?
+LIMIT 1;
+
 -- name: GetCardByNameAndIssuer :one
 SELECT * FROM cards
 WHERE name = ? AND issuer = ?
@@ -23,6 +45,11 @@ LIMIT 1;
 SELECT * FROM cards
 ORDER BY name ASC;
 
+-- name: GetUserCards :many
+SELECT * FROM cards
+WHERE user_id = ?
+ORDER BY name ASC;
+
 -- name: UpdateCard :one
 UPDATE cards
 SET name = ?,
@@ -30,6 +57,37 @@ SET name = ?,
     last4_digits = ?,
     expiry_date = ?,
     default_reward_rate = ?,
-    card_type = ?
-WHERE id = ?
-RETURNING *;
\ No newline at end of file
+    card_type = ?,
+    statement_day = ?,
+    payment_due_days = ?,
+    predefined_card_key = ?,
+    credit_limit_minor = ?,
+    shared = ?
+WHERE id = ? AND user_id = ?
+RETURNING *;
+
+-- name: GetCardBalances :many
+-- Outstanding balance of every card of a user: logged transactions minus recorded payments.
+SELECT sqlc.embed(cards),
+       CAST(COALESCE((SELECT SUM(t.amount_minor) FROM transactions t WHERE t.card_id = cards.id), 0) AS INTEGER) AS spent,
+       CAST(COALESCE((SELECT SUM(p.amount_minor) FROM payments p WHERE p.card_id = cards.id), 0) AS INTEGER) AS paid
+FROM cards
+WHERE cards.user_id = ?
+ORDER BY cards.name ASC;
+
+-- name: GetWalletBalances :many
+-- Outstanding balance of every card of a user and of the cards the other members of their
+-- household share, along with the username of the card's holder.
+SELECT sqlc.embed(cards),
+       users.username,
+       CAST(COALESCE((SELECT SUM(t.amount_minor) FROM transactions t WHERE t.card_id = cards.id), 0) AS INTEGER) AS spent,
+       CAST(COALESCE((SELECT SUM(p.amount_minor) FROM payments p WHERE p.card_id = cards.id), 0) AS INTEGER) AS paid
+FROM cards
+         JOIN users ON users.id = cards.user_id
+WHERE cards.user_id = @user_id
+   OR (cards.shared AND cards.user_id IN (SELECT m.user_id
+                                          FROM household_members m
+                                          WHERE m.household_id = (SELECT household_id
+                                                                  FROM household_members
+                                                                  WHERE household_members.user_id = @user_id)))
+ORDER BY cards.name ASC;
\ No newline at end of file
@@ -0,0 +1,32 @@
+-- name: CreateCategoryModel :one
+INSERT INTO category_models (user_id, samples, categories, model)
+VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetLatestCategoryModel :one
+SELECT * FROM category_models
+WHERE user_id = ?
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: GetLatestCategoryModelID :one
+SELECT id FROM category_models
+WHERE user_id = ?
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: DeleteCategoryModelsBefore :exec
+-- Removes the models of the user replaced by a newer one.
+DELETE FROM category_models
+WHERE user_id = ?
+  AND id < ?;
+
+-- name: GetCategorisedSpends :many
+-- Spends of the user's ledger with a category, the samples their category model is trained on.
+SELECT transactions.* FROM transactions
+JOIN cards ON cards.id = transactions.card_id
+WHERE cards.user_id = @user_id
+  AND transactions.category IS NOT NULL
+  AND transactions.category != ''
+  AND transactions.amount_minor > 0
+ORDER BY transactions.id;
\ No newline at end of file
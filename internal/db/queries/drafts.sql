@@ -0,0 +1,108 @@
+-- name: CreateStatementImport :one
+INSERT INTO statement_imports (card_id,
+                               template,
+                               statement_date,
+                               due_date,
+                               total_due_minor,
+                               minimum_due_minor,
+                               points_opening,
+                               points_earned,
+                               points_redeemed,
+                               points_expired,
+                               points_closing)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetStatementImports :many
+SELECT * FROM statement_imports
+WHERE card_id IN (SELECT id FROM cards WHERE user_id = ?)
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetStatementImportByID :one
+SELECT * FROM statement_imports
+WHERE id = ?
+LIMIT 1;
+
+-- name: CreateDraftTransaction :one
+INSERT INTO draft_transactions (card_id,
+                                statement_import_id,
+                                source,
+                                kind,
+                                transaction_date,
+                                description,
+                                category,
+                                amount_minor,
+                                external_id,
+                                duplicate,
+                                merchant,
+                                parser,
+                                confidence,
+                                suggested_category,
+                                suggestion_confidence)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetDraftTransactions :many
+SELECT * FROM draft_transactions
+ORDER BY transaction_date, id;
+
+-- name: GetUserDraftTransactions :many
+SELECT * FROM draft_transactions
+WHERE card_id IN (SELECT id FROM cards WHERE user_id = ?)
+ORDER BY transaction_date, id;
+
+-- name: GetDraftTransactionsByStatementImportID :many
+SELECT * FROM draft_transactions
+WHERE statement_import_id = ?
+  AND card_id IN (SELECT id FROM cards WHERE user_id = ?)
+ORDER BY transaction_date, id;
+
+-- name: GetCardDraftTransactionsBetween :many
+-- Drafts of a card dated from FromDate to ToDate, both inclusive.
+SELECT * FROM draft_transactions
+WHERE card_id = @card_id
+  AND transaction_date >= @from_date
+  AND transaction_date <= @to_date
+ORDER BY transaction_date, id;
+
+-- name: GetDraftTransactionByID :one
+SELECT * FROM draft_transactions
+WHERE draft_transactions.id = ?
+  AND card_id IN (SELECT id FROM cards WHERE user_id = ?)
+LIMIT 1;
+
+-- name: UpdateDraftTransaction :one
+-- Edits a draft under review, which is then as certain as a transaction entered by hand.
+UPDATE draft_transactions
+SET card_id          = @card_id,
+    kind             = @kind,
+    transaction_date = @transaction_date,
+    description      = @description,
+    merchant         = @merchant,
+    category         = @category,
+    amount_minor     = @amount_minor,
+    confidence       = 1
+WHERE draft_transactions.id = @id
+  AND card_id IN (SELECT id FROM cards WHERE user_id = @user_id)
+RETURNING *;
+
+-- name: MergeDraftTransaction :one
+-- Keeps what the duplicates of a draft merged into it knew that it did not.
+UPDATE draft_transactions
+SET merchant    = ?,
+    category    = ?,
+    external_id = ?,
+    confidence  = ?
+WHERE id = ?
+RETURNING *;
+
+-- name: UpdateDraftSuggestion :exec
+UPDATE draft_transactions
+SET suggested_category    = ?,
+    suggestion_confidence = ?
+WHERE id = ?;
+
+-- name: DeleteDraftTransaction :execrows
+DELETE FROM draft_transactions
+WHERE draft_transactions.id = ?
+  AND card_id IN (SELECT id FROM cards WHERE user_id = ?);
\ No newline at end of file
@@ -0,0 +1,25 @@
+-- name: UpsertExchangeRate :one
+INSERT INTO exchange_rates (
+    currency,
+    rate_to_inr,
+    as_of
+) VALUES (
+    ?, -- currency
+    ?, -- rate_to_inr
+    ? -- as_of
+)
+ON CONFLICT (currency) DO UPDATE SET
+    rate_to_inr = excluded.rate_to_inr,
+    as_of = excluded.as_of,
+    updated_at = CURRENT_TIMESTAMP
+WHERE excluded.as_of >= exchange_rates.as_of
+RETURNING *;
+
+-- name: GetExchangeRate :one
+SELECT * FROM exchange_rates
+WHERE currency = ?
+LIMIT 1;
+
+-- name: GetAllExchangeRates :many
+SELECT * FROM exchange_rates
+ORDER BY currency;
@@ -0,0 +1,100 @@
+-- name: CreateHousehold :one
+INSERT INTO households (name, owner_id)
+VALUES (?, ?)
+RETURNING *;
+
+-- name: GetHousehold :one
+SELECT * FROM households
+WHERE id = ?
+LIMIT 1;
+
+-- name: GetUserHousehold :one
+-- The household the user is a member of.
+SELECT households.*
+FROM households
+         JOIN household_members ON household_members.household_id = households.id
+WHERE household_members.user_id = ?
+LIMIT 1;
+
+-- name: DeleteHouseholdMembers :exec
+DELETE FROM household_members
+WHERE household_id = ?;
+
+-- name: DeleteHouseholdInvites :exec
+DELETE FROM household_invites
+WHERE household_id = ?;
+
+-- name: DeleteHousehold :exec
+DELETE FROM households
+WHERE id = ?;
+
+-- name: AddHouseholdMember :exec
+INSERT INTO household_members (household_id, user_id)
+VALUES (?, ?);
+
+-- name: GetHouseholdMembers :many
+SELECT users.id, users.username, household_members.joined_at
+FROM household_members
+         JOIN users ON users.id = household_members.user_id
+WHERE household_members.household_id = ?
+ORDER BY household_members.joined_at ASC, users.id ASC;
+
+-- name: IsHouseholdMember :one
+-- Whether the member belongs to the household of the user.
+SELECT EXISTS (SELECT 1
+               FROM household_members a
+                        JOIN household_members b ON b.household_id = a.household_id
+               WHERE a.user_id = @user_id
+                 AND b.user_id = @member_id);
+
+-- name: DeleteHouseholdMember :execrows
+DELETE FROM household_members
+WHERE household_id = ? AND user_id = ?;
+
+-- name: GetHouseholdCards :many
+-- The cards the members of the household share, along with the username of their holder.
+SELECT sqlc.embed(cards), users.username
+FROM cards
+         JOIN household_members ON household_members.user_id = cards.user_id
+         JOIN users ON users.id = cards.user_id
+WHERE household_members.household_id = ?
+  AND cards.shared
+ORDER BY users.username ASC, cards.name ASC;
+
+-- name: CreateHouseholdInvite :one
+INSERT INTO household_invites (household_id, user_id, invited_by)
+VALUES (?, ?, ?)
+RETURNING *;
+
+-- name: GetHouseholdInvites :many
+-- The pending invites of the household, along with the username of the invited user.
+SELECT sqlc.embed(household_invites), users.username
+FROM household_invites
+         JOIN users ON users.id = household_invites.user_id
+WHERE household_invites.household_id = ?
+ORDER BY household_invites.created_at DESC, household_invites.id DESC;
+
+-- name: GetUserHouseholdInvites :many
+-- The pending invites of the user, along with the household and the username of who sent them.
+SELECT sqlc.embed(household_invites), households.name AS household, users.username AS invited_by_username
+FROM household_invites
+         JOIN households ON households.id = household_invites.household_id
+         JOIN users ON users.id = household_invites.invited_by
+WHERE household_invites.user_id = ?
+ORDER BY household_invites.created_at DESC, household_invites.id DESC;
+
+-- name: GetUserHouseholdInvite :one
+SELECT * FROM household_invites
+WHERE id = ? AND user_id = ?
+LIMIT 1;
+
+-- name: DeleteHouseholdInvite :execrows
+-- Declines an invite to the user, or revokes an invite sent by the household the user owns.
+DELETE FROM household_invites
+WHERE household_invites.id = @id
+  AND (household_invites.user_id = @user_id
+    OR household_invites.household_id IN (SELECT households.id FROM households WHERE households.owner_id = @user_id));
+
+-- name: DeleteUserHouseholdInvites :exec
+DELETE FROM household_invites
+WHERE user_id = ?;
\ No newline at end of file
@@ -0,0 +1,23 @@
+-- name: GetMailCheckpoint :one
+SELECT * FROM mail_checkpoints
+WHERE source = ?
+LIMIT 1;
+
+-- name: UpsertMailCheckpoint :exec
+INSERT INTO mail_checkpoints (source, cursor)
+VALUES (?, ?)
+ON CONFLICT (source) DO UPDATE SET cursor     = excluded.cursor,
+                                   updated_at = CURRENT_TIMESTAMP;
+
+-- name: IsMailMessageProcessed :one
+SELECT EXISTS (SELECT 1 FROM mail_messages WHERE message_id = ?);
+
+-- name: CreateMailMessage :one
+INSERT INTO mail_messages (source, message_id, sender, subject, received_at, status, drafts, reason)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetMailMessages :many
+SELECT * FROM mail_messages
+ORDER BY id DESC
+LIMIT ?;
\ No newline at end of file
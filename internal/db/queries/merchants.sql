@@ -0,0 +1,22 @@
+-- name: GetMerchantMappings :many
+-- Mappings of every user, which the normaliser keeps in memory.
+SELECT * FROM merchant_mappings
+ORDER BY user_id, descriptor;
+
+-- name: GetUserMerchantMappings :many
+SELECT * FROM merchant_mappings
+WHERE user_id = ?
+ORDER BY descriptor;
+
+-- name: UpsertMerchantMapping :one
+INSERT INTO merchant_mappings (user_id, descriptor, merchant, category)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (user_id, descriptor) DO UPDATE SET merchant   = excluded.merchant,
+                                                category   = excluded.category,
+                                                updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteMerchantMapping :execrows
+DELETE FROM merchant_mappings
+WHERE user_id = ?
+  AND descriptor = ?;
\ No newline at end of file
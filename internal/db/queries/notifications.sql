@@ -0,0 +1,46 @@
+-- name: UpsertNotificationPreference :one
+INSERT INTO notification_preferences (user_id, kind, channel, enabled)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (user_id, kind, channel) DO UPDATE SET enabled = excluded.enabled
+RETURNING *;
+
+-- name: GetNotificationPreferences :many
+SELECT * FROM notification_preferences
+WHERE user_id = ?
+ORDER BY kind, channel;
+
+-- name: CreateNotificationDelivery :one
+INSERT INTO notification_deliveries (user_id, kind, channel, title, body, data)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetDueNotificationDeliveries :many
+-- Pending deliveries whose next attempt is due.
+SELECT * FROM notification_deliveries
+WHERE status = 'pending'
+  AND next_attempt_at <= @now
+ORDER BY next_attempt_at ASC, id ASC
+LIMIT 100;
+
+-- name: GetNotificationDeliveries :many
+SELECT * FROM notification_deliveries
+WHERE user_id = ?
+ORDER BY id DESC
+LIMIT ?;
+
+-- name: MarkNotificationDelivered :exec
+UPDATE notification_deliveries
+SET status = 'sent',
+    attempts = attempts + 1,
+    last_error = NULL,
+    delivered_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: MarkNotificationAttemptFailed :exec
+-- Records a failed attempt; the delivery fails for good once it runs out of attempts.
+UPDATE notification_deliveries
+SET attempts = attempts + 1,
+    last_error = @last_error,
+    next_attempt_at = @next_attempt_at,
+    status = CASE WHEN attempts + 1 >= @max_attempts THEN 'failed' ELSE 'pending' END
+WHERE id = @id;
\ No newline at end of file
@@ -0,0 +1,38 @@
+-- name: CreatePasskey :one
+INSERT INTO passkeys (user_id, name, credential_id, credential)
+VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetUserPasskeys :many
+SELECT * FROM passkeys
+WHERE user_id = ?
+ORDER BY created_at DESC, id DESC;
+
+-- name: UpdatePasskeyUse :exec
+-- Stores the credential as updated by a login, e.g. its signature counter.
+UPDATE passkeys
+SET credential   = @credential,
+    last_used_at = @now
+WHERE id = @id;
+
+-- name: DeleteUserPasskey :execrows
+DELETE FROM passkeys
+WHERE id = ?
+  AND user_id = ?;
+
+-- name: CreateWebauthnCeremony :exec
+INSERT INTO webauthn_ceremonies (id, user_id, kind, session, expires_at)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: TakeWebauthnCeremony :one
+-- Removes a ceremony that has not expired and returns it, so that its challenge is only answered
+-- once.
+DELETE FROM webauthn_ceremonies
+WHERE id = @id
+  AND kind = @kind
+  AND expires_at > @now
+RETURNING *;
+
+-- name: DeleteExpiredWebauthnCeremonies :execrows
+DELETE FROM webauthn_ceremonies
+WHERE expires_at <= ?;
\ No newline at end of file
@@ -0,0 +1,33 @@
+-- name: CreatePayment :one
+INSERT INTO payments (card_id,
+                      amount_minor,
+                      payment_date,
+                      notes,
+                      statement_id,
+                      source,
+                      external_id)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetPayments :many
+SELECT * FROM payments
+WHERE card_id IN (SELECT id FROM cards WHERE user_id = ?)
+ORDER BY payment_date DESC, id DESC;
+
+-- name: GetPaymentsByCardID :many
+SELECT * FROM payments
+WHERE card_id = ?
+  AND card_id IN (SELECT id FROM cards WHERE user_id = ?)
+ORDER BY payment_date DESC, id DESC;
+
+-- name: PaymentExternalIDExists :one
+-- Whether a payment towards the card was imported with the external ID.
+SELECT EXISTS (SELECT 1 FROM payments WHERE card_id = @card_id AND external_id = @external_id);
+
+-- name: GetCardPaymentsBetween :many
+-- Payments towards a card dated from FromDate to ToDate, both inclusive.
+SELECT * FROM payments
+WHERE card_id = @card_id
+  AND payment_date >= @from_date
+  AND payment_date <= @to_date
+ORDER BY payment_date, id;
\ No newline at end of file
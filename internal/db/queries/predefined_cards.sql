@@ -7,8 +7,12 @@ INSERT INTO predefined_cards (
     default_reward_rate,
     reward_type,
     point_value,
-    annual_fee,
-    annual_fee_waiver
+    annual_fee_minor,
+    annual_fee_waiver,
+    forex_markup,
+    earning_block_size_minor,
+    earning_points_per_block,
+    earning_rounding
 ) VALUES (
     ?, -- card_key
     ?, -- name
@@ -17,8 +21,12 @@ INSERT INTO predefined_cards (
     ?, -- default_reward_rate
     ?, -- reward_type
     ?, -- point_value
-    ?, -- annual_fee
-    ? -- annual_fee_waiver
+    ?, -- annual_fee_minor
+    ?, -- annual_fee_waiver
+    ?, -- forex_markup
+    ?, -- earning_block_size_minor
+    ?, -- earning_points_per_block
+    ? -- earning_rounding
 )
 ON CONFLICT (card_key) DO UPDATE SET
     name = excluded.name,
@@ -27,8 +35,12 @@ ON CONFLICT (card_key) DO UPDATE SET
     default_reward_rate = excluded.default_reward_rate,
     reward_type = excluded.reward_type,
     point_value = excluded.point_value,
-    annual_fee = excluded.annual_fee,
-    annual_fee_waiver = excluded.annual_fee_waiver
+    annual_fee_minor = excluded.annual_fee_minor,
+    annual_fee_waiver = excluded.annual_fee_waiver,
+    forex_markup = excluded.forex_markup,
+    earning_block_size_minor = excluded.earning_block_size_minor,
+    earning_points_per_block = excluded.earning_points_per_block,
+    earning_rounding = excluded.earning_rounding
 RETURNING *;
 
 -- name: GetPredefinedCardByKey :one
@@ -46,20 +58,37 @@ INSERT INTO predefined_reward_rules (
     type,
     entity_name,
     reward_rate,
-    reward_type
+    reward_type,
+    spend_cap_minor,
+    channel,
+    precedence,
+    stacking,
+    exclusive,
+    points_per_block
 ) VALUES (
     ?, -- predefined_card_id
     ?, -- type
     ?, -- entity_name
     ?, -- reward_rate
-    ? -- reward_type
+    ?, -- reward_type
+    ?, -- spend_cap_minor
+    ?, -- channel
+    ?, -- precedence
+    ?, -- stacking
+    ?, -- exclusive
+    ? -- points_per_block
 )
-ON CONFLICT (predefined_card_id, type, entity_name) DO UPDATE SET
+ON CONFLICT (predefined_card_id, type, entity_name, channel) DO UPDATE SET
     reward_rate = excluded.reward_rate,
-    reward_type = excluded.reward_type
+    reward_type = excluded.reward_type,
+    spend_cap_minor = excluded.spend_cap_minor,
+    precedence = excluded.precedence,
+    stacking = excluded.stacking,
+    exclusive = excluded.exclusive,
+    points_per_block = excluded.points_per_block
 RETURNING *;
 
 -- name: GetPredefinedRewardRulesByCardID :many
 SELECT * FROM predefined_reward_rules
 WHERE predefined_card_id = ?
-ORDER BY type, entity_name;
\ No newline at end of file
+ORDER BY type, entity_name, channel;
\ No newline at end of file
@@ -0,0 +1,34 @@
+-- name: UpsertPushSubscription :one
+-- An endpoint subscribed by another user is left as it is, returning no row.
+INSERT INTO push_subscriptions (endpoint, p256dh, auth, user_id)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (endpoint) DO UPDATE SET p256dh = excluded.p256dh,
+                                     auth   = excluded.auth
+WHERE push_subscriptions.user_id = excluded.user_id
+RETURNING *;
+
+-- name: GetPushSubscriptions :many
+SELECT * FROM push_subscriptions
+WHERE user_id = ?
+ORDER BY id;
+
+-- name: CountPushSubscriptions :one
+SELECT COUNT(*) FROM push_subscriptions
+WHERE user_id = ?;
+
+-- name: DeletePushSubscription :execrows
+DELETE FROM push_subscriptions
+WHERE endpoint = ?;
+
+-- name: DeleteUserPushSubscription :execrows
+DELETE FROM push_subscriptions
+WHERE endpoint = ? AND user_id = ?;
+
+-- name: GetVAPIDKeys :one
+SELECT * FROM vapid_keys
+WHERE id = 1;
+
+-- name: CreateVAPIDKeys :one
+INSERT INTO vapid_keys (id, public_key, private_key)
+VALUES (1, ?, ?)
+RETURNING *;
\ No newline at end of file
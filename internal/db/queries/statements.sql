@@ -0,0 +1,96 @@
+-- name: CreateStatement :one
+-- Statements are generated once per cycle. The totals of an existing statement are recomputed while
+-- it is unpaid, as transactions can still be logged or removed after its statement date; it is
+-- settled once its payments cover the new total. Nothing is returned when the statement is paid
+-- or its totals did not change.
+INSERT INTO statements (card_id,
+                        cycle_start,
+                        statement_date,
+                        due_date,
+                        total_due_minor,
+                        minimum_due_minor,
+                        paid_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (card_id, statement_date) DO UPDATE
+    SET total_due_minor   = excluded.total_due_minor,
+        minimum_due_minor = excluded.minimum_due_minor,
+        paid_at           = CASE
+                                WHEN excluded.paid_at IS NOT NULL THEN excluded.paid_at
+                                WHEN statements.paid_minor >= excluded.total_due_minor THEN DATE('now')
+                            END
+WHERE statements.paid_at IS NULL
+  AND (statements.total_due_minor != excluded.total_due_minor
+    OR statements.minimum_due_minor != excluded.minimum_due_minor)
+RETURNING *;
+
+-- name: GetStatementByID :one
+SELECT * FROM statements
+WHERE id = ?
+LIMIT 1;
+
+-- name: GetUserStatementByID :one
+SELECT * FROM statements
+WHERE statements.id = ?
+  AND card_id IN (SELECT id FROM cards WHERE user_id = ?)
+LIMIT 1;
+
+-- name: GetStatements :many
+SELECT * FROM statements
+WHERE card_id IN (SELECT id FROM cards WHERE user_id = ?)
+ORDER BY due_date DESC, id DESC;
+
+-- name: GetStatementsByCardID :many
+SELECT * FROM statements
+WHERE card_id = ?
+  AND card_id IN (SELECT id FROM cards WHERE user_id = ?)
+ORDER BY due_date DESC, id DESC;
+
+-- name: GetOldestUnpaidStatement :one
+SELECT * FROM statements
+WHERE card_id = ? AND paid_at IS NULL
+ORDER BY statement_date ASC
+LIMIT 1;
+
+-- name: GetLatestStatement :one
+SELECT * FROM statements
+WHERE card_id = ?
+ORDER BY statement_date DESC
+LIMIT 1;
+
+-- name: AddStatementPayment :one
+UPDATE statements
+SET paid_minor = paid_minor + @amount_minor,
+    paid_at = CASE
+                  WHEN paid_at IS NULL AND paid_minor + @amount_minor >= total_due_minor THEN @payment_date
+                  ELSE paid_at
+              END
+WHERE id = @id
+RETURNING *;
+
+-- name: GetStatementsToRemind :many
+-- Unpaid statements due between the given dates that no reminder was raised for.
+SELECT * FROM statements
+WHERE paid_at IS NULL
+  AND reminded_at IS NULL
+  AND due_date >= @from_date
+  AND due_date <= @to_date
+ORDER BY due_date ASC;
+
+-- name: MarkStatementReminded :exec
+UPDATE statements
+SET reminded_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: GetFirstTransactionDate :one
+SELECT CAST(COALESCE(MIN(transaction_date), '') AS TEXT) AS first_date
+FROM transactions
+WHERE card_id = ?;
+
+-- name: GetCardBalanceAsOf :one
+-- Outstanding balance of a card at the end of the given date.
+SELECT CAST(COALESCE((SELECT SUM(t.amount_minor)
+                      FROM transactions t
+                      WHERE t.card_id = @card_id AND t.transaction_date <= @as_of), 0) AS INTEGER) AS spent,
+       CAST(COALESCE((SELECT SUM(p.amount_minor)
+                      FROM payments p
+                      WHERE p.card_id = @card_id AND p.payment_date <= @as_of), 0) AS INTEGER) AS paid;
\ No newline at end of file
@@ -0,0 +1,59 @@
+-- name: CreateTransaction :one
+INSERT INTO transactions (card_id,
+                          merchant,
+                          category,
+                          amount_minor,
+                          transaction_date,
+                          channel,
+                          notes,
+                          source,
+                          external_id,
+                          spent_by)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetTransactions :many
+SELECT * FROM transactions
+WHERE card_id IN (SELECT id FROM cards WHERE user_id = ?)
+ORDER BY transaction_date DESC, id DESC;
+
+-- name: GetTransactionsByCardID :many
+SELECT * FROM transactions
+WHERE card_id = ?
+  AND card_id IN (SELECT id FROM cards WHERE user_id = ?)
+ORDER BY transaction_date DESC, id DESC;
+
+-- name: GetCardTransactionsBetween :many
+-- Transactions of a card dated from FromDate to ToDate, both inclusive.
+SELECT * FROM transactions
+WHERE card_id = @card_id
+  AND transaction_date >= @from_date
+  AND transaction_date <= @to_date
+ORDER BY transaction_date, id;
+
+-- name: TransactionExternalIDExists :one
+-- Whether a transaction of the card was imported with the external ID.
+SELECT EXISTS (SELECT 1 FROM transactions WHERE card_id = @card_id AND external_id = @external_id);
+
+-- name: GetHouseholdLedger :many
+-- Transactions dated from FromDate to ToDate, both inclusive, of the cards of a user and of the
+-- cards the other members of their household share. Each is attributed to the member who made
+-- the spend, the card's holder unless recorded otherwise.
+SELECT sqlc.embed(transactions),
+       cards.name      AS card_name,
+       holder.username AS holder,
+       member.id       AS member_id,
+       member.username AS member
+FROM transactions
+         JOIN cards ON cards.id = transactions.card_id
+         JOIN users holder ON holder.id = cards.user_id
+         JOIN users member ON member.id = COALESCE(transactions.spent_by, cards.user_id)
+WHERE (cards.user_id = @user_id
+    OR (cards.shared AND cards.user_id IN (SELECT m.user_id
+                                           FROM household_members m
+                                           WHERE m.household_id = (SELECT household_id
+                                                                   FROM household_members
+                                                                   WHERE household_members.user_id = @user_id))))
+  AND transactions.transaction_date >= @from_date
+  AND transactions.transaction_date <= @to_date
+ORDER BY transactions.transaction_date DESC, transactions.id DESC;
\ No newline at end of file
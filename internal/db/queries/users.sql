@@ -0,0 +1,65 @@
+-- name: CreateUser :one
+INSERT INTO users (username, password_hash)
+VALUES (?, ?)
+RETURNING *;
+
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = ?
+LIMIT 1;
+
+-- name: GetUserByUsername :one
+SELECT * FROM users
+WHERE username = ?
+LIMIT 1;
+
+-- name: GetFirstUser :one
+-- The first user to register, who owns what was added before there were users.
+SELECT * FROM users
+ORDER BY id ASC
+LIMIT 1;
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
+
+-- name: ClaimUnownedCards :execrows
+UPDATE cards
+SET user_id = ?
+WHERE user_id IS NULL;
+
+-- name: ClaimUnownedNotificationPreferences :exec
+UPDATE notification_preferences
+SET user_id = ?
+WHERE user_id IS NULL;
+
+-- name: ClaimUnownedPushSubscriptions :exec
+UPDATE push_subscriptions
+SET user_id = ?
+WHERE user_id IS NULL;
+
+-- name: ClaimUnownedMerchantMappings :exec
+UPDATE merchant_mappings
+SET user_id = ?
+WHERE user_id IS NULL;
+
+-- name: CreateSession :one
+INSERT INTO sessions (id, user_id, expires_at)
+VALUES (?, ?, ?)
+RETURNING *;
+
+-- name: GetSessionUser :one
+-- The user logged in with a session that has not expired.
+SELECT sqlc.embed(users)
+FROM sessions
+         JOIN users ON users.id = sessions.user_id
+WHERE sessions.id = @id
+  AND sessions.expires_at > @now
+LIMIT 1;
+
+-- name: DeleteSession :exec
+DELETE FROM sessions
+WHERE id = ?;
+
+-- name: DeleteExpiredSessions :execrows
+DELETE FROM sessions
+WHERE expires_at <= ?;
\ No newline at end of file
@@ -0,0 +1,260 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/billing"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"log/slog"
+	"time"
+)
+
+// ErrStatementCardMismatch is returned when a payment is made towards another card's statement
+var ErrStatementCardMismatch = errors.New("statement does not belong to the card")
+
+// RecordPaymentParams are the details of a payment towards a card
+type RecordPaymentParams struct {
+	CardID int64
+	// StatementID is the statement the payment is made towards. When nil, the payment is applied
+	// to the card's oldest unpaid statement, if any.
+	StatementID *int64
+	Amount      money.Money
+	PaymentDate string
+	Notes       *string
+	// Source is where the payment came from, defaults to SourceManual
+	Source string
+	// ExternalID is the ID of an imported payment in its source
+	ExternalID *string
+}
+
+// CardSchedule returns the billing schedule of a user card
+func CardSchedule(card *models.Card) billing.Schedule {
+	return billing.Schedule{
+		StatementDay:   int(card.StatementDay),
+		PaymentDueDays: int(card.PaymentDueDays),
+	}
+}
+
+// GenerateStatements generates the statements of every user card for the cycles that closed
+// before now, starting from the cycle of the card's latest statement, or of its first transaction
+// when it has none.
+// The total of the latest statement is recomputed while it is unpaid, so spends logged late are
+// billed: a statement bills the whole outstanding balance, including late spends of earlier
+// cycles. It returns the number of new or updated statements.
+func (d *DB) GenerateStatements(ctx context.Context, log *slog.Logger, now time.Time) (int, error) {
+	cardList, err := d.Queries.GetAllCards(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get cards: %w", err)
+	}
+
+	today := billing.Date(now)
+	generated := 0
+
+	for _, card := range cardList {
+		schedule := CardSchedule(card)
+
+		cycle, ok, err := d.firstCycle(ctx, card.ID, schedule)
+		if err != nil {
+			return generated, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		// A cycle's statement is generated once its statement date has passed
+		for cycle.StatementDate.Before(today) {
+			created, err := d.createStatement(ctx, card.ID, cycle)
+			if err != nil {
+				return generated, err
+			}
+
+			if created {
+				generated++
+			}
+
+			cycle = schedule.CycleFor(cycle.StatementDate.AddDate(0, 0, 1))
+		}
+	}
+
+	if generated > 0 {
+		log.InfoContext(ctx, "generated or updated statements", slog.Int("count", generated))
+	}
+
+	return generated, nil
+}
+
+// firstCycle returns the cycle statements of the card are generated from: that of its latest
+// statement, or of its first transaction when it has none. It reports false for a card with
+// neither.
+func (d *DB) firstCycle(ctx context.Context, cardID int64, schedule billing.Schedule) (billing.Cycle, bool, error) {
+	var from string
+
+	latest, err := d.Queries.GetLatestStatement(ctx, cardID)
+
+	switch {
+	case err == nil:
+		from = latest.StatementDate
+	case errors.Is(err, sql.ErrNoRows):
+		from, err = d.Queries.GetFirstTransactionDate(ctx, cardID)
+		if err != nil {
+			return billing.Cycle{}, false, fmt.Errorf("failed to get first transaction of card %d: %w", cardID, err)
+		}
+	default:
+		return billing.Cycle{}, false, fmt.Errorf("failed to get latest statement of card %d: %w", cardID, err)
+	}
+
+	if from == "" {
+		return billing.Cycle{}, false, nil
+	}
+
+	date, err := time.Parse(time.DateOnly, from)
+	if err != nil {
+		return billing.Cycle{}, false, fmt.Errorf("invalid date %q of card %d: %w", from, cardID, err)
+	}
+
+	return schedule.CycleFor(date), true, nil
+}
+
+// createStatement generates the statement of a cycle or refreshes its totals, reporting whether it
+// was created or updated
+func (d *DB) createStatement(ctx context.Context, cardID int64, cycle billing.Cycle) (bool, error) {
+	statementDate := cycle.StatementDate.Format(time.DateOnly)
+
+	balance, err := d.Queries.GetCardBalanceAsOf(ctx, models.GetCardBalanceAsOfParams{
+		CardID: cardID,
+		AsOf:   statementDate,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get balance of card %d on %s: %w", cardID, statementDate, err)
+	}
+
+	// A credit balance carries forward, nothing is due on it
+	total := money.Max(money.New(balance.Spent-balance.Paid, money.INR), money.Money{})
+
+	// A statement with nothing due is settled as soon as it is generated
+	var paidAt *string
+	if total.IsZero() {
+		paidAt = &statementDate
+	}
+
+	_, err = d.Queries.CreateStatement(ctx, models.CreateStatementParams{
+		CardID:          cardID,
+		CycleStart:      cycle.Start.Format(time.DateOnly),
+		StatementDate:   statementDate,
+		DueDate:         cycle.DueDate.Format(time.DateOnly),
+		TotalDueMinor:   total,
+		MinimumDueMinor: billing.MinimumDue(total),
+		PaidAt:          paidAt,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to create statement of card %d on %s: %w", cardID, statementDate, err)
+	}
+
+	return true, nil
+}
+
+// RecordPayment records a payment towards a card and applies it to a statement.
+// It returns the payment and the statement it was applied to, which is nil if there was none.
+func (d *DB) RecordPayment(
+	ctx context.Context,
+	log *slog.Logger,
+	params RecordPaymentParams,
+) (payment *models.Payment, statement *models.Statement, err error) {
+	tx, err := d.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := d.Queries.WithTx(tx)
+
+	// If we return with an error, rollback the transaction
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.ErrorContext(ctx, "failed to rollback transaction", logger.Error(rbErr))
+			}
+		}
+	}()
+
+	payment, statement, err = recordPayment(ctx, q, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return payment, statement, nil
+}
+
+// recordPayment records a payment and applies it to a statement using the given queries
+func recordPayment(
+	ctx context.Context,
+	q *models.Queries,
+	params RecordPaymentParams,
+) (payment *models.Payment, statement *models.Statement, err error) {
+	if params.Source == "" {
+		params.Source = SourceManual
+	}
+
+	if params.StatementID != nil {
+		statement, err = q.GetStatementByID(ctx, *params.StatementID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get statement %d: %w", *params.StatementID, err)
+		}
+
+		if statement.CardID != params.CardID {
+			return nil, nil, ErrStatementCardMismatch
+		}
+	} else {
+		statement, err = q.GetOldestUnpaidStatement(ctx, params.CardID)
+		if errors.Is(err, sql.ErrNoRows) {
+			statement, err = nil, nil
+		}
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get unpaid statement of card %d: %w", params.CardID, err)
+		}
+	}
+
+	var statementID *int64
+	if statement != nil {
+		statementID = &statement.ID
+	}
+
+	payment, err = q.CreatePayment(ctx, models.CreatePaymentParams{
+		CardID:      params.CardID,
+		AmountMinor: params.Amount,
+		PaymentDate: params.PaymentDate,
+		Notes:       params.Notes,
+		StatementID: statementID,
+		Source:      params.Source,
+		ExternalID:  params.ExternalID,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create payment: %w", err)
+	}
+
+	if statement != nil {
+		statement, err = q.AddStatementPayment(ctx, models.AddStatementPaymentParams{
+			ID:          statement.ID,
+			AmountMinor: params.Amount,
+			PaymentDate: &params.PaymentDate,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to apply payment to statement %d: %w", statement.ID, err)
+		}
+	}
+
+	return payment, statement, nil
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+// statement is what a generated statement bills
+type statement struct {
+	date       string
+	total      string
+	minimumDue string
+	paid       bool
+}
+
+// generate generates the statements due on the date and checks the card's statements, oldest first
+func generate(t *testing.T, card *models.Card, now string, want []statement) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	date, _ := time.Parse(time.DateOnly, now)
+
+	_, err := testDB.GenerateStatements(ctx, discard, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := testDB.Queries.GetStatementsByCardID(ctx, models.GetStatementsByCardIDParams{
+		CardID: card.ID,
+		UserID: card.UserID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slices.Reverse(list)
+
+	if len(list) != len(want) {
+		t.Fatalf("%s: %d statements, want %d", now, len(list), len(want))
+	}
+
+	for i, w := range want {
+		got := list[i]
+
+		if got.StatementDate != w.date || got.TotalDueMinor.Cmp(inr(w.total)) != 0 ||
+			got.MinimumDueMinor.Cmp(inr(w.minimumDue)) != 0 || (got.PaidAt != nil) != w.paid {
+			t.Errorf("%s: statement %s of %v, minimum %v, paid %t, want %s of %s, minimum %s, paid %t",
+				now, got.StatementDate, got.TotalDueMinor, got.MinimumDueMinor, got.PaidAt != nil,
+				w.date, w.total, w.minimumDue, w.paid)
+		}
+	}
+}
+
+func TestGenerateStatements(t *testing.T) {
+	ctx := context.Background()
+
+	card := newCard(t, "statements")
+
+	generate(t, card, "2026-09-20", nil)
+
+	spend(t, card, "2026-08-10", "3000")
+	spend(t, card, "2026-09-01", "1000")
+	spend(t, card, "2026-09-10", "100")
+
+	// The cycle from 6 September is open until its statement date has passed
+	generate(t, card, "2026-09-20", []statement{
+		{date: "2026-09-05", total: "4000", minimumDue: "200"},
+	})
+
+	// A spend logged late is billed while the statement is unpaid
+	spend(t, card, "2026-08-20", "500")
+	generate(t, card, "2026-09-20", []statement{
+		{date: "2026-09-05", total: "4500", minimumDue: "225"},
+	})
+
+	_, paid, err := testDB.RecordPayment(ctx, discard, RecordPaymentParams{
+		CardID:      card.ID,
+		Amount:      inr("4500"),
+		PaymentDate: "2026-09-22",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if paid == nil || paid.StatementDate != "2026-09-05" || paid.PaidAt == nil {
+		t.Fatalf("payment settled %+v, want the statement of 5 September", paid)
+	}
+
+	// A paid statement is not recomputed, a late spend is billed on the next one
+	spend(t, card, "2026-09-02", "300")
+	spend(t, card, "2026-09-12", "-50")
+	generate(t, card, "2026-09-25", []statement{
+		{date: "2026-09-05", total: "4500", minimumDue: "225", paid: true},
+	})
+
+	generate(t, card, "2026-10-18", []statement{
+		{date: "2026-09-05", total: "4500", minimumDue: "225", paid: true},
+		{date: "2026-10-05", total: "350", minimumDue: "200"},
+	})
+
+	spend(t, card, "2026-10-01", "120")
+	generate(t, card, "2026-10-18", []statement{
+		{date: "2026-09-05", total: "4500", minimumDue: "225", paid: true},
+		{date: "2026-10-05", total: "470", minimumDue: "200"},
+	})
+}
+
+// TestGenerateStatementsFromLatest checks that statements are generated from the card's latest
+// statement rather than its first transaction, e.g. after a statement was imported
+func TestGenerateStatementsFromLatest(t *testing.T) {
+	card := newCard(t, "statements-latest")
+
+	spend(t, card, "2026-06-10", "2000")
+	spend(t, card, "2026-08-10", "500")
+
+	_, err := testDB.Queries.CreateStatement(context.Background(), models.CreateStatementParams{
+		CardID:          card.ID,
+		CycleStart:      "2026-08-06",
+		StatementDate:   "2026-09-05",
+		DueDate:         "2026-09-25",
+		TotalDueMinor:   inr("2000"),
+		MinimumDueMinor: inr("200"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The unpaid latest statement is brought up to the balance of the ledger
+	generate(t, card, "2026-10-18", []statement{
+		{date: "2026-09-05", total: "2500", minimumDue: "200"},
+		{date: "2026-10-05", total: "2500", minimumDue: "200"},
+	})
+}
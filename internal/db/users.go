@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"log/slog"
+)
+
+var (
+	// ErrUsernameTaken is returned when registering a username that is in use, regardless of case
+	ErrUsernameTaken = errors.New("username is already taken")
+	// ErrRegistrationClosed is returned when registering once there is an account and registration
+	// is not open
+	ErrRegistrationClosed = errors.New("registration is closed")
+)
+
+// RegisterUser creates a user in a single database transaction. Once there is a user, only open
+// registration creates more. The first user claims the cards, notification preferences, push
+// subscriptions and merchant mappings added before there were users.
+func (d *DB) RegisterUser(
+	ctx context.Context,
+	log *slog.Logger,
+	username, passwordHash string,
+	open bool,
+) (user *models.User, err error) {
+	tx, err := d.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	q := d.Queries.WithTx(tx)
+
+	// If we return with an error, rollback the transaction
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.ErrorContext(ctx, "failed to rollback transaction", logger.Error(rbErr))
+			}
+		}
+	}()
+
+	count, err := q.CountUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	if count > 0 && !open {
+		return nil, ErrRegistrationClosed
+	}
+
+	_, err = q.GetUserByUsername(ctx, username)
+	if err == nil {
+		return nil, ErrUsernameTaken
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	user, err = q.CreateUser(ctx, models.CreateUserParams{
+		Username:     username,
+		PasswordHash: passwordHash,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	if count == 0 {
+		claimed, err := q.ClaimUnownedCards(ctx, &user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim cards: %w", err)
+		}
+
+		err = q.ClaimUnownedNotificationPreferences(ctx, &user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim notification preferences: %w", err)
+		}
+
+		err = q.ClaimUnownedPushSubscriptions(ctx, &user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim push subscriptions: %w", err)
+		}
+
+		err = q.ClaimUnownedMerchantMappings(ctx, &user.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim merchant mappings: %w", err)
+		}
+
+		log.InfoContext(ctx, "first user claimed existing cards", slog.Int64("cards", claimed))
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return user, nil
+}
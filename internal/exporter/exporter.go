@@ -0,0 +1,80 @@
+// Package exporter writes the ledger as OFX and QIF files, for personal finance tools and for
+// importing into another CardMax instance
+package exporter
+
+import (
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"strings"
+	"time"
+)
+
+// Types of entries
+const (
+	TypeDebit   = "DEBIT"
+	TypeCredit  = "CREDIT"
+	TypePayment = "PAYMENT"
+)
+
+type (
+	// Entry is a transaction or payment of a card
+	Entry struct {
+		// ID identifies the entry across exports, written as the FITID
+		ID   string
+		Type string
+		Date time.Time
+		Name string
+		// Amount is signed the way statements are, negative for a spend
+		Amount   money.Money
+		Category string
+		// Reward describes the reward the spend earned, e.g. "50 points (INR 12.50)"
+		Reward string
+		Notes  string
+	}
+
+	// Account is a card and its entries
+	Account struct {
+		Name  string
+		Last4 string
+		// Balance is the amount owed on the card, negative as in statements
+		Balance money.Money
+		Entries []Entry
+	}
+
+	// Statement is the accounts exported for a date range
+	Statement struct {
+		Start, End time.Time
+		// Generated is when the export was made
+		Generated time.Time
+		Accounts  []Account
+	}
+)
+
+// Memo returns the memo of the entry, holding its category, reward and notes
+func (e *Entry) Memo() string {
+	var parts []string
+
+	if e.Category != "" {
+		parts = append(parts, "Category: "+e.Category)
+	}
+
+	if e.Reward != "" {
+		parts = append(parts, "Reward: "+e.Reward)
+	}
+
+	if e.Notes != "" && e.Notes != e.Name {
+		parts = append(parts, e.Notes)
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// accountID is the masked card number of the account
+func (a *Account) accountID() string {
+	return "XXXXXXXXXXXX" + a.Last4
+}
+
+// qifName is the account name, ending in the last four digits so that imports can match the card
+func (a *Account) qifName() string {
+	return fmt.Sprintf("%s %s", a.Name, a.Last4)
+}
@@ -0,0 +1,170 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// OFX versions that can be written
+const (
+	// OFXVersion1 is SGML based OFX 1.0.3, read by most desktop finance tools
+	OFXVersion1 = 1
+	// OFXVersion2 is XML based OFX 2.1.1
+	OFXVersion2 = 2
+)
+
+// ofxNameLength is the longest NAME allowed by the specification
+const ofxNameLength = 32
+
+const ofxV1Header = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:103
+SECURITY:NONE
+ENCODING:UTF-8
+CHARSET:NONE
+COMPRESSION:NONE
+OLDFILEUID:NONE
+NEWFILEUID:NONE
+
+`
+
+const ofxV2Header = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="211" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+`
+
+var ofxEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// ofxWriter writes OFX aggregates and elements. In version 1 elements have no end tags.
+type ofxWriter struct {
+	w       *bufio.Writer
+	version int
+	depth   int
+}
+
+// WriteOFX writes the statement as a credit card statement download, with one statement per account
+func WriteOFX(w io.Writer, s *Statement, version int) error {
+	if version != OFXVersion1 && version != OFXVersion2 {
+		return fmt.Errorf("unsupported OFX version %d", version)
+	}
+
+	o := &ofxWriter{w: bufio.NewWriter(w), version: version}
+
+	if version == OFXVersion1 {
+		o.w.WriteString(ofxV1Header)
+	} else {
+		o.w.WriteString(ofxV2Header)
+	}
+
+	o.open("OFX")
+
+	o.open("SIGNONMSGSRSV1")
+	o.open("SONRS")
+	o.status()
+	o.element("DTSERVER", ofxDateTime(s.Generated))
+	o.element("LANGUAGE", "ENG")
+	o.close("SONRS")
+	o.close("SIGNONMSGSRSV1")
+
+	o.open("CREDITCARDMSGSRSV1")
+
+	for i, account := range s.Accounts {
+		o.open("CCSTMTTRNRS")
+		o.element("TRNUID", fmt.Sprint(i+1))
+		o.status()
+
+		o.open("CCSTMTRS")
+		o.element("CURDEF", "INR")
+		o.open("CCACCTFROM")
+		o.element("ACCTID", account.accountID())
+		o.close("CCACCTFROM")
+
+		o.open("BANKTRANLIST")
+		o.element("DTSTART", ofxDate(s.Start))
+		o.element("DTEND", ofxDate(s.End))
+
+		for _, entry := range account.Entries {
+			o.open("STMTTRN")
+			o.element("TRNTYPE", entry.Type)
+			o.element("DTPOSTED", ofxDate(entry.Date))
+			o.element("TRNAMT", entry.Amount.Decimal())
+			o.element("FITID", entry.ID)
+			o.element("NAME", truncate(entry.Name, ofxNameLength))
+
+			if memo := entry.Memo(); memo != "" {
+				o.element("MEMO", memo)
+			}
+
+			o.close("STMTTRN")
+		}
+
+		o.close("BANKTRANLIST")
+
+		o.open("LEDGERBAL")
+		o.element("BALAMT", account.Balance.Decimal())
+		o.element("DTASOF", ofxDateTime(s.Generated))
+		o.close("LEDGERBAL")
+
+		o.close("CCSTMTRS")
+		o.close("CCSTMTTRNRS")
+	}
+
+	o.close("CREDITCARDMSGSRSV1")
+	o.close("OFX")
+
+	return o.w.Flush()
+}
+
+func (o *ofxWriter) open(name string) {
+	o.indent()
+	fmt.Fprintf(o.w, "<%s>\n", name)
+	o.depth++
+}
+
+func (o *ofxWriter) close(name string) {
+	o.depth--
+	o.indent()
+	fmt.Fprintf(o.w, "</%s>\n", name)
+}
+
+func (o *ofxWriter) element(name, value string) {
+	o.indent()
+
+	if o.version == OFXVersion1 {
+		fmt.Fprintf(o.w, "<%s>%s\n", name, ofxEscaper.Replace(value))
+		return
+	}
+
+	fmt.Fprintf(o.w, "<%s>%s</%s>\n", name, ofxEscaper.Replace(value), name)
+}
+
+func (o *ofxWriter) status() {
+	o.open("STATUS")
+	o.element("CODE", "0")
+	o.element("SEVERITY", "INFO")
+	o.close("STATUS")
+}
+
+func (o *ofxWriter) indent() {
+	o.w.WriteString(strings.Repeat("  ", o.depth))
+}
+
+func ofxDate(t time.Time) string {
+	return t.Format("20060102")
+}
+
+func ofxDateTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
+
+// truncate shortens the string to at most n characters
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+
+	return string(r[:n])
+}
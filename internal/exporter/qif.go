@@ -0,0 +1,48 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// qifPaymentCategory marks a payment as a transfer into the card account
+const qifPaymentCategory = "[Payments]"
+
+// WriteQIF writes the statement as QIF, with an account block and the credit card transactions of
+// each account. Dates are written month first, as QIF readers expect.
+func WriteQIF(w io.Writer, s *Statement) error {
+	bw := bufio.NewWriter(w)
+
+	for _, account := range s.Accounts {
+		fmt.Fprintf(bw, "!Account\nN%s\nTCCard\n^\n", qifField(account.qifName()))
+		fmt.Fprintln(bw, "!Type:CCard")
+
+		for _, entry := range account.Entries {
+			fmt.Fprintf(bw, "D%s\n", entry.Date.Format("01/02/2006"))
+			fmt.Fprintf(bw, "T%s\n", entry.Amount.Decimal())
+			fmt.Fprintf(bw, "P%s\n", qifField(entry.Name))
+
+			if memo := entry.Memo(); memo != "" {
+				fmt.Fprintf(bw, "M%s\n", qifField(memo))
+			}
+
+			switch {
+			case entry.Type == TypePayment:
+				fmt.Fprintf(bw, "L%s\n", qifPaymentCategory)
+			case entry.Category != "":
+				fmt.Fprintf(bw, "L%s\n", qifField(entry.Category))
+			}
+
+			fmt.Fprintf(bw, "N%s\n^\n", qifField(entry.ID))
+		}
+	}
+
+	return bw.Flush()
+}
+
+// qifField removes line breaks, which would end the field
+func qifField(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
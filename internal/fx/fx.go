@@ -0,0 +1,75 @@
+package fx
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"strings"
+)
+
+// BaseCurrency is the currency all amounts are converted into before rewards are calculated
+const BaseCurrency = "INR"
+
+type (
+	// Rate is the value of one unit of a foreign currency in INR
+	Rate struct {
+		Currency  string  `json:"currency"`
+		RateToINR float64 `json:"rate_to_inr"`
+		AsOf      string  `json:"as_of"`
+	}
+
+	// rateTable is the format of the exchange rate file shipped in data/fx
+	rateTable struct {
+		Base  string             `json:"base"`
+		AsOf  string             `json:"as_of"`
+		Rates map[string]float64 `json:"rates"`
+	}
+)
+
+// Parse parses the exchange rate table from data/fx/rates.json
+func Parse(data embed.FS) ([]*Rate, error) {
+	const fn = "data/fx/rates.json"
+
+	file, err := data.ReadFile(fn)
+	if err != nil {
+		return nil, fmt.Errorf("error reading exchange rate file %s: %w", fn, err)
+	}
+
+	var table rateTable
+
+	err = json.Unmarshal(file, &table)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling exchange rates %s: %w", fn, err)
+	}
+
+	if !IsBase(table.Base) {
+		return nil, fmt.Errorf("exchange rates %s must be based on %s, got %q", fn, BaseCurrency, table.Base)
+	}
+
+	rates := make([]*Rate, 0, len(table.Rates))
+
+	for currency, rate := range table.Rates {
+		if rate <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate %f for %s in %s", rate, currency, fn)
+		}
+
+		rates = append(rates, &Rate{
+			Currency:  strings.ToUpper(currency),
+			RateToINR: rate,
+			AsOf:      table.AsOf,
+		})
+	}
+
+	return rates, nil
+}
+
+// IsBase reports whether the currency is the base currency. An empty currency is treated as the base.
+func IsBase(currency string) bool {
+	return currency == "" || strings.EqualFold(currency, BaseCurrency)
+}
+
+// ToINR converts an amount in the rate's currency to INR, rounded half to even to the paisa
+func (r *Rate) ToINR(amount money.Money) money.Money {
+	return amount.Convert(r.RateToINR, money.INR, money.HalfEven)
+}
@@ -0,0 +1,328 @@
+package importer
+
+import (
+	"cmp"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type (
+	// AlertTemplate describes the transaction alerts, SMS or email, of an issuer
+	AlertTemplate struct {
+		Key    string `json:"key"`
+		Issuer string `json:"issuer"`
+		Name   string `json:"name"`
+		// Detect are phrases identifying the issuer's alerts, matched case-insensitively
+		Detect []string `json:"detect"`
+		// DateFormats are the Go layouts dates are parsed with, tried in order
+		DateFormats []string `json:"date_formats"`
+		// Patterns are tried in order against an alert, with its whitespace collapsed
+		Patterns []*AlertPattern `json:"patterns"`
+		// Examples are alerts along with the row they must be read as. They are checked when the
+		// template is parsed, so a pattern that no longer matches them fails at startup.
+		Examples []AlertExample `json:"examples,omitempty"`
+	}
+
+	// AlertPattern is a Go regular expression matching one kind of alert. It has an "amount"
+	// group and optional "card", "merchant" and "date" groups. Alerts without a date are dated
+	// the day they were received.
+	AlertPattern struct {
+		// Kind is the kind of the transactions the pattern matches: spend, refund or payment
+		Kind    string `json:"kind"`
+		Pattern string `json:"pattern"`
+
+		re *regexp.Regexp
+	}
+
+	// AlertExample is an alert along with the row it must be read as. Empty fields are not checked.
+	AlertExample struct {
+		Text        string `json:"text"`
+		Kind        string `json:"kind"`
+		Date        string `json:"date,omitempty"`
+		Amount      string `json:"amount"`
+		Last4       string `json:"last4,omitempty"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// Alert is a message of pasted or forwarded alert text
+	Alert struct {
+		// Line is the line of the text the message starts on
+		Line int
+		Text string
+	}
+)
+
+// ParseAlertTemplates parses the alert templates in the data/alerts directory and checks each
+// against its examples
+func ParseAlertTemplates(data embed.FS) ([]*AlertTemplate, error) {
+	entries, err := data.ReadDir("data/alerts")
+	if err != nil {
+		return nil, fmt.Errorf("error reading alerts dir: %w", err)
+	}
+
+	templates := make([]*AlertTemplate, 0, len(entries))
+
+	for _, entry := range entries {
+		fn := fmt.Sprintf("data/alerts/%s", entry.Name())
+
+		file, err := data.ReadFile(fn)
+		if err != nil {
+			return nil, fmt.Errorf("error reading alert template file %s: %w", fn, err)
+		}
+
+		var t AlertTemplate
+
+		err = json.Unmarshal(file, &t)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling alert template %s: %w", fn, err)
+		}
+
+		err = t.compile()
+		if err != nil {
+			return nil, fmt.Errorf("invalid alert template %s: %w", fn, err)
+		}
+
+		err = t.Check()
+		if err != nil {
+			return nil, fmt.Errorf("alert template %s fails its examples: %w", fn, err)
+		}
+
+		templates = append(templates, &t)
+	}
+
+	return templates, nil
+}
+
+// SplitAlerts splits text into messages separated by blank lines, e.g. several pasted SMS
+func SplitAlerts(text string) []Alert {
+	var (
+		alerts []Alert
+		lines  []string
+		start  int
+	)
+
+	flush := func() {
+		if len(lines) > 0 {
+			alerts = append(alerts, Alert{Line: start, Text: strings.Join(lines, " ")})
+			lines = nil
+		}
+	}
+
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			flush()
+			continue
+		}
+
+		if len(lines) == 0 {
+			start = i + 1
+		}
+
+		lines = append(lines, line)
+	}
+
+	flush()
+
+	return alerts
+}
+
+// DetectAlertTemplate returns the template of an alert. When the phrases of more than one
+// template appear, the earliest one wins.
+func DetectAlertTemplate(templates []*AlertTemplate, text string) (*AlertTemplate, bool) {
+	text = strings.ToUpper(text)
+
+	var (
+		found *AlertTemplate
+		first = -1
+	)
+
+	for _, t := range templates {
+		for _, phrase := range t.Detect {
+			i := strings.Index(text, strings.ToUpper(phrase))
+			if i >= 0 && (first < 0 || i < first) {
+				found, first = t, i
+			}
+		}
+	}
+
+	return found, found != nil
+}
+
+// ParseAlerts reads the transactions of alert text, with one alert per message. Each message
+// is read with the template detected from it, or with the only template when just one is given.
+// Alerts without a date are dated the day they were received. Messages that are not a
+// transaction alert are returned as row errors.
+func ParseAlerts(templates []*AlertTemplate, text string, received time.Time) *File {
+	file := &File{Source: db.SourceAlert}
+	if len(templates) == 1 {
+		file.Mapping = templates[0].Key
+	}
+
+	for _, alert := range SplitAlerts(text) {
+		t, ok := DetectAlertTemplate(templates, alert.Text)
+		if !ok && len(templates) == 1 {
+			t, ok = templates[0], true
+		}
+
+		if !ok {
+			file.Errors = append(file.Errors, RowError{Line: alert.Line, Error: "no alert template matches the message"})
+			continue
+		}
+
+		row, err := t.Parse(alert.Text, received)
+		if err != nil {
+			file.Errors = append(file.Errors, RowError{Line: alert.Line, Error: err.Error()})
+			continue
+		}
+
+		row.Line = alert.Line
+		file.Rows = append(file.Rows, row)
+	}
+
+	return file
+}
+
+// Parse reads the transaction of an alert with the first pattern that matches it
+func (t *AlertTemplate) Parse(text string, received time.Time) (Row, error) {
+	text = strings.Join(strings.Fields(text), " ")
+
+	for _, p := range t.Patterns {
+		m := p.re.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+
+		return t.parseRow(p, m, received)
+	}
+
+	return Row{}, fmt.Errorf("the message is not a transaction alert of %s", t.Issuer)
+}
+
+func (t *AlertTemplate) parseRow(p *AlertPattern, m []string, received time.Time) (Row, error) {
+	group := func(name string) string {
+		if i := p.re.SubexpIndex(name); i > 0 {
+			return strings.TrimSpace(m[i])
+		}
+
+		return ""
+	}
+
+	date := received
+	if value := group("date"); value != "" {
+		var err error
+
+		date, err = parseDate(t.DateFormats, value)
+		if err != nil {
+			return Row{}, err
+		}
+	}
+
+	amount, _, err := parseAmount(group("amount"))
+	if err != nil {
+		return Row{}, err
+	}
+
+	if amount.IsZero() {
+		return Row{}, fmt.Errorf("amount is zero")
+	}
+
+	merchant := strings.Trim(strings.Join(strings.Fields(group("merchant")), " "), ".,")
+
+	row := Row{
+		Date:        date.Format(time.DateOnly),
+		Description: cmp.Or(merchant, fmt.Sprintf("%s %s", t.Issuer, p.Kind)),
+		Amount:      amount,
+		Credit:      p.Kind != db.DraftKindSpend,
+		Payment:     p.Kind == db.DraftKindPayment,
+		Last4:       last4(group("card")),
+	}
+
+	row.score(db.SourceAlert+":"+t.Key, confidenceAlert)
+
+	if group("date") == "" {
+		row.Penalise(penaltyNoDate)
+	}
+
+	if merchant == "" {
+		row.Penalise(penaltyNoMerchant)
+	}
+
+	return row, nil
+}
+
+// Check reads the examples of the template, returning an error for the first one that is not
+// read as expected
+func (t *AlertTemplate) Check() error {
+	received := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, ex := range t.Examples {
+		row, err := t.Parse(ex.Text, received)
+		if err != nil {
+			return fmt.Errorf("example %d: %w", i+1, err)
+		}
+
+		kind := db.DraftKindSpend
+		switch {
+		case row.Payment:
+			kind = db.DraftKindPayment
+		case row.Credit:
+			kind = db.DraftKindRefund
+		}
+
+		for _, field := range []struct{ name, want, got string }{
+			{"kind", ex.Kind, kind},
+			{"date", ex.Date, row.Date},
+			{"amount", ex.Amount, row.Amount.Decimal()},
+			{"last4", ex.Last4, row.Last4},
+			{"description", ex.Description, row.Description},
+		} {
+			if field.want != "" && field.want != field.got {
+				return fmt.Errorf("example %d: %s is %q, expected %q", i+1, field.name, field.got, field.want)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t *AlertTemplate) compile() error {
+	switch {
+	case t.Key == "":
+		return fmt.Errorf("key is required")
+	case len(t.Detect) == 0:
+		return fmt.Errorf("at least one detect phrase is required")
+	case len(t.Patterns) == 0:
+		return fmt.Errorf("at least one pattern is required")
+	}
+
+	for _, p := range t.Patterns {
+		switch p.Kind {
+		case db.DraftKindSpend, db.DraftKindRefund, db.DraftKindPayment:
+		default:
+			return fmt.Errorf("invalid kind %q of pattern %q, expected spend, refund or payment", p.Kind, p.Pattern)
+		}
+
+		var err error
+
+		p.re, err = regexp.Compile(p.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", p.Pattern, err)
+		}
+
+		if p.re.SubexpIndex("amount") < 0 {
+			return fmt.Errorf("pattern %q has no \"amount\" group", p.Pattern)
+		}
+
+		if p.re.SubexpIndex("date") >= 0 && len(t.DateFormats) == 0 {
+			return fmt.Errorf("pattern %q has a \"date\" group but the template has no date formats", p.Pattern)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"encoding/json"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// alertFixtures are real-shaped SMS and email alerts of an issuer, along with messages that are
+// not transactions and must not be read into drafts
+type alertFixtures struct {
+	Alerts []struct {
+		Name     string `json:"name"`
+		Text     string `json:"text"`
+		Kind     string `json:"kind"`
+		Date     string `json:"date"`
+		Amount   string `json:"amount"`
+		Last4    string `json:"last4"`
+		Merchant string `json:"merchant"`
+	} `json:"alerts"`
+	Ignored []struct {
+		Name string `json:"name"`
+		Text string `json:"text"`
+	} `json:"ignored"`
+}
+
+// loadAlertTemplates parses the alert templates shipped in data/alerts
+func loadAlertTemplates(t *testing.T) []*AlertTemplate {
+	t.Helper()
+
+	files, err := filepath.Glob("../../data/alerts/*.json")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no alert templates found: %v", err)
+	}
+
+	templates := make([]*AlertTemplate, 0, len(files))
+
+	for _, fn := range files {
+		b, err := os.ReadFile(fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var tmpl AlertTemplate
+
+		err = json.Unmarshal(b, &tmpl)
+		if err != nil {
+			t.Fatalf("%s: %v", fn, err)
+		}
+
+		err = tmpl.compile()
+		if err != nil {
+			t.Fatalf("%s: %v", fn, err)
+		}
+
+		err = tmpl.Check()
+		if err != nil {
+			t.Fatalf("%s: %v", fn, err)
+		}
+
+		templates = append(templates, &tmpl)
+	}
+
+	return templates
+}
+
+// message collapses an alert into the single message an email is read as
+func message(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func TestAlertTemplates(t *testing.T) {
+	templates := loadAlertTemplates(t)
+	received := time.Date(2026, time.October, 12, 9, 30, 0, 0, time.UTC)
+
+	for _, tmpl := range templates {
+		t.Run(tmpl.Key, func(t *testing.T) {
+			b, err := os.ReadFile(filepath.Join("testdata", "alerts", tmpl.Key+".json"))
+			if err != nil {
+				t.Fatalf("no fixtures for the template: %v", err)
+			}
+
+			var fixtures alertFixtures
+
+			err = json.Unmarshal(b, &fixtures)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, a := range fixtures.Alerts {
+				t.Run(a.Name, func(t *testing.T) {
+					text := message(a.Text)
+
+					detected, ok := DetectAlertTemplate(templates, text)
+					if !ok || detected.Key != tmpl.Key {
+						t.Fatalf("detected template %v, want %s", detected, tmpl.Key)
+					}
+
+					file := ParseAlerts(templates, text, received)
+					if len(file.Errors) > 0 || len(file.Rows) != 1 {
+						t.Fatalf("got %d rows and errors %v, want a single row", len(file.Rows), file.Errors)
+					}
+
+					row := file.Rows[0]
+
+					kind := db.DraftKindSpend
+					switch {
+					case row.Payment:
+						kind = db.DraftKindPayment
+					case row.Credit:
+						kind = db.DraftKindRefund
+					}
+
+					for _, field := range []struct{ name, got, want string }{
+						{"kind", kind, a.Kind},
+						{"date", row.Date, a.Date},
+						{"amount", row.Amount.Decimal(), a.Amount},
+						{"last4", row.Last4, a.Last4},
+						{"merchant", row.Description, a.Merchant},
+					} {
+						if field.got != field.want {
+							t.Errorf("%s = %q, want %q", field.name, field.got, field.want)
+						}
+					}
+				})
+			}
+
+			for _, ignored := range fixtures.Ignored {
+				t.Run(ignored.Name, func(t *testing.T) {
+					file := ParseAlerts(templates, message(ignored.Text), received)
+
+					if len(file.Rows) != 0 {
+						t.Errorf("read %+v, want no draft", file.Rows[0])
+					}
+
+					if len(file.Errors) != 1 {
+						t.Errorf("got errors %v, want the message reported", file.Errors)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestParseAlertsPasted(t *testing.T) {
+	templates := loadAlertTemplates(t)
+
+	text := "Rs 1,234.00 spent on HDFC Bank Card XX1234 at SWIGGY on 2026-10-01\n\n" +
+		"OTP is 482913 for txn of INR 2,499.00 at AMAZON on HDFC Bank Card ending 1234. Valid till 18:25.\n" +
+		"Do not share OTP for security reasons.\n\n\n" +
+		"Rs.2,150.50 spent on your SBI Credit Card ending 4321 at BIGBASKET\non 01/10/26.\n\n" +
+		"Hello, are we meeting today?"
+
+	file := ParseAlerts(templates, text, time.Now())
+
+	var lines []int
+	for _, row := range file.Rows {
+		lines = append(lines, row.Line)
+	}
+
+	if len(lines) != 2 || lines[0] != 1 || lines[1] != 7 {
+		t.Errorf("rows read from lines %v, want [1 7]", lines)
+	}
+
+	var errLines []int
+	for _, e := range file.Errors {
+		errLines = append(errLines, e.Line)
+	}
+
+	if len(errLines) != 2 || errLines[0] != 3 || errLines[1] != 10 {
+		t.Errorf("errors on lines %v, want [3 10]", errLines)
+	}
+}
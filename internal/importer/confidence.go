@@ -0,0 +1,41 @@
+package importer
+
+import (
+	"math"
+)
+
+// Confidence of the rows read from each format. Structured exports are read field by field, while
+// statements and alerts are matched with patterns over free text, which can mistake a line or a
+// phrase for a transaction.
+const (
+	confidenceOFX   = 0.95
+	confidenceCSV   = 0.9
+	confidenceQIF   = 0.9
+	confidencePDF   = 0.8
+	confidenceAlert = 0.75
+)
+
+// How much less confident a row is for what it lacks
+const (
+	// penaltyNoDescription is for a row without a description, it cannot be matched to a merchant
+	penaltyNoDescription = 0.2
+	// penaltyNoDate is for an alert without a date, dated the day it was received
+	penaltyNoDate = 0.15
+	// penaltyNoMerchant is for an alert without a merchant, described by its issuer and kind
+	penaltyNoMerchant = 0.1
+)
+
+// Penalise lowers the confidence of the row by p, to no less than zero
+func (r *Row) Penalise(p float64) {
+	r.Confidence = max(0, math.Round((r.Confidence-p)*100)/100)
+}
+
+// score sets the parser and the confidence of a row read with the base confidence of its format
+func (r *Row) score(parser string, base float64) {
+	r.Parser = parser
+	r.Confidence = base
+
+	if r.Description == "" {
+		r.Penalise(penaltyNoDescription)
+	}
+}
@@ -0,0 +1,303 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrNoHeader is returned when no row of the file has the columns of the mapping
+var ErrNoHeader = errors.New("no header row with the mapping's columns found")
+
+type (
+	// Row is a transaction read from an export
+	Row struct {
+		// Line is the line of the file the row starts on
+		Line int `json:"line"`
+		// Date is the transaction date as YYYY-MM-DD
+		Date        string `json:"date"`
+		Description string `json:"description"`
+		// Amount is the unsigned amount of the row
+		Amount money.Money `json:"amount"`
+		// Credit marks a refund or a payment, otherwise the row is a spend
+		Credit bool `json:"credit"`
+		// Payment marks a credit as a bill payment
+		Payment bool `json:"payment"`
+		// Last4 is the last four digits of the card number, if the export has them
+		Last4 string `json:"last4,omitempty"`
+		// Category is the category of the transaction, if the export has one
+		Category string `json:"category,omitempty"`
+		// Merchant is the taxonomy name of the merchant the description names, e.g. swiggy
+		Merchant string `json:"merchant,omitempty"`
+		// ExternalID identifies the transaction in its source, e.g. the FITID of an OFX transaction
+		ExternalID string `json:"external_id,omitempty"`
+		// Parser is what the row was read with: the format, along with the key of its mapping or
+		// template, e.g. csv:hdfc or alert:icici
+		Parser string `json:"parser"`
+		// Confidence is how likely the row was read right, from 0 to 1
+		Confidence float64 `json:"confidence"`
+	}
+
+	// RowError is a row of an export that could not be read
+	RowError struct {
+		Line  int    `json:"line"`
+		Error string `json:"error"`
+	}
+
+	// File is the transactions read from an export
+	File struct {
+		// Source is the format of the export, recorded as the source of the ledger rows
+		Source string
+		// Mapping is the key of the mapping a CSV export or the template a PDF statement was read with
+		Mapping string
+		Rows    []Row
+		// Errors are the rows that could not be read
+		Errors []RowError
+		// Statement is the summary of a PDF statement
+		Statement *Statement
+	}
+
+	// columnIndex is the position of the mapping's columns in the header row, -1 when absent
+	columnIndex struct {
+		date, description, amount, debitCredit, debit, credit, card int
+	}
+)
+
+// ParseCSV reads the transactions of a CSV export. Lines before the header row, such as the
+// account summary some issuers put on top, are skipped, as are rows without a date.
+// Rows that cannot be read are returned as row errors rather than failing the whole file.
+func (m *Mapping) ParseCSV(r io.Reader) (*File, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	if m.Delimiter != "" {
+		reader.Comma = []rune(m.Delimiter)[0]
+	}
+
+	var header *columnIndex
+
+	file := &File{Source: db.SourceCSV, Mapping: m.Key}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if header == nil {
+			header = m.findColumns(record)
+			continue
+		}
+
+		row, ok, err := m.parseRecord(header, record)
+		if err != nil {
+			file.Errors = append(file.Errors, RowError{Line: line, Error: err.Error()})
+			continue
+		}
+
+		if ok {
+			row.Line = line
+			row.score(db.SourceCSV+":"+m.Key, confidenceCSV)
+			file.Rows = append(file.Rows, row)
+		}
+	}
+
+	if header == nil {
+		return nil, ErrNoHeader
+	}
+
+	return file, nil
+}
+
+// findColumns returns the position of the mapping's columns if the record is the header row
+func (m *Mapping) findColumns(record []string) *columnIndex {
+	find := func(name string) int {
+		if name == "" {
+			return -1
+		}
+
+		for i, field := range record {
+			field = strings.TrimPrefix(field, "\ufeff")
+			if strings.EqualFold(strings.TrimSpace(field), name) {
+				return i
+			}
+		}
+
+		return -1
+	}
+
+	idx := &columnIndex{
+		date:        find(m.Columns.Date),
+		description: find(m.Columns.Description),
+		amount:      find(m.Columns.Amount),
+		debitCredit: find(m.Columns.DebitCredit),
+		debit:       find(m.Columns.Debit),
+		credit:      find(m.Columns.Credit),
+		card:        find(m.Columns.Card),
+	}
+
+	if idx.date < 0 || idx.description < 0 {
+		return nil
+	}
+
+	if m.Columns.Amount != "" && idx.amount < 0 {
+		return nil
+	}
+
+	if m.Columns.Amount == "" && (idx.debit < 0 || idx.credit < 0) {
+		return nil
+	}
+
+	return idx
+}
+
+// parseRecord reads a row, returning false for rows that are not transactions
+func (m *Mapping) parseRecord(idx *columnIndex, record []string) (Row, bool, error) {
+	field := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	// Blank lines and footers such as totals have no date
+	if field(idx.date) == "" {
+		return Row{}, false, nil
+	}
+
+	date, err := m.parseDate(field(idx.date))
+	if err != nil {
+		return Row{}, false, err
+	}
+
+	row := Row{
+		Date:        date.Format(time.DateOnly),
+		Description: strings.Join(strings.Fields(field(idx.description)), " "),
+		Last4:       last4(field(idx.card)),
+	}
+
+	if idx.amount >= 0 {
+		row.Amount, row.Credit, err = parseAmount(field(idx.amount))
+		if err != nil {
+			return Row{}, false, err
+		}
+
+		if idx.debitCredit >= 0 && m.isCredit(field(idx.debitCredit)) {
+			row.Credit = true
+		}
+	} else {
+		debit, credit := field(idx.debit), field(idx.credit)
+
+		switch {
+		case debit != "" && !isZero(debit):
+			row.Amount, _, err = parseAmount(debit)
+		case credit != "":
+			row.Amount, _, err = parseAmount(credit)
+			row.Credit = true
+		default:
+			return Row{}, false, fmt.Errorf("neither a debit nor a credit amount")
+		}
+
+		if err != nil {
+			return Row{}, false, err
+		}
+	}
+
+	if row.Amount.IsZero() {
+		return Row{}, false, fmt.Errorf("amount is zero")
+	}
+
+	row.Payment = row.Credit && m.isPayment(row.Description)
+
+	return row, true, nil
+}
+
+func (m *Mapping) parseDate(value string) (time.Time, error) {
+	return parseDate(m.DateFormats, value)
+}
+
+// parseDate parses the date with the first of the layouts it matches
+func parseDate(layouts []string, value string) (time.Time, error) {
+	for _, layout := range layouts {
+		date, err := time.Parse(layout, value)
+		if err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("date %q does not match the formats %s", value, strings.Join(layouts, ", "))
+}
+
+// parseAmount reads an amount such as "₹1,234.50", "Rs 1,234.50", "1234.50 Cr", "(1,234.50)" or
+// "-1234.50", returning its absolute value and whether it is marked as a credit
+func parseAmount(value string) (money.Money, bool, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	credit := false
+
+	for _, suffix := range []string{"CR.", "CR", "DR.", "DR"} {
+		if strings.HasSuffix(s, suffix) {
+			credit = strings.HasPrefix(suffix, "CR")
+			s = strings.TrimSuffix(s, suffix)
+
+			break
+		}
+	}
+
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		credit = true
+		s = s[1 : len(s)-1]
+	}
+
+	s = strings.NewReplacer("₹", "", "RS.", "", "RS", "", "INR", "", ",", "", " ", "").Replace(s)
+
+	if strings.HasPrefix(s, "-") {
+		credit = true
+		s = s[1:]
+	}
+
+	s = strings.TrimPrefix(s, "+")
+
+	amount, err := money.Parse(s, money.INR)
+	if err != nil {
+		return money.Money{}, false, fmt.Errorf("invalid amount %q", value)
+	}
+
+	return amount, credit, nil
+}
+
+func isZero(value string) bool {
+	amount, _, err := parseAmount(value)
+
+	return err == nil && amount.IsZero()
+}
+
+// last4 returns the last four digits of a card number, masked or not
+func last4(value string) string {
+	var digits []rune
+
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, r)
+		}
+	}
+
+	if len(digits) < 4 {
+		return ""
+	}
+
+	return string(digits[len(digits)-4:])
+}
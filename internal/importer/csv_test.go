@@ -0,0 +1,218 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// loadMapping parses the issuer mapping shipped in data/imports with the key
+func loadMapping(t *testing.T, key string) *Mapping {
+	t.Helper()
+
+	b, err := os.ReadFile("../../data/imports/" + key + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var m Mapping
+
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		t.Fatalf("%s: %v", key, err)
+	}
+
+	err = m.validate()
+	if err != nil {
+		t.Fatalf("%s: %v", key, err)
+	}
+
+	return &m
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   string
+		credit bool
+		err    bool
+	}{
+		{value: "1234.50", want: "1234.50"},
+		{value: "₹1,234.50", want: "1234.50"},
+		{value: "₹ 1,23,456.78", want: "123456.78"},
+		{value: "Rs. 1,234.50", want: "1234.50"},
+		{value: "RS.500", want: "500"},
+		{value: "Rs 1,234.50", want: "1234.50"},
+		{value: "Rs1234.50", want: "1234.50"},
+		{value: "rs 99", want: "99"},
+		{value: "INR 2,000.00", want: "2000"},
+		{value: "1234.50 Cr", want: "1234.50", credit: true},
+		{value: "Rs 1,234.50 CR.", want: "1234.50", credit: true},
+		{value: "1234.50 Dr", want: "1234.50"},
+		{value: "(1,234.50)", want: "1234.50", credit: true},
+		{value: "-1234.50", want: "1234.50", credit: true},
+		{value: "Rs -250", want: "250", credit: true},
+		{value: "+99", want: "99"},
+		{value: "Rs", err: true},
+		{value: "1,234.50 USD", err: true},
+		{value: "twelve", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, credit, err := parseAmount(tt.value)
+			if tt.err {
+				if err == nil {
+					t.Errorf("parseAmount(%q) = %v, want an error", tt.value, got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parseAmount(%q) failed: %v", tt.value, err)
+			}
+
+			if want := money.MustParse(tt.want, money.INR); got != want || credit != tt.credit {
+				t.Errorf("parseAmount(%q) = %v, credit %t, want %v, credit %t", tt.value, got, credit, want, tt.credit)
+			}
+		})
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	inr := func(s string) money.Money {
+		return money.MustParse(s, money.INR)
+	}
+
+	// debitCredit is a mapping with separate debit and credit columns
+	debitCredit := &Mapping{
+		Key:             "split",
+		Columns:         Columns{Date: "Txn Date", Description: "Narration", Debit: "Withdrawal", Credit: "Deposit"},
+		Delimiter:       ";",
+		DateFormats:     []string{"02 Jan 2006", "2006-01-02"},
+		PaymentPatterns: []string{"AUTOPAY"},
+	}
+
+	tests := []struct {
+		name    string
+		mapping *Mapping
+		csv     string
+		rows    []Row
+		// errors are the lines of the rows that could not be read
+		errors []int
+	}{
+		{
+			name:    "generic",
+			mapping: loadMapping(t, "generic"),
+			csv: "date,description,amount,card\n" +
+				"2026-10-03,SWIGGY BANGALORE,450.00,XXXX XXXX XXXX 1234\n" +
+				"04/10/2026,AMAZON   RETAIL,\"Rs 1,299.00\",1234\n" +
+				"2026-10-05,AMAZON REFUND,-299.00,\n" +
+				"2026-10-06,PAYMENT RECEIVED,(5000.00),\n" +
+				"\n" +
+				"2026/10/07,BAD DATE,10.00,\n" +
+				"2026-10-08,ZERO,0.00,\n" +
+				",TOTAL,7048.00,\n",
+			rows: []Row{
+				{Line: 2, Date: "2026-10-03", Description: "SWIGGY BANGALORE", Amount: inr("450"), Last4: "1234"},
+				{Line: 3, Date: "2026-10-04", Description: "AMAZON RETAIL", Amount: inr("1299"), Last4: "1234"},
+				{Line: 4, Date: "2026-10-05", Description: "AMAZON REFUND", Amount: inr("299"), Credit: true},
+				{Line: 5, Date: "2026-10-06", Description: "PAYMENT RECEIVED", Amount: inr("5000"), Credit: true, Payment: true},
+			},
+			errors: []int{7, 8},
+		},
+		{
+			name:    "hdfc",
+			mapping: loadMapping(t, "hdfc"),
+			csv: "Statement for card ending 1234\n" +
+				"Period,01/10/2026 - 31/10/2026\n" +
+				"Date,Description,Amount,Debit / Credit,Card No\n" +
+				"03/10/2026,ZOMATO,620.00,,4321XXXXXXXX1234\n" +
+				"04/10/2026 18:22:09,UBER INDIA,Rs 312.40,Dr,4321XXXXXXXX1234\n" +
+				"05-10-2026,ZOMATO REFUND,620.00,Cr,4321XXXXXXXX1234\n" +
+				"06/10/2026,NETBANKING TRANSFER - THANK YOU,\"10,000.00\",CR,4321XXXXXXXX1234\n",
+			rows: []Row{
+				{Line: 4, Date: "2026-10-03", Description: "ZOMATO", Amount: inr("620"), Last4: "1234"},
+				{Line: 5, Date: "2026-10-04", Description: "UBER INDIA", Amount: inr("312.40"), Last4: "1234"},
+				{Line: 6, Date: "2026-10-05", Description: "ZOMATO REFUND", Amount: inr("620"), Credit: true, Last4: "1234"},
+				{Line: 7, Date: "2026-10-06", Description: "NETBANKING TRANSFER - THANK YOU", Amount: inr("10000"), Credit: true, Payment: true, Last4: "1234"},
+			},
+		},
+		{
+			name:    "icici",
+			mapping: loadMapping(t, "icici"),
+			csv: "\ufeffDate,Sr.No.,Transaction Details,Reward Point Header,Intl.Amount,Amount (in Rs),BillingAmountSign\n" +
+				"03-Oct-2026,1,IND*AMAZON,12,,\"1,499.00\",\n" +
+				"04/10/2026,2,BBPS PAYMENT RECEIVED,0,,Rs20000.00,CR\n" +
+				"05-10-2026,3,FLIPKART,4,,Rs.450,\n" +
+				"Oct 6 2026,4,MYNTRA,2,,300,\n",
+			rows: []Row{
+				{Line: 2, Date: "2026-10-03", Description: "IND*AMAZON", Amount: inr("1499")},
+				{Line: 3, Date: "2026-10-04", Description: "BBPS PAYMENT RECEIVED", Amount: inr("20000"), Credit: true, Payment: true},
+				{Line: 4, Date: "2026-10-05", Description: "FLIPKART", Amount: inr("450")},
+			},
+			errors: []int{5},
+		},
+		{
+			name:    "debit and credit columns",
+			mapping: debitCredit,
+			csv: "Txn Date;Narration;Withdrawal;Deposit\n" +
+				"03 Oct 2026;BIGBASKET;Rs 1,050.00;\n" +
+				"04 Oct 2026;BIGBASKET REFUND;0.00;Rs 150.00\n" +
+				"2026-10-05;AUTOPAY HDFC CARD;;8000\n" +
+				"06 Oct 2026;NOTHING;;\n",
+			rows: []Row{
+				{Line: 2, Date: "2026-10-03", Description: "BIGBASKET", Amount: inr("1050")},
+				{Line: 3, Date: "2026-10-04", Description: "BIGBASKET REFUND", Amount: inr("150"), Credit: true},
+				{Line: 4, Date: "2026-10-05", Description: "AUTOPAY HDFC CARD", Amount: inr("8000"), Credit: true, Payment: true},
+			},
+			errors: []int{5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := tt.mapping.ParseCSV(strings.NewReader(tt.csv))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if file.Mapping != tt.mapping.Key {
+				t.Errorf("mapping = %q, want %q", file.Mapping, tt.mapping.Key)
+			}
+
+			if len(file.Rows) != len(tt.rows) {
+				t.Fatalf("read %d rows, want %d: %+v", len(file.Rows), len(tt.rows), file.Rows)
+			}
+
+			for i, want := range tt.rows {
+				want.Parser, want.Confidence = "csv:"+tt.mapping.Key, confidenceCSV
+
+				if got := file.Rows[i]; got != want {
+					t.Errorf("row %d = %+v, want %+v", i, got, want)
+				}
+			}
+
+			lines := make([]int, 0, len(file.Errors))
+			for _, e := range file.Errors {
+				lines = append(lines, e.Line)
+			}
+
+			if !slices.Equal(lines, tt.errors) {
+				t.Errorf("errors on lines %v, want %v: %+v", lines, tt.errors, file.Errors)
+			}
+		})
+	}
+
+	t.Run("no header", func(t *testing.T) {
+		_, err := loadMapping(t, "hdfc").ParseCSV(strings.NewReader("date,details,amount\n2026-10-03,SWIGGY,450\n"))
+		if !errors.Is(err, ErrNoHeader) {
+			t.Errorf("err = %v, want %v", err, ErrNoHeader)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+// Package importer parses transactions out of the statement exports of card issuers
+package importer
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type (
+	// Columns names the columns of an export, matched case-insensitively against its header row
+	Columns struct {
+		Date        string `json:"date"`
+		Description string `json:"description"`
+		// Amount holds the amount of a row. It is a debit unless it is negative, in parentheses,
+		// suffixed with Cr or marked as a credit by the DebitCredit column.
+		Amount      string `json:"amount,omitempty"`
+		DebitCredit string `json:"debit_credit,omitempty"`
+		// Debit and Credit are separate amount columns, used instead of Amount
+		Debit  string `json:"debit,omitempty"`
+		Credit string `json:"credit,omitempty"`
+		// Card holds the card number, usually masked, whose last four digits pick the user card
+		Card string `json:"card,omitempty"`
+	}
+
+	// Mapping describes the CSV export format of an issuer
+	Mapping struct {
+		Key     string  `json:"key"`
+		Issuer  string  `json:"issuer"`
+		Columns Columns `json:"columns"`
+		// Delimiter is the field separator, a comma when empty
+		Delimiter string `json:"delimiter,omitempty"`
+		// DateFormats are the Go layouts dates are parsed with, tried in order
+		DateFormats []string `json:"date_formats"`
+		// CreditValues are the values of the DebitCredit column marking a credit
+		CreditValues []string `json:"credit_values,omitempty"`
+		// PaymentPatterns mark a credit whose description contains one of them as a bill payment
+		// rather than a refund. They are matched case-insensitively.
+		PaymentPatterns []string `json:"payment_patterns,omitempty"`
+	}
+)
+
+// ParseMappings parses the issuer mappings in the data/imports directory
+func ParseMappings(data embed.FS) ([]*Mapping, error) {
+	entries, err := data.ReadDir("data/imports")
+	if err != nil {
+		return nil, fmt.Errorf("error reading imports dir: %w", err)
+	}
+
+	mappings := make([]*Mapping, 0, len(entries))
+
+	for _, entry := range entries {
+		fn := fmt.Sprintf("data/imports/%s", entry.Name())
+
+		file, err := data.ReadFile(fn)
+		if err != nil {
+			return nil, fmt.Errorf("error reading import mapping file %s: %w", fn, err)
+		}
+
+		var m Mapping
+
+		err = json.Unmarshal(file, &m)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling import mapping %s: %w", fn, err)
+		}
+
+		err = m.validate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid import mapping %s: %w", fn, err)
+		}
+
+		mappings = append(mappings, &m)
+	}
+
+	return mappings, nil
+}
+
+func (m *Mapping) validate() error {
+	switch {
+	case m.Key == "":
+		return fmt.Errorf("key is required")
+	case m.Columns.Date == "" || m.Columns.Description == "":
+		return fmt.Errorf("date and description columns are required")
+	case m.Columns.Amount == "" && (m.Columns.Debit == "" || m.Columns.Credit == ""):
+		return fmt.Errorf("either an amount column or debit and credit columns are required")
+	case len(m.DateFormats) == 0:
+		return fmt.Errorf("at least one date format is required")
+	case len([]rune(m.Delimiter)) > 1:
+		return fmt.Errorf("delimiter must be a single character")
+	}
+
+	return nil
+}
+
+// isCredit reports whether the value of the DebitCredit column marks a credit
+func (m *Mapping) isCredit(value string) bool {
+	value = strings.TrimSpace(value)
+
+	for _, v := range m.CreditValues {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isPayment reports whether a credit with the description is a bill payment
+func (m *Mapping) isPayment(description string) bool {
+	return isPaymentDescription(m.PaymentPatterns, description)
+}
+
+// isPaymentDescription reports whether the description contains one of the payment patterns
+func isPaymentDescription(patterns []string, description string) bool {
+	description = strings.ToUpper(description)
+
+	for _, p := range patterns {
+		if strings.Contains(description, strings.ToUpper(p)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,251 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrNotOFX is returned for files without an OFX element
+var ErrNotOFX = errors.New("not an OFX file")
+
+// ofxNode is an OFX element. Aggregates have children, elements have a value.
+type ofxNode struct {
+	name     string
+	value    string
+	line     int
+	children []*ofxNode
+}
+
+// ParseOFX reads the transactions of an OFX or QFX file, in both the SGML (1.x) and the XML (2.x)
+// flavours. Bank and credit card statements are read, the card of a transaction is taken from the
+// last four digits of its statement's account ID. The FITID of a transaction is its external ID.
+func ParseOFX(r io.Reader) (*File, error) {
+	root, err := parseOFXTree(r)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &File{Source: db.SourceOFX}
+
+	var walk func(n *ofxNode, account string)
+	walk = func(n *ofxNode, account string) {
+		switch n.name {
+		case "STMTRS", "CCSTMTRS":
+			for _, from := range []string{"BANKACCTFROM", "CCACCTFROM"} {
+				if id := n.find(from, "ACCTID"); id != "" {
+					account = id
+				}
+			}
+		case "STMTTRN":
+			row, err := ofxRow(n, account)
+			if err != nil {
+				file.Errors = append(file.Errors, RowError{Line: n.line, Error: err.Error()})
+			} else {
+				row.score(db.SourceOFX, confidenceOFX)
+				file.Rows = append(file.Rows, row)
+			}
+
+			return
+		}
+
+		for _, c := range n.children {
+			walk(c, account)
+		}
+	}
+
+	walk(root, "")
+
+	return file, nil
+}
+
+// ofxRow reads a STMTTRN aggregate. Negative amounts are debits, as in both bank and credit card
+// statements money leaving the account is negative.
+func ofxRow(n *ofxNode, account string) (Row, error) {
+	posted := n.get("DTPOSTED")
+	if len(posted) < 8 {
+		return Row{}, fmt.Errorf("invalid DTPOSTED %q", posted)
+	}
+
+	date, err := time.Parse("20060102", posted[:8])
+	if err != nil {
+		return Row{}, fmt.Errorf("invalid DTPOSTED %q", posted)
+	}
+
+	value, credit, err := parseAmount(ofxAmount(n.get("TRNAMT")))
+	if err != nil {
+		return Row{}, err
+	}
+
+	if value.IsZero() {
+		return Row{}, fmt.Errorf("amount is zero")
+	}
+
+	description := n.get("NAME")
+	if description == "" {
+		description = n.get("MEMO")
+	}
+
+	trnType := strings.ToUpper(n.get("TRNTYPE"))
+
+	// A positive amount is a credit to the account
+	credit = !credit
+
+	return Row{
+		Line:        n.line,
+		Date:        date.Format(time.DateOnly),
+		Description: strings.Join(strings.Fields(description), " "),
+		Amount:      value,
+		Credit:      credit,
+		Payment: credit && (trnType == "PAYMENT" || trnType == "XFER" ||
+			strings.Contains(strings.ToUpper(description), "PAYMENT")),
+		Last4:      last4(account),
+		Category:   memoCategory(n.get("MEMO")),
+		ExternalID: n.get("FITID"),
+	}, nil
+}
+
+// ofxAmount writes an amount with a decimal point. Exports use either a point or a comma as the
+// decimal separator, possibly with the other grouping thousands, so the last separator is the
+// decimal one: "1.234,50" and "1,234.50" are both 1234.50. A separator found more than once only
+// groups, e.g. "1.234.567".
+func ofxAmount(amount string) string {
+	ungrouped := strings.NewReplacer(".", "", ",", "")
+
+	i := strings.LastIndexAny(amount, ".,")
+	if i < 0 {
+		return amount
+	}
+
+	if strings.Contains(amount[:i], amount[i:i+1]) {
+		return ungrouped.Replace(amount)
+	}
+
+	return ungrouped.Replace(amount[:i]) + "." + amount[i+1:]
+}
+
+// memoCategory returns the category of a memo written by a CardMax export, e.g.
+// "Category: dining; Reward: 50 points (INR 12.50)"
+func memoCategory(memo string) string {
+	for _, part := range strings.Split(memo, ";") {
+		category, ok := strings.CutPrefix(strings.TrimSpace(part), "Category:")
+		if ok {
+			return strings.ToLower(strings.TrimSpace(category))
+		}
+	}
+
+	return ""
+}
+
+// parseOFXTree parses the OFX element of a file. SGML leaf elements have no end tag, so an element
+// followed by text is a leaf, and the end tag of an aggregate closes every element opened after it.
+func parseOFXTree(r io.Reader) (*ofxNode, error) {
+	br := bufio.NewReader(r)
+
+	var (
+		root  *ofxNode
+		stack []*ofxNode
+		leaf  *ofxNode
+		line  = 1
+	)
+
+	for {
+		text, err := br.ReadString('<')
+		line += strings.Count(text, "\n")
+
+		// An element followed by text is a leaf, whether or not an end tag follows
+		if value := strings.TrimSpace(strings.TrimSuffix(text, "<")); value != "" && leaf != nil {
+			leaf.value = html.UnescapeString(value)
+			stack = stack[:len(stack)-1]
+			leaf = nil
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read OFX: %w", err)
+		}
+
+		tag, err := br.ReadString('>')
+		if err != nil {
+			return nil, fmt.Errorf("unterminated tag on line %d", line)
+		}
+
+		line += strings.Count(tag, "\n")
+		tag = strings.TrimSpace(strings.TrimSuffix(tag, ">"))
+
+		switch {
+		case strings.HasPrefix(tag, "?"), strings.HasPrefix(tag, "!"):
+			// XML declaration, OFX processing instruction or comment
+			continue
+		case strings.HasPrefix(tag, "/"):
+			name := strings.ToUpper(strings.TrimPrefix(tag, "/"))
+			leaf = nil
+
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i].name == name {
+					stack = stack[:i]
+					break
+				}
+			}
+
+			// An end tag of an element that is not open, e.g. of a leaf in OFX 2.x, is ignored
+			continue
+		}
+
+		n := &ofxNode{name: strings.ToUpper(strings.Fields(tag)[0]), line: line}
+
+		if len(stack) > 0 {
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, n)
+		} else if n.name == "OFX" {
+			root = n
+		} else {
+			continue
+		}
+
+		stack = append(stack, n)
+		leaf = n
+	}
+
+	if root == nil {
+		return nil, ErrNotOFX
+	}
+
+	return root, nil
+}
+
+// get returns the value of the child element with the name
+func (n *ofxNode) get(name string) string {
+	for _, c := range n.children {
+		if c.name == name {
+			return c.value
+		}
+	}
+
+	return ""
+}
+
+// find returns the value of the element at the path below the node
+func (n *ofxNode) find(path ...string) string {
+	for _, c := range n.children {
+		if c.name != path[0] {
+			continue
+		}
+
+		if len(path) == 1 {
+			return c.value
+		}
+
+		return c.find(path[1:]...)
+	}
+
+	return ""
+}
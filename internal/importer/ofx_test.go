@@ -0,0 +1,143 @@
+package importer
+
+import (
+	"bytes"
+	"github.com/pushkar-anand/cardmax/internal/exporter"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestOFXAmount(t *testing.T) {
+	tests := []struct {
+		amount string
+		want   string
+	}{
+		{amount: "-450.00", want: "-450.00"},
+		{amount: "-450", want: "-450"},
+		{amount: "-450,5", want: "-450.5"},
+		{amount: "1234.50", want: "1234.50"},
+		{amount: "1,234.50", want: "1234.50"},
+		{amount: "1.234,50", want: "1234.50"},
+		{amount: "-1.234,50", want: "-1234.50"},
+		{amount: "1,23,456.78", want: "123456.78"},
+		{amount: "1.234.567", want: "1234567"},
+		{amount: "1,234,567", want: "1234567"},
+		{amount: "1.234.567,89", want: "1234567.89"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			if got := ofxAmount(tt.amount); got != tt.want {
+				t.Errorf("ofxAmount(%q) = %q, want %q", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestExportRoundTrip checks that the rows of an export read back as they were written
+func TestExportRoundTrip(t *testing.T) {
+	inr := func(s string) money.Money {
+		return money.MustParse(s, money.INR)
+	}
+
+	date := func(s string) time.Time {
+		d, _ := time.Parse(time.DateOnly, s)
+
+		return d
+	}
+
+	s := &exporter.Statement{
+		Start:     date("2026-09-01"),
+		End:       date("2026-09-30"),
+		Generated: date("2026-10-01"),
+		Accounts: []exporter.Account{{
+			Name:    "HDFC Regalia Gold",
+			Last4:   "1234",
+			Balance: inr("-123546.28"),
+			Entries: []exporter.Entry{
+				{
+					ID: "txn-1", Type: exporter.TypeDebit, Date: date("2026-09-03"), Name: "Swiggy",
+					Amount: inr("-450"), Category: "Dining", Reward: "18 points (INR 4.50)",
+				},
+				{
+					ID: "txn-2", Type: exporter.TypeDebit, Date: date("2026-09-12"), Name: "Tanishq & Sons <Gold>",
+					Amount: inr("-123456.78"), Notes: "Anniversary",
+				},
+				{
+					ID: "txn-3", Type: exporter.TypeCredit, Date: date("2026-09-15"), Name: "Amazon",
+					Amount: inr("120.50"), Category: "shopping",
+				},
+				{
+					ID: "payment-1", Type: exporter.TypePayment, Date: date("2026-09-20"), Name: "Payment",
+					Amount: inr("10000"),
+				},
+			},
+		}},
+	}
+
+	want := []Row{
+		{Date: "2026-09-03", Description: "Swiggy", Amount: inr("450"), Last4: "1234", Category: "dining", ExternalID: "txn-1"},
+		{Date: "2026-09-12", Description: "Tanishq & Sons <Gold>", Amount: inr("123456.78"), Last4: "1234", ExternalID: "txn-2"},
+		{
+			Date: "2026-09-15", Description: "Amazon", Amount: inr("120.50"), Credit: true, Last4: "1234",
+			Category: "shopping", ExternalID: "txn-3",
+		},
+		{
+			Date: "2026-09-20", Description: "Payment", Amount: inr("10000"), Credit: true, Payment: true, Last4: "1234",
+			ExternalID: "payment-1",
+		},
+	}
+
+	tests := []struct {
+		name  string
+		write func(w io.Writer) error
+		parse func(r io.Reader) (*File, error)
+	}{
+		{
+			name:  "ofx v1",
+			write: func(w io.Writer) error { return exporter.WriteOFX(w, s, exporter.OFXVersion1) },
+			parse: ParseOFX,
+		},
+		{
+			name:  "ofx v2",
+			write: func(w io.Writer) error { return exporter.WriteOFX(w, s, exporter.OFXVersion2) },
+			parse: ParseOFX,
+		},
+		{
+			name:  "qif",
+			write: func(w io.Writer) error { return exporter.WriteQIF(w, s) },
+			parse: ParseQIF,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := tt.write(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := tt.parse(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(file.Errors) > 0 || len(file.Rows) != len(want) {
+				t.Fatalf("read %d rows and errors %v, want %d rows", len(file.Rows), file.Errors, len(want))
+			}
+
+			for i, row := range file.Rows {
+				w := want[i]
+				if row.Date != w.Date || row.Description != w.Description || row.Amount.Cmp(w.Amount) != 0 ||
+					row.Credit != w.Credit || row.Payment != w.Payment || row.Last4 != w.Last4 ||
+					row.Category != w.Category || row.ExternalID != w.ExternalID {
+					t.Errorf("row %d read as %+v, want %+v", i, row, w)
+				}
+			}
+		})
+	}
+}
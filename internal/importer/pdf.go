@@ -0,0 +1,147 @@
+package importer
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"github.com/ledongthuc/pdf"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"slices"
+	"strings"
+)
+
+const (
+	// lineGap is the gap between two pieces of text, relative to the font size, that is a space
+	lineGap = 0.2
+	// baselineTolerance is how far apart, relative to the font size, pieces of text on the same
+	// line can be drawn vertically, e.g. superscripts or cells aligned differently
+	baselineTolerance = 0.3
+)
+
+var (
+	// ErrPasswordRequired is returned for an encrypted PDF when no password is given
+	ErrPasswordRequired = errors.New("the PDF is password protected, a password is required")
+	// ErrWrongPassword is returned when the PDF cannot be decrypted with the password
+	ErrWrongPassword = errors.New("the password of the PDF is wrong")
+)
+
+// ExtractText returns the lines of text of a PDF, top to bottom on every page. The cells of a
+// table row end up on one line, separated by spaces.
+// An encrypted PDF is decrypted with the password, usually derived from the card holder's name
+// and date of birth for Indian issuers.
+func ExtractText(data []byte, password string) (lines []string, err error) {
+	// The readers panic on malformed documents, from parsing the trailer to drawing a page
+	defer func() {
+		if x := recover(); x != nil {
+			lines, err = nil, fmt.Errorf("malformed PDF: %v", x)
+		}
+	}()
+
+	if isEncrypted(data) {
+		decrypted, err := decryptPDF(data, password)
+		if err != nil {
+			return nil, err
+		}
+
+		data = decrypted
+	}
+
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	for i := 1; i <= r.NumPage(); i++ {
+		page := r.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+
+		lines = append(lines, textLines(page.Content().Text)...)
+	}
+
+	return lines, nil
+}
+
+// textLines groups the pieces of text into lines by their baseline, top to bottom. A gap between
+// two pieces wider than a fraction of the font size is a space.
+func textLines(texts []pdf.Text) []string {
+	slices.SortStableFunc(texts, func(a, b pdf.Text) int {
+		return cmp.Compare(b.Y, a.Y)
+	})
+
+	var (
+		lines []string
+		line  []pdf.Text
+	)
+
+	flush := func() {
+		slices.SortStableFunc(line, func(a, b pdf.Text) int {
+			return cmp.Compare(a.X, b.X)
+		})
+
+		var sb strings.Builder
+
+		for i, t := range line {
+			if i > 0 {
+				prev := line[i-1]
+				if t.X-(prev.X+prev.W) > lineGap*max(t.FontSize, prev.FontSize) {
+					sb.WriteByte(' ')
+				}
+			}
+
+			sb.WriteString(t.S)
+		}
+
+		if text := strings.Join(strings.Fields(sb.String()), " "); text != "" {
+			lines = append(lines, text)
+		}
+
+		line = line[:0]
+	}
+
+	for _, t := range texts {
+		if len(line) > 0 && line[0].Y-t.Y > baselineTolerance*max(t.FontSize, 1) {
+			flush()
+		}
+
+		line = append(line, t)
+	}
+
+	flush()
+
+	return lines
+}
+
+// isEncrypted reports whether the trailer of the PDF refers to an encryption dictionary
+func isEncrypted(data []byte) bool {
+	return bytes.Contains(data, []byte("/Encrypt"))
+}
+
+// decryptPDF returns the PDF without its encryption. Both the user and the owner password are
+// tried with the password.
+func decryptPDF(data []byte, password string) ([]byte, error) {
+	// Configuration is not read from or written to the user's config directory
+	api.DisableConfigDir()
+
+	conf := model.NewDefaultConfiguration()
+	conf.UserPW = password
+	conf.OwnerPW = password
+
+	var out bytes.Buffer
+
+	err := api.Decrypt(bytes.NewReader(data), &out, conf)
+	switch {
+	case errors.Is(err, pdfcpu.ErrWrongPassword) && password == "":
+		return nil, ErrPasswordRequired
+	case errors.Is(err, pdfcpu.ErrWrongPassword):
+		return nil, ErrWrongPassword
+	case err != nil:
+		return nil, fmt.Errorf("failed to decrypt PDF: %w", err)
+	}
+
+	return out.Bytes(), nil
+}
@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildPDF assembles a PDF of the objects, numbered from 1, with a valid cross-reference table
+func buildPDF(objects ...string) string {
+	var b strings.Builder
+
+	b.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(&b, "trailer\n<< /Root 1 0 R /Size %d >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return b.String()
+}
+
+// onePage returns a single page PDF drawing the content stream with Helvetica as /F1
+func onePage(stream string) string {
+	return buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		stream,
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	)
+}
+
+func contents(s string) string {
+	return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(s), s)
+}
+
+func TestExtractText(t *testing.T) {
+	data := onePage(contents("BT /F1 12 Tf 72 720 Td (HDFC Bank) Tj ET"))
+
+	lines, err := ExtractText([]byte(data), "")
+	if err != nil {
+		t.Fatalf("ExtractText() error = %v", err)
+	}
+
+	if len(lines) != 1 || lines[0] != "HDFC Bank" {
+		t.Errorf("ExtractText() = %q, want [\"HDFC Bank\"]", lines)
+	}
+}
+
+func TestExtractTextMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "not a PDF",
+			data: "hello world",
+			want: "invalid header",
+		},
+		{
+			name: "page tree object mislabelled",
+			data: strings.Replace(onePage(contents("BT ET")), "2 0 obj", "7 0 obj", 1),
+			want: "malformed PDF",
+		},
+		{
+			name: "unknown stream filter",
+			data: onePage("<< /Length 5 /Filter /Bogus >>\nstream\nxxxxx\nendstream"),
+			want: "malformed PDF",
+		},
+		{
+			name: "corrupt compressed stream",
+			data: onePage("<< /Length 5 /Filter /FlateDecode >>\nstream\nxxxxx\nendstream"),
+			want: "malformed PDF",
+		},
+		{
+			name: "unbalanced graphics state",
+			data: onePage(contents("q Q Q Q ET ET Tj")),
+			want: "malformed PDF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, err := ExtractText([]byte(tt.data), "")
+			if err == nil {
+				t.Fatalf("ExtractText() = %q, want an error", lines)
+			}
+
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ExtractText() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
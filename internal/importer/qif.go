@@ -0,0 +1,157 @@
+package importer
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"io"
+	"strings"
+	"time"
+)
+
+// qifDateLayouts are the date formats of QIF exports, which use US month first dates
+var qifDateLayouts = []string{
+	"01/02/2006", "1/2/2006", "01/02'06", "1/2'06", "01/02/06", "1/2/06", "01-02-2006", "2006-01-02",
+}
+
+// ParseQIF reads the transactions of a QIF file. Transactions of bank, cash and credit card
+// accounts are read, the card of a transaction is taken from the last four digits of the name of
+// the account it is listed under. Negative amounts are charges and positive amounts credits.
+// The check number field, used by some tools for a transaction ID, is the external ID.
+func ParseQIF(r io.Reader) (*File, error) {
+	scanner := bufio.NewScanner(r)
+	file := &File{Source: db.SourceQIF}
+
+	var (
+		line, start int
+		account     string
+		section     string
+		record      map[byte]string
+		inAccount   bool
+	)
+
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimRight(strings.TrimPrefix(scanner.Text(), "\ufeff"), " \t\r")
+		if text == "" {
+			continue
+		}
+
+		if strings.HasPrefix(text, "!") {
+			header := strings.ToLower(text)
+
+			switch {
+			case header == "!account":
+				inAccount = true
+			case strings.HasPrefix(header, "!type:"):
+				section = strings.TrimSpace(strings.TrimPrefix(header, "!type:"))
+				inAccount = false
+			}
+
+			record = nil
+
+			continue
+		}
+
+		if text == "^" {
+			if inAccount {
+				account = record['N']
+			} else if record != nil && isQIFTransactionType(section) {
+				row, err := qifRow(record, start, account)
+				if err != nil {
+					file.Errors = append(file.Errors, RowError{Line: start, Error: err.Error()})
+				} else {
+					row.score(db.SourceQIF, confidenceQIF)
+					file.Rows = append(file.Rows, row)
+				}
+			}
+
+			record = nil
+
+			continue
+		}
+
+		if record == nil {
+			record = make(map[byte]string)
+			start = line
+		}
+
+		// Split lines only keep the first field, e.g. of the category
+		if _, ok := record[text[0]]; !ok {
+			record[text[0]] = strings.TrimSpace(text[1:])
+		}
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read QIF: %w", err)
+	}
+
+	return file, nil
+}
+
+func isQIFTransactionType(section string) bool {
+	switch section {
+	case "bank", "cash", "ccard", "oth l", "oth a":
+		return true
+	default:
+		return false
+	}
+}
+
+// qifRow reads a QIF transaction record
+func qifRow(record map[byte]string, line int, account string) (Row, error) {
+	date, err := parseQIFDate(record['D'])
+	if err != nil {
+		return Row{}, err
+	}
+
+	value, credit, err := parseAmount(cmp.Or(record['T'], record['U']))
+	if err != nil {
+		return Row{}, err
+	}
+
+	if value.IsZero() {
+		return Row{}, fmt.Errorf("amount is zero")
+	}
+
+	description := cmp.Or(record['P'], record['M'])
+	category := record['L']
+
+	// A category in brackets is a transfer from another account, e.g. a bill payment
+	transfer := strings.HasPrefix(category, "[")
+	if transfer {
+		category = ""
+	}
+
+	// A positive amount is a credit to the account
+	credit = !credit
+
+	return Row{
+		Line:        line,
+		Date:        date.Format(time.DateOnly),
+		Description: strings.Join(strings.Fields(description), " "),
+		Amount:      value,
+		Credit:      credit,
+		Payment:     credit && (transfer || strings.Contains(strings.ToUpper(description), "PAYMENT")),
+		Last4:       last4(account),
+		Category:    strings.ToLower(category),
+		ExternalID:  record['N'],
+	}, nil
+}
+
+func parseQIFDate(value string) (time.Time, error) {
+	// Quicken pads single digit days with a space, e.g. " 1/ 2/2006"
+	value = strings.ReplaceAll(value, " ", "")
+
+	for _, layout := range qifDateLayouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
@@ -0,0 +1,328 @@
+package importer
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// summaryFields are the groups the summary patterns of a template can capture
+var summaryFields = []string{
+	"statement_date", "due_date", "total_due", "minimum_due",
+	"points_opening", "points_earned", "points_redeemed", "points_expired", "points_closing",
+}
+
+// summaryDateLayouts are tried after the template's date formats for the statement and due dates,
+// which statements often print in words
+var summaryDateLayouts = []string{"January 2, 2006", "Jan 2, 2006", "02 Jan 2006", "2 January 2006", "02-Jan-2006"}
+
+type (
+	// Template describes the PDF statement layout of an issuer. Patterns are Go regular
+	// expressions with named groups.
+	Template struct {
+		Key    string `json:"key"`
+		Issuer string `json:"issuer"`
+		Name   string `json:"name"`
+		// Detect are phrases identifying the issuer's statements, matched case-insensitively
+		Detect []string `json:"detect"`
+		// DateFormats are the Go layouts dates are parsed with, tried in order
+		DateFormats []string `json:"date_formats"`
+		// Card matches a line with a card number in a "card" group. The rows after it belong to
+		// that card, e.g. the section of an add-on card.
+		Card string `json:"card,omitempty"`
+		// Transaction matches a transaction line, with "date", "description" and "amount" groups
+		// and an optional "credit" group that is non-empty for a credit
+		Transaction string `json:"transaction"`
+		// Summary patterns are matched against the whole text, so they can span lines, and
+		// capture the statement totals and reward points, e.g. a "total_due" group
+		Summary []string `json:"summary,omitempty"`
+		// PaymentPatterns mark a credit whose description contains one of them as a bill payment
+		// rather than a refund. They are matched case-insensitively.
+		PaymentPatterns []string `json:"payment_patterns,omitempty"`
+
+		card        *regexp.Regexp
+		transaction *regexp.Regexp
+		summary     []*regexp.Regexp
+	}
+
+	// Statement is the summary of a PDF statement. Amounts and points it does not show are zero.
+	Statement struct {
+		// StatementDate and DueDate are YYYY-MM-DD, empty when not found
+		StatementDate string      `json:"statement_date,omitempty"`
+		DueDate       string      `json:"due_date,omitempty"`
+		TotalDue      money.Money `json:"total_due"`
+		MinimumDue    money.Money `json:"minimum_due"`
+		// Points is the reward points summary, nil when the statement has none
+		Points *RewardPoints `json:"reward_points,omitempty"`
+	}
+
+	// RewardPoints is the reward points summary of a statement
+	RewardPoints struct {
+		Opening  float64 `json:"opening"`
+		Earned   float64 `json:"earned"`
+		Redeemed float64 `json:"redeemed"`
+		Expired  float64 `json:"expired"`
+		Closing  float64 `json:"closing"`
+	}
+)
+
+// ParseTemplates parses the PDF statement templates in the data/statements directory
+func ParseTemplates(data embed.FS) ([]*Template, error) {
+	entries, err := data.ReadDir("data/statements")
+	if err != nil {
+		return nil, fmt.Errorf("error reading statements dir: %w", err)
+	}
+
+	templates := make([]*Template, 0, len(entries))
+
+	for _, entry := range entries {
+		fn := fmt.Sprintf("data/statements/%s", entry.Name())
+
+		file, err := data.ReadFile(fn)
+		if err != nil {
+			return nil, fmt.Errorf("error reading statement template file %s: %w", fn, err)
+		}
+
+		var t Template
+
+		err = json.Unmarshal(file, &t)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling statement template %s: %w", fn, err)
+		}
+
+		err = t.compile()
+		if err != nil {
+			return nil, fmt.Errorf("invalid statement template %s: %w", fn, err)
+		}
+
+		templates = append(templates, &t)
+	}
+
+	return templates, nil
+}
+
+// DetectTemplate returns the template of the statement text. When the phrases of more than one
+// template appear, e.g. another bank's name in a payment description, the earliest one wins.
+func DetectTemplate(templates []*Template, lines []string) (*Template, bool) {
+	text := strings.ToUpper(strings.Join(lines, "\n"))
+
+	var (
+		found *Template
+		first = -1
+	)
+
+	for _, t := range templates {
+		for _, phrase := range t.Detect {
+			i := strings.Index(text, strings.ToUpper(phrase))
+			if i >= 0 && (first < 0 || i < first) {
+				found, first = t, i
+			}
+		}
+	}
+
+	return found, found != nil
+}
+
+// Parse reads the transactions and the summary of a statement's text. Lines that look like a
+// transaction but cannot be read are returned as row errors.
+func (t *Template) Parse(lines []string) *File {
+	file := &File{Source: db.SourcePDF, Mapping: t.Key, Statement: t.parseSummary(lines)}
+
+	var card string
+
+	for i, line := range lines {
+		if t.card != nil {
+			if m := t.card.FindStringSubmatch(line); m != nil {
+				card = last4(m[t.card.SubexpIndex("card")])
+				continue
+			}
+		}
+
+		m := t.transaction.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		row, err := t.parseRow(m, i+1, card)
+		if err != nil {
+			file.Errors = append(file.Errors, RowError{Line: i + 1, Error: err.Error()})
+			continue
+		}
+
+		row.score(db.SourcePDF+":"+t.Key, confidencePDF)
+		file.Rows = append(file.Rows, row)
+	}
+
+	return file
+}
+
+func (t *Template) parseRow(m []string, line int, card string) (Row, error) {
+	group := func(name string) string {
+		if i := t.transaction.SubexpIndex(name); i > 0 {
+			return strings.TrimSpace(m[i])
+		}
+
+		return ""
+	}
+
+	date, err := parseDate(t.DateFormats, group("date"))
+	if err != nil {
+		return Row{}, err
+	}
+
+	amount, credit, err := parseAmount(group("amount"))
+	if err != nil {
+		return Row{}, err
+	}
+
+	if amount.IsZero() {
+		return Row{}, fmt.Errorf("amount is zero")
+	}
+
+	credit = credit || group("credit") != ""
+	description := strings.Join(strings.Fields(group("description")), " ")
+
+	return Row{
+		Line:        line,
+		Date:        date.Format(time.DateOnly),
+		Description: description,
+		Amount:      amount,
+		Credit:      credit,
+		Payment:     credit && isPaymentDescription(t.PaymentPatterns, description),
+		Last4:       card,
+	}, nil
+}
+
+// parseSummary captures the summary fields. A field captured by more than one pattern keeps
+// the first value.
+func (t *Template) parseSummary(lines []string) *Statement {
+	text := strings.Join(lines, "\n")
+	values := make(map[string]string)
+
+	for _, re := range t.summary {
+		m := re.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+
+		for i, name := range re.SubexpNames() {
+			if _, ok := values[name]; name != "" && !ok && m[i] != "" {
+				values[name] = strings.TrimSpace(m[i])
+			}
+		}
+	}
+
+	s := &Statement{
+		StatementDate: t.summaryDate(values["statement_date"]),
+		DueDate:       t.summaryDate(values["due_date"]),
+		TotalDue:      summaryAmount(values["total_due"]),
+		MinimumDue:    summaryAmount(values["minimum_due"]),
+	}
+
+	for _, name := range summaryFields {
+		if strings.HasPrefix(name, "points_") && values[name] != "" {
+			s.Points = &RewardPoints{
+				Opening:  summaryPoints(values["points_opening"]),
+				Earned:   summaryPoints(values["points_earned"]),
+				Redeemed: summaryPoints(values["points_redeemed"]),
+				Expired:  summaryPoints(values["points_expired"]),
+				Closing:  summaryPoints(values["points_closing"]),
+			}
+
+			break
+		}
+	}
+
+	return s
+}
+
+func (t *Template) summaryDate(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	date, err := parseDate(slices.Concat(t.DateFormats, summaryDateLayouts), value)
+	if err != nil {
+		return ""
+	}
+
+	return date.Format(time.DateOnly)
+}
+
+func summaryAmount(value string) money.Money {
+	amount, _, err := parseAmount(strings.TrimPrefix(value, "`"))
+	if err != nil {
+		return money.New(0, money.INR)
+	}
+
+	return amount
+}
+
+func summaryPoints(value string) float64 {
+	points, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+	if err != nil {
+		return 0
+	}
+
+	return points
+}
+
+func (t *Template) compile() error {
+	var err error
+
+	switch {
+	case t.Key == "":
+		return fmt.Errorf("key is required")
+	case len(t.Detect) == 0:
+		return fmt.Errorf("at least one detect phrase is required")
+	case len(t.DateFormats) == 0:
+		return fmt.Errorf("at least one date format is required")
+	}
+
+	t.transaction, err = regexp.Compile(t.Transaction)
+	if err != nil {
+		return fmt.Errorf("invalid transaction pattern: %w", err)
+	}
+
+	for _, name := range []string{"date", "description", "amount"} {
+		if t.transaction.SubexpIndex(name) < 0 {
+			return fmt.Errorf("transaction pattern has no %q group", name)
+		}
+	}
+
+	if t.Card != "" {
+		t.card, err = regexp.Compile(t.Card)
+		if err != nil {
+			return fmt.Errorf("invalid card pattern: %w", err)
+		}
+
+		if t.card.SubexpIndex("card") < 0 {
+			return fmt.Errorf("card pattern has no \"card\" group")
+		}
+	}
+
+	for _, pattern := range t.Summary {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid summary pattern %q: %w", pattern, err)
+		}
+
+		for _, name := range re.SubexpNames() {
+			if name != "" && !slices.Contains(summaryFields, name) {
+				return fmt.Errorf("unknown summary group %q, expected one of %s",
+					name, strings.Join(summaryFields, ", "))
+			}
+		}
+
+		t.summary = append(t.summary, re)
+	}
+
+	return nil
+}
@@ -0,0 +1,266 @@
+package mailbox
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+	"github.com/pushkar-anand/cardmax/config"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// imapTimeout bounds the connection and every command to the IMAP server
+const imapTimeout = time.Minute
+
+// IMAP reads the messages of a mailbox on an IMAP server. The mailbox is opened read-only, so
+// messages stay unread. Its cursor is the UIDVALIDITY of the mailbox and the UID of the last
+// message processed, e.g. "1700000000:42". A mailbox whose UIDVALIDITY changed is read as if it
+// had no cursor.
+type IMAP struct {
+	cfg config.IMAP
+}
+
+// NewIMAP creates a source reading the configured IMAP mailbox
+func NewIMAP(cfg config.IMAP) *IMAP {
+	return &IMAP{cfg: cfg}
+}
+
+// Name identifies the mailbox by its user, server and name
+func (s *IMAP) Name() string {
+	return fmt.Sprintf("imap://%s@%s:%d/%s", s.cfg.Username, s.cfg.Host, s.cfg.Port, s.cfg.Mailbox)
+}
+
+// Fetch returns the messages with a UID after the cursor, oldest first. Without a cursor,
+// messages received in the last firstPollWindow are returned. Only the envelopes of messages
+// are fetched until the sender is accepted.
+func (s *IMAP) Fetch(ctx context.Context, cursor string, accept func(from string) bool) ([]*Message, string, error) {
+	c, err := s.connect()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Commands cannot be cancelled, so closing the connection aborts them
+	stop := context.AfterFunc(ctx, func() {
+		_ = c.Terminate()
+	})
+
+	defer func() {
+		if stop() {
+			_ = c.Logout()
+		}
+	}()
+
+	status, err := c.Select(s.cfg.Mailbox, true)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to select mailbox %s: %w", s.cfg.Mailbox, err)
+	}
+
+	validity, last, ok := parseIMAPCursor(cursor)
+	if ok && validity != status.UidValidity {
+		ok = false
+	}
+
+	criteria := imap.NewSearchCriteria()
+	if ok {
+		criteria.Uid = new(imap.SeqSet)
+		criteria.Uid.AddRange(last+1, 0)
+	} else {
+		last = 0
+		criteria.Since = time.Now().Add(-firstPollWindow)
+
+		// Nothing recent: resume after the newest message
+		if status.UidNext > 0 {
+			last = status.UidNext - 1
+		}
+	}
+
+	uids, err := c.UidSearch(criteria)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to search mailbox: %w", err)
+	}
+
+	// A UID range ending in * always matches the newest message, even if it is older
+	if ok {
+		uids = slices.DeleteFunc(uids, func(uid uint32) bool {
+			return uid <= last
+		})
+	}
+
+	slices.Sort(uids)
+
+	if len(uids) == 0 {
+		return nil, formatIMAPCursor(status.UidValidity, last), nil
+	}
+
+	next := formatIMAPCursor(status.UidValidity, max(last, uids[len(uids)-1]))
+
+	accepted, err := s.acceptedUIDs(c, uids, accept)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(accepted) == 0 {
+		return nil, next, nil
+	}
+
+	messages, err := s.fetchBodies(c, status.UidValidity, accepted)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return messages, next, nil
+}
+
+func (s *IMAP) connect() (*client.Client, error) {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := &net.Dialer{Timeout: imapTimeout}
+	tlsConfig := &tls.Config{ServerName: s.cfg.Host}
+
+	var (
+		c   *client.Client
+		err error
+	)
+
+	if s.cfg.Port == 993 {
+		c, err = client.DialWithDialerTLS(dialer, addr, tlsConfig)
+	} else {
+		c, err = client.DialWithDialer(dialer, addr)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to IMAP server %s: %w", addr, err)
+	}
+
+	c.Timeout = imapTimeout
+
+	if !c.IsTLS() {
+		if startTLS, _ := c.SupportStartTLS(); startTLS {
+			err = c.StartTLS(tlsConfig)
+			if err != nil {
+				_ = c.Terminate()
+				return nil, fmt.Errorf("failed to start TLS with IMAP server %s: %w", addr, err)
+			}
+		}
+	}
+
+	err = c.Login(s.cfg.Username, s.cfg.Password)
+	if err != nil {
+		_ = c.Terminate()
+		return nil, fmt.Errorf("failed to log in to IMAP server %s: %w", addr, err)
+	}
+
+	return c, nil
+}
+
+// acceptedUIDs returns the UIDs of the messages whose sender is accepted
+func (s *IMAP) acceptedUIDs(c *client.Client, uids []uint32, accept func(from string) bool) ([]uint32, error) {
+	set := new(imap.SeqSet)
+	set.AddNum(uids...)
+
+	var accepted []uint32
+
+	err := fetch(c, set, []imap.FetchItem{imap.FetchUid, imap.FetchEnvelope}, func(msg *imap.Message) {
+		if msg.Envelope == nil {
+			return
+		}
+
+		for _, from := range msg.Envelope.From {
+			if accept(strings.ToLower(from.Address())) {
+				accepted = append(accepted, msg.Uid)
+				return
+			}
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch envelopes: %w", err)
+	}
+
+	slices.Sort(accepted)
+
+	return accepted, nil
+}
+
+// fetchBodies fetches and reads the messages with the UIDs, without marking them as seen
+func (s *IMAP) fetchBodies(c *client.Client, validity uint32, uids []uint32) ([]*Message, error) {
+	set := new(imap.SeqSet)
+	set.AddNum(uids...)
+
+	section := &imap.BodySectionName{Peek: true}
+	byUID := make(map[uint32]*Message, len(uids))
+
+	err := fetch(c, set, []imap.FetchItem{imap.FetchUid, imap.FetchInternalDate, section.FetchItem()}, func(msg *imap.Message) {
+		cursor := formatIMAPCursor(validity, msg.Uid)
+		id := s.Name() + "/" + cursor
+
+		body := msg.GetBody(section)
+		if body == nil {
+			byUID[msg.Uid] = &Message{ID: id, Date: msg.InternalDate, Cursor: cursor, Invalid: "the server returned no body"}
+			return
+		}
+
+		m, err := ReadMessage(body, id, msg.InternalDate)
+		if err != nil {
+			m = &Message{ID: id, Date: msg.InternalDate, Invalid: err.Error()}
+		}
+
+		m.Cursor = cursor
+		byUID[msg.Uid] = m
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch messages: %w", err)
+	}
+
+	// Servers may return messages in any order
+	messages := make([]*Message, 0, len(uids))
+	for _, uid := range uids {
+		if m, ok := byUID[uid]; ok {
+			messages = append(messages, m)
+		}
+	}
+
+	return messages, nil
+}
+
+// fetch runs a UID FETCH, calling fn for every message returned
+func fetch(c *client.Client, set *imap.SeqSet, items []imap.FetchItem, fn func(*imap.Message)) error {
+	ch := make(chan *imap.Message, 10)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- c.UidFetch(set, items, ch)
+	}()
+
+	for msg := range ch {
+		fn(msg)
+	}
+
+	return <-done
+}
+
+func parseIMAPCursor(cursor string) (validity, uid uint32, ok bool) {
+	v, u, found := strings.Cut(cursor, ":")
+	if !found {
+		return 0, 0, false
+	}
+
+	validity64, err := strconv.ParseUint(v, 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	uid64, err := strconv.ParseUint(u, 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return uint32(validity64), uint32(uid64), true
+}
+
+func formatIMAPCursor(validity, uid uint32) string {
+	return fmt.Sprintf("%d:%d", validity, uid)
+}
@@ -0,0 +1,217 @@
+package mailbox
+
+import (
+	"bytes"
+	"context"
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/backend"
+	"github.com/emersion/go-imap/backend/memory"
+	"github.com/emersion/go-imap/server"
+	"github.com/pushkar-anand/cardmax/config"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// seenBackend is the in-memory backend of go-imap, marking messages seen when their body is
+// fetched without BODY.PEEK as IMAP servers do
+type seenBackend struct {
+	*memory.Backend
+}
+
+type seenUser struct {
+	backend.User
+}
+
+type seenMailbox struct {
+	backend.Mailbox
+}
+
+func (be seenBackend) Login(info *imap.ConnInfo, username, password string) (backend.User, error) {
+	u, err := be.Backend.Login(info, username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return seenUser{u}, nil
+}
+
+func (u seenUser) GetMailbox(name string) (backend.Mailbox, error) {
+	mbox, err := u.User.GetMailbox(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return seenMailbox{mbox}, nil
+}
+
+func (mbox seenMailbox) ListMessages(uid bool, seqSet *imap.SeqSet, items []imap.FetchItem, ch chan<- *imap.Message) error {
+	err := mbox.Mailbox.ListMessages(uid, seqSet, items, ch)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		section, err := imap.ParseBodySectionName(item)
+		if err == nil && !section.Peek {
+			return mbox.UpdateMessagesFlags(uid, seqSet, imap.AddFlags, []string{imap.SeenFlag})
+		}
+	}
+
+	return nil
+}
+
+// newIMAPServer serves an in-memory mailbox, returning its INBOX and the settings reading it
+func newIMAPServer(t *testing.T) (*memory.Mailbox, config.IMAP) {
+	t.Helper()
+
+	be := memory.New()
+
+	user, err := be.Login(nil, "username", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inbox, err := user.GetMailbox("INBOX")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := server.New(seenBackend{be})
+	s.AllowInsecureAuth = true
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() { _ = s.Serve(ln) }()
+
+	t.Cleanup(func() { _ = s.Close() })
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+
+	return inbox.(*memory.Mailbox), config.IMAP{
+		Host:     host,
+		Port:     p,
+		Username: "username",
+		Password: "password",
+		Mailbox:  "INBOX",
+	}
+}
+
+// appendMessage delivers a message to the mailbox, returning its UID
+func appendMessage(t *testing.T, mbox *memory.Mailbox, data []byte, flags []string, date time.Time) uint32 {
+	t.Helper()
+
+	err := mbox.CreateMessage(flags, date, bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mbox.Messages[len(mbox.Messages)-1].Uid
+}
+
+func seen(mbox *memory.Mailbox, uid uint32) bool {
+	for _, msg := range mbox.Messages {
+		if msg.Uid == uid {
+			return slices.Contains(msg.Flags, imap.SeenFlag)
+		}
+	}
+
+	return false
+}
+
+func TestIMAPFetch(t *testing.T) {
+	ctx := context.Background()
+	inbox, cfg := newIMAPServer(t)
+	now := time.Now()
+
+	// The backend starts with a message of its own, from a sender that is not accepted
+	appendMessage(t, inbox, fixture(t, "hdfc-spend.eml"), nil, now.Add(-2*firstPollWindow))
+	hdfcUID := appendMessage(t, inbox, fixture(t, "hdfc-spend.eml"), nil, now.Add(-2*time.Hour))
+	iciciUID := appendMessage(t, inbox, fixture(t, "icici-spend.eml"), []string{imap.SeenFlag}, now.Add(-time.Hour))
+	offerUID := appendMessage(t, inbox, fixture(t, "newsletter.eml"), nil, now)
+
+	s := NewIMAP(cfg)
+	cursor := func(uid uint32) string {
+		return "1:" + strconv.FormatUint(uint64(uid), 10)
+	}
+
+	messages, next, err := s.Fetch(ctx, "", acceptBanks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"20261005182240.1234@hdfcbank.net", "a7c3e1f0.icici.20261006101550@icicibank.com"}
+	if got := messageIDs(messages); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("messages %v, want %v", got, want)
+	}
+
+	// The cursor moves past the messages of senders that are not accepted
+	if next != cursor(offerUID) {
+		t.Errorf("cursor = %s, want %s", next, cursor(offerUID))
+	}
+
+	if messages[0].Cursor != cursor(hdfcUID) || messages[1].Cursor != cursor(iciciUID) {
+		t.Errorf("message cursors %s and %s, want %s and %s", messages[0].Cursor, messages[1].Cursor,
+			cursor(hdfcUID), cursor(iciciUID))
+	}
+
+	if !strings.Contains(messages[0].Text, "Rs 2,345.00 at ZOMATO") || !strings.Contains(messages[1].Text, "Info: AMAZON PAY INDIA.") {
+		t.Errorf("texts %q and %q", messages[0].Text, messages[1].Text)
+	}
+
+	if seen(inbox, hdfcUID) {
+		t.Error("unread alert marked seen by the poll")
+	}
+
+	if !seen(inbox, iciciUID) {
+		t.Error("read alert lost its seen flag")
+	}
+
+	t.Run("resumes after the cursor", func(t *testing.T) {
+		messages, again, err := s.Fetch(ctx, next, acceptBanks)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(messages) != 0 || again != next {
+			t.Fatalf("got %v and cursor %s, want nothing new", messageIDs(messages), again)
+		}
+
+		// Without a Message-ID header, the message is identified by its mailbox and UID
+		data := strings.Replace(string(fixture(t, "hdfc-spend.eml")),
+			"Message-ID: <20261005182240.1234@hdfcbank.net>\r\n", "", 1)
+		uid := appendMessage(t, inbox, []byte(data), nil, time.Now())
+
+		messages, again, err = s.Fetch(ctx, next, acceptBanks)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if want := s.Name() + "/" + cursor(uid); len(messages) != 1 || messages[0].ID != want {
+			t.Fatalf("got %v, want %s", messageIDs(messages), want)
+		}
+
+		if again != cursor(uid) {
+			t.Errorf("cursor = %s, want %s", again, cursor(uid))
+		}
+	})
+
+	t.Run("changed UIDVALIDITY", func(t *testing.T) {
+		messages, _, err := s.Fetch(ctx, "2:"+strconv.FormatUint(uint64(offerUID), 10), acceptBanks)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The mailbox is read again as if it had no cursor
+		if len(messages) != 3 || messages[0].ID != want[0] {
+			t.Errorf("got %v, want the messages of the last %s", messageIDs(messages), firstPollWindow)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package mailbox
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Maildir reads the messages of a local Maildir. Messages are left where they are, so that
+// a mail client sharing the Maildir is not disturbed. Its cursor is the modification time, in
+// Unix nanoseconds, of the last message processed.
+type Maildir struct {
+	path string
+}
+
+// NewMaildir creates a source reading the Maildir at path
+func NewMaildir(path string) *Maildir {
+	return &Maildir{path: path}
+}
+
+// Name identifies the Maildir by its path
+func (m *Maildir) Name() string {
+	return "maildir://" + m.path
+}
+
+// Fetch returns the messages in the new and cur directories modified at or after the cursor,
+// oldest first. Without a cursor, messages of the last firstPollWindow are returned.
+func (m *Maildir) Fetch(ctx context.Context, cursor string, accept func(from string) bool) ([]*Message, string, error) {
+	since := time.Now().Add(-firstPollWindow)
+
+	if cursor != "" {
+		nanos, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid maildir cursor %q: %w", cursor, err)
+		}
+
+		since = time.Unix(0, nanos)
+	}
+
+	type file struct {
+		path    string
+		modTime time.Time
+	}
+
+	var files []file
+
+	for _, dir := range []string{"new", "cur"} {
+		entries, err := os.ReadDir(filepath.Join(m.path, dir))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read maildir %s: %w", dir, err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				// Moved to cur by a mail client since the directory was read
+				continue
+			}
+
+			if !info.ModTime().Before(since) {
+				files = append(files, file{path: filepath.Join(m.path, dir, entry.Name()), modTime: info.ModTime()})
+			}
+		}
+	}
+
+	slices.SortFunc(files, func(a, b file) int {
+		return cmp.Or(a.modTime.Compare(b.modTime), cmp.Compare(filepath.Base(a.path), filepath.Base(b.path)))
+	})
+
+	messages := make([]*Message, 0, len(files))
+	next := cursor
+
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, "", err
+		}
+
+		next = strconv.FormatInt(f.modTime.UnixNano(), 10)
+
+		raw, err := os.Open(f.path)
+		if err != nil {
+			// Moved to cur, or deleted, by a mail client; a moved message is read on the next poll
+			continue
+		}
+
+		// The unique part of the file name stays the same when a mail client marks the message
+		unique, _, _ := strings.Cut(filepath.Base(f.path), ":")
+
+		msg, err := ReadMessage(raw, "maildir:"+unique, f.modTime)
+		_ = raw.Close()
+
+		switch {
+		case err != nil:
+			msg = &Message{ID: "maildir:" + unique, Date: f.modTime, Invalid: err.Error()}
+		case !accept(msg.From):
+			continue
+		}
+
+		msg.Cursor = next
+		messages = append(messages, msg)
+	}
+
+	return messages, next, nil
+}
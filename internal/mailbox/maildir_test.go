@@ -0,0 +1,195 @@
+package mailbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixture returns an email of the testdata directory
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+// newMaildir creates an empty Maildir
+func newMaildir(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, sub := range []string{"new", "cur", "tmp"} {
+		err := os.Mkdir(filepath.Join(dir, sub), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// deliver writes a message to the Maildir as delivered at the time
+func deliver(t *testing.T, maildir, name string, data []byte, at time.Time) {
+	t.Helper()
+
+	path := filepath.Join(maildir, name)
+
+	err := os.WriteFile(path, data, 0o644)
+	if err == nil {
+		err = os.Chtimes(path, at, at)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func acceptBanks(from string) bool {
+	return strings.HasSuffix(from, "@hdfcbank.net") || strings.HasSuffix(from, "@icicibank.com")
+}
+
+func messageIDs(messages []*Message) []string {
+	ids := make([]string, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.ID)
+	}
+
+	return ids
+}
+
+func nanos(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func TestMaildirFetch(t *testing.T) {
+	ctx := context.Background()
+	dir := newMaildir(t)
+	now := time.Now().Truncate(time.Second)
+
+	hdfcAt, iciciAt, offerAt := now.Add(-3*time.Hour), now.Add(-2*time.Hour), now.Add(-time.Hour)
+
+	deliver(t, dir, "new/1700000000.M1P1.host", fixture(t, "hdfc-spend.eml"), hdfcAt)
+	// Read, and so marked seen, by a mail client
+	deliver(t, dir, "cur/1700000001.M2P1.host:2,S", fixture(t, "icici-spend.eml"), iciciAt)
+	deliver(t, dir, "new/1700000002.M3P1.host", fixture(t, "newsletter.eml"), offerAt)
+	// Older than the first poll reads, still being delivered and hidden
+	deliver(t, dir, "cur/1600000000.M4P1.host:2,S", fixture(t, "hdfc-spend.eml"), now.Add(-2*firstPollWindow))
+	deliver(t, dir, "tmp/1700000003.M5P1.host", fixture(t, "hdfc-spend.eml"), now)
+	deliver(t, dir, "new/.1700000004.M6P1.host", fixture(t, "hdfc-spend.eml"), now)
+
+	m := NewMaildir(dir)
+
+	messages, next, err := m.Fetch(ctx, "", acceptBanks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"20261005182240.1234@hdfcbank.net", "a7c3e1f0.icici.20261006101550@icicibank.com"}
+	if got := messageIDs(messages); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("messages %v, want %v", got, want)
+	}
+
+	// The cursor moves past the messages of senders that are not accepted
+	if next != nanos(offerAt) {
+		t.Errorf("cursor = %s, want the newsletter's %s", next, nanos(offerAt))
+	}
+
+	hdfc, icici := messages[0], messages[1]
+
+	if hdfc.From != "alerts@hdfcbank.net" || hdfc.Cursor != nanos(hdfcAt) {
+		t.Errorf("hdfc message from %s with cursor %s", hdfc.From, hdfc.Cursor)
+	}
+
+	if !strings.Contains(hdfc.Text, "Rs 2,345.00 at ZOMATO") {
+		t.Errorf("quoted-printable text not decoded: %q", hdfc.Text)
+	}
+
+	if want := time.Date(2026, time.October, 5, 12, 52, 40, 0, time.UTC); !hdfc.Date.Equal(want) {
+		t.Errorf("date = %s, want %s", hdfc.Date, want)
+	}
+
+	if !strings.Contains(icici.Text, "Info: AMAZON PAY INDIA.") || strings.Contains(icici.Text, "color") {
+		t.Errorf("HTML part not read as text: %q", icici.Text)
+	}
+
+	t.Run("resumes from the cursor", func(t *testing.T) {
+		messages, next, err := m.Fetch(ctx, hdfc.Cursor, acceptBanks)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The message at the cursor is listed again, the worker skips it by its ID
+		want := []string{hdfc.ID, icici.ID}
+		if got := messageIDs(messages); strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("messages %v, want %v", got, want)
+		}
+
+		if next != nanos(offerAt) {
+			t.Errorf("cursor = %s, want %s", next, nanos(offerAt))
+		}
+
+		messages, next, err = m.Fetch(ctx, next, acceptBanks)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(messages) != 0 || next != nanos(offerAt) {
+			t.Errorf("got %v and cursor %s after the last message", messageIDs(messages), next)
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, _, err := m.Fetch(ctx, "1:2", acceptBanks)
+		if err == nil {
+			t.Error("Fetch succeeded, want an error")
+		}
+	})
+}
+
+// TestMaildirSeen checks that a message keeps its ID when a mail client marks it seen by moving
+// it from new to cur
+func TestMaildirSeen(t *testing.T) {
+	ctx := context.Background()
+	dir := newMaildir(t)
+	at := time.Now().Add(-time.Hour)
+
+	// Without a Message-ID header, the message is identified by its file name
+	data := []byte(strings.Replace(string(fixture(t, "hdfc-spend.eml")),
+		"Message-ID: <20261005182240.1234@hdfcbank.net>\r\n", "", 1))
+
+	deliver(t, dir, "new/1700000000.M1P1.host", data, at)
+
+	m := NewMaildir(dir)
+
+	before, _, err := m.Fetch(ctx, "", acceptBanks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Rename(filepath.Join(dir, "new/1700000000.M1P1.host"), filepath.Join(dir, "cur/1700000000.M1P1.host:2,S"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after, _, err := m.Fetch(ctx, "", acceptBanks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(before) != 1 || len(after) != 1 {
+		t.Fatalf("got %d messages before and %d after, want 1", len(before), len(after))
+	}
+
+	if before[0].ID != "maildir:1700000000.M1P1.host" || after[0].ID != before[0].ID {
+		t.Errorf("ID %q before marking seen and %q after, want maildir:1700000000.M1P1.host", before[0].ID, after[0].ID)
+	}
+}
@@ -0,0 +1,166 @@
+// Package mailbox reads transaction alert emails from a Maildir or an IMAP mailbox into drafts
+package mailbox
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// htmlBreaks are the tags that end a line of text
+	htmlBreaks = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|tr|li|h[1-6])>`)
+	// htmlHidden are the elements whose content is not text
+	htmlHidden = regexp.MustCompile(`(?is)<(style|script|head)\b.*?</(style|script|head)>`)
+	htmlTags   = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// Message is an email read from a mailbox
+type Message struct {
+	// ID is the Message-ID header, or an ID derived from the mailbox when the message has none
+	ID      string
+	From    string
+	Subject string
+	Date    time.Time
+	// Text is the plain text of the message, or the text of its HTML part when it has no plain
+	// text part
+	Text string
+	// Cursor is where the next poll resumes once the message is processed
+	Cursor string
+	// Invalid is why a message that could not be read was not, it is recorded as ignored
+	Invalid string
+}
+
+// ReadMessage reads an RFC 5322 message. fallbackID identifies a message without a Message-ID
+// header, and fallbackDate dates one without a Date header.
+func ReadMessage(r io.Reader, fallbackID string, fallbackDate time.Time) (*Message, error) {
+	m, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+
+	msg := &Message{
+		ID:   strings.Trim(m.Header.Get("Message-Id"), "<> "),
+		Date: fallbackDate,
+	}
+
+	if msg.ID == "" {
+		msg.ID = fallbackID
+	}
+
+	if date, err := m.Header.Date(); err == nil {
+		msg.Date = date
+	}
+
+	if from, err := mail.ParseAddress(m.Header.Get("From")); err == nil {
+		msg.From = strings.ToLower(from.Address)
+	}
+
+	var dec mime.WordDecoder
+
+	msg.Subject, err = dec.DecodeHeader(m.Header.Get("Subject"))
+	if err != nil {
+		msg.Subject = m.Header.Get("Subject")
+	}
+
+	plain, htmlText, err := readBody(m.Header.Get("Content-Type"), m.Header.Get("Content-Transfer-Encoding"), m.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	msg.Text = plain
+	if strings.TrimSpace(msg.Text) == "" {
+		msg.Text = htmlToText(htmlText)
+	}
+
+	return msg, nil
+}
+
+// readBody returns the first plain text and the first HTML part of a body, descending into
+// multipart bodies
+func readBody(contentType, encoding string, body io.Reader) (plain, htmlText string, err error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return plain, htmlText, nil
+			}
+
+			if err != nil {
+				return "", "", fmt.Errorf("failed to read message part: %w", err)
+			}
+
+			p, h, err := readBody(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				return "", "", err
+			}
+
+			if plain == "" {
+				plain = p
+			}
+
+			if htmlText == "" {
+				htmlText = h
+			}
+		}
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return "", "", nil
+	}
+
+	data, err := io.ReadAll(decode(encoding, body))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode message part: %w", err)
+	}
+
+	if mediaType == "text/html" {
+		return "", string(data), nil
+	}
+
+	return string(data), "", nil
+}
+
+// decode undoes the content transfer encoding of a part
+func decode(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	default:
+		return r
+	}
+}
+
+// htmlToText returns the text of an HTML body, a line per paragraph, row or break
+func htmlToText(body string) string {
+	body = htmlHidden.ReplaceAllString(body, "")
+	body = htmlBreaks.ReplaceAllString(body, "\n")
+	body = html.UnescapeString(htmlTags.ReplaceAllString(body, " "))
+
+	var lines []string
+
+	for _, line := range strings.Split(body, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
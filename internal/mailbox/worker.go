@@ -0,0 +1,284 @@
+package mailbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// firstPollWindow is how far back a mailbox without a checkpoint is read
+const firstPollWindow = 30 * 24 * time.Hour
+
+// Statuses of processed messages
+const (
+	// StatusImported messages had alerts that were saved as drafts
+	StatusImported = "imported"
+	// StatusIgnored messages had no alert that could be saved
+	StatusIgnored = "ignored"
+)
+
+// AlertImporter saves alerts as drafts, e.g. *imports.Importer
+type AlertImporter interface {
+	// SaveAlerts saves the alerts of text received at the time as drafts on the cards of the user.
+	// It returns why none was saved when the text has no alert that can be.
+	SaveAlerts(
+		ctx context.Context,
+		log *slog.Logger,
+		userID *int64,
+		text string,
+		received time.Time,
+	) ([]*models.DraftTransaction, string, error)
+}
+
+// Source is a mailbox alert emails are read from
+type Source interface {
+	// Name identifies the mailbox, its checkpoint is stored under it
+	Name() string
+	// Fetch returns the messages after the cursor from accepted senders, oldest first, along with
+	// the cursor after every message listed, accepted or not
+	Fetch(ctx context.Context, cursor string, accept func(from string) bool) ([]*Message, string, error)
+}
+
+// Worker periodically reads alert emails from the mailboxes into drafts. Each mailbox resumes
+// from its checkpoint, and messages already processed, e.g. a message that is both in a Maildir
+// and on the IMAP server, are skipped by their Message-ID.
+type Worker struct {
+	log     *slog.Logger
+	db      *db.DB
+	im      AlertImporter
+	sources []Source
+	senders []string
+	cfg     config.Mail
+}
+
+// NewWorker creates a worker for the configured Maildir and IMAP mailbox. It has no sources
+// when neither is configured.
+func NewWorker(log *slog.Logger, dbConn *db.DB, im AlertImporter, cfg config.Mail) *Worker {
+	w := &Worker{
+		log: log,
+		db:  dbConn,
+		im:  im,
+		cfg: cfg,
+	}
+
+	if cfg.Maildir != "" {
+		w.sources = append(w.sources, NewMaildir(cfg.Maildir))
+	}
+
+	if cfg.IMAP.Host != "" {
+		w.sources = append(w.sources, NewIMAP(cfg.IMAP))
+	}
+
+	for _, sender := range strings.Split(cfg.Senders, ",") {
+		if sender = strings.ToLower(strings.TrimSpace(sender)); sender != "" {
+			w.senders = append(w.senders, sender)
+		}
+	}
+
+	return w
+}
+
+// Run polls the mailboxes on every interval until the context is cancelled. It returns at once
+// when no mailbox is configured. Failures are logged and retried on the next interval.
+func (w *Worker) Run(ctx context.Context) error {
+	if len(w.sources) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(w.sources))
+	for _, s := range w.sources {
+		names = append(names, s.Name())
+	}
+
+	w.log.InfoContext(ctx, "starting mail worker",
+		slog.Any("mailboxes", names),
+		slog.Any("senders", w.senders),
+		slog.Duration("interval", w.cfg.Interval))
+
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		for _, source := range w.sources {
+			err := w.poll(ctx, source)
+			if err != nil && ctx.Err() == nil {
+				w.log.ErrorContext(ctx, "failed to poll mailbox", slog.String("mailbox", source.Name()), logger.Error(err))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll reads the new messages of a mailbox, advancing its checkpoint after every message so that
+// a failure resumes with the message that failed. The mailbox is not read until there is a user
+// to import the alerts for.
+func (w *Worker) poll(ctx context.Context, source Source) error {
+	user, err := w.user(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		w.log.InfoContext(ctx, "skipping mailbox without a user to import for", slog.String("mailbox", source.Name()))
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	var cursor string
+
+	checkpoint, err := w.db.Queries.GetMailCheckpoint(ctx, source.Name())
+	switch {
+	case err == nil:
+		cursor = checkpoint.Cursor
+	case !errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("failed to get checkpoint: %w", err)
+	}
+
+	messages, next, err := source.Fetch(ctx, cursor, w.accept)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range messages {
+		err = w.process(ctx, &user.ID, source, msg)
+		if err != nil {
+			return fmt.Errorf("failed to process message %s: %w", msg.ID, err)
+		}
+
+		if msg.Cursor == cursor {
+			continue
+		}
+
+		cursor = msg.Cursor
+
+		err = w.saveCheckpoint(ctx, source, cursor)
+		if err != nil {
+			return err
+		}
+	}
+
+	if next == cursor {
+		return nil
+	}
+
+	return w.saveCheckpoint(ctx, source, next)
+}
+
+// user returns the user alert emails are imported for: the configured one, the first user
+// otherwise
+func (w *Worker) user(ctx context.Context) (*models.User, error) {
+	if w.cfg.User != "" {
+		return w.db.Queries.GetUserByUsername(ctx, w.cfg.User)
+	}
+
+	return w.db.Queries.GetFirstUser(ctx)
+}
+
+// process reads the alerts of a message into drafts on the cards of the user and records it as
+// processed
+func (w *Worker) process(ctx context.Context, userID *int64, source Source, msg *Message) error {
+	processed, err := w.db.Queries.IsMailMessageProcessed(ctx, msg.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check message: %w", err)
+	}
+
+	if processed != 0 {
+		return nil
+	}
+
+	params := models.CreateMailMessageParams{
+		Source:     source.Name(),
+		MessageID:  msg.ID,
+		Sender:     msg.From,
+		Subject:    msg.Subject,
+		ReceivedAt: msg.Date.UTC(),
+		Status:     StatusIgnored,
+	}
+
+	drafts, reason, err := w.importAlerts(ctx, userID, msg)
+	if err != nil {
+		return err
+	}
+
+	if drafts > 0 {
+		params.Status = StatusImported
+		params.Drafts = int64(drafts)
+	} else {
+		params.Reason = &reason
+	}
+
+	_, err = w.db.Queries.CreateMailMessage(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to record message: %w", err)
+	}
+
+	w.log.InfoContext(ctx, "processed alert email",
+		slog.String("message_id", msg.ID),
+		slog.String("from", msg.From),
+		slog.String("status", params.Status),
+		slog.Int("drafts", drafts))
+
+	return nil
+}
+
+// importAlerts saves the alerts of a message as drafts, returning their number or why there
+// were none. The body is read as a single alert, as emails wrap an alert over several lines.
+func (w *Worker) importAlerts(ctx context.Context, userID *int64, msg *Message) (int, string, error) {
+	if msg.Invalid != "" {
+		return 0, msg.Invalid, nil
+	}
+
+	text := strings.Join(strings.Fields(msg.Subject+"\n"+msg.Text), " ")
+
+	drafts, reason, err := w.im.SaveAlerts(ctx, w.log, userID, text, msg.Date)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return len(drafts), reason, nil
+}
+
+func (w *Worker) saveCheckpoint(ctx context.Context, source Source, cursor string) error {
+	err := w.db.Queries.UpsertMailCheckpoint(ctx, models.UpsertMailCheckpointParams{
+		Source: source.Name(),
+		Cursor: cursor,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// accept reports whether alert emails are accepted from the address: any address when no
+// senders are configured, otherwise one of the senders or an address at one of their domains
+func (w *Worker) accept(from string) bool {
+	if len(w.senders) == 0 {
+		return true
+	}
+
+	for _, sender := range w.senders {
+		if from == sender {
+			return true
+		}
+
+		_, domain, ok := strings.Cut(from, "@")
+		if ok && !strings.Contains(sender, "@") && (domain == sender || strings.HasSuffix(domain, "."+sender)) {
+			return true
+		}
+	}
+
+	return false
+}
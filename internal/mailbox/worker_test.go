@@ -0,0 +1,196 @@
+package mailbox
+
+import (
+	"context"
+	"github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeImporter saves a draft for every alert that mentions a credit card
+type fakeImporter struct {
+	texts []string
+}
+
+func (im *fakeImporter) SaveAlerts(
+	_ context.Context,
+	_ *slog.Logger,
+	_ *int64,
+	text string,
+	_ time.Time,
+) ([]*models.DraftTransaction, string, error) {
+	im.texts = append(im.texts, text)
+
+	if !strings.Contains(text, "Credit Card") {
+		return nil, "no alert template matched", nil
+	}
+
+	return []*models.DraftTransaction{{}}, "", nil
+}
+
+func TestWorkerPoll(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	dbConn, err := db.New(ctx, log, &db.Config{Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	maildir := newMaildir(t)
+	inbox, imapCfg := newIMAPServer(t)
+	now := time.Now().Truncate(time.Second)
+
+	statement := []byte("From: HDFC Bank <alerts@hdfcbank.net>\r\n" +
+		"Subject: Your credit card statement is ready\r\n" +
+		"Message-ID: <statement-1@hdfcbank.net>\r\n" +
+		"Date: Sun, 11 Oct 2026 09:00:00 +0530\r\n" +
+		"\r\n" +
+		"Your statement for October is ready to view.\r\n")
+
+	deliver(t, maildir, "new/1700000000.M1P1.host", fixture(t, "hdfc-spend.eml"), now.Add(-3*time.Hour))
+	deliver(t, maildir, "new/1700000001.M2P1.host", fixture(t, "newsletter.eml"), now.Add(-2*time.Hour))
+	deliver(t, maildir, "new/1700000002.M3P1.host", statement, now.Add(-time.Hour))
+
+	// The IMAP mailbox has a copy of the HDFC alert, e.g. fetched to the Maildir by a mail client
+	appendMessage(t, inbox, fixture(t, "hdfc-spend.eml"), nil, now.Add(-3*time.Hour))
+	appendMessage(t, inbox, fixture(t, "icici-spend.eml"), nil, now.Add(-2*time.Hour))
+
+	im := &fakeImporter{}
+	w := NewWorker(log, dbConn, im, config.Mail{
+		Maildir:  maildir,
+		IMAP:     imapCfg,
+		Senders:  "hdfcbank.net, credit_cards@icicibank.com",
+		Interval: time.Minute,
+	})
+
+	if len(w.sources) != 2 {
+		t.Fatalf("worker has %d sources, want the Maildir and the IMAP mailbox", len(w.sources))
+	}
+
+	mdir, imapSource := w.sources[0], w.sources[1]
+
+	t.Run("without a user", func(t *testing.T) {
+		err := w.poll(ctx, mdir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(im.texts) != 0 {
+			t.Errorf("imported %d messages, want none", len(im.texts))
+		}
+	})
+
+	_, err = dbConn.RegisterUser(ctx, log, "alice", "hash", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoint := func(t *testing.T, source Source) string {
+		t.Helper()
+
+		cp, err := dbConn.Queries.GetMailCheckpoint(ctx, source.Name())
+		if err != nil {
+			t.Fatalf("no checkpoint for %s: %v", source.Name(), err)
+		}
+
+		return cp.Cursor
+	}
+
+	tests := []struct {
+		name   string
+		source Source
+		// imported are the messages read into drafts by the poll, ignored the ones without an alert
+		imported []string
+		ignored  []string
+		cursor   string
+	}{
+		{
+			name:     "maildir",
+			source:   mdir,
+			imported: []string{"20261005182240.1234@hdfcbank.net"},
+			ignored:  []string{"statement-1@hdfcbank.net"},
+			cursor:   nanos(now.Add(-time.Hour)),
+		},
+		{
+			name:   "maildir polled again",
+			source: mdir,
+			cursor: nanos(now.Add(-time.Hour)),
+		},
+		{
+			// The HDFC alert was imported from the Maildir
+			name:     "imap",
+			source:   imapSource,
+			imported: []string{"a7c3e1f0.icici.20261006101550@icicibank.com"},
+			cursor:   "1:8",
+		},
+		{
+			name:   "imap polled again",
+			source: imapSource,
+			cursor: "1:8",
+		},
+	}
+
+	recorded := make(map[string]*models.MailMessage)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := len(im.texts)
+
+			err := w.poll(ctx, tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := len(im.texts)-calls, len(tt.imported)+len(tt.ignored); got != want {
+				t.Errorf("importer called %d times, want %d", got, want)
+			}
+
+			messages, err := dbConn.Queries.GetMailMessages(ctx, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var added []*models.MailMessage
+
+			for _, m := range messages {
+				if recorded[m.MessageID] == nil {
+					recorded[m.MessageID] = m
+					added = append(added, m)
+				}
+			}
+
+			if len(added) != len(tt.imported)+len(tt.ignored) {
+				t.Errorf("recorded %d messages, want %d", len(added), len(tt.imported)+len(tt.ignored))
+			}
+
+			for _, id := range tt.imported {
+				m := recorded[id]
+				if m == nil || m.Status != StatusImported || m.Drafts != 1 || m.Source != tt.source.Name() {
+					t.Errorf("message %s recorded as %+v, want imported from %s", id, m, tt.source.Name())
+				}
+			}
+
+			for _, id := range tt.ignored {
+				m := recorded[id]
+				if m == nil || m.Status != StatusIgnored || m.Reason == nil || *m.Reason != "no alert template matched" {
+					t.Errorf("message %s recorded as %+v, want ignored", id, m)
+				}
+			}
+
+			if got := checkpoint(t, tt.source); got != tt.cursor {
+				t.Errorf("checkpoint = %s, want %s", got, tt.cursor)
+			}
+		})
+	}
+
+	if !strings.Contains(im.texts[0], "HDFC Bank Credit Card ending 1234 for Rs 2,345.00 at ZOMATO") {
+		t.Errorf("alert read as %q, want the subject and body on a single line", im.texts[0])
+	}
+}
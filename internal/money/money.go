@@ -0,0 +1,354 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code
+type Currency string
+
+// INR is the currency every amount is stored and compared in
+const INR Currency = "INR"
+
+// RoundingMode decides how an amount that falls between two minor units is rounded
+type RoundingMode int
+
+const (
+	// HalfEven rounds to the nearest minor unit, ties to the even one (banker's rounding)
+	HalfEven RoundingMode = iota
+	// HalfUp rounds to the nearest minor unit, ties away from zero
+	HalfUp
+	// Floor rounds towards negative infinity
+	Floor
+	// Ceil rounds towards positive infinity
+	Ceil
+)
+
+// Money is a fixed-point amount held in the minor units of its currency, e.g. paise for INR.
+//
+// It is encoded in JSON as a number of major units (e.g. 1234.50) so that it stays compatible
+// with the float64 amounts it replaced, and stored in the database as an integer of minor units.
+type Money struct {
+	minor    int64
+	currency Currency
+}
+
+// Exponent returns the number of minor unit digits of the currency
+func (c Currency) Exponent() int {
+	switch c {
+	case "JPY", "KRW", "VND", "CLP", "ISK", "IDR":
+		return 0
+	case "KWD", "BHD", "OMR", "JOD", "TND":
+		return 3
+	default:
+		return 2
+	}
+}
+
+func (c Currency) scale() int64 {
+	return int64(math.Pow10(c.Exponent()))
+}
+
+// New creates an amount from minor units
+func New(minor int64, c Currency) Money {
+	return Money{minor: minor, currency: c}
+}
+
+// FromFloat creates an amount from a float of major units, rounding to the nearest minor unit
+// with the given mode. Use it only for results of rate arithmetic, never for parsing input.
+func FromFloat(f float64, c Currency, mode RoundingMode) Money {
+	return Money{minor: round(f*float64(c.scale()), mode), currency: c}
+}
+
+// Parse parses a decimal amount of major units (e.g. "1234.5") exactly.
+// Digits beyond the currency's minor units are rounded half to even.
+func Parse(s string, c Currency) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Money{}, fmt.Errorf("money: empty amount")
+	}
+
+	neg := false
+	switch s[0] {
+	case '-':
+		neg = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return Money{}, fmt.Errorf("money: invalid amount %q", s)
+	}
+
+	if whole == "" {
+		whole = "0"
+	}
+
+	if strings.ContainsAny(whole+frac, "eE+-") {
+		return Money{}, fmt.Errorf("money: invalid amount %q", s)
+	}
+
+	exp := c.Exponent()
+
+	// Keep one extra digit to round the remainder
+	var rest string
+	if len(frac) > exp {
+		frac, rest = frac[:exp], frac[exp:]
+	}
+
+	frac += strings.Repeat("0", exp-len(frac))
+
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("money: invalid amount %q: %w", s, err)
+	}
+
+	if rest != "" {
+		if strings.Trim(rest, "0123456789") != "" {
+			return Money{}, fmt.Errorf("money: invalid amount %q", s)
+		}
+
+		// rest is compared with an exact half, e.g. "5", "50", "500"
+		half := "5" + strings.Repeat("0", len(rest)-1)
+		switch {
+		case rest > half, rest == half && minor%2 == 1:
+			minor++
+		}
+	}
+
+	if neg {
+		minor = -minor
+	}
+
+	return Money{minor: minor, currency: c}, nil
+}
+
+// MustParse is like Parse but panics on an invalid amount. It is meant for constants.
+func MustParse(s string, c Currency) Money {
+	m, err := Parse(s, c)
+	if err != nil {
+		panic(err)
+	}
+
+	return m
+}
+
+// Minor returns the amount in minor units
+func (m Money) Minor() int64 {
+	return m.minor
+}
+
+// Currency returns the currency of the amount, INR unless set otherwise
+func (m Money) Currency() Currency {
+	if m.currency == "" {
+		return INR
+	}
+
+	return m.currency
+}
+
+// Float returns the amount in major units. It is meant for rate arithmetic only.
+func (m Money) Float() float64 {
+	return float64(m.minor) / float64(m.Currency().scale())
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+// IsPositive reports whether the amount is above zero
+func (m Money) IsPositive() bool {
+	return m.minor > 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.minor < 0
+}
+
+// Cmp compares two amounts of the same currency and returns -1, 0 or +1
+func (m Money) Cmp(o Money) int {
+	m.mustMatch(o)
+
+	switch {
+	case m.minor < o.minor:
+		return -1
+	case m.minor > o.minor:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Add returns m + o
+func (m Money) Add(o Money) Money {
+	m.mustMatch(o)
+
+	return Money{minor: m.minor + o.minor, currency: m.Currency()}
+}
+
+// Sub returns m - o
+func (m Money) Sub(o Money) Money {
+	m.mustMatch(o)
+
+	return Money{minor: m.minor - o.minor, currency: m.Currency()}
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{minor: -m.minor, currency: m.currency}
+}
+
+// Min returns the smaller of two amounts
+func Min(a, b Money) Money {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+
+	return b
+}
+
+// Max returns the larger of two amounts
+func Max(a, b Money) Money {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+
+	return b
+}
+
+// Mul returns the amount multiplied by a factor, rounded with the given mode
+func (m Money) Mul(factor float64, mode RoundingMode) Money {
+	return Money{minor: round(float64(m.minor)*factor, mode), currency: m.currency}
+}
+
+// Percent returns rate percent of the amount, rounded with the given mode
+func (m Money) Percent(rate float64, mode RoundingMode) Money {
+	return m.Mul(rate/100, mode)
+}
+
+// Convert converts the amount into another currency, where one unit of m's currency is
+// worth rate units of the target currency
+func (m Money) Convert(rate float64, to Currency, mode RoundingMode) Money {
+	return FromFloat(m.Float()*rate, to, mode)
+}
+
+// In returns the same number of major units in another currency, e.g. when the currency of an
+// amount is only known after it has been decoded
+func (m Money) In(c Currency) Money {
+	if m.Currency() == c {
+		return m
+	}
+
+	if m.Currency().Exponent() == c.Exponent() {
+		return Money{minor: m.minor, currency: c}
+	}
+
+	return FromFloat(m.Float(), c, HalfEven)
+}
+
+// Decimal formats the amount as major units with all minor digits, e.g. "1234.50"
+func (m Money) Decimal() string {
+	exp := m.Currency().Exponent()
+
+	minor := m.minor
+	sign := ""
+
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+
+	if exp == 0 {
+		return sign + strconv.FormatInt(minor, 10)
+	}
+
+	scale := m.Currency().scale()
+
+	return fmt.Sprintf("%s%d.%0*d", sign, minor/scale, exp, minor%scale)
+}
+
+// String formats the amount with its currency, e.g. "INR 1234.50"
+func (m Money) String() string {
+	return string(m.Currency()) + " " + m.Decimal()
+}
+
+// MarshalJSON encodes the amount as a JSON number of major units
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.Decimal()), nil
+}
+
+// UnmarshalJSON decodes a JSON number or string of major units.
+// The currency is kept if already set, INR otherwise.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+
+	return m.UnmarshalText(bytes.Trim(b, `"`))
+}
+
+// UnmarshalText decodes a decimal of major units, used for form values
+func (m *Money) UnmarshalText(b []byte) error {
+	parsed, err := Parse(string(b), m.Currency())
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+
+	return nil
+}
+
+// Value stores the amount as an integer of minor units. Only INR amounts can be stored.
+func (m Money) Value() (driver.Value, error) {
+	if m.Currency() != INR {
+		return nil, fmt.Errorf("money: cannot store %s amount, convert it to %s first", m.Currency(), INR)
+	}
+
+	return m.minor, nil
+}
+
+// Scan reads an integer of INR minor units
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case int64:
+		*m = Money{minor: v, currency: INR}
+	case nil:
+		*m = Money{currency: INR}
+	default:
+		return fmt.Errorf("money: cannot scan %T into Money", src)
+	}
+
+	return nil
+}
+
+func (m Money) mustMatch(o Money) {
+	if m.Currency() != o.Currency() {
+		panic(fmt.Sprintf("money: currency mismatch %s and %s", m.Currency(), o.Currency()))
+	}
+}
+
+func round(f float64, mode RoundingMode) int64 {
+	// Snap away float error first, e.g. 7502.500000000001 paise is an exact half
+	f = math.Round(f*1e6) / 1e6
+
+	switch mode {
+	case HalfUp:
+		return int64(math.Round(f))
+	case Floor:
+		return int64(math.Floor(f))
+	case Ceil:
+		return int64(math.Ceil(f))
+	default:
+		return int64(math.RoundToEven(f))
+	}
+}
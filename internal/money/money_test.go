@@ -0,0 +1,55 @@
+package money
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency Currency
+		minor    int64
+	}{
+		{in: "1234.5", currency: INR, minor: 123450},
+		{in: "1234", currency: INR, minor: 123400},
+		{in: ".5", currency: INR, minor: 50},
+		{in: " +10.01 ", currency: INR, minor: 1001},
+		{in: "-10.01", currency: INR, minor: -1001},
+		// Ties are rounded to the even minor unit
+		{in: "0.125", currency: INR, minor: 12},
+		{in: "0.135", currency: INR, minor: 14},
+		{in: "0.1250", currency: INR, minor: 12},
+		{in: "-0.125", currency: INR, minor: -12},
+		{in: "-0.135", currency: INR, minor: -14},
+		// Anything past the half rounds up, below it down
+		{in: "0.1251", currency: INR, minor: 13},
+		{in: "0.12500001", currency: INR, minor: 13},
+		{in: "0.1249999", currency: INR, minor: 12},
+		{in: "9.995", currency: INR, minor: 1000},
+		{in: "2.5", currency: "JPY", minor: 2},
+		{in: "3.5", currency: "JPY", minor: 4},
+		{in: "1.0005", currency: "KWD", minor: 1000},
+		{in: "1.0015", currency: "KWD", minor: 1002},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.currency)+" "+tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in, tt.currency)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.in, err)
+			}
+
+			if want := New(tt.minor, tt.currency); got != want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.in, got.Minor(), tt.minor)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{"", "-", ".", "1e3", "12.3.4", "12.34x5", "--1", "abc"} {
+		t.Run(in, func(t *testing.T) {
+			if got, err := Parse(in, INR); err == nil {
+				t.Errorf("Parse(%q) = %v, want an error", in, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package money
+
+import (
+	"github.com/go-playground/validator/v10"
+	validatorpkg "github.com/pushkar-anand/build-with-go/validator"
+)
+
+// ValidationTags are the validation tags for Money fields, to be registered with the validator.
+//
+//	positive_money: the amount must be above zero
+//	nonnegative_money: the amount must not be below zero
+var ValidationTags = map[string]validatorpkg.ValidationFunc{
+	"positive_money":    isPositive,
+	"nonnegative_money": isNonNegative,
+}
+
+func isPositive(fl validator.FieldLevel) bool {
+	switch m := fl.Field().Interface().(type) {
+	case Money:
+		return m.IsPositive()
+	case *Money:
+		return m != nil && m.IsPositive()
+	default:
+		return false
+	}
+}
+
+func isNonNegative(fl validator.FieldLevel) bool {
+	switch m := fl.Field().Interface().(type) {
+	case Money:
+		return !m.IsNegative()
+	case *Money:
+		return m == nil || !m.IsNegative()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,253 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// AllKinds is the preference kind applying to every kind of notification
+const AllKinds = "*"
+
+// Dispatcher delivers notifications through every channel the user has enabled for their kind.
+// Each delivery is recorded in the delivery log, and failed deliveries are retried with
+// exponential backoff until they run out of attempts.
+type Dispatcher struct {
+	log      *slog.Logger
+	db       *db.DB
+	channels map[string]Notifier
+	cfg      config.Retry
+}
+
+// NewDispatcher creates a dispatcher delivering through the given channels
+func NewDispatcher(log *slog.Logger, dbConn *db.DB, channels map[string]Notifier, cfg config.Retry) *Dispatcher {
+	return &Dispatcher{
+		log:      log,
+		db:       dbConn,
+		channels: channels,
+		cfg:      cfg,
+	}
+}
+
+// Channels returns the names of the configured channels, sorted
+func (d *Dispatcher) Channels() []string {
+	names := make([]string, 0, len(d.channels))
+	for name := range d.channels {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// UserChannels returns the names of the configured channels that can deliver to the user, sorted.
+// The webhook, email and ntfy destinations belong to a single account and are left out for
+// everyone else.
+func (d *Dispatcher) UserChannels(ctx context.Context, userID *int64) ([]string, error) {
+	var names []string
+
+	for _, name := range d.Channels() {
+		if o, ok := d.channels[name].(*OwnedNotifier); ok {
+			owned, err := o.HasRecipients(ctx, userID)
+			if err != nil {
+				return nil, err
+			}
+
+			if !owned {
+				continue
+			}
+		}
+
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// Notify records a delivery for each channel the user of the notification enabled and attempts it
+// right away.
+// It only fails when the deliveries cannot be recorded, failed attempts are retried by Run.
+func (d *Dispatcher) Notify(ctx context.Context, n Notification) error {
+	channels, err := d.EnabledChannels(ctx, n.UserID, n.Kind)
+	if err != nil {
+		return err
+	}
+
+	var data *string
+	if len(n.Data) > 0 {
+		b, err := json.Marshal(n.Data)
+		if err != nil {
+			return fmt.Errorf("failed to encode notification data: %w", err)
+		}
+
+		s := string(b)
+		data = &s
+	}
+
+	for _, channel := range channels {
+		delivery, err := d.db.Queries.CreateNotificationDelivery(ctx, models.CreateNotificationDeliveryParams{
+			UserID:  n.UserID,
+			Kind:    n.Kind,
+			Channel: channel,
+			Title:   n.Title,
+			Body:    n.Body,
+			Data:    data,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record %s delivery: %w", channel, err)
+		}
+
+		d.deliver(ctx, delivery, n, time.Now())
+	}
+
+	return nil
+}
+
+// Run retries the failed deliveries that are due on every retry interval until the context is
+// cancelled
+func (d *Dispatcher) Run(ctx context.Context) error {
+	d.log.InfoContext(ctx, "starting notification dispatcher",
+		slog.Any("channels", d.Channels()),
+		slog.Int("attempts", d.cfg.Attempts),
+		slog.Duration("interval", d.cfg.Interval))
+
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		err := d.retry(ctx, time.Now())
+		if err != nil {
+			d.log.ErrorContext(ctx, "failed to retry notification deliveries", logger.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// retry attempts the pending deliveries whose next attempt is due
+func (d *Dispatcher) retry(ctx context.Context, now time.Time) error {
+	deliveries, err := d.db.Queries.GetDueNotificationDeliveries(ctx, now.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to get due deliveries: %w", err)
+	}
+
+	for _, delivery := range deliveries {
+		n := Notification{
+			UserID: delivery.UserID,
+			Kind:   delivery.Kind,
+			Title:  delivery.Title,
+			Body:   delivery.Body,
+		}
+
+		if delivery.Data != nil {
+			err = json.Unmarshal([]byte(*delivery.Data), &n.Data)
+			if err != nil {
+				return fmt.Errorf("failed to decode data of delivery %d: %w", delivery.ID, err)
+			}
+		}
+
+		d.deliver(ctx, delivery, n, now)
+	}
+
+	return nil
+}
+
+// deliver attempts the delivery and records the outcome. A failed attempt is retried after the
+// retry interval, doubled for every earlier attempt.
+func (d *Dispatcher) deliver(ctx context.Context, delivery *models.NotificationDelivery, n Notification, now time.Time) {
+	log := d.log.With(
+		slog.Int64("delivery_id", delivery.ID),
+		slog.String("channel", delivery.Channel),
+		slog.String("kind", delivery.Kind))
+
+	var err error
+
+	notifier, ok := d.channels[delivery.Channel]
+	if ok {
+		err = notifier.Notify(ctx, n)
+	} else {
+		err = fmt.Errorf("channel %q is not configured", delivery.Channel)
+	}
+
+	if err == nil {
+		err = d.db.Queries.MarkNotificationDelivered(ctx, delivery.ID)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to mark notification delivered", logger.Error(err))
+		}
+
+		return
+	}
+
+	log.WarnContext(ctx, "failed to deliver notification",
+		slog.Int64("attempt", delivery.Attempts+1), logger.Error(err))
+
+	lastError := err.Error()
+
+	err = d.db.Queries.MarkNotificationAttemptFailed(ctx, models.MarkNotificationAttemptFailedParams{
+		LastError:     &lastError,
+		NextAttemptAt: now.UTC().Add(d.cfg.Interval << delivery.Attempts),
+		MaxAttempts:   int64(d.cfg.Attempts),
+		ID:            delivery.ID,
+	})
+	if err != nil {
+		log.ErrorContext(ctx, "failed to record failed notification attempt", logger.Error(err))
+	}
+}
+
+// EnabledChannels returns the configured channels the user enabled for the kind. A preference for
+// the kind takes precedence over one for all kinds, and channels without a preference are enabled.
+// Channels with no one of the user to deliver to, e.g. the destinations of another account, are left
+// out.
+func (d *Dispatcher) EnabledChannels(ctx context.Context, userID *int64, kind string) ([]string, error) {
+	prefs, err := d.db.Queries.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	enabled := make(map[string]bool, len(d.channels))
+	for name := range d.channels {
+		enabled[name] = true
+	}
+
+	for _, k := range []string{AllKinds, kind} {
+		for _, pref := range prefs {
+			if _, ok := enabled[pref.Channel]; ok && pref.Kind == k {
+				enabled[pref.Channel] = pref.Enabled
+			}
+		}
+	}
+
+	var channels []string
+	for _, name := range d.Channels() {
+		if !enabled[name] {
+			continue
+		}
+
+		if r, ok := d.channels[name].(Recipients); ok {
+			has, err := r.HasRecipients(ctx, userID)
+			if err != nil {
+				return nil, err
+			}
+
+			if !has {
+				continue
+			}
+		}
+
+		channels = append(channels, name)
+	}
+
+	return channels, nil
+}
@@ -0,0 +1,236 @@
+package notify
+
+import (
+	"context"
+	"github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"io"
+	"log/slog"
+	"net"
+	"net/textproto"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpServer is a local SMTP server that rejects the first failures messages with a temporary
+// error and accepts the rest
+type smtpServer struct {
+	ln       net.Listener
+	mu       sync.Mutex
+	failures int
+	attempts int
+	messages []string
+}
+
+func newSMTPServer(t *testing.T, failures int) *smtpServer {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &smtpServer{ln: ln, failures: failures}
+
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+// config returns the SMTP settings sending to the server
+func (s *smtpServer) config() config.SMTP {
+	host, port, _ := net.SplitHostPort(s.ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+
+	return config.SMTP{Host: host, Port: p, From: "cardmax@localhost", To: "alice@localhost"}
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost ESMTP")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		cmd := strings.ToUpper(strings.Fields(line + " ")[0])
+
+		switch cmd {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250 localhost")
+		case "MAIL":
+			s.mu.Lock()
+			s.attempts++
+			fail := s.attempts <= s.failures
+			s.mu.Unlock()
+
+			if fail {
+				_ = tp.PrintfLine("451 4.3.0 try again later")
+				continue
+			}
+
+			_ = tp.PrintfLine("250 OK")
+		case "RCPT":
+			_ = tp.PrintfLine("250 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+
+			body, err := io.ReadAll(tp.DotReader())
+			if err != nil {
+				return
+			}
+
+			s.mu.Lock()
+			s.messages = append(s.messages, string(body))
+			s.mu.Unlock()
+
+			_ = tp.PrintfLine("250 OK")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func (s *smtpServer) counts() (attempts, messages int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.attempts, len(s.messages)
+}
+
+func TestDispatcherRetriesEmail(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	dbConn, err := db.New(ctx, log, &db.Config{Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := dbConn.RegisterUser(ctx, log, "alice", "hash", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each step retries the due deliveries after the time since the notification was sent
+	type step struct {
+		after    time.Duration
+		attempts int
+		status   string
+	}
+
+	tests := []struct {
+		name     string
+		failures int
+		steps    []step
+		messages int
+	}{
+		{
+			name: "delivered at once",
+			steps: []step{
+				{after: 0, attempts: 1, status: "sent"},
+				{after: time.Hour, attempts: 1, status: "sent"},
+			},
+			messages: 1,
+		},
+		{
+			name:     "retried with backoff",
+			failures: 2,
+			steps: []step{
+				{after: 0, attempts: 1, status: "pending"},
+				// The first retry is due after the interval
+				{after: 50 * time.Second, attempts: 1, status: "pending"},
+				{after: 70 * time.Second, attempts: 2, status: "pending"},
+				// The second one after twice the interval
+				{after: 70*time.Second + 110*time.Second, attempts: 2, status: "pending"},
+				{after: 70*time.Second + 130*time.Second, attempts: 3, status: "sent"},
+			},
+			messages: 1,
+		},
+		{
+			name:     "given up after the last attempt",
+			failures: 5,
+			steps: []step{
+				{after: 0, attempts: 1, status: "pending"},
+				{after: 70 * time.Second, attempts: 2, status: "pending"},
+				{after: 70*time.Second + 130*time.Second, attempts: 3, status: "failed"},
+				{after: 24 * time.Hour, attempts: 3, status: "failed"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newSMTPServer(t, tt.failures)
+
+			d := NewDispatcher(log, dbConn, map[string]Notifier{
+				ChannelEmail: NewEmailNotifier(server.config()),
+			}, config.Retry{Attempts: 3, Interval: time.Minute})
+
+			sent := time.Now()
+
+			err := d.Notify(ctx, Notification{UserID: &user.ID, Kind: KindTest, Title: tt.name, Body: "body"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, s := range tt.steps {
+				if s.after > 0 {
+					err = d.retry(ctx, sent.Add(s.after))
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				deliveries, err := dbConn.Queries.GetNotificationDeliveries(ctx, models.GetNotificationDeliveriesParams{
+					UserID: &user.ID,
+					Limit:  1,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				delivery := deliveries[0]
+				if delivery.Title != tt.name {
+					t.Fatalf("latest delivery is %q, want %q", delivery.Title, tt.name)
+				}
+
+				if delivery.Status != s.status || delivery.Attempts != int64(s.attempts) {
+					t.Errorf("after %s: delivery %s after %d attempts, want %s after %d", s.after,
+						delivery.Status, delivery.Attempts, s.status, s.attempts)
+				}
+
+				if attempts, _ := server.counts(); attempts != s.attempts {
+					t.Errorf("after %s: server saw %d attempts, want %d", s.after, attempts, s.attempts)
+				}
+			}
+
+			if _, messages := server.counts(); messages != tt.messages {
+				t.Errorf("server received %d messages, want %d", messages, tt.messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/config"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// emailTimeout is how long sending an email may take, from connecting to the server to it
+// accepting the message
+const emailTimeout = 30 * time.Second
+
+// EmailNotifier sends notifications as plain text email over SMTP
+type EmailNotifier struct {
+	cfg config.SMTP
+}
+
+// NewEmailNotifier creates a notifier sending email through the configured SMTP server.
+// The connection is upgraded with STARTTLS when the server supports it.
+func NewEmailNotifier(cfg config.SMTP) *EmailNotifier {
+	return &EmailNotifier{cfg: cfg}
+}
+
+// Notify emails the notification to the configured recipients, giving up when the context is done
+// or the server takes longer than emailTimeout
+func (e *EmailNotifier) Notify(ctx context.Context, n Notification) error {
+	to := recipients(e.cfg.To)
+	if len(to) == 0 {
+		return fmt.Errorf("no email recipients configured")
+	}
+
+	msg, err := e.message(n, to, time.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, emailTimeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port))
+
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+
+	// The deadline bounds a server that stops responding, and the connection is closed when the
+	// context is cancelled
+	deadline, _ := ctx.Deadline()
+
+	err = conn.SetDeadline(deadline)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to set SMTP deadline: %w", err)
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+
+	c, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	defer c.Close()
+
+	err = e.send(c, to, msg)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// send sends the message over the connection as smtp.SendMail does: upgrading it with STARTTLS
+// when the server supports it and authenticating when a username is configured
+func (e *EmailNotifier) send(c *smtp.Client, to []string, msg []byte) error {
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		err := c.StartTLS(&tls.Config{ServerName: e.cfg.Host})
+		if err != nil {
+			return err
+		}
+	}
+
+	if e.cfg.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("server does not support AUTH")
+		}
+
+		err := c.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host))
+		if err != nil {
+			return err
+		}
+	}
+
+	err := c.Mail(e.cfg.From)
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range to {
+		err = c.Rcpt(addr)
+		if err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(msg)
+	if err != nil {
+		return err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// message builds the email with the notification's title as the subject
+func (e *EmailNotifier) message(n Notification, to []string, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", e.cfg.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Title))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+
+	_, err := qp.Write([]byte(n.Body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode email body: %w", err)
+	}
+
+	err = qp.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode email body: %w", err)
+	}
+
+	buf.WriteString("\r\n")
+
+	return buf.Bytes(), nil
+}
+
+// recipients splits a comma separated list of addresses
+func recipients(list string) []string {
+	var to []string
+
+	for _, addr := range strings.Split(list, ",") {
+		addr = strings.TrimSpace(addr)
+		if addr != "" {
+			to = append(to, addr)
+		}
+	}
+
+	return to
+}
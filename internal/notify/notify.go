@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/webpush"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var (
+	// client is the HTTP client used by the channels posting to a URL
+	client = &http.Client{Timeout: 10 * time.Second}
+	// pushClient is the HTTP client push messages are sent with. The endpoints are given by
+	// browsers, so it connects straight to public addresses only, whatever their names resolve to.
+	pushClient = &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         (&net.Dialer{Timeout: 5 * time.Second, Control: dialPublic}).DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			ForceAttemptHTTP2:   true,
+		},
+	}
+)
+
+// dialPublic refuses connections to addresses that are not public
+func dialPublic(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !webpush.PublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("refusing to connect to %s, which is not a public address", address)
+	}
+
+	return nil
+}
+
+// Kinds of notifications
+const (
+	// KindPaymentDue reminds the user of a statement that is due soon
+	KindPaymentDue = "payment_due"
+	// KindCapAlert warns that the spend of a cycle is approaching or has reached a reward cap
+	KindCapAlert = "cap_alert"
+	// KindTest is sent on request to check that the channels are set up correctly
+	KindTest = "test"
+)
+
+// Channels notifications are delivered through
+const (
+	ChannelLog     = "log"
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+	ChannelNtfy    = "ntfy"
+	ChannelPush    = "push"
+)
+
+type (
+	// Notification is a message raised for a user
+	Notification struct {
+		// UserID is the user the notification is for, whose preferences and push subscriptions
+		// apply
+		UserID *int64 `json:"-"`
+		Kind   string `json:"kind"`
+		Title  string `json:"title"`
+		Body   string `json:"body"`
+		// Data carries the structured details of the notification, e.g. the statement ID
+		Data map[string]any `json:"data,omitempty"`
+	}
+
+	// Notifier delivers notifications to the user
+	Notifier interface {
+		Notify(ctx context.Context, n Notification) error
+	}
+
+	// Recipients is implemented by notifiers that may have no one of the user to deliver to,
+	// e.g. before any of their browsers subscribed to push. They are skipped while that is the case.
+	Recipients interface {
+		HasRecipients(ctx context.Context, userID *int64) (bool, error)
+	}
+
+	// LogNotifier writes notifications to the log. It is the default notifier.
+	LogNotifier struct {
+		log *slog.Logger
+	}
+)
+
+// NewLogNotifier creates a notifier that writes notifications to the log
+func NewLogNotifier(log *slog.Logger) *LogNotifier {
+	return &LogNotifier{log: log}
+}
+
+// Notify logs the notification
+func (l *LogNotifier) Notify(ctx context.Context, n Notification) error {
+	l.log.InfoContext(ctx, "notification",
+		slog.String("kind", n.Kind),
+		slog.String("title", n.Title),
+		slog.String("body", n.Body),
+		slog.Any("data", n.Data))
+
+	return nil
+}
+
+// NewChannels creates the notifiers of the channels set up in the config, keyed by channel.
+// Notifications are always written to the log and pushed to the subscribed browsers. The webhook,
+// email and ntfy destinations are those of the configured user and only deliver their notifications.
+func NewChannels(log *slog.Logger, dbConn *db.DB, vapid *webpush.VAPID, cfg config.Notify) map[string]Notifier {
+	channels := map[string]Notifier{
+		ChannelLog:  NewLogNotifier(log),
+		ChannelPush: NewPushNotifier(log, dbConn, webpush.NewSender(vapid, pushClient, cfg.Push.TTL)),
+	}
+
+	if cfg.Webhook.URL != "" {
+		channels[ChannelWebhook] = NewOwnedNotifier(dbConn, cfg.User, NewWebhookNotifier(cfg.Webhook))
+	}
+
+	if cfg.SMTP.Host != "" {
+		channels[ChannelEmail] = NewOwnedNotifier(dbConn, cfg.User, NewEmailNotifier(cfg.SMTP))
+	}
+
+	if cfg.Ntfy.URL != "" {
+		channels[ChannelNtfy] = NewOwnedNotifier(dbConn, cfg.User, NewNtfyNotifier(cfg.Ntfy))
+	}
+
+	return channels
+}
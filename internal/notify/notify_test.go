@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"context"
+	"github.com/pushkar-anand/cardmax/config"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPushClientRefusesLocalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("push client connected to the local server")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	// The server by its address and by a name, as the addresses names resolve to are checked too
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	for _, endpoint := range []string{server.URL, url} {
+		resp, err := pushClient.Post(endpoint, "application/octet-stream", nil)
+		if err == nil {
+			resp.Body.Close()
+			t.Errorf("posted to %s, want the connection refused", endpoint)
+		}
+	}
+}
+
+func TestEmailNotifierStopsWithContext(t *testing.T) {
+	// A server that accepts connections but never greets
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			defer conn.Close()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+
+	e := NewEmailNotifier(config.SMTP{Host: host, Port: p, From: "cardmax@localhost", To: "alice@localhost"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	err = e.Notify(ctx, Notification{Kind: KindTest, Title: "Test", Body: "Test"})
+	if err == nil {
+		t.Fatal("Notify succeeded, want the context's error")
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Notify returned after %s, want when the context is done", elapsed)
+	}
+}
@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/config"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// NtfyNotifier publishes notifications to an ntfy topic, which pushes them to the
+// devices subscribed to it
+type NtfyNotifier struct {
+	cfg config.Ntfy
+}
+
+// NewNtfyNotifier creates a notifier publishing to the configured topic URL
+func NewNtfyNotifier(cfg config.Ntfy) *NtfyNotifier {
+	return &NtfyNotifier{cfg: cfg}
+}
+
+// Notify publishes the notification's body with its title and kind as a tag
+func (nt *NtfyNotifier) Notify(ctx context.Context, n Notification) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, nt.cfg.URL, strings.NewReader(n.Body))
+	if err != nil {
+		return fmt.Errorf("failed to create ntfy request: %w", err)
+	}
+
+	req.Header.Set("Title", mime.QEncoding.Encode("utf-8", n.Title))
+	req.Header.Set("Tags", n.Kind)
+
+	if nt.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+nt.cfg.Token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to publish to ntfy: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("ntfy responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+)
+
+// OwnedNotifier delivers through a destination of a single account, e.g. the webhook URL set up in
+// the config. Notifications of other users are not sent to it.
+type OwnedNotifier struct {
+	Notifier
+	db *db.DB
+	// username is the owner of the destination, the first account when empty
+	username string
+}
+
+// NewOwnedNotifier creates a notifier delivering the notifications of the given user through n
+func NewOwnedNotifier(dbConn *db.DB, username string, n Notifier) *OwnedNotifier {
+	return &OwnedNotifier{Notifier: n, db: dbConn, username: username}
+}
+
+// HasRecipients reports whether the user owns the destination. Before any account exists, the
+// notifications of no user in particular are delivered to it.
+func (o *OwnedNotifier) HasRecipients(ctx context.Context, userID *int64) (bool, error) {
+	owner, err := o.owner(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return userID == nil && o.username == "", nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("failed to get the owner of the channel: %w", err)
+	}
+
+	return userID != nil && *userID == owner.ID, nil
+}
+
+// owner returns the account the destination belongs to: the configured one, the first user
+// otherwise
+func (o *OwnedNotifier) owner(ctx context.Context) (*models.User, error) {
+	if o.username != "" {
+		return o.db.Queries.GetUserByUsername(ctx, o.username)
+	}
+
+	return o.db.Queries.GetFirstUser(ctx)
+}
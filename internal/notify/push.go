@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/webpush"
+	"log/slog"
+)
+
+// PushNotifier sends notifications through Web Push to every browser of their user that subscribed
+// to them
+type PushNotifier struct {
+	log    *slog.Logger
+	db     *db.DB
+	sender *webpush.Sender
+}
+
+// NewPushNotifier creates a notifier pushing to the stored subscriptions
+func NewPushNotifier(log *slog.Logger, dbConn *db.DB, sender *webpush.Sender) *PushNotifier {
+	return &PushNotifier{
+		log:    log,
+		db:     dbConn,
+		sender: sender,
+	}
+}
+
+// HasRecipients reports whether any browser of the user has subscribed
+func (p *PushNotifier) HasRecipients(ctx context.Context, userID *int64) (bool, error) {
+	count, err := p.db.Queries.CountPushSubscriptions(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to count push subscriptions: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// Notify pushes the notification as JSON to every subscription of its user. Subscriptions the push service
+// reports gone are removed. It fails only when no subscription received the notification, so that
+// a retry does not repeat it on the browsers that did.
+func (p *PushNotifier) Notify(ctx context.Context, n Notification) error {
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	subs, err := p.db.Queries.GetPushSubscriptions(ctx, n.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get push subscriptions: %w", err)
+	}
+
+	var (
+		delivered int
+		errs      []error
+	)
+
+	for _, sub := range subs {
+		err = p.sender.Send(ctx, webpush.Subscription{
+			Endpoint: sub.Endpoint,
+			P256dh:   sub.P256dh,
+			Auth:     sub.Auth,
+		}, payload)
+
+		switch {
+		case errors.Is(err, webpush.ErrGone):
+			p.log.InfoContext(ctx, "removing expired push subscription", slog.Int64("subscription_id", sub.ID))
+
+			_, err = p.db.Queries.DeletePushSubscription(ctx, sub.Endpoint)
+			if err != nil {
+				p.log.ErrorContext(ctx, "failed to remove push subscription", logger.Error(err))
+			}
+		case err != nil:
+			p.log.WarnContext(ctx, "failed to push notification",
+				slog.Int64("subscription_id", sub.ID), logger.Error(err))
+			errs = append(errs, err)
+		default:
+			delivered++
+		}
+	}
+
+	if delivered == 0 && len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// LoadVAPID returns the configured VAPID keys. When none are configured, a key pair is generated
+// on first use and stored so that browsers stay subscribed across restarts.
+func LoadVAPID(ctx context.Context, log *slog.Logger, dbConn *db.DB, cfg config.Push) (*webpush.VAPID, error) {
+	if cfg.VAPID.Public != "" || cfg.VAPID.Private != "" {
+		return webpush.NewVAPID(cfg.Subject, cfg.VAPID.Public, cfg.VAPID.Private)
+	}
+
+	keys, err := dbConn.Queries.GetVAPIDKeys(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		public, private, err := webpush.GenerateKeys()
+		if err != nil {
+			return nil, err
+		}
+
+		keys, err = dbConn.Queries.CreateVAPIDKeys(ctx, models.CreateVAPIDKeysParams{
+			PublicKey:  public,
+			PrivateKey: private,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to store VAPID keys: %w", err)
+		}
+
+		log.InfoContext(ctx, "generated VAPID keys", slog.String("public_key", public))
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get VAPID keys: %w", err)
+	}
+
+	return webpush.NewVAPID(cfg.Subject, keys.PublicKey, keys.PrivateKey)
+}
@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/config"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers sent with webhook requests
+const (
+	// HeaderTimestamp is the Unix time the request was signed at
+	HeaderTimestamp = "X-Cardmax-Timestamp"
+	// HeaderSignature is "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>"
+	HeaderSignature = "X-Cardmax-Signature"
+)
+
+// WebhookNotifier posts notifications as JSON to a URL
+type WebhookNotifier struct {
+	cfg config.Webhook
+}
+
+// NewWebhookNotifier creates a notifier posting to the configured URL.
+// Requests are signed when a secret is set.
+func NewWebhookNotifier(cfg config.Webhook) *WebhookNotifier {
+	return &WebhookNotifier{cfg: cfg}
+}
+
+// Notify posts the notification, failing unless the receiver responds with a 2xx status
+func (wh *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if wh.cfg.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+		req.Header.Set(HeaderTimestamp, timestamp)
+		req.Header.Set(HeaderSignature, "sha256="+Sign(wh.cfg.Secret, timestamp, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" with the secret. Receivers compute
+// it the same way to verify a request, and reject stale timestamps to prevent replays.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,160 @@
+package reminders
+
+import (
+	"context"
+	"fmt"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/internal/cards"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"github.com/pushkar-anand/cardmax/internal/notify"
+	"log/slog"
+	"time"
+)
+
+// Levels of a cap alert
+const (
+	capWarning = "warning"
+	capReached = "reached"
+)
+
+// checkCaps raises an alert once per cycle when the spend of a card's current cycle against a
+// capped reward rule crosses the warning threshold, and again when it reaches the cap
+func (w *Worker) checkCaps(ctx context.Context, now time.Time) error {
+	list, err := w.db.Queries.GetAllCards(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get cards: %w", err)
+	}
+
+	for _, card := range list {
+		if card.PredefinedCardKey == nil {
+			continue
+		}
+
+		predefined, ok := w.cards[*card.PredefinedCardKey]
+		if !ok {
+			continue
+		}
+
+		cycle := db.CardSchedule(card).CycleFor(now)
+
+		var txns []*models.Transaction
+
+		for i := range predefined.RewardRules {
+			rule := &predefined.RewardRules[i]
+			if !rule.SpendCap.IsPositive() {
+				continue
+			}
+
+			if txns == nil {
+				txns, err = w.db.Queries.GetCardTransactionsBetween(ctx, models.GetCardTransactionsBetweenParams{
+					CardID:   card.ID,
+					FromDate: cycle.Start.Format(time.DateOnly),
+					ToDate:   cycle.StatementDate.Format(time.DateOnly),
+				})
+				if err != nil {
+					return fmt.Errorf("failed to get transactions of card %d: %w", card.ID, err)
+				}
+			}
+
+			spent := capSpend(rule, txns)
+
+			var level string
+
+			switch {
+			case spent.Cmp(rule.SpendCap) >= 0:
+				level = capReached
+			case spent.Cmp(rule.SpendCap.Percent(w.cfg.Cap.Threshold, money.HalfEven)) >= 0:
+				level = capWarning
+			default:
+				continue
+			}
+
+			params := models.CapAlertExistsParams{
+				CardID:     card.ID,
+				Rule:       rule.Type + "|" + rule.EntityName + "|" + rule.Channel,
+				CycleStart: cycle.Start.Format(time.DateOnly),
+				Level:      level,
+			}
+
+			exists, err := w.db.Queries.CapAlertExists(ctx, params)
+			if err != nil {
+				return fmt.Errorf("failed to check cap alert: %w", err)
+			}
+
+			if exists != 0 {
+				continue
+			}
+
+			err = w.notifier.Notify(ctx, capNotification(card, rule, level, spent, cycle.StatementDate))
+			if err != nil {
+				// Leave the alert unrecorded so that it is retried
+				w.log.ErrorContext(ctx, "failed to deliver cap alert",
+					slog.Int64("card_id", card.ID), logger.Error(err))
+				continue
+			}
+
+			err = w.db.Queries.CreateCapAlert(ctx, models.CreateCapAlertParams(params))
+			if err != nil {
+				return fmt.Errorf("failed to record cap alert: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// capSpend sums the transactions the rule applies to, refunds included
+func capSpend(rule *cards.Reward, txns []*models.Transaction) money.Money {
+	var spent money.Money
+
+	for _, txn := range txns {
+		if rule.AppliesToSpend(deref(txn.Merchant), deref(txn.Category), deref(txn.Channel)) {
+			spent = spent.Add(txn.AmountMinor)
+		}
+	}
+
+	return spent
+}
+
+func capNotification(card *models.Card, rule *cards.Reward, level string, spent money.Money, statementDate time.Time) notify.Notification {
+	name := rule.EntityName
+	if rule.Channel != "" {
+		name += " (" + rule.Channel + ")"
+	}
+
+	title := fmt.Sprintf("%s: %s reward cap almost used", card.Name, name)
+	body := fmt.Sprintf("₹%s of the ₹%s cap on %s has been spent on your %s card ending %s this cycle. "+
+		"Spend beyond the cap until %s earns the base rate.",
+		spent.Decimal(), rule.SpendCap.Decimal(), name, card.Name, card.Last4Digits,
+		statementDate.Format(time.DateOnly))
+
+	if level == capReached {
+		title = fmt.Sprintf("%s: %s reward cap reached", card.Name, name)
+	}
+
+	return notify.Notification{
+		UserID: card.UserID,
+		Kind:   notify.KindCapAlert,
+		Title:  title,
+		Body:   body,
+		Data: map[string]any{
+			"card_id":        card.ID,
+			"rule_type":      rule.Type,
+			"entity_name":    rule.EntityName,
+			"level":          level,
+			"spent":          spent,
+			"spend_cap":      rule.SpendCap,
+			"statement_date": statementDate.Format(time.DateOnly),
+		},
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
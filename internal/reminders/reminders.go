@@ -0,0 +1,128 @@
+package reminders
+
+import (
+	"context"
+	"fmt"
+	"github.com/pushkar-anand/build-with-go/logger"
+	"github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/cards"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/notify"
+	"log/slog"
+	"time"
+)
+
+// Worker periodically generates the statements of closed cycles and raises a reminder for every
+// unpaid statement that is due within the configured number of days. It also alerts when a
+// cycle's spend approaches or reaches a reward cap.
+type Worker struct {
+	log      *slog.Logger
+	db       *db.DB
+	notifier notify.Notifier
+	// cards are the predefined cards keyed by card key
+	cards map[string]*cards.Card
+	cfg   config.Reminder
+}
+
+// NewWorker creates a reminder worker delivering reminders through the notifier
+func NewWorker(
+	log *slog.Logger,
+	dbConn *db.DB,
+	notifier notify.Notifier,
+	cardList []*cards.Card,
+	cfg config.Reminder,
+) *Worker {
+	byKey := make(map[string]*cards.Card, len(cardList))
+	for _, card := range cardList {
+		byKey[card.Key] = card
+	}
+
+	return &Worker{
+		log:      log,
+		db:       dbConn,
+		notifier: notifier,
+		cards:    byKey,
+		cfg:      cfg,
+	}
+}
+
+// Run checks for reminders on every interval until the context is cancelled.
+// Failures are logged and retried on the next interval.
+func (w *Worker) Run(ctx context.Context) error {
+	w.log.InfoContext(ctx, "starting reminder worker",
+		slog.Int("days", w.cfg.Days),
+		slog.Float64("cap_threshold", w.cfg.Cap.Threshold),
+		slog.Duration("interval", w.cfg.Interval))
+
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		err := w.check(ctx, time.Now())
+		if err != nil {
+			w.log.ErrorContext(ctx, "failed to check reminders", logger.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// check generates the statements of closed cycles, raises the reminders due on the date and
+// checks the reward caps
+func (w *Worker) check(ctx context.Context, now time.Time) error {
+	_, err := w.db.GenerateStatements(ctx, w.log, now)
+	if err != nil {
+		return fmt.Errorf("failed to generate statements: %w", err)
+	}
+
+	statements, err := w.db.Queries.GetStatementsToRemind(ctx, models.GetStatementsToRemindParams{
+		FromDate: now.Format(time.DateOnly),
+		ToDate:   now.AddDate(0, 0, w.cfg.Days).Format(time.DateOnly),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get statements to remind: %w", err)
+	}
+
+	for _, statement := range statements {
+		card, err := w.db.Queries.GetCardByID(ctx, statement.CardID)
+		if err != nil {
+			return fmt.Errorf("failed to get card %d: %w", statement.CardID, err)
+		}
+
+		remaining := statement.TotalDueMinor.Sub(statement.PaidMinor)
+
+		err = w.notifier.Notify(ctx, notify.Notification{
+			UserID: card.UserID,
+			Kind:   notify.KindPaymentDue,
+			Title:  fmt.Sprintf("%s payment due on %s", card.Name, statement.DueDate),
+			Body: fmt.Sprintf("₹%s is due on your %s card ending %s by %s (minimum ₹%s).",
+				remaining.Decimal(), card.Name, card.Last4Digits, statement.DueDate,
+				statement.MinimumDueMinor.Decimal()),
+			Data: map[string]any{
+				"card_id":      card.ID,
+				"statement_id": statement.ID,
+				"due_date":     statement.DueDate,
+				"total_due":    remaining,
+				"minimum_due":  statement.MinimumDueMinor,
+			},
+		})
+		if err != nil {
+			// Leave the statement unmarked so that the reminder is retried
+			w.log.ErrorContext(ctx, "failed to deliver payment reminder",
+				slog.Int64("statement_id", statement.ID), logger.Error(err))
+			continue
+		}
+
+		err = w.db.Queries.MarkStatementReminded(ctx, statement.ID)
+		if err != nil {
+			return fmt.Errorf("failed to mark statement %d reminded: %w", statement.ID, err)
+		}
+	}
+
+	return w.checkCaps(ctx, now)
+}
@@ -0,0 +1,318 @@
+package taxonomy
+
+import (
+	"context"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"math"
+	"strings"
+	"sync"
+)
+
+// How a descriptor was matched to its merchant
+const (
+	// MethodLearned is a mapping learned from the user's corrections
+	MethodLearned = "learned"
+	// MethodDictionary is a keyword or an alias of the taxonomy
+	MethodDictionary = "dictionary"
+	// MethodSimilar is a keyword the descriptor spells closely enough, e.g. "SWIGY"
+	MethodSimilar = "similar"
+)
+
+const (
+	// minSimilarity is the lowest similarity of a word to a keyword for the word to name the
+	// keyword's merchant
+	minSimilarity = 0.8
+	// minSimilarKeyword is the shortest keyword matched by similarity. Shorter keywords are a
+	// letter or two away from too many words.
+	minSimilarKeyword = 5
+)
+
+type (
+	// Normalised is the merchant a descriptor was matched to
+	Normalised struct {
+		// Descriptor is the cleaned descriptor, the key of learned mappings
+		Descriptor string  `json:"descriptor"`
+		Merchant   string  `json:"merchant"`
+		Category   string  `json:"category,omitempty"`
+		Method     string  `json:"method"`
+		Score      float64 `json:"score"`
+	}
+
+	// Normaliser matches statement descriptors to merchants: from the mappings learned from the
+	// user's corrections first, then the keywords and aliases of the taxonomy, and at last by
+	// the similarity of the descriptor's words to the keywords
+	Normaliser struct {
+		db       *db.DB
+		taxonomy *Taxonomy
+
+		mu      sync.RWMutex
+		learned map[learnedKey]*models.MerchantMapping
+	}
+
+	// learnedKey is a cleaned descriptor as mapped by a user, whose mappings only apply to them
+	learnedKey struct {
+		userID     int64
+		descriptor string
+	}
+)
+
+// NewNormaliser creates a normaliser over the taxonomy with the mappings learned so far
+func NewNormaliser(ctx context.Context, dbConn *db.DB, t *Taxonomy) (*Normaliser, error) {
+	list, err := dbConn.Queries.GetMerchantMappings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merchant mappings: %w", err)
+	}
+
+	n := &Normaliser{
+		db:       dbConn,
+		taxonomy: t,
+		learned:  make(map[learnedKey]*models.MerchantMapping, len(list)),
+	}
+
+	for _, m := range list {
+		// Mappings are only unowned until the first user registers and claims them
+		if m.UserID != nil {
+			n.learned[learnedKey{userID: *m.UserID, descriptor: m.Descriptor}] = m
+		}
+	}
+
+	return n, nil
+}
+
+// Normalise returns the merchant a descriptor names for the user, and false when it names none
+// known. The mappings learned from the user's corrections apply to them only, none to a nil user.
+func (n *Normaliser) Normalise(userID *int64, descriptor string) (*Normalised, bool) {
+	cleaned := n.taxonomy.Clean(descriptor)
+	if cleaned == "" {
+		return nil, false
+	}
+
+	var learned *models.MerchantMapping
+
+	if userID != nil {
+		n.mu.RLock()
+		learned = n.learned[learnedKey{userID: *userID, descriptor: cleaned}]
+		n.mu.RUnlock()
+	}
+
+	if learned != nil {
+		res := &Normalised{Descriptor: cleaned, Merchant: learned.Merchant, Method: MethodLearned, Score: 1}
+		if learned.Category != nil {
+			res.Category = *learned.Category
+		}
+
+		return res, true
+	}
+
+	if m, ok := n.taxonomy.Match(cleaned); ok {
+		return &Normalised{
+			Descriptor: cleaned,
+			Merchant:   m.Name,
+			Category:   m.Category,
+			Method:     MethodDictionary,
+			Score:      1,
+		}, true
+	}
+
+	if m, score, ok := n.taxonomy.similar(cleaned); ok {
+		return &Normalised{
+			Descriptor: cleaned,
+			Merchant:   m.Name,
+			Category:   m.Category,
+			Method:     MethodSimilar,
+			Score:      score,
+		}, true
+	}
+
+	return nil, false
+}
+
+// Learn maps the descriptor to the merchant and category the user corrected it to. Nothing is
+// learned when the descriptor already normalises to them for the user.
+func (n *Normaliser) Learn(
+	ctx context.Context,
+	userID *int64,
+	descriptor, merchant string,
+	category *string,
+) error {
+	res, ok := n.Normalise(userID, descriptor)
+	if ok && res.Merchant == strings.ToLower(strings.TrimSpace(merchant)) &&
+		(category == nil || *category == res.Category) {
+		return nil
+	}
+
+	_, err := n.Teach(ctx, userID, descriptor, merchant, category)
+
+	return err
+}
+
+// Teach maps the cleaned descriptor to the merchant and category for the user, replacing what it
+// was mapped to. Nothing is mapped, and nil is returned, when either is empty or there is no user.
+func (n *Normaliser) Teach(
+	ctx context.Context,
+	userID *int64,
+	descriptor, merchant string,
+	category *string,
+) (*models.MerchantMapping, error) {
+	cleaned := n.taxonomy.Clean(descriptor)
+	merchant = strings.ToLower(strings.TrimSpace(merchant))
+
+	if userID == nil || cleaned == "" || merchant == "" {
+		return nil, nil
+	}
+
+	m, err := n.db.Queries.UpsertMerchantMapping(ctx, models.UpsertMerchantMappingParams{
+		UserID:     userID,
+		Descriptor: cleaned,
+		Merchant:   merchant,
+		Category:   category,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert merchant mapping: %w", err)
+	}
+
+	n.mu.Lock()
+	n.learned[learnedKey{userID: *userID, descriptor: m.Descriptor}] = m
+	n.mu.Unlock()
+
+	return m, nil
+}
+
+// Forget removes the user's mapping of the cleaned descriptor, reporting false when there was none
+func (n *Normaliser) Forget(ctx context.Context, userID *int64, descriptor string) (bool, error) {
+	cleaned := n.taxonomy.Clean(descriptor)
+
+	if userID == nil {
+		return false, nil
+	}
+
+	rows, err := n.db.Queries.DeleteMerchantMapping(ctx, models.DeleteMerchantMappingParams{
+		UserID:     userID,
+		Descriptor: cleaned,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete merchant mapping: %w", err)
+	}
+
+	n.mu.Lock()
+	delete(n.learned, learnedKey{userID: *userID, descriptor: cleaned})
+	n.mu.Unlock()
+
+	return rows > 0, nil
+}
+
+// Clean reduces a descriptor to the words that name its merchant: lower case, without the
+// processor prefixes leading it, the noise words and the reference numbers. A descriptor of
+// nothing else is kept whole.
+func (t *Taxonomy) Clean(descriptor string) string {
+	words := tokens(descriptor)
+
+	for len(words) > 0 && t.prefixes[words[0]] {
+		words = words[1:]
+	}
+
+	cleaned := make([]string, 0, len(words))
+
+	for _, word := range words {
+		if t.noise[word] || reference(word) {
+			continue
+		}
+
+		cleaned = append(cleaned, word)
+	}
+
+	if len(cleaned) == 0 {
+		return strings.Join(tokens(descriptor), " ")
+	}
+
+	return strings.Join(cleaned, " ")
+}
+
+// similar returns the merchant whose keyword a word of the cleaned descriptor, or two adjacent
+// words written together, spells most closely. Ties go to the merchant listed first.
+func (t *Taxonomy) similar(cleaned string) (*Merchant, float64, bool) {
+	words := strings.Fields(cleaned)
+
+	candidates := make([]string, 0, 2*len(words))
+	for i, word := range words {
+		candidates = append(candidates, word)
+		if i > 0 {
+			candidates = append(candidates, words[i-1]+word)
+		}
+	}
+
+	var (
+		found *Merchant
+		best  float64
+	)
+
+	for _, m := range t.merchants {
+		for _, keyword := range m.keywords() {
+			kw := strings.Join(tokens(keyword), "")
+			if len(kw) < minSimilarKeyword {
+				continue
+			}
+
+			for _, c := range candidates {
+				score := similarity(c, kw)
+				if score >= minSimilarity && score > best {
+					found, best = m, score
+				}
+			}
+		}
+	}
+
+	return found, math.Round(best*100) / 100, found != nil
+}
+
+// similarity is one minus the edit distance between a and b over the length of the longer one
+func similarity(a, b string) float64 {
+	longest := max(len(a), len(b))
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(distance(a, b))/float64(longest)
+}
+
+// distance is the Levenshtein distance between a and b, which tokens keeps to single bytes
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+// reference reports whether a word is a reference or store number rather than a name, i.e. at
+// least half of it is digits
+func reference(word string) bool {
+	digits := 0
+	for _, r := range word {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+
+	return digits > 0 && 2*digits >= len(word)
+}
@@ -0,0 +1,223 @@
+// Package taxonomy names the merchants behind transaction descriptions, e.g. the "SWIGGY" of a
+// card alert, with the entity names the reward rules of the card catalog use
+package taxonomy
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/cards"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// minSubstringKeyword is the shortest keyword matched inside a word, e.g. "swiggy" in
+// "SWIGGYINSTAMART". Shorter keywords, such as "jio", only match whole words.
+const minSubstringKeyword = 5
+
+type (
+	// Merchant is a merchant of the taxonomy
+	Merchant struct {
+		// Name is the entity name Merchant reward rules refer to the merchant by, e.g. swiggy
+		Name string `json:"name"`
+		// Category is the entity name of the merchant's category, e.g. food_delivery
+		Category string `json:"category,omitempty"`
+		// Keywords are the words descriptions name the merchant with, matched case-insensitively
+		Keywords []string `json:"keywords"`
+	}
+
+	// Aliases are the cleaning rules and alternate names of merchants in an aliases file
+	Aliases struct {
+		// Prefixes are the words of payment processors and channels that lead descriptors, e.g.
+		// the "PAYU" of "PAYU*SWIGGY", dropped from the start of a descriptor
+		Prefixes []string `json:"prefixes"`
+		// Noise are the words that say nothing of the merchant, such as cities and company
+		// suffixes, dropped wherever they are in a descriptor
+		Noise []string `json:"noise"`
+		// Names are the alternate names of merchants keyed by merchant name, such as the legal
+		// name a descriptor may carry instead of the brand, e.g. "bundl technologies" for swiggy
+		Names map[string][]string `json:"aliases"`
+	}
+
+	// Taxonomy matches descriptions to merchants
+	Taxonomy struct {
+		merchants []*Merchant
+		prefixes  map[string]bool
+		noise     map[string]bool
+	}
+)
+
+// Parse parses the merchants in the data/taxonomy directory and the cleaning rules and aliases
+// in the data/aliases directory. Merchants of the reward rules of the card catalog that no file
+// lists are added with their entity name as the keyword.
+func Parse(data embed.FS, cardList []*cards.Card) (*Taxonomy, error) {
+	entries, err := data.ReadDir("data/taxonomy")
+	if err != nil {
+		return nil, fmt.Errorf("error reading taxonomy dir: %w", err)
+	}
+
+	t := &Taxonomy{
+		prefixes: make(map[string]bool),
+		noise:    make(map[string]bool),
+	}
+	known := make(map[string]*Merchant)
+
+	for _, entry := range entries {
+		fn := fmt.Sprintf("data/taxonomy/%s", entry.Name())
+
+		file, err := data.ReadFile(fn)
+		if err != nil {
+			return nil, fmt.Errorf("error reading taxonomy file %s: %w", fn, err)
+		}
+
+		var merchants []*Merchant
+
+		err = json.Unmarshal(file, &merchants)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling taxonomy %s: %w", fn, err)
+		}
+
+		for _, m := range merchants {
+			if m.Name == "" {
+				return nil, fmt.Errorf("merchant without a name in %s", fn)
+			}
+
+			m.Name = strings.ToLower(m.Name)
+			known[m.Name] = m
+			t.merchants = append(t.merchants, m)
+		}
+	}
+
+	for _, card := range cardList {
+		for _, rule := range card.RewardRules {
+			name := strings.ToLower(rule.EntityName)
+			if rule.Type != "Merchant" || known[name] != nil {
+				continue
+			}
+
+			m := &Merchant{Name: name}
+			known[name] = m
+			t.merchants = append(t.merchants, m)
+		}
+	}
+
+	err = t.parseAliases(data, known)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// parseAliases parses the files of the data/aliases directory. The aliases of a merchant are
+// added to its keywords, after its name when it has none.
+func (t *Taxonomy) parseAliases(data embed.FS, known map[string]*Merchant) error {
+	entries, err := data.ReadDir("data/aliases")
+	if err != nil {
+		return fmt.Errorf("error reading aliases dir: %w", err)
+	}
+
+	for _, entry := range entries {
+		fn := fmt.Sprintf("data/aliases/%s", entry.Name())
+
+		file, err := data.ReadFile(fn)
+		if err != nil {
+			return fmt.Errorf("error reading aliases file %s: %w", fn, err)
+		}
+
+		var a Aliases
+
+		err = json.Unmarshal(file, &a)
+		if err != nil {
+			return fmt.Errorf("error unmarshalling aliases %s: %w", fn, err)
+		}
+
+		for _, word := range a.Prefixes {
+			t.prefixes[strings.ToLower(word)] = true
+		}
+
+		for _, word := range a.Noise {
+			t.noise[strings.ToLower(word)] = true
+		}
+
+		// Sorted, so that the keywords of a merchant do not depend on the order of the map
+		names := slices.Sorted(maps.Keys(a.Names))
+
+		for _, name := range names {
+			m := known[strings.ToLower(name)]
+			if m == nil {
+				return fmt.Errorf("aliases of unknown merchant %q in %s", name, fn)
+			}
+
+			m.Keywords = append(m.keywords(), a.Names[name]...)
+		}
+	}
+
+	return nil
+}
+
+// Merchants returns the merchants of the taxonomy
+func (t *Taxonomy) Merchants() []*Merchant {
+	return t.merchants
+}
+
+// Match returns the merchant a description names. Keywords matching whole words are preferred
+// over keywords inside a word, and longer keywords over shorter ones, so that "AMAZON PRIME
+// VIDEO" is amazon_prime rather than amazon.
+func (t *Taxonomy) Match(description string) (*Merchant, bool) {
+	words := tokens(description)
+	if len(words) == 0 {
+		return nil, false
+	}
+
+	joined := " " + strings.Join(words, " ") + " "
+	compact := strings.Join(words, "")
+
+	var (
+		found *Merchant
+		best  int
+	)
+
+	for _, m := range t.merchants {
+		for _, keyword := range m.keywords() {
+			kw := tokens(keyword)
+			if len(kw) == 0 {
+				continue
+			}
+
+			// Whole word matches rank above every match inside a word
+			score := 0
+			length := len(strings.Join(kw, ""))
+
+			switch {
+			case strings.Contains(joined, " "+strings.Join(kw, " ")+" "):
+				score = 1000 + length
+			case length >= minSubstringKeyword && strings.Contains(compact, strings.Join(kw, "")):
+				score = length
+			}
+
+			if score > best {
+				found, best = m, score
+			}
+		}
+	}
+
+	return found, found != nil
+}
+
+// keywords returns the keywords of the merchant, its name when it has none
+func (m *Merchant) keywords() []string {
+	if len(m.Keywords) == 0 {
+		return []string{strings.ReplaceAll(m.Name, "_", " ")}
+	}
+
+	return m.Keywords
+}
+
+// tokens splits text into lower case words of letters, digits and ampersands
+func tokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '&')
+	})
+}
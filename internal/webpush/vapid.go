@@ -0,0 +1,127 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"time"
+)
+
+// VAPID identifies the application server to push services (RFC 8292)
+type VAPID struct {
+	// Subject is a mailto: or https: URL the push service can contact the operator at
+	Subject string
+
+	key    *ecdsa.PrivateKey
+	public []byte
+}
+
+// GenerateKeys creates a new VAPID key pair, encoded as unpadded base64url
+func GenerateKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate VAPID key: %w", err)
+	}
+
+	return encode(key.PublicKey().Bytes()), encode(key.Bytes()), nil
+}
+
+// NewVAPID parses a base64url private key, the 32 byte P-256 scalar, and checks that it
+// matches the public key, the 65 byte uncompressed point
+func NewVAPID(subject, publicKey, privateKey string) (*VAPID, error) {
+	d, err := decode(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	key, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	public := key.PublicKey().Bytes()
+	if encode(public) != publicKey {
+		return nil, fmt.Errorf("VAPID public key does not match the private key")
+	}
+
+	return &VAPID{
+		Subject: subject,
+		key: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(public[1:33]),
+				Y:     new(big.Int).SetBytes(public[33:]),
+			},
+			D: new(big.Int).SetBytes(d),
+		},
+		public: public,
+	}, nil
+}
+
+// PublicKey returns the public key as unpadded base64url, the applicationServerKey browsers
+// subscribe with
+func (v *VAPID) PublicKey() string {
+	return encode(v.public)
+}
+
+// authorization returns the Authorization header for a request to the endpoint: a JWT signed with
+// ES256 for the endpoint's origin, valid for 12 hours, along with the public key
+func (v *VAPID) authorization(endpoint string, now time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	header, err := json.Marshal(map[string]string{"typ": "JWT", "alg": "ES256"})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]any{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": now.Add(12 * time.Hour).Unix(),
+		"sub": v.Subject,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := encode(header) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+
+	r, s, err := ecdsa.Sign(rand.Reader, v.key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+
+	// ES256 signatures are r and s as 32 byte big-endian integers
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	return fmt.Sprintf("vapid t=%s.%s, k=%s", unsigned, encode(sig), v.PublicKey()), nil
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decode accepts base64url with or without padding, as browsers produce either
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(trimPadding(s))
+}
+
+func trimPadding(s string) string {
+	for len(s) > 0 && s[len(s)-1] == '=' {
+		s = s[:len(s)-1]
+	}
+
+	return s
+}
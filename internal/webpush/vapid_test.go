@@ -0,0 +1,157 @@
+package webpush
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// checkAuthorization verifies a VAPID Authorization header for the origin (RFC 8292)
+func checkAuthorization(t *testing.T, header, origin string, vapid *VAPID, now time.Time) {
+	t.Helper()
+
+	token, key, ok := strings.Cut(strings.TrimPrefix(header, "vapid t="), ", k=")
+	if !strings.HasPrefix(header, "vapid t=") || !ok {
+		t.Errorf("Authorization = %q, want vapid t=<token>, k=<key>", header)
+		return
+	}
+
+	if key != vapid.PublicKey() {
+		t.Errorf("k = %s, want the VAPID public key %s", key, vapid.PublicKey())
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Errorf("token %q is not a JWT", token)
+		return
+	}
+
+	var (
+		head   map[string]string
+		claims struct {
+			Aud string `json:"aud"`
+			Exp int64  `json:"exp"`
+			Sub string `json:"sub"`
+		}
+	)
+
+	for i, v := range []any{&head, &claims} {
+		b, err := decode(parts[i])
+		if err == nil {
+			err = json.Unmarshal(b, v)
+		}
+
+		if err != nil {
+			t.Errorf("failed to decode token part %d: %v", i, err)
+			return
+		}
+	}
+
+	if head["alg"] != "ES256" || head["typ"] != "JWT" {
+		t.Errorf("token header = %v, want an ES256 JWT", head)
+	}
+
+	if claims.Aud != origin {
+		t.Errorf("aud = %s, want %s", claims.Aud, origin)
+	}
+
+	if claims.Sub != vapid.Subject {
+		t.Errorf("sub = %s, want %s", claims.Sub, vapid.Subject)
+	}
+
+	// RFC 8292 limits tokens to 24 hours
+	if exp := time.Unix(claims.Exp, 0); exp.Before(now) || exp.After(now.Add(24*time.Hour)) {
+		t.Errorf("exp = %s, want within 24 hours of %s", exp, now)
+	}
+
+	sig, err := decode(parts[2])
+	if err != nil || len(sig) != 64 {
+		t.Errorf("signature is not 64 bytes: %v", err)
+		return
+	}
+
+	public, err := decode(key)
+	if err != nil || len(public) != 65 {
+		t.Errorf("k is not an uncompressed P-256 point: %v", err)
+		return
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	pub := &ecdsa.PublicKey{
+		Curve: vapid.key.Curve,
+		X:     new(big.Int).SetBytes(public[1:33]),
+		Y:     new(big.Int).SetBytes(public[33:]),
+	}
+
+	if !ecdsa.Verify(pub, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+		t.Error("token signature does not verify with k")
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	public, private, err := GenerateKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vapid, err := NewVAPID("mailto:cardmax@localhost", public, private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+
+	header, err := vapid.authorization("https://fcm.googleapis.com/fcm/send/abc?x=1", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkAuthorization(t, header, "https://fcm.googleapis.com", vapid, now)
+}
+
+func TestNewVAPID(t *testing.T) {
+	public, private, err := GenerateKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, _, err := GenerateKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		public  string
+		private string
+		valid   bool
+	}{
+		{name: "generated pair", public: public, private: private, valid: true},
+		{name: "padded private key", public: public, private: private + "=", valid: true},
+		{name: "mismatched public key", public: other, private: private},
+		{name: "malformed private key", public: public, private: "not a key"},
+		{name: "short private key", public: public, private: private[:20]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewVAPID("mailto:cardmax@localhost", tt.public, tt.private)
+
+			if tt.valid && err != nil {
+				t.Fatalf("NewVAPID failed: %v", err)
+			}
+
+			if !tt.valid && err == nil {
+				t.Fatal("NewVAPID succeeded, want an error")
+			}
+
+			if tt.valid && v.PublicKey() != tt.public {
+				t.Errorf("public key = %s, want %s", v.PublicKey(), tt.public)
+			}
+		})
+	}
+}
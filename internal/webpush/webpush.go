@@ -0,0 +1,262 @@
+// Package webpush sends Web Push messages: payloads are encrypted with aes128gcm (RFC 8291)
+// and requests are authenticated with VAPID (RFC 8292).
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recordSize is the record size of the encrypted content. Payloads are sent as a single record.
+const recordSize = 4096
+
+// MaxPayloadSize is the largest payload that fits a single record: the record size less the
+// header, the padding delimiter and the AEAD tag
+const MaxPayloadSize = recordSize - 86 - 1 - 16
+
+var (
+	// ErrGone is returned when the push service no longer accepts messages for the
+	// subscription, which should be removed
+	ErrGone = errors.New("push subscription is gone")
+	// ErrPayloadTooLarge is returned for payloads above MaxPayloadSize
+	ErrPayloadTooLarge = errors.New("push payload is too large")
+)
+
+type (
+	// Subscription is a browser's push subscription
+	Subscription struct {
+		Endpoint string
+		// P256dh is the user agent's public key as base64url
+		P256dh string
+		// Auth is the user agent's authentication secret as base64url
+		Auth string
+	}
+
+	// Sender sends push messages to subscriptions
+	Sender struct {
+		vapid  *VAPID
+		client *http.Client
+		// TTL is how long the push service keeps a message for an offline user agent
+		TTL time.Duration
+	}
+)
+
+// NewSender creates a sender authenticating with the VAPID keys
+func NewSender(vapid *VAPID, client *http.Client, ttl time.Duration) *Sender {
+	return &Sender{
+		vapid:  vapid,
+		client: client,
+		TTL:    ttl,
+	}
+}
+
+// Send encrypts the payload for the subscription and posts it to its endpoint
+func (s *Sender) Send(ctx context.Context, sub Subscription, payload []byte) error {
+	body, err := Encrypt(sub, payload)
+	if err != nil {
+		return err
+	}
+
+	auth, err := s.vapid.authorization(sub.Endpoint, time.Now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create push request: %w", err)
+	}
+
+	req.Header.Set("Authorization", auth)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(s.TTL.Seconds())))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send push message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrGone
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("push service responded with status %d: %s", resp.StatusCode, msg)
+	}
+
+	return nil
+}
+
+// Encrypt encrypts the payload for the subscription as a single aes128gcm record (RFC 8291)
+func Encrypt(sub Subscription, payload []byte) ([]byte, error) {
+	if len(payload) > MaxPayloadSize {
+		return nil, ErrPayloadTooLarge
+	}
+
+	// Every message is encrypted with a new key pair and salt
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate message key: %w", err)
+	}
+
+	salt := make([]byte, 16)
+
+	_, err = rand.Read(salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	return encrypt(sub, payload, asPrivate, salt)
+}
+
+// encrypt encrypts the payload with the key pair and salt of the message
+func encrypt(sub Subscription, payload []byte, asPrivate *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	uaPublicBytes, err := decode(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription key: %w", err)
+	}
+
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription key: %w", err)
+	}
+
+	authSecret, err := decode(sub.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription auth secret: %w", err)
+	}
+
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	ecdhSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive shared secret: %w", err)
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublicBytes...)
+	keyInfo = append(keyInfo, asPublic...)
+
+	ikm, err := hkdf.Key(sha256.New, ecdhSecret, authSecret, string(keyInfo), 32)
+	if err != nil {
+		return nil, err
+	}
+
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// The header is the salt, the record size and the sender's public key as the key ID
+	header := make([]byte, 0, 21+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	// 0x02 delimits the last (and only) record
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// Validate checks that the subscription's endpoint is an https URL of a public host, as the server
+// posts to it, and that its key and authentication secret are well formed
+func (s Subscription) Validate() error {
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Hostname() == "" || endpoint.User != nil {
+		return errors.New("endpoint is not an https URL")
+	}
+
+	if !PublicHost(endpoint.Hostname()) {
+		return fmt.Errorf("endpoint host %s is not public", endpoint.Hostname())
+	}
+
+	key, err := decode(s.P256dh)
+	if err == nil {
+		_, err = ecdh.P256().NewPublicKey(key)
+	}
+
+	if err != nil {
+		return fmt.Errorf("p256dh is not a P-256 public key: %w", err)
+	}
+
+	auth, err := decode(s.Auth)
+	if err != nil || len(auth) != 16 {
+		return errors.New("auth is not a 16 byte secret")
+	}
+
+	return nil
+}
+
+// PublicHost reports whether the host, a name or an address, is one of the internet rather than
+// of the server or its network. Names are only refused by their suffix, the addresses they
+// resolve to are checked by PublicAddr when connecting.
+func PublicHost(host string) bool {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return PublicAddr(addr)
+	}
+
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	// Names of a single label are of the local network
+	if !strings.Contains(host, ".") {
+		return false
+	}
+
+	for _, suffix := range []string{".localhost", ".local", ".internal", ".home.arpa", ".lan"} {
+		if strings.HasSuffix(host, suffix) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), not routed on the internet
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// PublicAddr reports whether the address is a global unicast address of the internet, refusing
+// loopback, private, link-local and shared addresses
+func PublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
@@ -9,8 +9,19 @@ import (
 	"github.com/pushkar-anand/build-with-go/http/response"
 	"github.com/pushkar-anand/build-with-go/logger"
 	"github.com/pushkar-anand/build-with-go/validator"
+	"github.com/pushkar-anand/cardmax/api/exports"
+	"github.com/pushkar-anand/cardmax/api/imports"
+	"github.com/pushkar-anand/cardmax/internal/auth"
 	"github.com/pushkar-anand/cardmax/internal/cards"
+	"github.com/pushkar-anand/cardmax/internal/categoriser"
 	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/fx"
+	"github.com/pushkar-anand/cardmax/internal/importer"
+	"github.com/pushkar-anand/cardmax/internal/mailbox"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"github.com/pushkar-anand/cardmax/internal/notify"
+	"github.com/pushkar-anand/cardmax/internal/reminders"
+	"github.com/pushkar-anand/cardmax/internal/taxonomy"
 	"github.com/pushkar-anand/cardmax/web"
 	"golang.org/x/sync/errgroup"
 	"log/slog"
@@ -83,13 +94,65 @@ func run(ctx context.Context) error {
 		return fmt.Errorf("failed to populate predefined cards: %w", err)
 	}
 
+	rates, err := fx.Parse(data)
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to parse exchange rates", logger.Error(err))
+		return fmt.Errorf("failed to parse exchange rates data: %w", err)
+	}
+
+	err = dbConn.PopulateExchangeRates(ctx, log, rates)
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to populate exchange rates", logger.Error(err))
+		return fmt.Errorf("failed to populate exchange rates: %w", err)
+	}
+
+	mappings, err := importer.ParseMappings(data)
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to parse import mappings", logger.Error(err))
+		return fmt.Errorf("failed to parse import mappings data: %w", err)
+	}
+
+	statementTemplates, err := importer.ParseTemplates(data)
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to parse statement templates", logger.Error(err))
+		return fmt.Errorf("failed to parse statement templates data: %w", err)
+	}
+
+	alertTemplates, err := importer.ParseAlertTemplates(data)
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to parse alert templates", logger.Error(err))
+		return fmt.Errorf("failed to parse alert templates data: %w", err)
+	}
+
+	merchants, err := taxonomy.Parse(data, parsedCards)
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to parse merchant taxonomy", logger.Error(err))
+		return fmt.Errorf("failed to parse merchant taxonomy data: %w", err)
+	}
+
+	normaliser, err := taxonomy.NewNormaliser(ctx, dbConn, merchants)
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to load merchant mappings", logger.Error(err))
+		return fmt.Errorf("failed to create merchant normaliser: %w", err)
+	}
+
+	suggester := categoriser.NewCategoriser(dbConn)
+
+	im := imports.NewImporter(dbConn, parsedCards, mappings, statementTemplates, alertTemplates, normaliser, suggester)
+	ex := exports.NewExporter(dbConn, parsedCards)
+
+	// Subcommands run instead of the server
+	if len(os.Args) > 1 {
+		return runCommand(ctx, log, dbConn, im, ex, suggester, os.Args[1:])
+	}
+
 	templates, err := web.GetTemplates()
 	if err != nil {
 		log.ErrorContext(ctx, "Failed to load templates", logger.Error(err))
 		return fmt.Errorf("failed to load templates data: %w", err)
 	}
 
-	v, err := validator.New()
+	v, err := validator.New(validator.WithCustomTags(money.ValidationTags))
 	if err != nil {
 		log.ErrorContext(ctx, "Failed to init validator", logger.Error(err))
 		return fmt.Errorf("failed to init validator: %w", err)
@@ -98,10 +161,40 @@ func run(ctx context.Context) error {
 	jw := response.NewJSONWriter(log)
 	rd := request.NewReader(log, v)
 
-	srv := NewServer(cfg.Server, log, templates, jw, rd, dbConn)
+	vapid, err := notify.LoadVAPID(ctx, log, dbConn, cfg.Notify.Push)
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to load VAPID keys", logger.Error(err))
+		return fmt.Errorf("failed to load VAPID keys: %w", err)
+	}
+
+	channels := notify.NewChannels(log, dbConn, vapid, cfg.Notify)
+	dispatcher := notify.NewDispatcher(log, dbConn, channels, cfg.Notify.Retry)
+
+	authn, err := auth.NewAuthenticator(log, dbConn, cfg.Auth)
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to set up authentication", logger.Error(err))
+		return fmt.Errorf("failed to create authenticator: %w", err)
+	}
+
+	srv := NewServer(cfg, log, templates, jw, rd, dbConn, parsedCards, dispatcher, vapid, im, ex, normaliser, authn)
 
 	g, ctx := errgroup.WithContext(ctx)
 
+	reminderWorker := reminders.NewWorker(log, dbConn, dispatcher, parsedCards, cfg.Reminder)
+	mailWorker := mailbox.NewWorker(log, dbConn, im, cfg.Mail)
+
+	g.Go(func() error {
+		return dispatcher.Run(ctx)
+	})
+
+	g.Go(func() error {
+		return reminderWorker.Run(ctx)
+	})
+
+	g.Go(func() error {
+		return mailWorker.Run(ctx)
+	})
+
 	g.Go(func() error {
 		err = Serve(ctx, srv)
 		if err != nil {
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/taxonomy"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// normalise returns the merchant the client's user gets for the descriptor, nil when it names none
+func normalise(t *testing.T, c *testClient, descriptor string) *taxonomy.Normalised {
+	t.Helper()
+
+	var m taxonomy.Normalised
+
+	status := c.do(http.MethodGet, "/api/merchants/normalise?descriptor="+url.QueryEscape(descriptor), nil, &m)
+
+	switch status {
+	case http.StatusOK:
+		return &m
+	case http.StatusNotFound:
+		return nil
+	}
+
+	t.Fatalf("normalising %q responded with %d", descriptor, status)
+
+	return nil
+}
+
+func TestNormalise(t *testing.T) {
+	c := register(t, "normalise-dictionary")
+
+	tests := []struct {
+		name       string
+		descriptor string
+		// want is nil when the descriptor names no known merchant
+		want *taxonomy.Normalised
+	}{
+		{
+			name:       "keyword",
+			descriptor: "SWIGGY",
+			want:       &taxonomy.Normalised{Descriptor: "swiggy", Merchant: "swiggy", Category: "food_delivery", Method: taxonomy.MethodDictionary, Score: 1},
+		},
+		{
+			name:       "processor prefix, city and reference",
+			descriptor: "PAYU*SWIGGY BANGALORE 12345678",
+			want:       &taxonomy.Normalised{Descriptor: "swiggy", Merchant: "swiggy", Category: "food_delivery", Method: taxonomy.MethodDictionary, Score: 1},
+		},
+		{
+			name:       "prefixes only lead",
+			descriptor: "POS 4521 UPI GURGAON BLINKIT",
+			want:       &taxonomy.Normalised{Descriptor: "upi blinkit", Merchant: "zomato", Category: "food_delivery", Method: taxonomy.MethodDictionary, Score: 1},
+		},
+		{
+			name:       "company suffixes",
+			descriptor: "Bundl Technologies Pvt Ltd",
+			want:       &taxonomy.Normalised{Descriptor: "bundl technologies", Merchant: "swiggy", Category: "food_delivery", Method: taxonomy.MethodDictionary, Score: 1},
+		},
+		{
+			name:       "alias",
+			descriptor: "UPI/FKRT/998877",
+			want:       &taxonomy.Normalised{Descriptor: "fkrt", Merchant: "flipkart", Category: "shopping", Method: taxonomy.MethodDictionary, Score: 1},
+		},
+		{
+			name:       "longer keyword",
+			descriptor: "AMAZON PRIME VIDEO MUMBAI",
+			want:       &taxonomy.Normalised{Descriptor: "amazon prime video", Merchant: "amazon_prime", Category: "subscriptions", Method: taxonomy.MethodDictionary, Score: 1},
+		},
+		{
+			name:       "keyword inside a word",
+			descriptor: "SWIGGYINSTAMART",
+			want:       &taxonomy.Normalised{Descriptor: "swiggyinstamart", Merchant: "swiggy", Category: "food_delivery", Method: taxonomy.MethodDictionary, Score: 1},
+		},
+		{
+			name:       "noise only is kept whole",
+			descriptor: "ONLINE PAYMENT 4521",
+		},
+		{
+			name:       "misspelt",
+			descriptor: "SWIGY BANGALORE",
+			want:       &taxonomy.Normalised{Descriptor: "swigy", Merchant: "swiggy", Category: "food_delivery", Method: taxonomy.MethodSimilar, Score: 0.83},
+		},
+		{
+			name:       "misspelt words written apart",
+			descriptor: "BIG BASKT",
+			want:       &taxonomy.Normalised{Descriptor: "big baskt", Merchant: "bigbasket", Category: "groceries", Method: taxonomy.MethodSimilar, Score: 0.89},
+		},
+		{
+			name:       "at the similarity threshold",
+			descriptor: "NYKKA",
+			want:       &taxonomy.Normalised{Descriptor: "nykka", Merchant: "nykaa", Category: "shopping", Method: taxonomy.MethodSimilar, Score: 0.8},
+		},
+		{name: "below the similarity threshold", descriptor: "FLPKRT"},
+		{name: "short keyword misspelt", descriptor: "JOI"},
+		{name: "unknown", descriptor: "CORNER BAKERY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalise(t, c, tt.descriptor)
+
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("normalised to %+v, want no merchant", *got)
+			case tt.want != nil && got == nil:
+				t.Errorf("no merchant, want %+v", *tt.want)
+			case tt.want != nil && *got != *tt.want:
+				t.Errorf("normalised to %+v, want %+v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestLearnedMappings(t *testing.T) {
+	alice, bob := register(t, "learned-alice"), register(t, "learned-bob")
+
+	category := "dining"
+
+	// The descriptor is cleaned to "swiggy" as it is stored
+	status := alice.do(http.MethodPut, "/api/merchants/mappings", map[string]any{
+		"descriptor": "PAYU*SWIGGY BANGALORE",
+		"merchant":   "Cloud Kitchen",
+		"category":   category,
+	}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("teaching a mapping responded with %d", status)
+	}
+
+	learned := &taxonomy.Normalised{Descriptor: "swiggy", Merchant: "cloud kitchen", Category: category, Method: taxonomy.MethodLearned, Score: 1}
+	dictionary := &taxonomy.Normalised{Descriptor: "swiggy", Merchant: "swiggy", Category: "food_delivery", Method: taxonomy.MethodDictionary, Score: 1}
+
+	t.Run("learned over the dictionary", func(t *testing.T) {
+		if got := normalise(t, alice, "SWIGGY MUMBAI 5521"); got == nil || *got != *learned {
+			t.Errorf("normalised to %+v, want %+v", got, *learned)
+		}
+	})
+
+	t.Run("only for the user", func(t *testing.T) {
+		if got := normalise(t, bob, "SWIGGY MUMBAI 5521"); got == nil || *got != *dictionary {
+			t.Errorf("normalised to %+v, want %+v", got, *dictionary)
+		}
+	})
+
+	t.Run("logged transactions", func(t *testing.T) {
+		card := addCard(t, alice, "Alice's learned Regalia", "HDFC-REGALIA-GOLD")
+
+		tests := []struct {
+			merchant string
+			want     string
+			category string
+		}{
+			{merchant: "Swiggy", want: "cloud kitchen", category: category},
+			{merchant: "Zomato", want: "zomato", category: "food_delivery"},
+			{merchant: "  Corner Bakery ", want: "corner bakery"},
+		}
+
+		for _, tt := range tests {
+			var txn models.Transaction
+
+			status := alice.do(http.MethodPost, "/api/transactions", map[string]any{
+				"card_id":  card.ID,
+				"merchant": tt.merchant,
+				"amount":   "250",
+			}, &txn)
+			if status != http.StatusCreated {
+				t.Fatalf("logging %q responded with %d", tt.merchant, status)
+			}
+
+			if got := deref(txn.Merchant); got != tt.want {
+				t.Errorf("merchant of %q = %q, want %q", tt.merchant, got, tt.want)
+			}
+
+			if got := deref(txn.Category); got != tt.category {
+				t.Errorf("category of %q = %q, want %q", tt.merchant, got, tt.category)
+			}
+		}
+	})
+
+	t.Run("forgotten", func(t *testing.T) {
+		status := alice.do(http.MethodDelete, "/api/merchants/mappings", map[string]any{"descriptor": "Swiggy"}, nil)
+		if status != http.StatusNoContent {
+			t.Fatalf("forgetting the mapping responded with %d", status)
+		}
+
+		if got := normalise(t, alice, "SWIGGY MUMBAI 5521"); got == nil || *got != *dictionary {
+			t.Errorf("normalised to %+v, want %+v", got, *dictionary)
+		}
+	})
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
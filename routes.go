@@ -4,8 +4,29 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/pushkar-anand/build-with-go/http/request"
 	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/cardmax/api/accounts"
 	"github.com/pushkar-anand/cardmax/api/cards"
+	"github.com/pushkar-anand/cardmax/api/drafts"
+	"github.com/pushkar-anand/cardmax/api/exports"
+	"github.com/pushkar-anand/cardmax/api/fx"
+	"github.com/pushkar-anand/cardmax/api/households"
+	"github.com/pushkar-anand/cardmax/api/imports"
+	"github.com/pushkar-anand/cardmax/api/merchants"
+	"github.com/pushkar-anand/cardmax/api/notifications"
+	"github.com/pushkar-anand/cardmax/api/payments"
+	"github.com/pushkar-anand/cardmax/api/push"
 	"github.com/pushkar-anand/cardmax/api/recommend"
+	"github.com/pushkar-anand/cardmax/api/statements"
+	"github.com/pushkar-anand/cardmax/api/tokens"
+	"github.com/pushkar-anand/cardmax/api/transactions"
+	"github.com/pushkar-anand/cardmax/api/usercards"
+	projectconfig "github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	internalcards "github.com/pushkar-anand/cardmax/internal/cards"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/notify"
+	"github.com/pushkar-anand/cardmax/internal/taxonomy"
+	"github.com/pushkar-anand/cardmax/internal/webpush"
 	"github.com/pushkar-anand/cardmax/web"
 	"log/slog"
 	"net/http"
@@ -17,16 +38,126 @@ func addRoutes(
 	tr *web.Renderer,
 	jsonWriter *response.JSONWriter,
 	reader *request.Reader,
+	cfg *projectconfig.Config,
+	dbConn *db.DB,
+	cardList []*internalcards.Card,
+	dispatcher *notify.Dispatcher,
+	vapid *webpush.VAPID,
+	im *imports.Importer,
+	ex *exports.Exporter,
+	normaliser *taxonomy.Normaliser,
+	authn *auth.Authenticator,
 ) {
+	router.Use(auth.CSRFMiddleware(logger, jsonWriter, cfg.Auth.Session.Secure))
+	router.Use(authn.Middleware(jsonWriter))
+
 	router.PathPrefix("/static/").Handler(web.StaticFilesHandler()).Methods(http.MethodGet)
 
 	router.HandleFunc("/", tr.HTMLHandler(web.TemplateHome)).Methods(http.MethodGet)
 	router.HandleFunc("/cards", tr.HTMLHandler(web.TemplateCards)).Methods(http.MethodGet)
 	router.HandleFunc("/recommend", tr.HTMLHandler(web.TemplateRecommend)).Methods(http.MethodGet)
 	router.HandleFunc("/transactions", tr.HTMLHandler(web.TemplateTransactions)).Methods(http.MethodGet)
+	router.HandleFunc("/drafts", tr.HTMLHandler(web.TemplateDrafts)).Methods(http.MethodGet)
+
+	router.HandleFunc(auth.LoginPath, accounts.LoginPageHandler(logger, tr, authn)).Methods(http.MethodGet)
+	router.HandleFunc(auth.LoginPath, accounts.LoginFormHandler(logger, reader, tr, authn)).Methods(http.MethodPost)
+	router.HandleFunc("/register", accounts.RegisterFormHandler(logger, reader, tr, authn)).Methods(http.MethodPost)
+	router.HandleFunc("/logout", accounts.LogoutFormHandler(logger, authn)).Methods(http.MethodPost)
 
 	apiRouter := router.PathPrefix("/api").Subrouter()
 
+	apiRouter.HandleFunc(
+		"/auth/login",
+		accounts.LoginHandler(logger, jsonWriter, reader, authn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/auth/register",
+		accounts.RegisterHandler(logger, jsonWriter, reader, authn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/auth/logout",
+		accounts.LogoutHandler(logger, jsonWriter, authn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/auth/me",
+		accounts.MeHandler(jsonWriter),
+	).Methods(http.MethodGet)
+
+	apiRouter.HandleFunc(
+		"/auth/passkeys",
+		accounts.GetPasskeysHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/auth/passkeys/register/begin",
+		accounts.BeginPasskeyRegistrationHandler(logger, jsonWriter, authn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/auth/passkeys/register/finish",
+		accounts.FinishPasskeyRegistrationHandler(logger, jsonWriter, reader, authn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/auth/passkeys/login/begin",
+		accounts.BeginPasskeyLoginHandler(logger, jsonWriter, authn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/auth/passkeys/login/finish",
+		accounts.FinishPasskeyLoginHandler(logger, jsonWriter, authn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/auth/passkeys/{id}",
+		accounts.DeletePasskeyHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodDelete)
+
+	apiRouter.HandleFunc(
+		"/tokens",
+		tokens.GetAllHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/tokens",
+		tokens.CreateHandler(logger, jsonWriter, reader, authn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/tokens/{id}",
+		tokens.DeleteHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodDelete)
+
+	apiRouter.HandleFunc(
+		"/household",
+		households.GetHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/household",
+		households.CreateHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/household",
+		households.DeleteHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodDelete)
+	apiRouter.HandleFunc(
+		"/household/members/{id}",
+		households.RemoveMemberHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodDelete)
+	apiRouter.HandleFunc(
+		"/household/invites",
+		households.GetInvitesHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/household/invites",
+		households.InviteHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/household/invites/{id}/accept",
+		households.AcceptInviteHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/household/invites/{id}",
+		households.DeleteInviteHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodDelete)
+	apiRouter.HandleFunc(
+		"/household/ledger",
+		households.LedgerHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodGet)
+
 	apiRouter.HandleFunc(
 		"/cards",
 		cards.GetAllHandler(logger, jsonWriter),
@@ -36,14 +167,222 @@ func addRoutes(
 		cards.GetByKeyHandler(logger, jsonWriter),
 	).Methods(http.MethodGet)
 
+	apiRouter.HandleFunc(
+		"/user-cards",
+		usercards.GetAllHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/user-cards",
+		usercards.CreateHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/user-cards/{id}",
+		usercards.GetByIDHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/user-cards/{id}",
+		usercards.UpdateHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodPut)
+
+	apiRouter.HandleFunc(
+		"/utilisation",
+		usercards.UtilisationHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodGet)
+
+	apiRouter.HandleFunc(
+		"/transactions",
+		transactions.GetAllHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/transactions",
+		transactions.CreateHandler(logger, jsonWriter, reader, dbConn, normaliser),
+	).Methods(http.MethodPost)
+
+	apiRouter.HandleFunc(
+		"/payments",
+		payments.GetAllHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/payments",
+		payments.CreateHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodPost)
+
+	apiRouter.HandleFunc(
+		"/imports/mappings",
+		imports.MappingsHandler(jsonWriter, im),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/imports/csv",
+		imports.CSVHandler(logger, jsonWriter, reader, im),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/imports/ofx",
+		imports.OFXHandler(logger, jsonWriter, reader, im),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/imports/qif",
+		imports.QIFHandler(logger, jsonWriter, reader, im),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/imports/templates",
+		imports.TemplatesHandler(jsonWriter, im),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/imports/pdf",
+		imports.PDFHandler(logger, jsonWriter, reader, im),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/imports/statements",
+		imports.StatementsHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/imports/alerts/templates",
+		imports.AlertTemplatesHandler(jsonWriter, im),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/imports/alerts",
+		imports.AlertsHandler(logger, jsonWriter, reader, im),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/imports/emails",
+		imports.EmailsHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodGet)
+
+	apiRouter.HandleFunc(
+		"/drafts",
+		drafts.GetAllHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/drafts/approve",
+		drafts.ApproveHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/drafts/discard",
+		drafts.DiscardHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/drafts/merge",
+		drafts.MergeHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/drafts/{id}",
+		drafts.UpdateHandler(logger, jsonWriter, reader, dbConn, normaliser),
+	).Methods(http.MethodPut)
+	apiRouter.HandleFunc(
+		"/drafts/{id}",
+		drafts.DeleteHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodDelete)
+
+	apiRouter.HandleFunc(
+		"/merchants/normalise",
+		merchants.NormaliseHandler(logger, jsonWriter, reader, normaliser),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/merchants/mappings",
+		merchants.GetMappingsHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/merchants/mappings",
+		merchants.TeachHandler(logger, jsonWriter, reader, normaliser),
+	).Methods(http.MethodPut)
+	apiRouter.HandleFunc(
+		"/merchants/mappings",
+		merchants.ForgetHandler(logger, jsonWriter, reader, normaliser),
+	).Methods(http.MethodDelete)
+
+	apiRouter.HandleFunc(
+		"/exports/ofx",
+		exports.OFXHandler(logger, jsonWriter, reader, ex),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/exports/qif",
+		exports.QIFHandler(logger, jsonWriter, reader, ex),
+	).Methods(http.MethodGet)
+
+	apiRouter.HandleFunc(
+		"/statements",
+		statements.GetAllHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/statements/generate",
+		statements.GenerateHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/statements/{id}",
+		statements.GetByIDHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/statements/{id}/payments",
+		statements.PayHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodPost)
+
+	apiRouter.HandleFunc(
+		"/notifications/preferences",
+		notifications.GetPreferencesHandler(logger, jsonWriter, dbConn, dispatcher),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/notifications/preferences",
+		notifications.UpdatePreferenceHandler(logger, jsonWriter, reader, dbConn, dispatcher),
+	).Methods(http.MethodPut)
+	apiRouter.HandleFunc(
+		"/notifications/deliveries",
+		notifications.GetDeliveriesHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/notifications/test",
+		notifications.TestHandler(logger, jsonWriter, dispatcher),
+	).Methods(http.MethodPost)
+
+	apiRouter.HandleFunc(
+		"/push/key",
+		push.KeyHandler(jsonWriter, vapid),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/push/subscriptions",
+		push.SubscribeHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/push/subscriptions",
+		push.UnsubscribeHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodDelete)
+
 	apiRouter.HandleFunc(
 		"/recommend",
-		recommend.GetRecommendationHandler(logger, jsonWriter, reader),
+		recommend.GetRecommendationHandler(logger, jsonWriter, reader, cfg.Recommend, dbConn, cardList, normaliser),
 	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/recommend/batch",
+		recommend.GetBatchRecommendationHandler(logger, jsonWriter, reader, cfg.Recommend, dbConn, cardList, normaliser),
+	).Methods(http.MethodPost)
+
+	apiRouter.HandleFunc(
+		"/fx/rates",
+		fx.GetAllRatesHandler(logger, jsonWriter, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/fx/rates/{currency}",
+		fx.UpdateRateHandler(logger, jsonWriter, reader, dbConn),
+	).Methods(http.MethodPut)
 
 	// HTML partials routes for htmx
 	apiRouter.HandleFunc(
 		"/recommend-html",
-		recommend.GetRecommendationHTMLHandler(logger, reader, tr),
+		recommend.GetRecommendationHTMLHandler(logger, reader, tr, cfg.Recommend, dbConn, cardList, normaliser),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/drafts-html",
+		drafts.ReviewHTMLHandler(logger, tr, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/drafts-html/{action:approve|discard|merge}",
+		drafts.ActionHTMLHandler(logger, reader, tr, dbConn),
+	).Methods(http.MethodPost)
+	apiRouter.HandleFunc(
+		"/drafts-html/{id:[0-9]+}/edit",
+		drafts.EditHTMLHandler(logger, tr, dbConn),
+	).Methods(http.MethodGet)
+	apiRouter.HandleFunc(
+		"/drafts-html/{id:[0-9]+}",
+		drafts.SaveHTMLHandler(logger, reader, tr, dbConn, normaliser),
 	).Methods(http.MethodPost)
 }
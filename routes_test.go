@@ -0,0 +1,648 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// addCard creates a card of the client's user
+func addCard(t *testing.T, c *testClient, name, key string) *models.Card {
+	t.Helper()
+
+	var card models.Card
+
+	status := c.do(http.MethodPost, "/api/user-cards", map[string]any{
+		"name":                name,
+		"issuer":              "HDFC Bank",
+		"last4_digits":        "1234",
+		"expiry_date":         "2030-12",
+		"card_type":           "credit",
+		"statement_day":       5,
+		"predefined_card_key": key,
+		"credit_limit":        "300000",
+	}, &card)
+	if status != http.StatusCreated {
+		t.Fatalf("creating card %s responded with %d", name, status)
+	}
+
+	return &card
+}
+
+// addDraft saves a draft spend on the card for review
+func addDraft(t *testing.T, cardID int64, description, amount string, externalID *string) *models.DraftTransaction {
+	t.Helper()
+
+	_, saved, err := srv.db.SaveDrafts(context.Background(), discard, nil, []models.CreateDraftTransactionParams{{
+		CardID:          cardID,
+		Source:          "csv",
+		Kind:            db.DraftKindSpend,
+		TransactionDate: "2026-10-03",
+		Description:     description,
+		AmountMinor:     money.MustParse(amount, money.INR),
+		ExternalID:      externalID,
+		Parser:          "test",
+		Confidence:      1,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return saved[0]
+}
+
+// pushSubscription is a browser push subscription of the endpoint with fresh keys
+func pushSubscription(t *testing.T, endpoint string) map[string]any {
+	t.Helper()
+
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := make([]byte, 16)
+	_, _ = rand.Read(secret)
+
+	return map[string]any{
+		"endpoint": endpoint,
+		"keys": map[string]string{
+			"p256dh": base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+			"auth":   base64.RawURLEncoding.EncodeToString(secret),
+		},
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		htmx     bool
+		status   int
+		location string
+		redirect string
+	}{
+		{name: "login page", method: http.MethodGet, path: "/login", status: http.StatusOK},
+		{name: "static file", method: http.MethodGet, path: "/static/manifest.json", status: http.StatusOK},
+		// Public API routes answer for themselves, here refusing an empty body
+		{name: "api login", method: http.MethodPost, path: "/api/auth/login", status: http.StatusBadRequest},
+		{name: "passkey login", method: http.MethodPost, path: "/api/auth/passkeys/login/begin", status: http.StatusOK},
+		{
+			name:     "page",
+			method:   http.MethodGet,
+			path:     "/transactions?card_id=1&from=2026-10-01",
+			status:   http.StatusSeeOther,
+			location: "/login?next=%2Ftransactions%3Fcard_id%3D1%26from%3D2026-10-01",
+		},
+		{name: "home page", method: http.MethodGet, path: "/", status: http.StatusSeeOther, location: "/login?next=%2F"},
+		// Only the exact public paths are public, not the routes next to them
+		{name: "account", method: http.MethodGet, path: "/api/auth/me", status: http.StatusUnauthorized},
+		{name: "api", method: http.MethodGet, path: "/api/user-cards", status: http.StatusUnauthorized},
+		{name: "api change", method: http.MethodPost, path: "/api/transactions", status: http.StatusUnauthorized},
+		{name: "passkey management", method: http.MethodGet, path: "/api/auth/passkeys", status: http.StatusUnauthorized},
+		{
+			name:     "htmx",
+			method:   http.MethodGet,
+			path:     "/api/drafts-html",
+			htmx:     true,
+			status:   http.StatusUnauthorized,
+			redirect: "/login",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClient(t)
+
+			r := c.request(tt.method, tt.path, nil)
+			if tt.htmx {
+				r.Header.Set("HX-Request", "true")
+			}
+
+			w := c.send(r)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+
+			if got := w.Header().Get("Location"); got != tt.location {
+				t.Errorf("Location = %q, want %q", got, tt.location)
+			}
+
+			if got := w.Header().Get("HX-Redirect"); got != tt.redirect {
+				t.Errorf("HX-Redirect = %q, want %q", got, tt.redirect)
+			}
+		})
+	}
+
+	t.Run("logged in", func(t *testing.T) {
+		c := register(t, "middleware")
+
+		if status := c.do(http.MethodGet, "/api/user-cards", nil, nil); status != http.StatusOK {
+			t.Errorf("status = %d, want %d", status, http.StatusOK)
+		}
+
+		if status := c.do(http.MethodPost, "/api/auth/logout", nil, nil); status != http.StatusNoContent {
+			t.Fatalf("logging out responded with %d", status)
+		}
+
+		if status := c.do(http.MethodGet, "/api/user-cards", nil, nil); status != http.StatusUnauthorized {
+			t.Errorf("status after logging out = %d, want %d", status, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		c := newClient(t)
+		c.cookies["cardmax_session"] = &http.Cookie{Name: "cardmax_session", Value: "forged"}
+
+		if status := c.do(http.MethodGet, "/api/user-cards", nil, nil); status != http.StatusUnauthorized {
+			t.Errorf("status = %d, want %d", status, http.StatusUnauthorized)
+		}
+	})
+}
+
+// TestUserIsolation checks that a user can neither read nor change what another user owns
+func TestUserIsolation(t *testing.T) {
+	alice, mallory := register(t, "isolation-alice"), register(t, "isolation-mallory")
+
+	card := addCard(t, alice, "Alice's Regalia", "HDFC-REGALIA-GOLD")
+	own := addCard(t, mallory, "Mallory's Regalia", "HDFC-REGALIA-GOLD")
+
+	status := alice.do(http.MethodPost, "/api/transactions", map[string]any{
+		"card_id":  card.ID,
+		"merchant": "Swiggy",
+		"amount":   "450",
+	}, nil)
+	if status != http.StatusCreated {
+		t.Fatalf("logging a transaction responded with %d", status)
+	}
+
+	draft := addDraft(t, card.ID, "ZOMATO", "620", nil)
+
+	endpoint := "https://fcm.googleapis.com/fcm/send/isolation-alice"
+	subscription := pushSubscription(t, endpoint)
+	if status := alice.do(http.MethodPost, "/api/push/subscriptions", subscription, nil); status != http.StatusCreated {
+		t.Fatalf("subscribing responded with %d", status)
+	}
+
+	cardPath := fmt.Sprintf("/api/user-cards/%d", card.ID)
+	draftPath := fmt.Sprintf("/api/drafts/%d", draft.ID)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		status int
+	}{
+		{name: "read card", method: http.MethodGet, path: cardPath, status: http.StatusNotFound},
+		{
+			name:   "change card",
+			method: http.MethodPut,
+			path:   cardPath,
+			body: map[string]any{
+				"name": "Mine now", "issuer": "HDFC Bank", "last4_digits": "9999", "expiry_date": "2030-12",
+				"card_type": "credit", "statement_day": 5, "credit_limit": "1",
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "log transaction on card",
+			method: http.MethodPost,
+			path:   "/api/transactions",
+			body:   map[string]any{"card_id": card.ID, "merchant": "Amazon", "amount": "999"},
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "change draft",
+			method: http.MethodPut,
+			path:   draftPath,
+			body: map[string]any{
+				"card_id": own.ID, "kind": "spend", "transaction_date": "2026-10-03", "description": "ZOMATO",
+				"amount": "620",
+			},
+			status: http.StatusNotFound,
+		},
+		{name: "delete draft", method: http.MethodDelete, path: draftPath, status: http.StatusNotFound},
+		{
+			name:   "take over push subscription",
+			method: http.MethodPost,
+			path:   "/api/push/subscriptions",
+			body:   pushSubscription(t, endpoint),
+			status: http.StatusConflict,
+		},
+		{
+			name:   "approve draft",
+			method: http.MethodPost,
+			path:   "/api/drafts/approve",
+			body:   map[string]any{"ids": []int64{draft.ID}},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "discard draft",
+			method: http.MethodPost,
+			path:   "/api/drafts/discard",
+			body:   map[string]any{"ids": []int64{draft.ID}},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "unsubscribe push subscription",
+			method: http.MethodDelete,
+			path:   "/api/push/subscriptions",
+			body:   map[string]any{"endpoint": endpoint},
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := mallory.do(tt.method, tt.path, tt.body, nil); status != tt.status {
+				t.Errorf("status = %d, want %d", status, tt.status)
+			}
+		})
+	}
+
+	t.Run("lists", func(t *testing.T) {
+		var cards struct {
+			Cards []*models.Card `json:"cards"`
+		}
+
+		mallory.do(http.MethodGet, "/api/user-cards", nil, &cards)
+
+		if len(cards.Cards) != 1 || cards.Cards[0].ID != own.ID {
+			t.Errorf("cards listed to another user: %d", len(cards.Cards))
+		}
+
+		for _, path := range []string{"/api/transactions", fmt.Sprintf("/api/transactions?card_id=%d", card.ID)} {
+			var txns struct {
+				Transactions []*models.Transaction `json:"transactions"`
+			}
+
+			mallory.do(http.MethodGet, path, nil, &txns)
+
+			if len(txns.Transactions) != 0 {
+				t.Errorf("%s lists %d transactions of another user", path, len(txns.Transactions))
+			}
+		}
+
+		var drafts struct {
+			Drafts []*models.DraftTransaction `json:"drafts"`
+		}
+
+		mallory.do(http.MethodGet, "/api/drafts", nil, &drafts)
+
+		if len(drafts.Drafts) != 0 {
+			t.Errorf("%d drafts of another user listed", len(drafts.Drafts))
+		}
+	})
+
+	t.Run("owner still has everything", func(t *testing.T) {
+		ctx := context.Background()
+
+		stored, err := srv.db.Queries.GetUserCardByID(ctx, models.GetUserCardByIDParams{ID: card.ID, UserID: &alice.User.ID})
+		if err != nil || stored.Name != "Alice's Regalia" {
+			t.Errorf("card %+v changed: %v", stored, err)
+		}
+
+		txns, err := srv.db.Queries.GetTransactions(ctx, &alice.User.ID)
+		if err != nil || len(txns) != 1 {
+			t.Errorf("owner has %d transactions, want 1: %v", len(txns), err)
+		}
+
+		drafts, err := srv.db.Queries.GetUserDraftTransactions(ctx, &alice.User.ID)
+		if err != nil || len(drafts) != 1 || drafts[0].Description != "ZOMATO" {
+			t.Errorf("owner's drafts changed: %v", err)
+		}
+
+		subs, err := srv.db.Queries.GetPushSubscriptions(ctx, &alice.User.ID)
+		keys := subscription["keys"].(map[string]string)
+		if err != nil || !slices.ContainsFunc(subs, func(s *models.PushSubscription) bool {
+			return s.Endpoint == endpoint && s.P256dh == keys["p256dh"] && s.Auth == keys["auth"]
+		}) {
+			t.Errorf("owner's push subscription removed or changed: %v", err)
+		}
+	})
+}
+
+// TestCSRFRotation checks that a CSRF token set before logging in, e.g. planted by another site, is
+// replaced by a new one for the session
+func TestCSRFRotation(t *testing.T) {
+	const planted = "csrf-token-planted-before-logging-in"
+
+	credentials := map[string]string{"username": "rotation", "password": "correct horse battery"}
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "register", path: "/api/auth/register", status: http.StatusCreated},
+		{name: "login", path: "/api/auth/login", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClient(t)
+			c.cookies[auth.CSRFCookieName] = &http.Cookie{Name: auth.CSRFCookieName, Value: planted}
+			c.csrf = planted
+
+			if status := c.do(http.MethodPost, tt.path, credentials, nil); status != tt.status {
+				t.Fatalf("status = %d, want %d", status, tt.status)
+			}
+
+			if cookie := c.cookies[auth.CSRFCookieName]; cookie == nil || cookie.Value == planted || c.csrf != cookie.Value {
+				t.Fatalf("CSRF cookie %v and header %q, want a new token", cookie, c.csrf)
+			}
+
+			// Another site knowing the planted token can no longer send it along the browser's cookie
+			rotated := c.csrf
+			c.csrf = planted
+
+			if status := c.do(http.MethodPost, "/api/auth/logout", nil, nil); status != http.StatusForbidden {
+				t.Errorf("logging out with the planted token responded with %d, want %d", status, http.StatusForbidden)
+			}
+
+			c.csrf = rotated
+
+			if status := c.do(http.MethodPost, "/api/auth/logout", nil, nil); status != http.StatusNoContent {
+				t.Errorf("logging out with the new token responded with %d", status)
+			}
+		})
+	}
+}
+
+// createToken creates a personal access token of the client's user granting the scopes, returning
+// the token and its id
+func createToken(t *testing.T, c *testClient, scopes ...string) (string, int64) {
+	t.Helper()
+
+	var created struct {
+		ID    int64  `json:"id"`
+		Token string `json:"token"`
+	}
+
+	status := c.do(http.MethodPost, "/api/tokens", map[string]any{"name": strings.Join(scopes, " "), "scopes": scopes}, &created)
+	if status != http.StatusCreated {
+		t.Fatalf("creating a token of %v responded with %d", scopes, status)
+	}
+
+	return created.Token, created.ID
+}
+
+// bearer returns an anonymous client sending the access token
+func bearer(t *testing.T, token string) *testClient {
+	c := newClient(t)
+	c.header.Set("Authorization", "Bearer "+token)
+
+	return c
+}
+
+// TestAccessTokens checks that tokens reach the routes of their scopes and nothing else
+func TestAccessTokens(t *testing.T) {
+	c := register(t, "tokens")
+	card := addCard(t, c, "Regalia", "HDFC-REGALIA-GOLD")
+
+	scopes := []string{auth.ScopeReadCards, auth.ScopeWriteTransactions, auth.ScopeRecommend}
+
+	tokens := make(map[string]string, len(scopes))
+	for _, scope := range scopes {
+		tokens[scope], _ = createToken(t, c, scope)
+	}
+
+	purchase := map[string]any{"merchant": "Swiggy", "amount": "450"}
+
+	// The routes available to tokens, keyed by their method and path template as the scopes are
+	routes := map[string]struct {
+		path   string
+		body   any
+		scope  string
+		status int
+	}{
+		"GET /api/cards":           {path: "/api/cards", scope: auth.ScopeReadCards, status: http.StatusOK},
+		"GET /api/cards/{key}":     {path: "/api/cards/HDFC-REGALIA-GOLD", scope: auth.ScopeReadCards, status: http.StatusOK},
+		"GET /api/user-cards":      {path: "/api/user-cards", scope: auth.ScopeReadCards, status: http.StatusOK},
+		"GET /api/user-cards/{id}": {path: fmt.Sprintf("/api/user-cards/%d", card.ID), scope: auth.ScopeReadCards, status: http.StatusOK},
+		"GET /api/utilisation":     {path: "/api/utilisation", scope: auth.ScopeReadCards, status: http.StatusOK},
+		"POST /api/transactions": {
+			path:   "/api/transactions",
+			body:   map[string]any{"card_id": card.ID, "merchant": "Swiggy", "amount": "450"},
+			scope:  auth.ScopeWriteTransactions,
+			status: http.StatusCreated,
+		},
+		"POST /api/recommend": {path: "/api/recommend", body: purchase, scope: auth.ScopeRecommend, status: http.StatusOK},
+		"POST /api/recommend/batch": {
+			path:   "/api/recommend/batch",
+			body:   map[string]any{"items": []any{purchase}},
+			scope:  auth.ScopeRecommend,
+			status: http.StatusOK,
+		},
+	}
+
+	for route, tt := range routes {
+		method, _, _ := strings.Cut(route, " ")
+
+		for _, scope := range scopes {
+			t.Run(route+" with "+scope, func(t *testing.T) {
+				want := http.StatusForbidden
+				if scope == tt.scope {
+					want = tt.status
+				}
+
+				if status := bearer(t, tokens[scope]).do(method, tt.path, tt.body, nil); status != want {
+					t.Errorf("status = %d, want %d", status, want)
+				}
+			})
+		}
+	}
+
+	t.Run("unmapped routes", func(t *testing.T) {
+		all, _ := createToken(t, c, scopes...)
+		b := bearer(t, all)
+
+		// Variables of the path templates, valid so that the routes are matched
+		vars := strings.NewReplacer("{key}", "HDFC-REGALIA-GOLD", "{currency}", "USD",
+			"{action:approve|discard|merge}", "approve", "{id:[0-9]+}", "1", "{id}", "1")
+
+		err := srv.handler.(*mux.Router).Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			template, err := route.GetPathTemplate()
+			if err != nil {
+				return nil
+			}
+
+			methods, err := route.GetMethods()
+			if err != nil {
+				return nil
+			}
+
+			for _, method := range methods {
+				if _, ok := routes[method+" "+template]; ok {
+					continue
+				}
+
+				path := vars.Replace(template)
+
+				w := b.send(b.request(method, path, nil))
+				if w.Code != http.StatusForbidden || w.Header().Get("WWW-Authenticate") != `Bearer error="insufficient_scope"` {
+					t.Errorf("%s %s responded with %d, want %d", method, path, w.Code, http.StatusForbidden)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		b := bearer(t, "cmx_unknown")
+
+		w := b.send(b.request(http.MethodGet, "/api/cards", nil))
+		if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != `Bearer error="invalid_token"` {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		_, token, err := srv.authn.CreateToken(context.Background(), c.User.ID, "expired",
+			[]string{auth.ScopeReadCards}, time.Now().Add(-time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b := bearer(t, token)
+
+		w := b.send(b.request(http.MethodGet, "/api/cards", nil))
+		if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != `Bearer error="invalid_token"` {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		token, id := createToken(t, c, auth.ScopeReadCards)
+
+		if status := bearer(t, token).do(http.MethodGet, "/api/cards", nil, nil); status != http.StatusOK {
+			t.Fatalf("status before revoking = %d, want %d", status, http.StatusOK)
+		}
+
+		// Only its user revokes a token
+		other := register(t, "tokens-other")
+
+		if status := other.do(http.MethodDelete, fmt.Sprintf("/api/tokens/%d", id), nil, nil); status != http.StatusNotFound {
+			t.Errorf("revoking another user's token responded with %d, want %d", status, http.StatusNotFound)
+		}
+
+		if status := c.do(http.MethodDelete, fmt.Sprintf("/api/tokens/%d", id), nil, nil); status != http.StatusNoContent {
+			t.Fatalf("revoking responded with %d", status)
+		}
+
+		if status := bearer(t, token).do(http.MethodGet, "/api/cards", nil, nil); status != http.StatusUnauthorized {
+			t.Errorf("status after revoking = %d, want %d", status, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("only the hash is stored", func(t *testing.T) {
+		records, err := srv.db.Queries.GetUserAccessTokens(context.Background(), c.User.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, scope := range scopes {
+			token := tokens[scope]
+			hash := sha256.Sum256([]byte(token))
+
+			i := slices.IndexFunc(records, func(r *models.AccessToken) bool { return r.TokenHash == hex.EncodeToString(hash[:]) })
+			if i < 0 {
+				t.Fatalf("no token of %s stored by its hash", scope)
+			}
+
+			if stored := fmt.Sprintf("%+v", *records[i]); strings.Contains(stored, token) {
+				t.Errorf("token stored as %s", stored)
+			}
+
+			if records[i].Hint != token[len(token)-4:] {
+				t.Errorf("hint = %q, want the last characters of the token", records[i].Hint)
+			}
+		}
+
+		var listed struct {
+			Tokens []map[string]any `json:"tokens"`
+		}
+
+		c.do(http.MethodGet, "/api/tokens", nil, &listed)
+
+		for _, token := range listed.Tokens {
+			if _, ok := token["token"]; ok {
+				t.Errorf("token %v listed", token["id"])
+			}
+		}
+	})
+}
+
+// TestNotificationPreferences checks that the destinations of the configured account are neither
+// listed to nor set up by other users
+func TestNotificationPreferences(t *testing.T) {
+	owner, other := register(t, notifyUser), register(t, "notify-other")
+
+	tests := []struct {
+		name     string
+		c        *testClient
+		channels []string
+		// status is that of a preference for each channel
+		status map[string]int
+	}{
+		{
+			name:     "owner",
+			c:        owner,
+			channels: []string{"log", "push", "webhook"},
+			status:   map[string]int{"log": http.StatusOK, "push": http.StatusOK, "webhook": http.StatusOK},
+		},
+		{
+			name:     "other user",
+			c:        other,
+			channels: []string{"log", "push"},
+			// Channels that are not set up can be switched off ahead of time
+			status: map[string]int{
+				"log": http.StatusOK, "push": http.StatusOK, "webhook": http.StatusUnprocessableEntity,
+				"email": http.StatusOK,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prefs struct {
+				Channels []string `json:"channels"`
+			}
+
+			tt.c.do(http.MethodGet, "/api/notifications/preferences", nil, &prefs)
+
+			if !slices.Equal(prefs.Channels, tt.channels) {
+				t.Errorf("channels = %v, want %v", prefs.Channels, tt.channels)
+			}
+
+			for channel, want := range tt.status {
+				status := tt.c.do(http.MethodPut, "/api/notifications/preferences", map[string]any{
+					"kind": "*", "channel": channel, "enabled": false,
+				}, nil)
+				if status != want {
+					t.Errorf("preference for %s responded with %d, want %d", channel, status, want)
+				}
+			}
+		})
+	}
+}
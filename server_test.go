@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/pushkar-anand/build-with-go/http/request"
+	"github.com/pushkar-anand/build-with-go/http/response"
+	"github.com/pushkar-anand/build-with-go/validator"
+	"github.com/pushkar-anand/cardmax/api/exports"
+	"github.com/pushkar-anand/cardmax/api/imports"
+	projectconfig "github.com/pushkar-anand/cardmax/config"
+	"github.com/pushkar-anand/cardmax/internal/auth"
+	"github.com/pushkar-anand/cardmax/internal/cards"
+	"github.com/pushkar-anand/cardmax/internal/categoriser"
+	"github.com/pushkar-anand/cardmax/internal/db"
+	"github.com/pushkar-anand/cardmax/internal/db/models"
+	"github.com/pushkar-anand/cardmax/internal/fx"
+	"github.com/pushkar-anand/cardmax/internal/importer"
+	"github.com/pushkar-anand/cardmax/internal/money"
+	"github.com/pushkar-anand/cardmax/internal/notify"
+	"github.com/pushkar-anand/cardmax/internal/taxonomy"
+	"github.com/pushkar-anand/cardmax/web"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testServer is the server with every route, on a database shared by the tests of the package as
+// the database is opened once per process
+type testServer struct {
+	db      *db.DB
+	authn   *auth.Authenticator
+	handler http.Handler
+}
+
+var (
+	srv     *testServer
+	discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "cardmax")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	srv, err = newTestServer(context.Background(), filepath.Join(dir, "test.db"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// notifyUser is the account the webhook of the test server belongs to
+const notifyUser = "notify-owner"
+
+// newTestServer sets the server up as run does, with registration open, a webhook owned by
+// notifyUser and without its workers
+func newTestServer(ctx context.Context, path string) (*testServer, error) {
+	log := discard
+
+	cfg := &projectconfig.Config{DB: projectconfig.DB{Path: path}}
+	cfg.Auth.Registration = true
+	cfg.Notify.Webhook.URL = "https://hooks.example.com/cardmax"
+	cfg.Notify.User = notifyUser
+
+	err := cfg.SetDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	dbConn, err := db.New(ctx, log, &db.Config{Path: cfg.DB.Path})
+	if err != nil {
+		return nil, err
+	}
+
+	cardList, err := cards.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dbConn.PopulatePredefinedCards(ctx, log, cardList)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := fx.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dbConn.PopulateExchangeRates(ctx, log, rates)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings, err := importer.ParseMappings(data)
+	if err != nil {
+		return nil, err
+	}
+
+	statementTemplates, err := importer.ParseTemplates(data)
+	if err != nil {
+		return nil, err
+	}
+
+	alertTemplates, err := importer.ParseAlertTemplates(data)
+	if err != nil {
+		return nil, err
+	}
+
+	merchants, err := taxonomy.Parse(data, cardList)
+	if err != nil {
+		return nil, err
+	}
+
+	normaliser, err := taxonomy.NewNormaliser(ctx, dbConn, merchants)
+	if err != nil {
+		return nil, err
+	}
+
+	suggester := categoriser.NewCategoriser(dbConn)
+	im := imports.NewImporter(dbConn, cardList, mappings, statementTemplates, alertTemplates, normaliser, suggester)
+	ex := exports.NewExporter(dbConn, cardList)
+
+	templates, err := web.GetTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := validator.New(validator.WithCustomTags(money.ValidationTags))
+	if err != nil {
+		return nil, err
+	}
+
+	vapid, err := notify.LoadVAPID(ctx, log, dbConn, cfg.Notify.Push)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := notify.NewChannels(log, dbConn, vapid, cfg.Notify)
+	dispatcher := notify.NewDispatcher(log, dbConn, channels, cfg.Notify.Retry)
+
+	authn, err := auth.NewAuthenticator(log, dbConn, cfg.Auth)
+	if err != nil {
+		return nil, err
+	}
+
+	router := mux.NewRouter()
+
+	addRoutes(router, log, templates, response.NewJSONWriter(log), request.NewReader(log, v), cfg, dbConn,
+		cardList, dispatcher, vapid, im, ex, normaliser, authn)
+
+	return &testServer{db: dbConn, authn: authn, handler: router}, nil
+}
+
+// testClient sends requests to the test server as a browser does: keeping its cookies and
+// repeating the CSRF token of the last response
+type testClient struct {
+	t       *testing.T
+	cookies map[string]*http.Cookie
+	csrf    string
+	header  http.Header
+	// User is the user the client registered as, nil for an anonymous client
+	User *models.User
+}
+
+func newClient(t *testing.T) *testClient {
+	return &testClient{t: t, cookies: make(map[string]*http.Cookie), header: make(http.Header)}
+}
+
+// register creates a user and returns a client logged in as them
+func register(t *testing.T, username string) *testClient {
+	t.Helper()
+
+	c := newClient(t)
+
+	status := c.do(http.MethodPost, "/api/auth/register", map[string]string{
+		"username": username,
+		"password": "correct horse battery",
+	}, nil)
+	if status != http.StatusCreated {
+		t.Fatalf("registering %s responded with %d", username, status)
+	}
+
+	user, err := srv.db.Queries.GetUserByUsername(context.Background(), username)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.User = user
+
+	return c
+}
+
+// request builds a request with the body encoded as JSON, unless it is a string sent as a form
+func (c *testClient) request(method, path string, body any) *http.Request {
+	c.t.Helper()
+
+	var r *http.Request
+
+	switch b := body.(type) {
+	case nil:
+		r = httptest.NewRequest(method, path, nil)
+	case string:
+		r = httptest.NewRequest(method, path, strings.NewReader(b))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+
+		r = httptest.NewRequest(method, path, bytes.NewReader(data))
+		r.Header.Set("Content-Type", "application/json")
+	}
+
+	for _, cookie := range c.cookies {
+		r.AddCookie(cookie)
+	}
+
+	if c.csrf != "" {
+		r.Header.Set(auth.CSRFHeader, c.csrf)
+	}
+
+	for name, values := range c.header {
+		r.Header[name] = values
+	}
+
+	return r
+}
+
+// upload sends the file as the "file" field of a multipart form along with the fields, decoding
+// the JSON response into out as do does
+func (c *testClient) upload(path string, fields map[string]string, file []byte, out any) int {
+	c.t.Helper()
+
+	var body bytes.Buffer
+
+	mw := multipart.NewWriter(&body)
+
+	for name, value := range fields {
+		_ = mw.WriteField(name, value)
+	}
+
+	fw, err := mw.CreateFormFile("file", "upload")
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	_, _ = fw.Write(file)
+	_ = mw.Close()
+
+	r := c.request(http.MethodPost, path, nil)
+	r.Body = io.NopCloser(&body)
+	r.ContentLength = int64(body.Len())
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	w := c.send(r)
+
+	if out != nil && w.Code < 300 {
+		err := json.Unmarshal(w.Body.Bytes(), out)
+		if err != nil {
+			c.t.Fatalf("POST %s: failed to decode response %s: %v", path, w.Body, err)
+		}
+	}
+
+	return w.Code
+}
+
+// send serves the request, keeping the cookies and CSRF token of the response
+func (c *testClient) send(r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	srv.handler.ServeHTTP(w, r)
+
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.MaxAge < 0 || cookie.Value == "" {
+			delete(c.cookies, cookie.Name)
+			continue
+		}
+
+		c.cookies[cookie.Name] = cookie
+	}
+
+	if token := w.Header().Get(auth.CSRFHeader); token != "" {
+		c.csrf = token
+	}
+
+	return w
+}
+
+// do sends a request and decodes the JSON response into out when it is not nil, returning the
+// status of the response
+func (c *testClient) do(method, path string, body, out any) int {
+	c.t.Helper()
+
+	w := c.send(c.request(method, path, body))
+
+	if out != nil && w.Code < 300 {
+		err := json.Unmarshal(w.Body.Bytes(), out)
+		if err != nil {
+			c.t.Fatalf("%s %s: failed to decode response %s: %v", method, path, w.Body, err)
+		}
+	}
+
+	return w.Code
+}
@@ -12,6 +12,48 @@ document.addEventListener('DOMContentLoaded', function() {
                 console.error('Service Worker registration failed:', error);
             });
     }
+
+    const pushButton = document.getElementById('enable-push');
+    if (pushButton) {
+        if ('serviceWorker' in navigator && 'PushManager' in window) {
+            pushButton.addEventListener('click', () => {
+                PushNotifications.enable()
+                    .then(() => Utils.showSuccess('Notifications enabled on this device'))
+                    .catch(error => Utils.showError(`Could not enable notifications: ${error.message}`));
+            });
+        } else {
+            pushButton.disabled = true;
+            pushButton.textContent = 'Notifications not supported';
+        }
+    }
+
+    const passkeyButton = document.getElementById('add-passkey');
+    if (passkeyButton) {
+        if (window.PublicKeyCredential) {
+            passkeyButton.addEventListener('click', () => {
+                const name = document.getElementById('passkey-name').value.trim() || 'Passkey';
+                Passkeys.register(name)
+                    .then(() => Utils.showSuccess('Passkey added, you can now log in with it'))
+                    .catch(error => Utils.showError(`Could not add the passkey: ${error.message}`));
+            });
+        } else {
+            passkeyButton.disabled = true;
+            passkeyButton.textContent = 'Passkeys not supported';
+        }
+    }
+
+    const passkeyLogin = document.getElementById('passkey-login');
+    if (passkeyLogin) {
+        if (window.PublicKeyCredential) {
+            passkeyLogin.addEventListener('click', () => {
+                Passkeys.login()
+                    .then(() => window.location.assign(passkeyLogin.dataset.next || '/'))
+                    .catch(error => Utils.showError(`Could not log in with a passkey: ${error.message}`));
+            });
+        } else {
+            passkeyLogin.hidden = true;
+        }
+    }
 });
 
 // API Endpoints
@@ -22,7 +64,129 @@ const API = {
     cardRule: (cardId, ruleId) => `/api/cards/${cardId}/rewards/${ruleId}`,
     recommend: '/api/recommend',
     transactions: '/api/transactions',
-    transaction: (id) => `/api/transactions/${id}`
+    transaction: (id) => `/api/transactions/${id}`,
+    pushKey: '/api/push/key',
+    pushSubscriptions: '/api/push/subscriptions',
+    passkeyRegisterBegin: '/api/auth/passkeys/register/begin',
+    passkeyRegisterFinish: (name) => `/api/auth/passkeys/register/finish?name=${encodeURIComponent(name)}`,
+    passkeyLoginBegin: '/api/auth/passkeys/login/begin',
+    passkeyLoginFinish: '/api/auth/passkeys/login/finish'
+};
+
+// Headers of requests that change something, carrying the CSRF token of the page
+const CSRF = {
+    headers: (headers = {}) => ({
+        ...headers,
+        'X-CSRF-Token': document.querySelector('meta[name="csrf-token"]')?.content ?? ''
+    })
+};
+
+// Web Push notifications
+const PushNotifications = {
+    // Convert the base64url VAPID public key to the bytes pushManager expects
+    decodeKey: (key) => {
+        const padded = key + '='.repeat((4 - key.length % 4) % 4);
+        const raw = atob(padded.replace(/-/g, '+').replace(/_/g, '/'));
+        return Uint8Array.from(raw, c => c.charCodeAt(0));
+    },
+
+    // Ask for permission, subscribe this browser and register the subscription with the server
+    enable: async () => {
+        const permission = await Notification.requestPermission();
+        if (permission !== 'granted') {
+            throw new Error('permission was not granted');
+        }
+
+        const registration = await navigator.serviceWorker.ready;
+        const { public_key } = await (await fetch(API.pushKey)).json();
+
+        const subscription = await registration.pushManager.subscribe({
+            userVisibleOnly: true,
+            applicationServerKey: PushNotifications.decodeKey(public_key)
+        });
+
+        const response = await fetch(API.pushSubscriptions, {
+            method: 'POST',
+            headers: CSRF.headers({ 'Content-Type': 'application/json' }),
+            body: JSON.stringify(subscription)
+        });
+        if (!response.ok) {
+            throw new Error(`server responded with ${response.status}`);
+        }
+    }
+};
+
+// Passkeys, created and used through WebAuthn. The server sends and expects binary fields as
+// base64url strings, which the browser API takes and gives as ArrayBuffers.
+const Passkeys = {
+    decode: (value) => {
+        const padded = value + '='.repeat((4 - value.length % 4) % 4);
+        const raw = atob(padded.replace(/-/g, '+').replace(/_/g, '/'));
+        return Uint8Array.from(raw, c => c.charCodeAt(0)).buffer;
+    },
+
+    encode: (buffer) => btoa(String.fromCharCode(...new Uint8Array(buffer)))
+        .replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, ''),
+
+    // Serialize a PublicKeyCredential to the JSON the server verifies
+    serialize: (credential) => {
+        const response = {};
+        for (const key of ['clientDataJSON', 'attestationObject', 'authenticatorData', 'signature', 'userHandle']) {
+            if (credential.response[key]) {
+                response[key] = Passkeys.encode(credential.response[key]);
+            }
+        }
+        if (typeof credential.response.getTransports === 'function') {
+            response.transports = credential.response.getTransports();
+        }
+
+        return {
+            id: credential.id,
+            rawId: Passkeys.encode(credential.rawId),
+            type: credential.type,
+            authenticatorAttachment: credential.authenticatorAttachment,
+            clientExtensionResults: credential.getClientExtensionResults(),
+            response
+        };
+    },
+
+    post: async (url, body) => {
+        const response = await fetch(url, {
+            method: 'POST',
+            headers: CSRF.headers({ 'Content-Type': 'application/json' }),
+            body: body === undefined ? undefined : JSON.stringify(body)
+        });
+        const data = await response.json().catch(() => ({}));
+        if (!response.ok) {
+            throw new Error(data.detail || `server responded with ${response.status}`);
+        }
+        return data;
+    },
+
+    // Create a passkey on this device and add it to the account
+    register: async (name) => {
+        const { publicKey } = await Passkeys.post(API.passkeyRegisterBegin);
+
+        publicKey.challenge = Passkeys.decode(publicKey.challenge);
+        publicKey.user.id = Passkeys.decode(publicKey.user.id);
+        publicKey.excludeCredentials = (publicKey.excludeCredentials || [])
+            .map(c => ({ ...c, id: Passkeys.decode(c.id) }));
+
+        const credential = await navigator.credentials.create({ publicKey });
+        return Passkeys.post(API.passkeyRegisterFinish(name), Passkeys.serialize(credential));
+    },
+
+    // Log in with any passkey of this site, the browser asks which
+    login: async () => {
+        const { publicKey } = await Passkeys.post(API.passkeyLoginBegin);
+
+        publicKey.challenge = Passkeys.decode(publicKey.challenge);
+        publicKey.allowCredentials = (publicKey.allowCredentials || [])
+            .map(c => ({ ...c, id: Passkeys.decode(c.id) }));
+
+        const credential = await navigator.credentials.get({ publicKey });
+        return Passkeys.post(API.passkeyLoginFinish, Passkeys.serialize(credential));
+    }
 };
 
 // Utility Functions
@@ -6,9 +6,9 @@ const RecommendationUI = {
         
         fetch('/api/recommend', {
             method: 'POST',
-            headers: {
+            headers: CSRF.headers({
                 'Content-Type': 'application/json',
-            },
+            }),
             body: JSON.stringify({
                 merchant: merchant,
                 category: category,
@@ -82,6 +82,7 @@ const RecommendationUI = {
                         </div>
                         <div class="card-result-details ${!isBest ? 'collapsed' : ''}">
                             <div>On ${Utils.formatCurrency(amount)} purchase</div>
+                            ${result.holder ? `<div>${result.holder.own ? 'Your card' : `${result.holder.username}'s card`}: ${result.holder.card_name}</div>` : ''}
                             ${result.rule ? `<div>Special rate for ${result.rule.type}: ${result.rule.entity_name}</div>` : ''}
                             <div class="card-issuer">Issued by: ${card.issuer}</div>
                         </div>
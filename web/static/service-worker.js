@@ -1,6 +1,6 @@
 // Service Worker for CardMax PWA
 
-const CACHE_NAME = 'cardmax-cache-v1';
+const CACHE_NAME = 'cardmax-cache-v2';
 const urlsToCache = [
   '/',
   '/cards',
@@ -95,3 +95,38 @@ self.addEventListener('fetch', event => {
     );
   }
 });
+
+// Push event - show notifications sent by the server
+self.addEventListener('push', event => {
+  let notification = { title: 'CardMax', body: '' };
+  if (event.data) {
+    try {
+      notification = event.data.json();
+    } catch (e) {
+      notification.body = event.data.text();
+    }
+  }
+
+  event.waitUntil(
+    self.registration.showNotification(notification.title, {
+      body: notification.body,
+      tag: notification.kind,
+      data: notification.data || {}
+    })
+  );
+});
+
+// Notification click event - focus an open window or open the dashboard
+self.addEventListener('notificationclick', event => {
+  event.notification.close();
+
+  event.waitUntil(
+    clients.matchAll({ type: 'window', includeUncontrolled: true })
+      .then(windowClients => {
+        if (windowClients.length > 0) {
+          return windowClients[0].focus();
+        }
+        return clients.openWindow('/');
+      })
+  );
+});
@@ -3,6 +3,7 @@ package web
 import (
 	"embed"
 	"fmt"
+	"github.com/pushkar-anand/cardmax/internal/auth"
 	"html/template"
 	"net/http"
 	"time"
@@ -25,10 +26,13 @@ const (
 	TemplateCards        = "cards.html"
 	TemplateRecommend    = "recommend.html"
 	TemplateTransactions = "transactions.html"
+	TemplateDrafts       = "drafts.html"
+	TemplateLogin        = "login.html"
 )
 
 const (
 	PartialRecommendationResult Partial = "recommendation_result"
+	PartialDraftQueue           Partial = "draft_queue"
 )
 
 func GetTemplates() (*Renderer, error) {
@@ -42,12 +46,13 @@ func GetTemplates() (*Renderer, error) {
 	}, nil
 }
 
-func (tr *Renderer) Render(w http.ResponseWriter, name Template, data map[string]any) error {
+func (tr *Renderer) Render(w http.ResponseWriter, r *http.Request, name Template, data map[string]any) error {
 	if data == nil {
 		data = make(map[string]any)
 	}
 
 	data["Year"] = time.Now().Year()
+	data["CSRFToken"] = auth.CSRFToken(r.Context())
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
@@ -61,7 +66,7 @@ func (tr *Renderer) Render(w http.ResponseWriter, name Template, data map[string
 
 func (tr *Renderer) HTMLDataHandler(name Template, data map[string]any) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		err := tr.Render(w, name, data)
+		err := tr.Render(w, r, name, data)
 		if err != nil {
 
 		}
@@ -73,12 +78,13 @@ func (tr *Renderer) HTMLHandler(name Template) http.HandlerFunc {
 }
 
 // RenderPartial renders a partial template (from partials directory)
-func (tr *Renderer) RenderPartial(w http.ResponseWriter, name Partial, data map[string]interface{}) error {
+func (tr *Renderer) RenderPartial(w http.ResponseWriter, r *http.Request, name Partial, data map[string]interface{}) error {
 	if data == nil {
 		data = make(map[string]interface{})
 	}
 
 	data["Year"] = time.Now().Year()
+	data["CSRFToken"] = auth.CSRFToken(r.Context())
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 